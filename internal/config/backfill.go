@@ -0,0 +1,17 @@
+package config
+
+import (
+	"fmt"
+)
+
+// MaxBackfillRevisions bounds how far before the initial list the history is replayed from,
+// the watch cache of the API server keeps a few minutes of history at most
+const MaxBackfillRevisions = 100000
+
+// validateBackfill checks the number of revisions replayed before the initial list
+func validateBackfill(resource string, revisions int) error {
+	if revisions < 0 || revisions > MaxBackfillRevisions {
+		return fmt.Errorf("invalid BackfillRevisions %d for %s: must be between 0 and %d", revisions, resource, MaxBackfillRevisions)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/meshery/meshkit/utils"
+	"golang.org/x/exp/slices"
+)
+
+// BlackListEntry is an entry of the blacklist, either the name of an excluded resource, f.e. "pods.v1.",
+// or an object with Events. Objects naming a Resource keep it watched with their Events,
+// objects without one replace DefaultEvents for all resources which are not excluded,
+// f.e. [{"Events":["ADDED","DELETED"]},{"Resource":"pods.v1.","Events":["ADDED","MODIFIED","DELETED"]},"secrets.v1."]
+type BlackListEntry struct {
+	Resource string   `json:",omitempty" yaml:",omitempty"`
+	Events   []string `json:",omitempty" yaml:",omitempty"`
+	// how Resource matches the registered resources, see MatchModes, defaults to MatchExact
+	Match string `json:",omitempty" yaml:",omitempty"`
+}
+
+func (e *BlackListEntry) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &e.Resource); err == nil {
+		return nil
+	}
+	type entry BlackListEntry
+	return json.Unmarshal(data, (*entry)(e))
+}
+
+// parseBlackList splits the blacklist into the excluded resources, the events of resources
+// which are not excluded and the events replacing DefaultEvents
+func parseBlackList(raw string, meshsyncConfig *MeshsyncConfig, registry map[string]PipelineConfigs) error {
+	entries := make([]BlackListEntry, 0)
+	if err := utils.Unmarshal(raw, &entries); err != nil {
+		return err
+	}
+	for i := range entries {
+		if !isPattern(entries[i].Match) {
+			entries[i].Resource = normalizeResourceName(entries[i].Resource)
+		}
+	}
+	entries, err := expandBlackList(entries, registry)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		switch {
+		case entry.Resource == "" && len(entry.Events) == 0:
+			return errors.New("invalid blacklist entry: neither Resource nor Events given")
+		case entry.Resource == "":
+			if meshsyncConfig.BlackListDefaultEvents != nil {
+				return errors.New("invalid blacklist: Events without Resource given more than once")
+			}
+			events, err := normalizeEvents("the blacklist default events", entry.Events, nil)
+			if err != nil {
+				return fmt.Errorf("invalid blacklist: %w", err)
+			}
+			meshsyncConfig.BlackListDefaultEvents = events
+		case len(entry.Events) == 0:
+			if _, ok := meshsyncConfig.BlackListEvents[entry.Resource]; ok {
+				return fmt.Errorf("invalid blacklist: %s is both excluded and given Events", entry.Resource)
+			}
+			meshsyncConfig.BlackList = append(meshsyncConfig.BlackList, entry.Resource)
+		default:
+			if _, ok := meshsyncConfig.BlackListEvents[entry.Resource]; ok || slices.Contains(meshsyncConfig.BlackList, entry.Resource) {
+				return fmt.Errorf("invalid blacklist: %s given more than once", entry.Resource)
+			}
+			events, err := normalizeEvents(entry.Resource, entry.Events, nil)
+			if err != nil {
+				return fmt.Errorf("invalid blacklist: %w", err)
+			}
+			if meshsyncConfig.BlackListEvents == nil {
+				meshsyncConfig.BlackListEvents = make(map[string][]string)
+			}
+			meshsyncConfig.BlackListEvents[entry.Resource] = events
+		}
+	}
+	return nil
+}
+
+// expandBlackList replaces the entries matching resources by pattern with an entry per matched resource.
+// Resources blacklisted by name keep their own entry, a resource matched by several patterns takes the first.
+func expandBlackList(entries []BlackListEntry, registry map[string]PipelineConfigs) ([]BlackListEntry, error) {
+	expanded := make([]BlackListEntry, 0, len(entries))
+	aliases := newResourceNames(registry)
+	named := make(map[string]bool)
+	for _, entry := range entries {
+		if !isPattern(entry.Match) {
+			// bare and group qualified names are resolved later on, their errors are reported then
+			name, _ := aliases.resolve(entry.Resource)
+			named[name] = true
+		}
+	}
+
+	for _, entry := range entries {
+		if !isPattern(entry.Match) {
+			expanded = append(expanded, entry)
+			continue
+		}
+		if entry.Resource == "" {
+			return nil, fmt.Errorf("invalid blacklist entry: Match %s given without Resource", entry.Match)
+		}
+		names, err := matchingResources(entry.Resource, entry.Match, registry)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if named[name] {
+				continue
+			}
+			named[name] = true
+			expanded = append(expanded, BlackListEntry{Resource: name, Events: entry.Events})
+		}
+	}
+	return expanded, nil
+}
+
+// blackListMode reports whether the registry is watched except for the blacklisted resources,
+// otherwise only the whitelisted resources are watched
+func (c *MeshsyncConfig) blackListMode() bool {
+	return len(c.BlackList) > 0 || len(c.BlackListEvents) > 0 || len(c.BlackListDefaultEvents) > 0
+}
+
+// blackListEventsOf returns the events of a resource which is not excluded by the blacklist
+func (c *MeshsyncConfig) blackListEventsOf(resource string) []string {
+	if events, ok := c.BlackListEvents[resource]; ok {
+		return events
+	}
+	if len(c.BlackListDefaultEvents) > 0 {
+		return c.BlackListDefaultEvents
+	}
+	return c.defaultEvents()
+}
+
+// validateBlackListEvents rejects events given for resources which are not in the registry,
+// they would silently be lost
+func validateBlackListEvents(events map[string][]string, registry map[string]PipelineConfigs) error {
+	for resource := range events {
+		registered := false
+		for _, pipelines := range registry {
+			registered = registered || slices.ContainsFunc(pipelines, func(pc PipelineConfig) bool { return pc.Name == resource })
+		}
+		if !registered {
+			return fmt.Errorf("invalid blacklist: Events given for %s, which is not a registered resource", resource)
+		}
+	}
+	return nil
+}
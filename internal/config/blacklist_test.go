@@ -0,0 +1,100 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBlackListEvents(t *testing.T) {
+	testCases := []struct {
+		name              string
+		blacklist         string
+		expectErr         bool
+		expectedBlackList []string
+		expectedEvents    map[string][]string
+	}{
+		{
+			name:              "plain resources",
+			blacklist:         "[\"secrets.v1.\",\"pods.v1.\"]",
+			expectedBlackList: []string{"secrets.v1.", "pods.v1."},
+			expectedEvents:    map[string][]string{"namespaces.v1.": DefaultEvents, "services.v1.": DefaultEvents},
+		},
+		{
+			name:              "events of a resource",
+			blacklist:         "[\"secrets.v1.\",{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\",\"DELETED\"]}]",
+			expectedBlackList: []string{"secrets.v1."},
+			expectedEvents:    map[string][]string{"namespaces.v1.": DefaultEvents, "pods.v1.": {"ADDED", "DELETED"}, "services.v1.": DefaultEvents},
+		},
+		{
+			name:              "events replacing the defaults",
+			blacklist:         "[{\"Events\":[\"ADDED\",\"DELETED\"]},{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\",\"MODIFIED\",\"DELETED\"]},\"secrets.v1.\"]",
+			expectedBlackList: []string{"secrets.v1."},
+			expectedEvents:    map[string][]string{"namespaces.v1.": {"ADDED", "DELETED"}, "pods.v1.": {"ADDED", "MODIFIED", "DELETED"}, "services.v1.": {"ADDED", "DELETED"}},
+		},
+		{
+			name:           "nothing excluded",
+			blacklist:      "[{\"Events\":[\"ADDED\"]}]",
+			expectedEvents: map[string][]string{"namespaces.v1.": {"ADDED"}, "pods.v1.": {"ADDED"}, "secrets.v1.": {"ADDED"}, "services.v1.": {"ADDED"}},
+		},
+		{
+			name:      "neither resource nor events",
+			blacklist: "[\"secrets.v1.\",{}]",
+			expectErr: true,
+		},
+		{
+			name:      "events replacing the defaults twice",
+			blacklist: "[{\"Events\":[\"ADDED\"]},{\"Events\":[\"DELETED\"]}]",
+			expectErr: true,
+		},
+		{
+			name:      "excluded resource with events",
+			blacklist: "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]},\"pods.v1.\"]",
+			expectErr: true,
+		},
+		{
+			name:      "unknown event of a resource",
+			blacklist: "[{\"Resource\":\"pods.v1.\",\"Events\":[\"bogus\"]}]",
+			expectErr: true,
+		},
+		{
+			name:      "unknown event replacing the defaults",
+			blacklist: "[{\"Events\":[\"ADD\",\"UPDATE\"]}]",
+			expectErr: true,
+		},
+		{
+			name:      "events of an unregistered resource",
+			blacklist: "[{\"Resource\":\"widgets.v1.example.com\",\"Events\":[\"ADDED\"]}]",
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{"blacklist": tc.blacklist})
+			if tc.expectErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			if !reflect.DeepEqual(meshsyncConfig.BlackList, tc.expectedBlackList) {
+				t.Errorf("expected blacklist %v, got %v", tc.expectedBlackList, meshsyncConfig.BlackList)
+			}
+
+			events := make(map[string][]string)
+			for _, pipelines := range meshsyncConfig.Pipelines {
+				for _, pc := range pipelines {
+					if _, ok := tc.expectedEvents[pc.Name]; ok || pc.Name == "secrets.v1." {
+						events[pc.Name] = pc.Events
+					}
+				}
+			}
+			if !reflect.DeepEqual(events, tc.expectedEvents) {
+				t.Errorf("expected events %v, got %v", tc.expectedEvents, events)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/slices"
+)
+
+// parts of the objects trimmed before they enter the informer cache, see ResourceConfig.CacheTrim
+const (
+	// metadata.managedFields
+	CacheTrimManagedFields = "managedFields"
+	// the kubectl.kubernetes.io/last-applied-configuration annotation
+	CacheTrimLastApplied = "lastApplied"
+	// the status subtree
+	CacheTrimStatus = "status"
+)
+
+var CacheTrimFields = []string{CacheTrimManagedFields, CacheTrimLastApplied, CacheTrimStatus}
+
+// validateCacheTrim checks the trimmed parts of the pipeline's objects,
+// monitoring container changes requires the container statuses to be cached
+func validateCacheTrim(pc PipelineConfig) error {
+	for _, field := range pc.CacheTrim {
+		if !slices.Contains(CacheTrimFields, field) {
+			return fmt.Errorf("invalid CacheTrim %q for %s, expected one of %v", field, pc.Name, CacheTrimFields)
+		}
+	}
+	if slices.Contains(pc.CacheTrim, CacheTrimStatus) && len(pc.ContainerFields) > 0 {
+		return fmt.Errorf("invalid CacheTrim for %s: ContainerChanges monitors the status which is trimmed", pc.Name)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+
+	mesherykube "github.com/meshery/meshkit/utils/kubernetes"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// the client-side rate limit of the requests to the API server, elevated from the defaults of client-go
+// so the initial lists of large clusters are not throttled
+const (
+	DefaultClientQPS   = 100
+	DefaultClientBurst = 200
+)
+
+// ClientOptions tunes the clients MeshSync lists, watches and discovers the resources with
+type ClientOptions struct {
+	// sustained requests per second and the requests allowed at once above it
+	QPS   float32
+	Burst int
+	// DisableRateLimit sends the requests without client-side rate limit,
+	// leaving their flow control to the API Priority and Fairness of the API server
+	DisableRateLimit bool
+}
+
+// DefaultClientOptions are the client options of a default install
+var DefaultClientOptions = ClientOptions{QPS: DefaultClientQPS, Burst: DefaultClientBurst}
+
+// Validate rejects rate limits which would stall the clients
+func (o ClientOptions) Validate() error {
+	if o.DisableRateLimit {
+		return nil
+	}
+	if o.QPS <= 0 {
+		return ErrInitConfig(fmt.Errorf("invalid client QPS %v: must be positive", o.QPS))
+	}
+	if o.Burst <= 0 {
+		return ErrInitConfig(fmt.Errorf("invalid client Burst %d: must be positive", o.Burst))
+	}
+	return nil
+}
+
+// ApplyTo sets the rate limit of the clients created from restConfig
+func (o ClientOptions) ApplyTo(restConfig *rest.Config) {
+	if o.DisableRateLimit {
+		// a negative QPS creates clients without rate limiter
+		restConfig.QPS = -1
+		restConfig.Burst = 0
+		return
+	}
+	restConfig.QPS = o.QPS
+	restConfig.Burst = o.Burst
+}
+
+// NewKubeClient creates the clients like mesherykube.New does, rate limited by the client options
+func NewKubeClient(kubeConfig []byte, options ClientOptions) (*mesherykube.Client, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	restConfig, err := mesherykube.DetectKubeConfig(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	options.ApplyTo(restConfig)
+
+	kubeClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, mesherykube.ErrNewKubeClient(err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, mesherykube.ErrNewDynClient(err)
+	}
+	return &mesherykube.Client{
+		RestConfig:        *restConfig,
+		KubeClient:        kubeClient,
+		DynamicKubeClient: dynamicClient,
+	}, nil
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"testing"
+)
+
+const testKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://127.0.0.1:6443
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user:
+    token: test
+`
+
+func TestNewKubeClient(t *testing.T) {
+	testCases := []struct {
+		name          string
+		options       ClientOptions
+		expectedQPS   float32
+		expectedBurst int
+	}{
+		{name: "defaults", options: DefaultClientOptions, expectedQPS: DefaultClientQPS, expectedBurst: DefaultClientBurst},
+		{name: "configured", options: ClientOptions{QPS: 400, Burst: 800}, expectedQPS: 400, expectedBurst: 800},
+		{name: "rate limit disabled", options: ClientOptions{QPS: 400, Burst: 800, DisableRateLimit: true}, expectedQPS: -1, expectedBurst: 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			kubeClient, err := NewKubeClient([]byte(testKubeConfig), tc.options)
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			if kubeClient.RestConfig.QPS != tc.expectedQPS || kubeClient.RestConfig.Burst != tc.expectedBurst {
+				t.Errorf("expected QPS %v and Burst %d, got %v and %d", tc.expectedQPS, tc.expectedBurst, kubeClient.RestConfig.QPS, kubeClient.RestConfig.Burst)
+			}
+			if kubeClient.KubeClient == nil || kubeClient.DynamicKubeClient == nil {
+				t.Error("expected the clients to be created")
+			}
+		})
+	}
+
+	for _, options := range []ClientOptions{{QPS: 0, Burst: 100}, {QPS: -5, Burst: 100}, {QPS: 50, Burst: 0}} {
+		_, err := NewKubeClient([]byte(testKubeConfig), options)
+		if err == nil {
+			t.Errorf("expected error for %+v", options)
+			continue
+		}
+		if codeOf(err) != ErrInitConfigCode {
+			t.Errorf("expected error code %s for %+v, got %s", ErrInitConfigCode, options, codeOf(err))
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/meshery/meshkit/utils"
+	"golang.org/x/exp/slices"
+	"sigs.k8s.io/yaml"
+)
+
+// ErrConfigFileFormat is wrapped by the errors of configuration files which are neither YAML nor JSON
+// of the expected shape, a missing or unreadable file wraps the error of the file system instead
+var ErrConfigFileFormat = errors.New("malformed MeshSync configuration file")
+
+// GetMeshsyncCRDConfigsFromFile reads the watch-list from a YAML or JSON file of the same keys
+// as the spec.config of the custom resource, f.e. for CI and local reproduction.
+// The values may be given as strings, as in the custom resource, or natively, f.e. whitelist as a list.
+func GetMeshsyncCRDConfigsFromFile(path string) (*MeshsyncConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read MeshSync configuration file: %w", err)
+	}
+	data, err := parseConfigFile(path, content)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %w", ErrConfigFileFormat, path, err)
+	}
+	return PopulateConfigsFromMap(data)
+}
+
+// GetMeshsyncCRDConfigsFromDir reads the watch-list fragments of the .yaml, .yml and .json files of the directory,
+// f.e. one per team, and resolves them into one config, other files and subdirectories are ignored.
+// The fragments are merged in the order of their file names, see mergeConfigFragments.
+func GetMeshsyncCRDConfigsFromDir(path string) (*MeshsyncConfig, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read MeshSync configuration directory: %w", err)
+	}
+	fragments := make(map[string]map[string]string)
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !isConfigFile(entry.Name()) {
+			continue
+		}
+		file := filepath.Join(path, entry.Name())
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read MeshSync configuration file: %w", err)
+		}
+		fragment, err := parseConfigFile(file, content)
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrConfigFileFormat, file, err)
+		}
+		fragments[file] = fragment
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil, ErrInitConfig(fmt.Errorf("no configuration files in %s", path))
+	}
+
+	data, err := mergeConfigFragments(files, fragments)
+	if err != nil {
+		return nil, ErrInitConfig(err)
+	}
+	return PopulateConfigsFromMap(data)
+}
+
+func isConfigFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// mergeConfigFragments unites the fragments of the files in the given order:
+//   - the whitelist entries of a resource, of the same Group, Version, Scope and Match, are watched for the union of their Events,
+//     their other settings must be the same,
+//   - the blacklist entries of a resource, or the entries without one, are united the same way,
+//     exclusions are deduplicated,
+//   - any other setting must have the same value in every fragment giving it.
+func mergeConfigFragments(files []string, fragments map[string]map[string]string) (map[string]string, error) {
+	var (
+		whitelist []ResourceConfig
+		blacklist []BlackListEntry
+		data      = make(map[string]string)
+		origin    = make(map[string]string)
+	)
+	for _, file := range files {
+		for key, value := range fragments[file] {
+			switch key {
+			case "whitelist":
+				entries := make([]ResourceConfig, 0)
+				if err := utils.Unmarshal(value, &entries); err != nil {
+					return nil, fmt.Errorf("invalid whitelist in %s: %w", file, err)
+				}
+				for _, entry := range entries {
+					idx := slices.IndexFunc(whitelist, func(rc ResourceConfig) bool {
+						return rc.Resource == entry.Resource && rc.Group == entry.Group && rc.Version == entry.Version &&
+							rc.Scope == entry.Scope && rc.Match == entry.Match
+					})
+					if idx == -1 {
+						whitelist = append(whitelist, entry)
+						continue
+					}
+					merged, other := whitelist[idx], entry
+					merged.Events, other.Events = nil, nil
+					if !reflect.DeepEqual(merged, other) {
+						return nil, fmt.Errorf("conflicting whitelist settings for %s in %s", entry.Resource, file)
+					}
+					whitelist[idx].Events = unionEvents(whitelist[idx].Events, entry.Events)
+				}
+			case "blacklist":
+				entries := make([]BlackListEntry, 0)
+				if err := utils.Unmarshal(value, &entries); err != nil {
+					return nil, fmt.Errorf("invalid blacklist in %s: %w", file, err)
+				}
+				for _, entry := range entries {
+					// an exclusion and an entry with Events of the same resource are left for the validation to report
+					idx := slices.IndexFunc(blacklist, func(e BlackListEntry) bool {
+						return e.Resource == entry.Resource && e.Match == entry.Match && (len(e.Events) == 0) == (len(entry.Events) == 0)
+					})
+					if idx == -1 {
+						blacklist = append(blacklist, entry)
+						continue
+					}
+					blacklist[idx].Events = unionEvents(blacklist[idx].Events, entry.Events)
+				}
+			default:
+				if previous, ok := data[key]; ok && previous != value {
+					return nil, fmt.Errorf("conflicting %s in %s and %s", key, origin[key], file)
+				}
+				data[key] = value
+				origin[key] = file
+			}
+		}
+	}
+
+	if len(whitelist) > 0 {
+		raw, err := json.Marshal(whitelist)
+		if err != nil {
+			return nil, err
+		}
+		data["whitelist"] = string(raw)
+	}
+	if len(blacklist) > 0 {
+		raw, err := json.Marshal(blacklist)
+		if err != nil {
+			return nil, err
+		}
+		data["blacklist"] = string(raw)
+	}
+	return data, nil
+}
+
+// parseConfigFile returns the settings of the file as strings, JSON by the .json extension
+// or a leading brace, YAML otherwise
+func parseConfigFile(path string, content []byte) (map[string]string, error) {
+	raw := make(map[string]interface{})
+	ext := strings.ToLower(filepath.Ext(path))
+	isJSON := ext == ".json" || (ext != ".yaml" && ext != ".yml" && bytes.HasPrefix(bytes.TrimSpace(content), []byte("{")))
+	if isJSON {
+		if err := json.Unmarshal(content, &raw); err != nil {
+			return nil, err
+		}
+	} else if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]string, len(raw))
+	for key, value := range raw {
+		if setting, ok := value.(string); ok {
+			data[key] = setting
+			continue
+		}
+		setting, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		data[key] = string(setting)
+	}
+	return data, nil
+}
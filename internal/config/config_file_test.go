@@ -0,0 +1,123 @@
+package config
+
+import (
+	stderrors "errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfigsFromFile(t *testing.T) {
+	expected, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist":        "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\",\"DELETED\"]}]",
+		"strictNamespaces": "true",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name:    "YAML",
+			file:    "meshsync.yaml",
+			content: "whitelist:\n  - Resource: pods.v1.\n    Events: [ADDED, DELETED]\nstrictNamespaces: true\n",
+		},
+		{
+			name:    "JSON of strings",
+			file:    "meshsync.json",
+			content: `{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\",\"DELETED\"]}]", "strictNamespaces": "true"}`,
+		},
+		{
+			name:    "JSON detected by content",
+			file:    "meshsync.conf",
+			content: `{"whitelist": [{"Resource": "pods.v1.", "Events": ["ADDED", "DELETED"]}], "strictNamespaces": true}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.file)
+			if err := os.WriteFile(path, []byte(tc.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			meshsyncConfig, err := GetMeshsyncCRDConfigsFromFile(path)
+			if err != nil {
+				t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+			}
+			if !reflect.DeepEqual(meshsyncConfig, expected) {
+				t.Errorf("expected %+v, got %+v", expected, meshsyncConfig)
+			}
+		})
+	}
+}
+
+func TestConfigsFromFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	_, err := GetMeshsyncCRDConfigsFromFile(filepath.Join(dir, "missing.yaml"))
+	if !stderrors.Is(err, fs.ErrNotExist) || stderrors.Is(err, ErrConfigFileFormat) {
+		t.Errorf("expected a file not found error, got %v", err)
+	}
+
+	malformed := filepath.Join(dir, "malformed.json")
+	if err := os.WriteFile(malformed, []byte(`{"whitelist": [`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = GetMeshsyncCRDConfigsFromFile(malformed)
+	if !stderrors.Is(err, ErrConfigFileFormat) || stderrors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected a format error, got %v", err)
+	}
+
+	invalid := filepath.Join(dir, "invalid.yaml")
+	if err := os.WriteFile(invalid, []byte("whitelist: not a watch-list\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = GetMeshsyncCRDConfigsFromFile(invalid)
+	if codeOf(err) != ErrInitConfigCode {
+		t.Errorf("expected error code %s for an invalid watch-list, got %v", ErrInitConfigCode, err)
+	}
+}
+
+func TestConfigsFromDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"platform.yaml": "whitelist:\n  - Resource: pods.v1.\n    Events: [ADDED, DELETED]\n  - Resource: namespaces.v1.\n    Events: [ADDED]\nstrictNamespaces: true\n",
+		"team-a.json":   `{"whitelist": [{"Resource": "pods.v1.", "Events": ["MODIFIED", "ADDED"]}, {"Resource": "services.v1.", "Events": ["ADDED"]}]}`,
+		"README.md":     "not a watch-list",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "archive.yaml"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist":        "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\",\"DELETED\",\"MODIFIED\"]},{\"Resource\":\"namespaces.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]",
+		"strictNamespaces": "true",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	meshsyncConfig, err := GetMeshsyncCRDConfigsFromDir(dir)
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	if !reflect.DeepEqual(meshsyncConfig, expected) {
+		t.Errorf("expected %+v, got %+v", expected, meshsyncConfig)
+	}
+
+	// a fragment setting a global setting differently
+	if err := os.WriteFile(filepath.Join(dir, "team-b.yaml"), []byte("strictNamespaces: false\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetMeshsyncCRDConfigsFromDir(dir); codeOf(err) != ErrInitConfigCode {
+		t.Errorf("expected error code %s for conflicting fragments, got %v", ErrInitConfigCode, err)
+	}
+}
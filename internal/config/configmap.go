@@ -0,0 +1,109 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/meshery/meshkit/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// key of the Custom Resource spec referencing a ConfigMap which holds the watch-list,
+// used when the spec has no inline watch-list
+const watchListRefKey = "watch-list-ref"
+
+var configMapsGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+// ConfigMapRef references the ConfigMap holding the watch-list
+type ConfigMapRef struct {
+	Name string `json:"name" yaml:"name"`
+	// defaults to the namespace of the Custom Resource
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+}
+
+// watchListRef reads the ConfigMap reference from the spec of the Custom Resource in crNamespace
+func watchListRef(spec map[string]interface{}, crNamespace string) (*ConfigMapRef, error) {
+	refObj, ok := spec[watchListRefKey]
+	if !ok || refObj == nil {
+		return nil, nil
+	}
+	refStr, err := utils.Marshal(refObj)
+	if err != nil {
+		return nil, err
+	}
+	ref := &ConfigMapRef{}
+	if err := utils.Unmarshal(refStr, ref); err != nil {
+		return nil, err
+	}
+	if ref.Name == "" {
+		return nil, errors.New("watch-list-ref is missing the name of the ConfigMap")
+	}
+	if ref.Namespace == "" {
+		ref.Namespace = crNamespace
+	}
+	return ref, nil
+}
+
+// getReferencedConfigMap fetches the ConfigMap holding the watch-list
+func getReferencedConfigMap(ctx context.Context, dyClient dynamic.Interface, ref ConfigMapRef) (corev1.ConfigMap, error) {
+	configMap := corev1.ConfigMap{}
+	obj, err := dyClient.Resource(configMapsGVR).Namespace(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return configMap, err
+	}
+	configStr, err := utils.Marshal(obj.Object)
+	if err != nil {
+		return configMap, err
+	}
+	err = utils.Unmarshal(configStr, &configMap)
+	return configMap, err
+}
+
+// WatchConfigMap watches the referenced ConfigMap with an informer and re-resolves the configuration
+// whenever its data changes, complementing the CRD watcher. The ConfigMap found initially is not reported,
+// it has been resolved when MeshSync started. onChange receives the error if the new data is not valid.
+func WatchConfigMap(client kubernetes.Interface, ref ConfigMapRef, stopCh <-chan struct{}, onChange func(*MeshsyncConfig, error)) {
+	factory := informers.NewSharedInformerFactoryWithOptions(client, 0,
+		informers.WithNamespace(ref.Namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", ref.Name).String()
+		}),
+	)
+
+	resolve := func(configMap *corev1.ConfigMap) {
+		if configMap.Name != ref.Name {
+			return
+		}
+		meshsyncConfig, err := PopulateConfigs(*configMap)
+		if err != nil {
+			onChange(nil, err)
+			return
+		}
+		meshsyncConfig.Source = &ref
+		onChange(meshsyncConfig, nil)
+	}
+
+	informer := factory.Core().V1().ConfigMaps().Informer()
+	_, _ = informer.AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			if !isInInitialList {
+				// the ConfigMap has been recreated
+				resolve(obj.(*corev1.ConfigMap))
+			}
+		},
+		UpdateFunc: func(oldObj, obj interface{}) {
+			if !reflect.DeepEqual(oldObj.(*corev1.ConfigMap).Data, obj.(*corev1.ConfigMap).Data) {
+				resolve(obj.(*corev1.ConfigMap))
+			}
+		},
+	})
+	factory.Start(stopCh)
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newWatchListConfigMap(name, whitelist string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: DefaultCRDConfig.Namespace},
+		Data:       map[string]string{"whitelist": whitelist},
+	}
+}
+
+func TestReferencedWatchList(t *testing.T) {
+	cr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": DefaultCRDConfig.Group + "/" + DefaultCRDConfig.Version,
+		"kind":       "MeshSync",
+		"metadata":   map[string]interface{}{"name": DefaultCRDConfig.Name, "namespace": DefaultCRDConfig.Namespace},
+		"spec": map[string]interface{}{
+			watchListRefKey: map[string]interface{}{"name": "meshsync-watch-list"},
+		},
+	}}
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "meshsync-watch-list", "namespace": DefaultCRDConfig.Namespace},
+		"data":       map[string]interface{}{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]"},
+	}}
+	dyClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		DefaultCRDConfig.GVR(): "MeshSyncList",
+		configMapsGVR:          "ConfigMapList",
+	}, cr, configMap)
+
+	meshsyncConfig, err := GetMeshsyncCRDConfigs(context.Background(), dyClient)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	assertPipelineNames(t, LocalResourceKey, meshsyncConfig.Pipelines[LocalResourceKey], []string{"pods.v1."})
+	expectedRef := ConfigMapRef{Name: "meshsync-watch-list", Namespace: DefaultCRDConfig.Namespace}
+	if meshsyncConfig.Source == nil || *meshsyncConfig.Source != expectedRef {
+		t.Errorf("expected source %+v, got %+v", expectedRef, meshsyncConfig.Source)
+	}
+}
+
+func TestPausedCustomResource(t *testing.T) {
+	cr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": DefaultCRDConfig.Group + "/" + DefaultCRDConfig.Version,
+		"kind":       "MeshSync",
+		"metadata":   map[string]interface{}{"name": DefaultCRDConfig.Name, "namespace": DefaultCRDConfig.Namespace},
+		"spec": map[string]interface{}{
+			"paused": true,
+			"watch-list": map[string]interface{}{
+				"data": map[string]interface{}{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]"},
+			},
+		},
+	}}
+	gvrToListKind := map[schema.GroupVersionResource]string{DefaultCRDConfig.GVR(): "MeshSyncList"}
+	dyClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvrToListKind, cr)
+
+	meshsyncConfig, err := GetMeshsyncCRDConfigs(context.Background(), dyClient)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if !meshsyncConfig.Paused || len(meshsyncConfig.Pipelines) != 0 {
+		t.Errorf("expected a paused config without pipelines, got paused %t and %v", meshsyncConfig.Paused, meshsyncConfig.Pipelines)
+	}
+	if len(meshsyncConfig.WhiteList) != 1 {
+		t.Errorf("expected the whitelist to be kept while paused, got %v", meshsyncConfig.WhiteList)
+	}
+
+	cr.Object["spec"].(map[string]interface{})["paused"] = "yes"
+	dyClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvrToListKind, cr)
+	if _, err := GetMeshsyncCRDConfigs(context.Background(), dyClient); err == nil {
+		t.Error("expected error for a paused value which is not a boolean")
+	}
+}
+
+func TestWatchConfigMapReResolvesOnChange(t *testing.T) {
+	configMap := newWatchListConfigMap("meshsync-watch-list", "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]")
+	client := fake.NewClientset(configMap, newWatchListConfigMap("unrelated", "[]"))
+
+	resolved := make(chan *MeshsyncConfig, 10)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	WatchConfigMap(client, ConfigMapRef{Name: "meshsync-watch-list", Namespace: DefaultCRDConfig.Namespace}, stopCh, func(meshsyncConfig *MeshsyncConfig, err error) {
+		if err != nil {
+			t.Errorf("unexpected error %s", err.Error())
+			return
+		}
+		resolved <- meshsyncConfig
+	})
+
+	expectNoResolution := func(reason string) {
+		t.Helper()
+		select {
+		case meshsyncConfig := <-resolved:
+			t.Errorf("expected no re-resolution %s, got %+v", reason, meshsyncConfig.Pipelines)
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+	expectNoResolution("for the initial ConfigMap")
+
+	// metadata changes leave the watch-list as it is
+	configMap = configMap.DeepCopy()
+	configMap.Labels = map[string]string{"team": "platform"}
+	if _, err := client.CoreV1().ConfigMaps(DefaultCRDConfig.Namespace).Update(context.TODO(), configMap, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	expectNoResolution("for a metadata change")
+
+	configMap = configMap.DeepCopy()
+	configMap.Data["whitelist"] = "[{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]"
+	if _, err := client.CoreV1().ConfigMaps(DefaultCRDConfig.Namespace).Update(context.TODO(), configMap, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	select {
+	case meshsyncConfig := <-resolved:
+		assertPipelineNames(t, LocalResourceKey, meshsyncConfig.Pipelines[LocalResourceKey], []string{"services.v1."})
+		if meshsyncConfig.Source == nil || meshsyncConfig.Source.Name != "meshsync-watch-list" {
+			t.Errorf("expected the ConfigMap as source, got %+v", meshsyncConfig.Source)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for re-resolution")
+	}
+
+	unrelated := newWatchListConfigMap("unrelated", "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]")
+	if _, err := client.CoreV1().ConfigMaps(DefaultCRDConfig.Namespace).Update(context.TODO(), unrelated, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	expectNoResolution("for another ConfigMap")
+}
+
+func TestListAllMeshsyncCRDConfigs(t *testing.T) {
+	newCR := func(ns string, spec map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": DefaultCRDConfig.Group + "/" + DefaultCRDConfig.Version,
+			"kind":       "MeshSync",
+			"metadata":   map[string]interface{}{"name": DefaultCRDConfig.Name, "namespace": ns},
+			"spec":       spec,
+		}}
+	}
+	inline := newCR("tenant-a", map[string]interface{}{
+		"watch-list": map[string]interface{}{
+			"data": map[string]interface{}{"whitelist": "[{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]"},
+		},
+	})
+	referencing := newCR("tenant-b", map[string]interface{}{
+		watchListRefKey: map[string]interface{}{"name": "meshsync-watch-list"},
+	})
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "meshsync-watch-list", "namespace": "tenant-b"},
+		"data":       map[string]interface{}{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]"},
+	}}
+	gvrToListKind := map[schema.GroupVersionResource]string{
+		DefaultCRDConfig.GVR(): "MeshSyncList",
+		configMapsGVR:          "ConfigMapList",
+	}
+	dyClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvrToListKind, inline, referencing, configMap)
+
+	configs, err := ListAllMeshsyncCRDConfigs(context.Background(), dyClient)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if len(configs) != 2 {
+		t.Fatalf("expected the configs of 2 namespaces, got %d", len(configs))
+	}
+	assertPipelineNames(t, LocalResourceKey, configs["tenant-a"].Pipelines[LocalResourceKey], []string{"services.v1."})
+	assertPipelineNames(t, LocalResourceKey, configs["tenant-b"].Pipelines[LocalResourceKey], []string{"pods.v1."})
+	expectedRef := ConfigMapRef{Name: "meshsync-watch-list", Namespace: "tenant-b"}
+	if configs["tenant-b"].Source == nil || *configs["tenant-b"].Source != expectedRef {
+		t.Errorf("expected source %+v, got %+v", expectedRef, configs["tenant-b"].Source)
+	}
+
+	duplicate := newCR("tenant-a", inline.Object["spec"].(map[string]interface{}))
+	duplicate.SetName("second")
+	dyClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvrToListKind, inline, duplicate)
+	if _, err := ListAllMeshsyncCRDConfigs(context.Background(), dyClient); err == nil {
+		t.Error("expected error for two Custom Resources in one namespace")
+	}
+}
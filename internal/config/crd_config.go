@@ -2,49 +2,103 @@ package config
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
-	"github.com/meshery/meshery-operator/pkg/client"
+	"github.com/meshery/meshkit/logger"
 	"github.com/meshery/meshkit/utils"
+	"github.com/meshery/meshsync/pkg/model"
 	"golang.org/x/exp/slices"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 )
 
-var (
-	namespace = "meshery"          // Namespace for the Custom Resource
-	crName    = "meshery-meshsync" // Name of the custom resource
-	version   = "v1alpha1"         // Version of the Custom Resource
-	group     = "meshery.io"       //Group for the Custom Resource
-	resource  = "meshsyncs"        //Name of the Resource
-)
+// GetMeshsyncCRDConfigs resolves the configs of the Custom Resource located by CRDConfigFromEnv
+func GetMeshsyncCRDConfigs(ctx context.Context, dyClient dynamic.Interface) (*MeshsyncConfig, error) {
+	return GetMeshsyncCRDConfigsFor(ctx, dyClient, CRDConfigFromEnv())
+}
+
+// GetMeshsyncCRDConfigsFor resolves the configs of the Custom Resource located by crdConfig
+func GetMeshsyncCRDConfigsFor(ctx context.Context, dyClient dynamic.Interface, crdConfig CRDConfig) (*MeshsyncConfig, error) {
+	return GetMeshsyncCRDConfigsWithRetry(ctx, dyClient, crdConfig, DefaultRetryOptions)
+}
 
-func GetMeshsyncCRDConfigs(dyClient dynamic.Interface) (*MeshsyncConfig, error) {
+// GetMeshsyncCRDConfigsWithRetry resolves the configs of the Custom Resource located by crdConfig,
+// retrying to get it on transient errors as bounded by retry
+func GetMeshsyncCRDConfigsWithRetry(ctx context.Context, dyClient dynamic.Interface, crdConfig CRDConfig, retry RetryOptions) (*MeshsyncConfig, error) {
 	// make a call to get the custom resource
-	crd, err := GetMeshsyncCRD(dyClient)
+	crd, err := GetMeshsyncCRDWithRetry(ctx, dyClient, crdConfig, retry)
 
 	if err != nil {
-		return nil, ErrInitConfig(err)
+		return nil, err
 	}
 
+	return GetMeshsyncCRDConfigsFrom(ctx, dyClient, crd)
+}
+
+// GetMeshsyncCRDConfigsFrom resolves the configs of a Custom Resource got before, f.e. by GetMeshsyncCRDWithRetry,
+// without getting it again
+func GetMeshsyncCRDConfigsFrom(ctx context.Context, dyClient dynamic.Interface, crd *unstructured.Unstructured) (*MeshsyncConfig, error) {
 	if crd == nil {
 		return nil, ErrInitConfig(errors.New("Custom Resource is nil"))
 	}
 
+	return configsFromCRD(ctx, dyClient, crd)
+}
+
+// ListAllMeshsyncCRDConfigs resolves the configs of the Custom Resources in all namespaces, keyed by namespace,
+// f.e. for a controller managing the MeshSync of every tenant namespace.
+// A referenced watch-list defaults to the namespace of its Custom Resource.
+func ListAllMeshsyncCRDConfigs(ctx context.Context, dyClient dynamic.Interface) (map[string]*MeshsyncConfig, error) {
+	crds, err := dyClient.Resource(CRDConfigFromEnv().GVR()).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, ErrInitConfig(contextErr(ctx, err))
+	}
+
+	configs := make(map[string]*MeshsyncConfig, len(crds.Items))
+	for i := range crds.Items {
+		crd := &crds.Items[i]
+		if _, ok := configs[crd.GetNamespace()]; ok {
+			return nil, ErrInitConfig(fmt.Errorf("more than one Custom Resource in namespace %s", crd.GetNamespace()))
+		}
+		meshsyncConfig, err := configsFromCRD(ctx, dyClient, crd)
+		if err != nil {
+			return nil, err
+		}
+		configs[crd.GetNamespace()] = meshsyncConfig
+	}
+	return configs, nil
+}
+
+// configsFromCRD resolves the configs of the Custom Resource, from its inline or referenced watch-list
+func configsFromCRD(ctx context.Context, dyClient dynamic.Interface, crd *unstructured.Unstructured) (*MeshsyncConfig, error) {
 	spec := crd.Object["spec"]
 	specMap, ok := spec.(map[string]interface{})
 	if !ok {
 		return nil, ErrInitConfig(errors.New("Unable to convert spec to map"))
 	}
+	paused, err := pausedFromSpec(specMap)
+	if err != nil {
+		return nil, ErrInitConfig(err)
+	}
 	configObj := specMap["watch-list"]
 	if configObj == nil {
-		return nil, ErrInitConfig(errors.New("Custom Resource does not have Meshsync Configs"))
+		meshsyncConfig, err := getReferencedConfigs(ctx, dyClient, specMap, crd.GetNamespace())
+		if err != nil {
+			return nil, err
+		}
+		if paused {
+			meshsyncConfig.pause()
+		}
+		return meshsyncConfig, nil
 	}
 	configStr, err := utils.Marshal(configObj)
 	if err != nil {
@@ -64,13 +118,117 @@ func GetMeshsyncCRDConfigs(dyClient dynamic.Interface) (*MeshsyncConfig, error)
 	if err != nil {
 		return nil, ErrInitConfig(err)
 	}
+	if paused {
+		meshsyncConfig.pause()
+	}
 	return meshsyncConfig, nil
 }
 
-func GetMeshsyncCRD(dyClient dynamic.Interface) (*unstructured.Unstructured, error) {
-	// initialize the group version resource to access the custom resource
-	gvr := schema.GroupVersionResource{Version: version, Group: group, Resource: resource}
-	return dyClient.Resource(gvr).Namespace(namespace).Get(context.TODO(), crName, metav1.GetOptions{})
+// pausedFromSpec reports whether the Custom Resource spec pauses the watching
+func pausedFromSpec(spec map[string]interface{}) (bool, error) {
+	pausedObj, ok := spec["paused"]
+	if !ok || pausedObj == nil {
+		return false, nil
+	}
+	paused, ok := pausedObj.(bool)
+	if !ok {
+		return false, fmt.Errorf("invalid paused value %v: must be a boolean", pausedObj)
+	}
+	return paused, nil
+}
+
+// getReferencedConfigs resolves the watch-list of the ConfigMap referenced by the Custom Resource spec
+func getReferencedConfigs(ctx context.Context, dyClient dynamic.Interface, spec map[string]interface{}, crNamespace string) (*MeshsyncConfig, error) {
+	ref, err := watchListRef(spec, crNamespace)
+	if err != nil {
+		return nil, ErrInitConfig(err)
+	}
+	if ref == nil {
+		return nil, ErrInitConfig(errors.New("Custom Resource does not have Meshsync Configs"))
+	}
+
+	configMap, err := getReferencedConfigMap(ctx, dyClient, *ref)
+	if err != nil {
+		return nil, ErrInitConfig(contextErr(ctx, err))
+	}
+
+	meshsyncConfig, err := PopulateConfigs(configMap)
+	if err != nil {
+		return nil, ErrInitConfig(err)
+	}
+	meshsyncConfig.Source = ref
+	return meshsyncConfig, nil
+}
+
+// GetMeshsyncCRD returns the Custom Resource located by CRDConfigFromEnv
+func GetMeshsyncCRD(ctx context.Context, dyClient dynamic.Interface) (*unstructured.Unstructured, error) {
+	return GetMeshsyncCRDFor(ctx, dyClient, CRDConfigFromEnv())
+}
+
+// GetMeshsyncCRDFor returns the Custom Resource located by crdConfig
+func GetMeshsyncCRDFor(ctx context.Context, dyClient dynamic.Interface, crdConfig CRDConfig) (*unstructured.Unstructured, error) {
+	return GetMeshsyncCRDWithRetry(ctx, dyClient, crdConfig, DefaultRetryOptions)
+}
+
+// GetMeshsyncCRDWithRetry returns the Custom Resource located by crdConfig, retrying to get it on transient errors,
+// f.e. while the API server starts up, as bounded by retry
+func GetMeshsyncCRDWithRetry(ctx context.Context, dyClient dynamic.Interface, crdConfig CRDConfig, retry RetryOptions) (*unstructured.Unstructured, error) {
+	if err := retry.Validate(); err != nil {
+		return nil, err
+	}
+	crd, err := fetchMeshsyncCRD(ctx, dyClient, crdConfig, retry)
+	if err != nil {
+		return nil, ErrInitConfig(err)
+	}
+	return crd, nil
+}
+
+// fetchMeshsyncCRD returns the Custom Resource located by crdConfig and the unwrapped error of its last request,
+// requests failing with a transient error are retried
+func fetchMeshsyncCRD(ctx context.Context, dyClient dynamic.Interface, crdConfig CRDConfig, retry RetryOptions) (*unstructured.Unstructured, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var crd *unstructured.Unstructured
+	err := withRetry(ctx, retry, func() error {
+		var err error
+		crd, err = dyClient.Resource(crdConfig.GVR()).Namespace(crdConfig.Namespace).Get(ctx, crdConfig.Name, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, contextErr(ctx, err)
+	}
+	return crd, nil
+}
+
+// GetMeshsyncCRDConfigsWithFallback resolves the configs like GetMeshsyncCRDConfigs but falls back to
+// GetMeshsyncCRDConfigsLocal, logging a warning, if the API server reports the Custom Resource NotFound,
+// f.e. in dev clusters the meshsyncs CRD has not been installed in yet.
+// Any other error, f.e. a forbidden or failed request, is returned as it points to a misconfiguration
+// the local configs would mask.
+func GetMeshsyncCRDConfigsWithFallback(ctx context.Context, dyClient dynamic.Interface, log logger.Handler) (*MeshsyncConfig, error) {
+	crdConfig := CRDConfigFromEnv()
+	crd, err := fetchMeshsyncCRD(ctx, dyClient, crdConfig, DefaultRetryOptions)
+	if apierrors.IsNotFound(err) {
+		log.Warnf("Custom Resource %s/%s of %s not found, falling back to the local configs: %v", crdConfig.Namespace, crdConfig.Name, crdConfig.GVR().GroupResource(), err)
+		return GetMeshsyncCRDConfigsLocal()
+	}
+	if err != nil {
+		return nil, ErrInitConfig(err)
+	}
+	if crd == nil {
+		return nil, ErrInitConfig(errors.New("Custom Resource is nil"))
+	}
+	return configsFromCRD(ctx, dyClient, crd)
+}
+
+// contextErr reports the error of ctx if it has been cancelled or has expired,
+// rather than the error of the request aborted by it, and err otherwise
+func contextErr(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
 }
 
 func GetMeshsyncCRDConfigsLocal() (*MeshsyncConfig, error) {
@@ -78,9 +236,7 @@ func GetMeshsyncCRDConfigsLocal() (*MeshsyncConfig, error) {
 	meshsyncConfig, err := PopulateConfigsFromMap(LocalMeshsyncConfig)
 
 	if err != nil {
-		// // this hides actual error message
-		// return nil, ErrInitConfig(err)
-		return nil, err
+		return nil, ErrInitConfig(err)
 	}
 	return meshsyncConfig, nil
 }
@@ -91,11 +247,29 @@ func PopulateConfigs(configMap corev1.ConfigMap) (*MeshsyncConfig, error) {
 }
 
 func PopulateConfigsFromMap(data map[string]string) (*MeshsyncConfig, error) {
+	if err := ValidateWatchList(data); err != nil {
+		return nil, err
+	}
+	registry, err := withUnregisteredResources(data, Pipelines, Scopes)
+	if err != nil {
+		return nil, ErrInitConfig(err)
+	}
+	return populateConfigsFromRegistry(data, registry)
+}
+
+// populateConfigsFromRegistry resolves the watch-list against the given pipelines registry
+func populateConfigsFromRegistry(data map[string]string, registry map[string]PipelineConfigs) (*MeshsyncConfig, error) {
 	meshsyncConfig := &MeshsyncConfig{}
 
+	defaultEvents, err := parseDefaultEvents(data)
+	if err != nil {
+		return nil, ErrInitConfig(err)
+	}
+	meshsyncConfig.DefaultEvents = defaultEvents
+
 	if _, ok := data["blacklist"]; ok {
 		if len(data["blacklist"]) > 0 {
-			err := utils.Unmarshal(data["blacklist"], &meshsyncConfig.BlackList)
+			err := parseBlackList(data["blacklist"], meshsyncConfig, registry)
 			if err != nil {
 				return nil, ErrInitConfig(err)
 			}
@@ -108,102 +282,315 @@ func PopulateConfigsFromMap(data map[string]string) (*MeshsyncConfig, error) {
 			if err != nil {
 				return nil, ErrInitConfig(err)
 			}
+			normalizeWhiteList(meshsyncConfig.WhiteList)
+			meshsyncConfig.WhiteList, err = expandWhiteList(meshsyncConfig.WhiteList, registry)
+			if err != nil {
+				return nil, ErrInitConfig(err)
+			}
 		}
 	}
 
-	// ensure that atleast one of whitelist or blacklist has been supplied
-	if len(meshsyncConfig.BlackList) == 0 && len(meshsyncConfig.WhiteList) == 0 {
-		return nil, ErrInitConfig(errors.New("Both whitelisted and blacklisted resources missing"))
+	meshsyncConfig.TenantLabel = data["tenantLabel"]
+	meshsyncConfig.TenantFallbackSubject = data["tenantFallbackSubject"]
+
+	meshsyncConfig.EmitStatus = true
+	if err := parseBoolSetting(data, "emitStatus", &meshsyncConfig.EmitStatus); err != nil {
+		return nil, err
+	}
+
+	meshsyncConfig.EnvelopeVersion = data["envelopeVersion"]
+	if !model.IsSupportedSchemaVersion(meshsyncConfig.EnvelopeVersion) {
+		return nil, ErrInitConfig(fmt.Errorf("unsupported envelopeVersion %q, supported versions are %v", meshsyncConfig.EnvelopeVersion, model.SchemaVersions))
+	}
+
+	if err := parseBoolSetting(data, "snapshotCompleteMarker", &meshsyncConfig.SnapshotCompleteMarker); err != nil {
+		return nil, err
+	}
+	if err := parseBoolSetting(data, "snapshotManifest", &meshsyncConfig.SnapshotManifest); err != nil {
+		return nil, err
+	}
+	if err := parseBoolSetting(data, "namespaceMetadata", &meshsyncConfig.NamespaceMetadata); err != nil {
+		return nil, err
+	}
+	if err := parseBoolSetting(data, "canonicalJSON", &meshsyncConfig.CanonicalJSON); err != nil {
+		return nil, err
+	}
+	if err := parseBoolSetting(data, "computeHealth", &meshsyncConfig.ComputeHealth); err != nil {
+		return nil, err
+	}
+	if err := parseBoolSetting(data, "redactConfigMaps", &meshsyncConfig.RedactConfigMaps); err != nil {
+		return nil, err
+	}
+	if err := parseBoolSetting(data, "strictNamespaces", &meshsyncConfig.StrictNamespaces); err != nil {
+		return nil, err
+	}
+	if err := parseBoolSetting(data, "namespacedClients", &meshsyncConfig.NamespacedClients); err != nil {
+		return nil, err
+	}
+	if err := parseBoolSetting(data, "paused", &meshsyncConfig.Paused); err != nil {
+		return nil, err
 	}
 
-	// ensure that only one of whitelist or blacklist has been supplied
-	if len(meshsyncConfig.BlackList) != 0 && len(meshsyncConfig.WhiteList) != 0 {
-		return nil, ErrInitConfig(errors.New("Both whitelisted and blacklisted resources not currently supported"))
+	if err := parseIntSetting(data, "maxConcurrentInitializing", &meshsyncConfig.MaxConcurrentInitializing); err != nil {
+		return nil, err
+	}
+	if meshsyncConfig.MaxConcurrentInitializing < 0 {
+		return nil, ErrInitConfig(fmt.Errorf("invalid maxConcurrentInitializing value %d: must not be negative", meshsyncConfig.MaxConcurrentInitializing))
 	}
 
-	// Handle global resources
-	globalPipelines := make(PipelineConfigs, 0)
-	localPipelines := make(PipelineConfigs, 0)
+	if err := parseDurationSetting(data, "resyncPeriod", &meshsyncConfig.ResyncPeriod); err != nil {
+		return nil, err
+	}
+	if meshsyncConfig.ResyncPeriod < 0 || (meshsyncConfig.ResyncPeriod > 0 && meshsyncConfig.ResyncPeriod < MinResyncPeriod) {
+		return nil, ErrInitConfig(fmt.Errorf("invalid resyncPeriod value %s: must be zero or at least %s", meshsyncConfig.ResyncPeriod, MinResyncPeriod))
+	}
 
-	if len(meshsyncConfig.WhiteList) != 0 {
-		for _, v := range Pipelines[GlobalResourceKey] {
-			if idx := slices.IndexFunc(meshsyncConfig.WhiteList, func(c ResourceConfig) bool { return c.Resource == v.Name }); idx != -1 {
-				config := meshsyncConfig.WhiteList[idx]
-				v.Events = config.Events
-				globalPipelines = append(globalPipelines, v)
-			}
+	if err := parseDurationSetting(data, "startupJitter", &meshsyncConfig.StartupJitter); err != nil {
+		return nil, err
+	}
+	if meshsyncConfig.StartupJitter < 0 {
+		return nil, ErrInitConfig(fmt.Errorf("invalid startupJitter value %s: must not be negative", meshsyncConfig.StartupJitter))
+	}
+	if err := parseDurationSetting(data, "startupStagger", &meshsyncConfig.StartupStagger); err != nil {
+		return nil, err
+	}
+	if meshsyncConfig.StartupStagger < 0 {
+		return nil, ErrInitConfig(fmt.Errorf("invalid startupStagger value %s: must not be negative", meshsyncConfig.StartupStagger))
+	}
+
+	if err := parseDurationSetting(data, "livenessInterval", &meshsyncConfig.LivenessInterval); err != nil {
+		return nil, err
+	}
+	if meshsyncConfig.LivenessInterval < 0 {
+		return nil, ErrInitConfig(fmt.Errorf("invalid livenessInterval value %s: must not be negative", meshsyncConfig.LivenessInterval))
+	}
+
+	objectLogSampling, err := parseObjectLogSampling(data)
+	if err != nil {
+		return nil, err
+	}
+	meshsyncConfig.ObjectLogSampling = objectLogSampling
+
+	eventTypeMapping, err := parseEventTypeMapping(data)
+	if err != nil {
+		return nil, ErrInitConfig(err)
+	}
+	meshsyncConfig.EventTypeMapping = eventTypeMapping
+
+	namespaces, err := parseNamespaceScopes(data)
+	if err != nil {
+		return nil, ErrInitConfig(err)
+	}
+	meshsyncConfig.Namespaces = namespaces
+	excludeNamespaces, err := parseExcludeNamespaces(data)
+	if err != nil {
+		return nil, ErrInitConfig(err)
+	}
+	meshsyncConfig.ExcludeNamespaces = excludeNamespaces
+	relationships, err := parseRelationships(data)
+	if err != nil {
+		return nil, ErrInitConfig(err)
+	}
+	meshsyncConfig.Relationships = relationships
+	fieldDenylist, err := parseFieldDenylist(data)
+	if err != nil {
+		return nil, ErrInitConfig(err)
+	}
+	meshsyncConfig.FieldDenylist = fieldDenylist
+	if err := parseNamespaceStrategy(data, meshsyncConfig); err != nil {
+		return nil, err
+	}
+
+	for _, rc := range meshsyncConfig.WhiteList {
+		if err := validateKeyFunc(rc.Resource, rc.KeyFunc, rc.KeyFuncFallback); err != nil {
+			return nil, ErrInitConfig(err)
 		}
-		if len(globalPipelines) > 0 {
-			meshsyncConfig.Pipelines = map[string]PipelineConfigs{}
-			meshsyncConfig.Pipelines[GlobalResourceKey] = globalPipelines
+		if err := validateSampling(rc.Resource, rc.Sampling); err != nil {
+			return nil, ErrInitConfig(err)
 		}
-
-		// Handle local resources
-		for _, v := range Pipelines[LocalResourceKey] {
-			if idx := slices.IndexFunc(meshsyncConfig.WhiteList, func(c ResourceConfig) bool { return c.Resource == v.Name }); idx != -1 {
-				config := meshsyncConfig.WhiteList[idx]
-				v.Events = config.Events
-				localPipelines = append(localPipelines, v)
+		if rc.Sink != "" {
+			if _, err := Sinks.Validate(rc.Sink); err != nil {
+				return nil, ErrInitConfig(fmt.Errorf("invalid sink for %s: %w", rc.Resource, err))
 			}
 		}
+	}
 
-		if len(localPipelines) > 0 {
-			if meshsyncConfig.Pipelines == nil {
-				meshsyncConfig.Pipelines = make(map[string]PipelineConfigs)
-			}
-			meshsyncConfig.Pipelines[LocalResourceKey] = localPipelines
+	if err := resolveResourceNames(meshsyncConfig, registry); err != nil {
+		return nil, ErrInitConfig(err)
+	}
+	if err := validateNoDuplicates(meshsyncConfig); err != nil {
+		return nil, ErrInitConfig(err)
+	}
+	if err := validateWhiteListScopes(meshsyncConfig.WhiteList, registry); err != nil {
+		return nil, ErrInitConfig(err)
+	}
+	if err := validateBlackListEvents(meshsyncConfig.BlackListEvents, registry); err != nil {
+		return nil, ErrInitConfig(err)
+	}
+	if meshsyncConfig.blackListMode() || len(meshsyncConfig.WhiteList) == 0 {
+		if err := validateBlackListScopes(meshsyncConfig.BlackList, meshsyncConfig.WhiteList, registry); err != nil {
+			return nil, ErrInitConfig(err)
 		}
+	}
 
-	} else {
+	// ensure that atleast one of whitelist or blacklist has been supplied
+	if !meshsyncConfig.blackListMode() && len(meshsyncConfig.WhiteList) == 0 {
+		return nil, ErrInitConfig(errors.New("Both whitelisted and blacklisted resources missing"))
+	}
 
-		for _, v := range Pipelines[GlobalResourceKey] {
-			if idx := slices.IndexFunc(meshsyncConfig.BlackList, func(c string) bool { return c == v.Name }); idx == -1 {
-				v.Events = DefaultEvents
-				globalPipelines = append(globalPipelines, v)
+	// The blacklist subtracts from the registry, the whitelist then overrides the pipelines of its resources.
+	// Without blacklist only the whitelisted resources are watched.
+	for _, bucket := range []string{GlobalResourceKey, LocalResourceKey} {
+		pipelines := make(PipelineConfigs, 0)
+		for _, v := range registry[bucket] {
+			if idx := slices.IndexFunc(meshsyncConfig.WhiteList, func(c ResourceConfig) bool { return c.Resource == v.Name && c.inScope(bucket) }); idx != -1 {
+				pc, err := meshsyncConfig.WhiteList[idx].applyTo(v, meshsyncConfig)
+				if err != nil {
+					return nil, ErrInitConfig(err)
+				}
+				if err := validateBucketNamespaces(bucket, pc); err != nil {
+					return nil, ErrInitConfig(err)
+				}
+				pipelines = append(pipelines, pc)
+				continue
 			}
-		}
-		if len(globalPipelines) > 0 {
-			meshsyncConfig.Pipelines = map[string]PipelineConfigs{}
-			meshsyncConfig.Pipelines[GlobalResourceKey] = globalPipelines
-		}
-
-		// Handle local resources
-		for _, v := range Pipelines[LocalResourceKey] {
-			if idx := slices.IndexFunc(meshsyncConfig.BlackList, func(c string) bool { return c == v.Name }); idx == -1 {
-				v.Events = DefaultEvents
-				localPipelines = append(localPipelines, v)
+			if !meshsyncConfig.blackListMode() ||
+				slices.Contains(meshsyncConfig.BlackList, v.Name) ||
+				slices.ContainsFunc(meshsyncConfig.WhiteList, func(c ResourceConfig) bool { return c.Resource == v.Name }) {
+				// not watched, or whitelisted in the other bucket
+				continue
 			}
+			v.Events = meshsyncConfig.blackListEventsOf(v.Name)
+			v.StripStatus = !meshsyncConfig.EmitStatus
+			v.ResyncPeriod = meshsyncConfig.ResyncPeriod
+			pipelines = append(pipelines, v)
 		}
-
-		if len(localPipelines) > 0 {
+		if len(pipelines) > 0 {
 			if meshsyncConfig.Pipelines == nil {
 				meshsyncConfig.Pipelines = make(map[string]PipelineConfigs)
 			}
-			meshsyncConfig.Pipelines[LocalResourceKey] = localPipelines
+			meshsyncConfig.Pipelines[bucket] = pipelines
 		}
 	}
 
+	if err := validateEventTypeMappingCoverage(meshsyncConfig.EventTypeMapping, meshsyncConfig.Pipelines); err != nil {
+		return nil, ErrInitConfig(err)
+	}
+
+	if err := applyNamespaceScopes(meshsyncConfig.Namespaces, meshsyncConfig.Pipelines); err != nil {
+		return nil, ErrInitConfig(err)
+	}
+	if err := applyExcludeNamespaces(meshsyncConfig.ExcludeNamespaces, meshsyncConfig.Pipelines); err != nil {
+		return nil, ErrInitConfig(err)
+	}
+
+	if err := validateRelationships(meshsyncConfig.Relationships, meshsyncConfig.Pipelines); err != nil {
+		return nil, ErrInitConfig(err)
+	}
+
+	applyTenancy(meshsyncConfig)
+	if err := validateNamespacedClients(meshsyncConfig); err != nil {
+		return nil, ErrInitConfig(err)
+	}
+
+	if meshsyncConfig.Paused {
+		meshsyncConfig.pause()
+	}
 	return meshsyncConfig, nil
 }
 
-func PatchCRVersion(config *rest.Config) error {
-	meshsyncClient, err := client.New(config)
+// pause drops the pipelines of the config, the validated watch-list is kept so resuming only re-reads it
+func (c *MeshsyncConfig) pause() {
+	c.Paused = true
+	c.PausedPipelines = c.Pipelines
+	c.Pipelines = make(map[string]PipelineConfigs)
+}
+
+// applyTenancy propagates the subject partitioning settings onto every pipeline
+func applyTenancy(meshsyncConfig *MeshsyncConfig) {
+	if meshsyncConfig.TenantLabel == "" {
+		return
+	}
+	for _, pipelines := range meshsyncConfig.Pipelines {
+		for i := range pipelines {
+			pipelines[i].TenantLabel = meshsyncConfig.TenantLabel
+			pipelines[i].TenantFallbackSubject = meshsyncConfig.TenantFallbackSubject
+		}
+	}
+}
+
+// parseBoolSetting sets value from the boolean watch-list setting key if present
+func parseBoolSetting(data map[string]string, key string, value *bool) error {
+	setting, ok := data[key]
+	if !ok || setting == "" {
+		return nil
+	}
+	parsed, err := strconv.ParseBool(setting)
+	if err != nil {
+		return ErrInitConfig(fmt.Errorf("invalid %s value %q: %w", key, setting, err))
+	}
+	*value = parsed
+	return nil
+}
+
+// parseIntSetting parses the optional integer setting into value, keeping its default when unset
+func parseIntSetting(data map[string]string, key string, value *int) error {
+	setting, ok := data[key]
+	if !ok || setting == "" {
+		return nil
+	}
+	parsed, err := strconv.Atoi(setting)
 	if err != nil {
-		return ErrInitConfig(fmt.Errorf("unable to update MeshSync configuration"))
+		return ErrInitConfig(fmt.Errorf("invalid %s value %q: %w", key, setting, err))
 	}
+	*value = parsed
+	return nil
+}
+
+// parseDurationSetting sets value from the duration watch-list setting key if present, f.e. "30s"
+func parseDurationSetting(data map[string]string, key string, value *time.Duration) error {
+	setting, ok := data[key]
+	if !ok || setting == "" {
+		return nil
+	}
+	parsed, err := time.ParseDuration(setting)
+	if err != nil {
+		return ErrInitConfig(fmt.Errorf("invalid %s value %q: %w", key, setting, err))
+	}
+	*value = parsed
+	return nil
+}
+
+// PatchCRVersion records the version of MeshSync in the Custom Resource located by CRDConfigFromEnv
+func PatchCRVersion(ctx context.Context, config *rest.Config) error {
+	return PatchCRVersionFor(ctx, config, CRDConfigFromEnv())
+}
+
+// PatchCRVersionFor records the version of MeshSync in the Custom Resource located by crdConfig
+func PatchCRVersionFor(ctx context.Context, config *rest.Config, crdConfig CRDConfig) error {
+	dyClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return ErrInitConfig(fmt.Errorf("unable to update MeshSync configuration: %w", err))
+	}
+	return patchCRVersion(ctx, dyClient, crdConfig, Server["version"])
+}
 
-	patchedResource := map[string]interface{}{
-		"spec": map[string]interface{}{
-			"version": Server["version"],
-		},
+// patchCRVersion sets spec.version of the Custom Resource and nothing else, creating spec if it is missing.
+// The merge patch carries no resourceVersion, so it neither conflicts with nor overwrites concurrent updates
+// of the operator, and as it is sent to the main resource it never touches the status subresource.
+func patchCRVersion(ctx context.Context, dyClient dynamic.Interface, crdConfig CRDConfig, version string) error {
+	if err := ctx.Err(); err != nil {
+		return ErrInitConfig(err)
 	}
-	byt, err := utils.Marshal(patchedResource)
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{"version": version},
+	})
 	if err != nil {
-		return ErrInitConfig(fmt.Errorf("unable to update MeshSync configuration"))
+		return ErrInitConfig(fmt.Errorf("unable to update MeshSync configuration: %w", err))
 	}
-	_, err = meshsyncClient.CoreV1Alpha1().MeshSyncs("meshery").Patch(context.TODO(), crName, types.MergePatchType, []byte(byt), metav1.PatchOptions{})
+	_, err = dyClient.Resource(crdConfig.GVR()).Namespace(crdConfig.Namespace).Patch(ctx, crdConfig.Name, types.MergePatchType, patch, metav1.PatchOptions{})
 	if err != nil {
-		return ErrInitConfig(fmt.Errorf("unable to update MeshSync configuration"))
+		return ErrInitConfig(fmt.Errorf("unable to update MeshSync configuration: %w", contextErr(ctx, err)))
 	}
 	return nil
 }
@@ -4,7 +4,9 @@ package config
 import (
 	"context"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -33,7 +35,7 @@ func TestWhiteListResources(t *testing.T) {
 		},
 		Data: map[string]string{
 			"blacklist": "",
-			"whitelist": "[{\"Resource\":\"namespaces.v1.\",\"Events\":[\"ADDED\",\"DELETE\"]},{\"Resource\":\"replicasets.v1.apps\",\"Events\":[\"ADDED\",\"DELETE\"]},{\"Resource\":\"pods.v1.\",\"Events\":[\"MODIFIED\"]}]",
+			"whitelist": "[{\"Resource\":\"namespaces.v1.\",\"Events\":[\"ADDED\",\"DELETED\"]},{\"Resource\":\"replicasets.v1.apps\",\"Events\":[\"ADDED\",\"DELETED\"]},{\"Resource\":\"pods.v1.\",\"Events\":[\"MODIFIED\"]}]",
 		},
 	}
 
@@ -47,8 +49,8 @@ func TestWhiteListResources(t *testing.T) {
 		t.Errorf("WhiteListed resources not correctly deserialized")
 	}
 	expectedWhiteList := []ResourceConfig{
-		{Resource: "namespaces.v1.", Events: []string{"ADDED", "DELETE"}},
-		{Resource: "replicasets.v1.apps", Events: []string{"ADDED", "DELETE"}},
+		{Resource: "namespaces.v1.", Events: []string{"ADDED", "DELETED"}},
+		{Resource: "replicasets.v1.apps", Events: []string{"ADDED", "DELETED"}},
 		{Resource: "pods.v1.", Events: []string{"MODIFIED"}},
 	}
 
@@ -118,3 +120,1435 @@ func TestBlackListResources(t *testing.T) {
 		t.Errorf("local pipelines not well configured expected %d", expectedLocalCount)
 	}
 }
+
+func TestTenantLabelPropagatesToPipelines(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist":             "[{\"Resource\":\"namespaces.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]",
+		"tenantLabel":           "tenant",
+		"tenantFallbackSubject": "meshery.meshsync.shared",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+
+	for key, pipelines := range meshsyncConfig.Pipelines {
+		for _, pipeline := range pipelines {
+			if pipeline.TenantLabel != "tenant" || pipeline.TenantFallbackSubject != "meshery.meshsync.shared" {
+				t.Errorf("%s pipeline %s not configured for tenant partitioning", key, pipeline.Name)
+			}
+		}
+	}
+}
+
+func TestEmitStatus(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist":  "[{\"Resource\":\"namespaces.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"EmitStatus\":true}]",
+		"emitStatus": "false",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	if !meshsyncConfig.Pipelines[GlobalResourceKey][0].StripStatus {
+		t.Error("status must be stripped globally when emitStatus is disabled")
+	}
+	if meshsyncConfig.Pipelines[LocalResourceKey][0].StripStatus {
+		t.Error("status must be retained when the resource overrides emitStatus")
+	}
+
+	meshsyncConfig, err = PopulateConfigsFromMap(map[string]string{
+		"blacklist": "[\"pods.v1.\"]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	for _, pipeline := range meshsyncConfig.Pipelines[LocalResourceKey] {
+		if pipeline.StripStatus {
+			t.Errorf("status must be emitted by default, stripped for %s", pipeline.Name)
+		}
+	}
+
+	if _, err := PopulateConfigsFromMap(map[string]string{
+		"blacklist":  "[\"pods.v1.\"]",
+		"emitStatus": "sometimes",
+	}); err == nil {
+		t.Error("expected error for invalid emitStatus value")
+	}
+}
+
+func TestKeyFuncValidation(t *testing.T) {
+	testCases := []struct {
+		name      string
+		whitelist string
+		expectErr bool
+	}{
+		{name: "label key function", whitelist: "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"KeyFunc\":\"label:app\"}]"},
+		{name: "label key function with fallback", whitelist: "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"KeyFunc\":\"label:app\",\"KeyFuncFallback\":\"namespace/name\"}]"},
+		{name: "unknown key function", whitelist: "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"KeyFunc\":\"hostname\"}]", expectErr: true},
+		{name: "label without key", whitelist: "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"KeyFunc\":\"label:\"}]", expectErr: true},
+		{name: "label based fallback", whitelist: "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"KeyFunc\":\"label:app\",\"KeyFuncFallback\":\"label:team\"}]", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{"whitelist": tc.whitelist})
+			if tc.expectErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			if meshsyncConfig.Pipelines[LocalResourceKey][0].KeyFunc != "label:app" {
+				t.Error("key function not propagated to the pipeline")
+			}
+		})
+	}
+}
+
+func TestMaxWatchAge(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"MaxWatchAge\":\"30m\"},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	for _, pipeline := range meshsyncConfig.Pipelines[LocalResourceKey] {
+		expected := time.Duration(0)
+		if pipeline.Name == "pods.v1." {
+			expected = 30 * time.Minute
+		}
+		if pipeline.MaxWatchAge != expected {
+			t.Errorf("expected max watch age %s for %s, got %s", expected, pipeline.Name, pipeline.MaxWatchAge)
+		}
+	}
+
+	for _, value := range []string{"soon", "-1m"} {
+		if _, err := PopulateConfigsFromMap(map[string]string{
+			"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"MaxWatchAge\":\"" + value + "\"}]",
+		}); err == nil {
+			t.Errorf("expected error for MaxWatchAge %q", value)
+		}
+	}
+}
+
+func TestEnvelopeVersion(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist":       "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]",
+		"envelopeVersion": "v1",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	if version := meshsyncConfig.EnvelopeVersion; version != "v1" {
+		t.Errorf("expected envelope version v1, got %q", version)
+	}
+
+	if _, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist":       "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]",
+		"envelopeVersion": "v0",
+	}); err == nil {
+		t.Error("expected error for unsupported envelope version")
+	}
+}
+
+func TestSamplingValidation(t *testing.T) {
+	testCases := []struct {
+		name      string
+		sampling  string
+		expectErr bool
+	}{
+		{name: "selector with rates", sampling: "{\"selector\":\"env=prod\",\"rate\":1,\"default-rate\":0.1}"},
+		{name: "selector only", sampling: "{\"selector\":\"env in (prod,staging)\"}"},
+		{name: "invalid selector", sampling: "{\"selector\":\"env==(prod\"}", expectErr: true},
+		{name: "rate out of range", sampling: "{\"selector\":\"env=prod\",\"default-rate\":1.5}", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+				"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"Sampling\":" + tc.sampling + "}]",
+			})
+			if tc.expectErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			if meshsyncConfig.Pipelines[LocalResourceKey][0].Sampling == nil {
+				t.Error("sampling not propagated to the pipeline")
+			}
+		})
+	}
+}
+
+func TestSnapshotCompleteMarker(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"blacklist":              "[\"pods.v1.\"]",
+		"snapshotCompleteMarker": "true",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	if !meshsyncConfig.SnapshotCompleteMarker {
+		t.Error("snapshot complete marker not enabled")
+	}
+
+	if _, err := PopulateConfigsFromMap(map[string]string{
+		"blacklist":              "[\"pods.v1.\"]",
+		"snapshotCompleteMarker": "always",
+	}); err == nil {
+		t.Error("expected error for invalid snapshotCompleteMarker value")
+	}
+}
+
+func TestSnapshotManifest(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"blacklist":        "[\"pods.v1.\"]",
+		"snapshotManifest": "true",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	if !meshsyncConfig.SnapshotManifest {
+		t.Error("snapshot manifest not enabled")
+	}
+
+	if _, err := PopulateConfigsFromMap(map[string]string{
+		"blacklist":        "[\"pods.v1.\"]",
+		"snapshotManifest": "yes please",
+	}); err == nil {
+		t.Error("expected error for invalid snapshotManifest value")
+	}
+}
+
+func TestSinkValidation(t *testing.T) {
+	testCases := []struct {
+		name      string
+		sink      string
+		expectErr bool
+	}{
+		{name: "nats", sink: "nats://broker:4222"},
+		{name: "absolute file", sink: "file:///tmp/events.yaml"},
+		{name: "relative file", sink: "file:events.yaml"},
+		{name: "nats without host", sink: "nats://", expectErr: true},
+		{name: "file without path", sink: "file://", expectErr: true},
+		{name: "unknown scheme", sink: "kafka://broker:9092", expectErr: true},
+		{name: "webhook", sink: "https://hooks.example.com/meshsync"},
+		{name: "tuned webhook", sink: "http://hooks.example.com/meshsync?batch=50&flush=2s&retries=5&timeout=3s"},
+		{name: "webhook without host", sink: "https:///meshsync", expectErr: true},
+		{name: "webhook with invalid batch", sink: "https://hooks.example.com/meshsync?batch=many", expectErr: true},
+		{name: "webhook with invalid timeout", sink: "https://hooks.example.com/meshsync?timeout=-1s", expectErr: true},
+		{name: "queued nats", sink: "nats://broker:4222?queue=/var/lib/meshsync/broker&queue-max=16Mi&queue-overflow=drop-oldest"},
+		{name: "queue with unknown overflow", sink: "nats://broker:4222?queue=/var/lib/meshsync/broker&queue-overflow=spill", expectErr: true},
+		{name: "queue with invalid size", sink: "nats://broker:4222?queue=/var/lib/meshsync/broker&queue-max=-1", expectErr: true},
+		{name: "queue without directory", sink: "nats://broker:4222?queue-max=16Mi", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+				"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"Sink\":\"" + tc.sink + "\"},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]",
+			})
+			if tc.expectErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			for _, pipeline := range meshsyncConfig.Pipelines[LocalResourceKey] {
+				expected := ""
+				if pipeline.Name == "pods.v1." {
+					expected = tc.sink
+				}
+				if pipeline.Sink != expected {
+					t.Errorf("expected sink %q for %s, got %q", expected, pipeline.Name, pipeline.Sink)
+				}
+			}
+		})
+	}
+}
+
+func TestSinkWebhookSettings(t *testing.T) {
+	u, err := Sinks.Validate("https://hooks.example.com/meshsync?token=abc&batch=50&flush=2s")
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	settings, err := SinkWebhookSettings(u)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	expected := DefaultWebhookSettings
+	expected.BatchSize = 50
+	expected.FlushInterval = 2 * time.Second
+	if !reflect.DeepEqual(settings, expected) {
+		t.Errorf("expected settings %+v, got %+v", expected, settings)
+	}
+
+	if target := SinkWebhookURL(u); target != "https://hooks.example.com/meshsync?token=abc" {
+		t.Errorf("expected webhook parameters to be removed from the URL, got %s", target)
+	}
+}
+
+func TestSinkQueueSettings(t *testing.T) {
+	u, err := Sinks.Validate("https://hooks.example.com/meshsync?batch=50&queue=/var/lib/meshsync/hooks&queue-max=1Mi")
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	settings, err := SinkQueueSettings(u)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	expected := QueueSettings{Dir: "/var/lib/meshsync/hooks", MaxBytes: 1 << 20, Overflow: QueueOverflowBlock}
+	if settings != expected {
+		t.Errorf("expected settings %+v, got %+v", expected, settings)
+	}
+
+	if target := SinkWebhookURL(u); target != "https://hooks.example.com/meshsync" {
+		t.Errorf("expected queue parameters to be removed from the URL, got %s", target)
+	}
+}
+
+func TestSinkMaxInflightBytes(t *testing.T) {
+	u, err := Sinks.Validate("https://hooks.example.com/meshsync?batch=50&max-inflight=8Mi")
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	maxBytes, err := SinkMaxInflightBytes(u)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if maxBytes != 8<<20 {
+		t.Errorf("expected %d in-flight bytes, got %d", 8<<20, maxBytes)
+	}
+	if target := SinkWebhookURL(u); target != "https://hooks.example.com/meshsync" {
+		t.Errorf("expected the in-flight parameter to be removed from the URL, got %s", target)
+	}
+
+	for _, uri := range []string{"nats://broker:4222?max-inflight=0", "file:///tmp/snapshot.yaml?max-inflight=lots"} {
+		if _, err := Sinks.Validate(uri); err == nil {
+			t.Errorf("expected error for %s", uri)
+		}
+	}
+}
+
+func TestNamespaceScopes(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"blacklist":  "[\"services.v1.\"]",
+		"namespaces": "{\"pods.v1.\":[\"default\",\"prod\"]}",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	for _, pipeline := range meshsyncConfig.Pipelines[LocalResourceKey] {
+		var expected []string
+		if pipeline.Name == "pods.v1." {
+			expected = []string{"default", "prod"}
+		}
+		if !reflect.DeepEqual(pipeline.Namespaces, expected) {
+			t.Errorf("expected %s to be scoped to %v, got %v", pipeline.Name, expected, pipeline.Namespaces)
+		}
+	}
+
+	invalid := []string{
+		// blacklisted resources are not watched
+		"{\"services.v1.\":[\"default\"]}",
+		"{\"pods.v1.\":[]}",
+		"{\"pods.v1.\":[\"\"]}",
+		"[\"default\"]",
+	}
+	for _, namespaces := range invalid {
+		if _, err := PopulateConfigsFromMap(map[string]string{
+			"blacklist":  "[\"services.v1.\"]",
+			"namespaces": namespaces,
+		}); err == nil {
+			t.Errorf("expected error for namespaces %s", namespaces)
+		}
+	}
+}
+
+func TestNamespaceMetadata(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist":         "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]",
+		"namespaceMetadata": "true",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	if !meshsyncConfig.NamespaceMetadata {
+		t.Error("namespace metadata enrichment not enabled")
+	}
+}
+
+func TestStrictNamespaces(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist":        "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"Namespaces\":[\"prod\"]}]",
+		"strictNamespaces": "true",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	if !meshsyncConfig.StrictNamespaces {
+		t.Error("strict namespaces not enabled")
+	}
+}
+
+func TestDefaultEvents(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist":     "[{\"Resource\":\"pods.v1.\"},{\"Resource\":\"services.v1.\",\"Events\":[\"MODIFIED\"]}]",
+		"defaultEvents": "[\"added\",\"DELETED\",\"ADDED\"]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	expected := map[string][]string{"pods.v1.": {"ADDED", "DELETED"}, "services.v1.": {"MODIFIED"}}
+	for _, pc := range meshsyncConfig.Pipelines[LocalResourceKey] {
+		if !reflect.DeepEqual(pc.Events, expected[pc.Name]) {
+			t.Errorf("expected the events %v for %s, got %v", expected[pc.Name], pc.Name, pc.Events)
+		}
+	}
+
+	// the resources the blacklist does not exclude
+	meshsyncConfig, err = PopulateConfigsFromMap(map[string]string{
+		"blacklist":     "[\"secrets.v1.\"]",
+		"defaultEvents": "[\"ADDED\",\"DELETED\"]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	for _, pipelines := range meshsyncConfig.Pipelines {
+		for _, pc := range pipelines {
+			if !reflect.DeepEqual(pc.Events, []string{"ADDED", "DELETED"}) {
+				t.Errorf("expected the default events for %s, got %v", pc.Name, pc.Events)
+			}
+		}
+	}
+
+	for _, invalid := range []string{"[\"ADD\"]", "[]", "ADDED"} {
+		_, err := PopulateConfigsFromMap(map[string]string{
+			"whitelist":     "[{\"Resource\":\"pods.v1.\"}]",
+			"defaultEvents": invalid,
+		})
+		if err == nil {
+			t.Errorf("expected error for the default events %s", invalid)
+		}
+	}
+}
+
+func TestLivenessInterval(t *testing.T) {
+	testCases := []struct {
+		name      string
+		interval  string
+		expected  time.Duration
+		expectErr bool
+	}{
+		{name: "interval", interval: "30s", expected: 30 * time.Second},
+		{name: "disabled", interval: "0s"},
+		{name: "negative", interval: "-30s", expectErr: true},
+		{name: "malformed", interval: "30", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+				"whitelist":        "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]",
+				"livenessInterval": tc.interval,
+			})
+			if tc.expectErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+			}
+			if interval := meshsyncConfig.LivenessInterval; interval != tc.expected {
+				t.Errorf("expected the liveness interval %s, got %s", tc.expected, interval)
+			}
+		})
+	}
+}
+
+func TestEventTypeMapping(t *testing.T) {
+	whitelist := "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\",\"DELETED\"]}]"
+	testCases := []struct {
+		name      string
+		mapping   string
+		expectErr bool
+	}{
+		{name: "all event types", mapping: "{\"ADDED\":\"CREATE\",\"MODIFIED\":\"UPDATE\",\"DELETED\":\"DELETE\"}"},
+		{name: "used event types", mapping: "{\"ADDED\":\"CREATE\",\"DELETED\":\"DELETE\"}"},
+		{name: "used event type not mapped", mapping: "{\"ADDED\":\"CREATE\"}", expectErr: true},
+		{name: "unknown event type", mapping: "{\"ADDED\":\"CREATE\",\"DELETED\":\"DELETE\",\"CREATED\":\"CREATE\"}", expectErr: true},
+		{name: "ambiguous values", mapping: "{\"ADDED\":\"CHANGE\",\"DELETED\":\"CHANGE\"}", expectErr: true},
+		{name: "empty value", mapping: "{\"ADDED\":\"\",\"DELETED\":\"DELETE\"}", expectErr: true},
+		{name: "malformed", mapping: "ADDED=CREATE", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+				"whitelist":        whitelist,
+				"eventTypeMapping": tc.mapping,
+			})
+			if tc.expectErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			if mapped := meshsyncConfig.EventTypeMapping["ADDED"]; mapped != "CREATE" {
+				t.Errorf("expected ADDED to be mapped to CREATE, got %q", mapped)
+			}
+		})
+	}
+}
+
+func TestBulkDelete(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"DELETED\"],\"BulkDelete\":{\"threshold\":50,\"window\":\"2s\",\"summary\":true}}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	pipeline := meshsyncConfig.Pipelines[LocalResourceKey][0]
+	if pipeline.BulkDeleteThreshold != 50 || pipeline.BulkDeleteWindow != 2*time.Second || !pipeline.BulkDeleteSummary {
+		t.Errorf("bulk delete settings not propagated to the pipeline, got %+v", pipeline)
+	}
+
+	for _, bulkDelete := range []string{
+		"{\"threshold\":0,\"window\":\"2s\"}",
+		"{\"threshold\":50}",
+		"{\"threshold\":50,\"window\":\"-2s\"}",
+	} {
+		if _, err := PopulateConfigsFromMap(map[string]string{
+			"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"DELETED\"],\"BulkDelete\":" + bulkDelete + "}]",
+		}); err == nil {
+			t.Errorf("expected error for BulkDelete %s", bulkDelete)
+		}
+	}
+}
+
+func TestStaleness(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"Staleness\":{\"after\":\"5m\",\"notify\":true}},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	for _, pipeline := range meshsyncConfig.Pipelines[LocalResourceKey] {
+		expected := PipelineConfig{}
+		if pipeline.Name == "pods.v1." {
+			expected = PipelineConfig{StaleAfter: 5 * time.Minute, StaleNotify: true}
+		}
+		if pipeline.StaleAfter != expected.StaleAfter || pipeline.StaleNotify != expected.StaleNotify {
+			t.Errorf("expected staleness %s/%t for %s, got %s/%t", expected.StaleAfter, expected.StaleNotify, pipeline.Name, pipeline.StaleAfter, pipeline.StaleNotify)
+		}
+	}
+
+	for _, staleness := range []string{
+		"{}",
+		"{\"after\":\"0s\"}",
+		"{\"after\":\"soon\"}",
+	} {
+		if _, err := PopulateConfigsFromMap(map[string]string{
+			"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"Staleness\":" + staleness + "}]",
+		}); err == nil {
+			t.Errorf("expected error for Staleness %s", staleness)
+		}
+	}
+}
+
+func TestMaxConcurrentInitializing(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"blacklist":                 "[\"pods.v1.\"]",
+		"maxConcurrentInitializing": "5",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	if meshsyncConfig.MaxConcurrentInitializing != 5 {
+		t.Errorf("expected max concurrent initializing 5, got %d", meshsyncConfig.MaxConcurrentInitializing)
+	}
+
+	for _, value := range []string{"-1", "many"} {
+		if _, err := PopulateConfigsFromMap(map[string]string{
+			"blacklist":                 "[\"pods.v1.\"]",
+			"maxConcurrentInitializing": value,
+		}); err == nil {
+			t.Errorf("expected error for maxConcurrentInitializing %q", value)
+		}
+	}
+}
+
+func TestSingleton(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"MODIFIED\"],\"Singleton\":{\"interval\":\"30s\"}},{\"Resource\":\"services.v1.\",\"Events\":[\"MODIFIED\"]}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	for _, pipeline := range meshsyncConfig.Pipelines[LocalResourceKey] {
+		expected := time.Duration(0)
+		if pipeline.Name == "pods.v1." {
+			expected = 30 * time.Second
+		}
+		if pipeline.SingletonInterval != expected {
+			t.Errorf("expected singleton interval %s for %s, got %s", expected, pipeline.Name, pipeline.SingletonInterval)
+		}
+	}
+
+	for _, singleton := range []string{"{}", "{\"interval\":\"0s\"}", "{\"interval\":\"often\"}"} {
+		if _, err := PopulateConfigsFromMap(map[string]string{
+			"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"MODIFIED\"],\"Singleton\":" + singleton + "}]",
+		}); err == nil {
+			t.Errorf("expected error for Singleton %s", singleton)
+		}
+	}
+}
+
+func TestWasm(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"Wasm\":{\"module\":\"/plugins/redact.wasm\"}},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"],\"Wasm\":{\"module\":\"/plugins/tag.wasm\",\"timeout\":\"1s\",\"maxMemoryMiB\":64}}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	expected := map[string]PipelineConfig{
+		"pods.v1.":     {WasmModule: "/plugins/redact.wasm", WasmTimeout: DefaultWasmTimeout, WasmMaxMemoryMiB: DefaultWasmMaxMemoryMiB},
+		"services.v1.": {WasmModule: "/plugins/tag.wasm", WasmTimeout: time.Second, WasmMaxMemoryMiB: 64},
+	}
+	for _, pipeline := range meshsyncConfig.Pipelines[LocalResourceKey] {
+		e := expected[pipeline.Name]
+		if pipeline.WasmModule != e.WasmModule || pipeline.WasmTimeout != e.WasmTimeout || pipeline.WasmMaxMemoryMiB != e.WasmMaxMemoryMiB {
+			t.Errorf("expected wasm %s/%s/%d for %s, got %s/%s/%d", e.WasmModule, e.WasmTimeout, e.WasmMaxMemoryMiB, pipeline.Name, pipeline.WasmModule, pipeline.WasmTimeout, pipeline.WasmMaxMemoryMiB)
+		}
+	}
+
+	for _, wasm := range []string{
+		"{}",
+		"{\"module\":\"/plugins/redact.wasm\",\"timeout\":\"-1s\"}",
+		"{\"module\":\"/plugins/redact.wasm\",\"maxMemoryMiB\":-1}",
+	} {
+		if _, err := PopulateConfigsFromMap(map[string]string{
+			"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"Wasm\":" + wasm + "}]",
+		}); err == nil {
+			t.Errorf("expected error for Wasm %s", wasm)
+		}
+	}
+}
+
+func TestContainerChanges(t *testing.T) {
+	testCases := []struct {
+		name           string
+		config         string
+		expectedFields []string
+	}{
+		{name: "defaults", config: "{}", expectedFields: DefaultContainerFields},
+		{name: "custom", config: "{\"fields\":[\"image\"]}", expectedFields: []string{"image"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+				"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"MODIFIED\"],\"ContainerChanges\":" + tc.config + "}]",
+			})
+			if err != nil {
+				t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+			}
+			fields := meshsyncConfig.Pipelines[LocalResourceKey][0].ContainerFields
+			if !reflect.DeepEqual(fields, tc.expectedFields) {
+				t.Errorf("expected container fields %v, got %v", tc.expectedFields, fields)
+			}
+		})
+	}
+
+	for _, whitelist := range []string{
+		"[{\"Resource\":\"services.v1.\",\"Events\":[\"MODIFIED\"],\"ContainerChanges\":{}}]",
+		"[{\"Resource\":\"pods.v1.\",\"Events\":[\"MODIFIED\"],\"ContainerChanges\":{\"fields\":[\"\"]}}]",
+	} {
+		if _, err := PopulateConfigsFromMap(map[string]string{"whitelist": whitelist}); err == nil {
+			t.Errorf("expected error for whitelist %s", whitelist)
+		}
+	}
+}
+
+func TestNamespaceStrategy(t *testing.T) {
+	testCases := []struct {
+		name              string
+		data              map[string]string
+		expectErr         bool
+		expectedStrategy  string
+		expectedThreshold int
+	}{
+		{name: "default", data: map[string]string{}, expectedStrategy: NamespaceStrategyAll, expectedThreshold: DefaultNamespaceStrategyThreshold},
+		{name: "per namespace", data: map[string]string{"namespaceStrategy": "perNamespace"}, expectedStrategy: NamespaceStrategyPerNamespace, expectedThreshold: DefaultNamespaceStrategyThreshold},
+		{name: "auto", data: map[string]string{"namespaceStrategy": "auto", "namespaceStrategyThreshold": "3"}, expectedStrategy: NamespaceStrategyAuto, expectedThreshold: 3},
+		{name: "unknown strategy", data: map[string]string{"namespaceStrategy": "some"}, expectErr: true},
+		{name: "negative threshold", data: map[string]string{"namespaceStrategyThreshold": "-1"}, expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.data["whitelist"] = "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]"
+			meshsyncConfig, err := PopulateConfigsFromMap(tc.data)
+			if tc.expectErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			if meshsyncConfig.NamespaceStrategy != tc.expectedStrategy || meshsyncConfig.NamespaceStrategyThreshold != tc.expectedThreshold {
+				t.Errorf("expected strategy %s with threshold %d, got %s with %d", tc.expectedStrategy, tc.expectedThreshold, meshsyncConfig.NamespaceStrategy, meshsyncConfig.NamespaceStrategyThreshold)
+			}
+		})
+	}
+}
+
+func TestWatchedNamespaces(t *testing.T) {
+	namespaces := []string{"default", "prod"}
+	testCases := []struct {
+		config   PipelineConfig
+		settings GlobalSettings
+		expected []string
+	}{
+		{config: PipelineConfig{}, settings: GlobalSettings{NamespaceStrategy: NamespaceStrategyPerNamespace}, expected: nil},
+		{config: PipelineConfig{Namespaces: namespaces}, settings: GlobalSettings{NamespaceStrategy: NamespaceStrategyAll}, expected: nil},
+		{config: PipelineConfig{Namespaces: namespaces}, settings: GlobalSettings{NamespaceStrategy: NamespaceStrategyPerNamespace}, expected: namespaces},
+		{config: PipelineConfig{Namespaces: namespaces}, settings: GlobalSettings{NamespaceStrategy: NamespaceStrategyAuto, NamespaceStrategyThreshold: 2}, expected: namespaces},
+		{config: PipelineConfig{Namespaces: namespaces}, settings: GlobalSettings{NamespaceStrategy: NamespaceStrategyAuto, NamespaceStrategyThreshold: 1}, expected: nil},
+		{config: PipelineConfig{Namespaces: namespaces}, settings: GlobalSettings{NamespaceStrategy: NamespaceStrategyAll, NamespacedClients: true}, expected: namespaces},
+	}
+	for _, tc := range testCases {
+		if watched := tc.config.WatchedNamespaces(tc.settings); !reflect.DeepEqual(watched, tc.expected) {
+			t.Errorf("expected %+v to watch %v under %+v, got %v", tc.config, tc.expected, tc.settings, watched)
+		}
+	}
+}
+
+func TestNamespacedClients(t *testing.T) {
+	scoped := "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"Namespaces\":[\"team-a\",\"team-b\"]}]"
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist":         scoped,
+		"namespacedClients": "true",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if !meshsyncConfig.NamespacedClients {
+		t.Error("expected the namespaced clients setting to be enabled")
+	}
+	if watched := meshsyncConfig.Pipelines[LocalResourceKey][0].WatchedNamespaces(meshsyncConfig.GlobalSettings); !reflect.DeepEqual(watched, []string{"team-a", "team-b"}) {
+		t.Errorf("expected the namespaces to be watched one by one, got %v", watched)
+	}
+
+	testCases := []struct {
+		name     string
+		data     map[string]string
+		expected string
+	}{
+		{
+			name:     "namespaced pipeline without namespaces",
+			data:     map[string]string{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"],\"Namespaces\":[\"team-a\"]}]"},
+			expected: "pipelines [pods.v1.] are cluster scoped",
+		},
+		{
+			name:     "cluster scoped resource",
+			data:     map[string]string{"whitelist": "[{\"Resource\":\"namespaces.v1.\",\"Events\":[\"ADDED\"]}]"},
+			expected: "pipelines [namespaces.v1.] are cluster scoped",
+		},
+		{
+			name:     "blacklist mode",
+			data:     map[string]string{"blacklist": "[\"pods.v1.\"]"},
+			expected: "are cluster scoped",
+		},
+		{
+			name:     "rollup",
+			data:     map[string]string{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"Namespaces\":[\"team-a\"],\"Rollup\":{}}]"},
+			expected: "pipelines [pods.v1.] roll up Pods",
+		},
+		{
+			name:     "strict namespaces",
+			data:     map[string]string{"whitelist": scoped, "strictNamespaces": "true"},
+			expected: "strictNamespaces requires to list the cluster scoped namespaces",
+		},
+		{
+			name:     "namespace metadata",
+			data:     map[string]string{"whitelist": scoped, "namespaceMetadata": "true"},
+			expected: "namespaceMetadata requires to list the cluster scoped namespaces",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.data["namespacedClients"] = "true"
+			_, err := PopulateConfigsFromMap(tc.data)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("expected the error to contain %q, got %s", tc.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestSummary(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"Summary\":{\"interval\":\"30s\",\"only\":true}},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	for _, pipeline := range meshsyncConfig.Pipelines[LocalResourceKey] {
+		expectedInterval, expectedOnly := time.Duration(0), false
+		if pipeline.Name == "pods.v1." {
+			expectedInterval, expectedOnly = 30*time.Second, true
+		}
+		if pipeline.SummaryInterval != expectedInterval || pipeline.SummaryOnly != expectedOnly {
+			t.Errorf("expected summary %s/%t for %s, got %s/%t", expectedInterval, expectedOnly, pipeline.Name, pipeline.SummaryInterval, pipeline.SummaryOnly)
+		}
+	}
+
+	for _, summary := range []string{"{}", "{\"interval\":\"0s\"}", "{\"interval\":\"often\"}"} {
+		if _, err := PopulateConfigsFromMap(map[string]string{
+			"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"Summary\":" + summary + "}]",
+		}); err == nil {
+			t.Errorf("expected error for Summary %s", summary)
+		}
+	}
+}
+
+func TestPruneDefaults(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"PruneDefaults\":true},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	for _, pipeline := range meshsyncConfig.Pipelines[LocalResourceKey] {
+		expected := pipeline.Name == "pods.v1."
+		if pipeline.PruneDefaults != expected {
+			t.Errorf("expected prune defaults %t for %s, got %t", expected, pipeline.Name, pipeline.PruneDefaults)
+		}
+	}
+}
+
+func TestIdentityPath(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"MODIFIED\"],\"Singleton\":{\"interval\":\"30s\"},\"IdentityPath\":\"$.spec.hostname\"},{\"Resource\":\"services.v1.\",\"Events\":[\"MODIFIED\"]}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	for _, pipeline := range meshsyncConfig.Pipelines[LocalResourceKey] {
+		expected := ""
+		if pipeline.Name == "pods.v1." {
+			expected = "$.spec.hostname"
+		}
+		if pipeline.IdentityPath != expected {
+			t.Errorf("expected identity path %q for %s, got %q", expected, pipeline.Name, pipeline.IdentityPath)
+		}
+	}
+
+	for _, resource := range []string{
+		"{\"Resource\":\"pods.v1.\",\"Events\":[\"MODIFIED\"],\"Singleton\":{\"interval\":\"30s\"},\"IdentityPath\":\"$.spec[\"}",
+		"{\"Resource\":\"pods.v1.\",\"Events\":[\"MODIFIED\"],\"IdentityPath\":\"$.spec.hostname\"}",
+	} {
+		if _, err := PopulateConfigsFromMap(map[string]string{"whitelist": "[" + resource + "]"}); err == nil {
+			t.Errorf("expected error for %s", resource)
+		}
+	}
+}
+
+func TestCacheTrim(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"MODIFIED\"],\"CacheTrim\":[\"managedFields\",\"lastApplied\",\"status\"]},{\"Resource\":\"services.v1.\",\"Events\":[\"MODIFIED\"]}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	for _, pipeline := range meshsyncConfig.Pipelines[LocalResourceKey] {
+		var expected []string
+		if pipeline.Name == "pods.v1." {
+			expected = []string{CacheTrimManagedFields, CacheTrimLastApplied, CacheTrimStatus}
+		}
+		if !reflect.DeepEqual(pipeline.CacheTrim, expected) {
+			t.Errorf("expected cache trim %v for %s, got %v", expected, pipeline.Name, pipeline.CacheTrim)
+		}
+	}
+
+	for _, resource := range []string{
+		"{\"Resource\":\"pods.v1.\",\"Events\":[\"MODIFIED\"],\"CacheTrim\":[\"spec\"]}",
+		"{\"Resource\":\"pods.v1.\",\"Events\":[\"MODIFIED\"],\"CacheTrim\":[\"status\"],\"ContainerChanges\":{}}",
+	} {
+		if _, err := PopulateConfigsFromMap(map[string]string{"whitelist": "[" + resource + "]"}); err == nil {
+			t.Errorf("expected error for %s", resource)
+		}
+	}
+}
+
+func TestMetadataChanges(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"MODIFIED\"],\"MetadataChanges\":{\"labels\":[\"team\"],\"annotations\":[\"owner\"]}}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	pipeline := meshsyncConfig.Pipelines[LocalResourceKey][0]
+	if !reflect.DeepEqual(pipeline.MetadataLabels, []string{"team"}) || !reflect.DeepEqual(pipeline.MetadataAnnotations, []string{"owner"}) {
+		t.Errorf("expected the monitored labels and annotations, got %v and %v", pipeline.MetadataLabels, pipeline.MetadataAnnotations)
+	}
+
+	for _, config := range []string{"{}", "{\"labels\":[\"\"]}"} {
+		if _, err := PopulateConfigsFromMap(map[string]string{
+			"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"MODIFIED\"],\"MetadataChanges\":" + config + "}]",
+		}); err == nil {
+			t.Errorf("expected error for MetadataChanges %s", config)
+		}
+	}
+}
+
+func TestDeleteSemantics(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"DELETED\"],\"DeleteSemantics\":\"onDeletionTimestamp\"},{\"Resource\":\"services.v1.\",\"Events\":[\"DELETED\"]}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	for _, pipeline := range meshsyncConfig.Pipelines[LocalResourceKey] {
+		expected := DeleteOnFinalRemoval
+		if pipeline.Name == "pods.v1." {
+			expected = DeleteOnDeletionTimestamp
+		}
+		if pipeline.DeleteSemantics != expected {
+			t.Errorf("expected delete semantics %q for %s, got %q", expected, pipeline.Name, pipeline.DeleteSemantics)
+		}
+	}
+
+	if _, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"DELETED\"],\"DeleteSemantics\":\"onFinalizer\"}]",
+	}); err == nil {
+		t.Error("expected error for an unknown DeleteSemantics")
+	}
+}
+
+func TestRollup(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"MODIFIED\"],\"Rollup\":{\"only\":true}}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	pipeline := meshsyncConfig.Pipelines[LocalResourceKey][0]
+	if !pipeline.Rollup || !pipeline.RollupOnly {
+		t.Errorf("expected the rollups to replace the Pod events, got rollup %t and only %t", pipeline.Rollup, pipeline.RollupOnly)
+	}
+
+	if _, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"services.v1.\",\"Events\":[\"MODIFIED\"],\"Rollup\":{}}]",
+	}); err == nil {
+		t.Error("expected error for a rollup of services")
+	}
+}
+
+func TestBackfillRevisions(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"BackfillRevisions\":500}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	if revisions := meshsyncConfig.Pipelines[LocalResourceKey][0].BackfillRevisions; revisions != 500 {
+		t.Errorf("expected 500 backfilled revisions, got %d", revisions)
+	}
+
+	for _, revisions := range []string{"-1", "100001"} {
+		if _, err := PopulateConfigsFromMap(map[string]string{
+			"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"BackfillRevisions\":" + revisions + "}]",
+		}); err == nil {
+			t.Errorf("expected error for BackfillRevisions %s", revisions)
+		}
+	}
+}
+
+func TestWhiteListNamespaces(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"Namespaces\":[\"team-a\",\"team-b\"]},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"namespaces.v1.\",\"Events\":[\"ADDED\"]}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	for _, pipeline := range meshsyncConfig.Pipelines[LocalResourceKey] {
+		var expected []string
+		if pipeline.Name == "pods.v1." {
+			expected = []string{"team-a", "team-b"}
+		}
+		if !reflect.DeepEqual(pipeline.Namespaces, expected) {
+			t.Errorf("expected %s to be scoped to %v, got %v", pipeline.Name, expected, pipeline.Namespaces)
+		}
+	}
+	if namespaces := meshsyncConfig.Pipelines[GlobalResourceKey][0].Namespaces; namespaces != nil {
+		t.Errorf("expected namespaces.v1. to be cluster-wide, got %v", namespaces)
+	}
+
+	invalid := map[string]map[string]string{
+		"global resource": {
+			"whitelist": "[{\"Resource\":\"namespaces.v1.\",\"Events\":[\"ADDED\"],\"Namespaces\":[\"team-a\"]}]",
+		},
+		"empty namespace": {
+			"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"Namespaces\":[\"\"]}]",
+		},
+		"scoped twice": {
+			"whitelist":  "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"Namespaces\":[\"team-a\"]}]",
+			"namespaces": "{\"pods.v1.\":[\"team-b\"]}",
+		},
+	}
+	for name, data := range invalid {
+		if _, err := PopulateConfigsFromMap(data); err == nil {
+			t.Errorf("expected error for %s", name)
+		}
+	}
+}
+
+func TestExcludeNamespaces(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist":         "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"Namespaces\":[\"team-a\",\"kube-system\"]},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"namespaces.v1.\",\"Events\":[\"ADDED\"]}]",
+		"namespaces":        "{\"services.v1.\":[\"team-b\"]}",
+		"excludeNamespaces": "[\"kube-system\",\"kube-public\"]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	expectedNamespaces := map[string][]string{
+		// the inclusion list minus the exclusions
+		"pods.v1.":     {"team-a"},
+		"services.v1.": {"team-b"},
+	}
+	for _, pipeline := range meshsyncConfig.Pipelines[LocalResourceKey] {
+		if !reflect.DeepEqual(pipeline.Namespaces, expectedNamespaces[pipeline.Name]) {
+			t.Errorf("expected %s to be scoped to %v, got %v", pipeline.Name, expectedNamespaces[pipeline.Name], pipeline.Namespaces)
+		}
+		if pipeline.ExcludeNamespaces != nil {
+			t.Errorf("expected no exclusions for %s scoped to namespaces, got %v", pipeline.Name, pipeline.ExcludeNamespaces)
+		}
+	}
+	// the pipelines watching all namespaces drop the objects of the excluded ones, cluster scoped objects have none
+	namespaces := meshsyncConfig.Pipelines[GlobalResourceKey][0]
+	if !reflect.DeepEqual(namespaces.ExcludeNamespaces, []string{"kube-system", "kube-public"}) || namespaces.Namespaces != nil {
+		t.Errorf("expected namespaces.v1. to watch all namespaces but the excluded ones, got %v excluding %v", namespaces.Namespaces, namespaces.ExcludeNamespaces)
+	}
+
+	invalid := map[string]map[string]string{
+		"every namespace excluded": {
+			"whitelist":         "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"Namespaces\":[\"kube-system\"]}]",
+			"excludeNamespaces": "[\"kube-system\"]",
+		},
+		"empty namespace": {
+			"whitelist":         "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]",
+			"excludeNamespaces": "[\"\"]",
+		},
+		"not a list": {
+			"whitelist":         "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]",
+			"excludeNamespaces": "kube-system",
+		},
+	}
+	for name, data := range invalid {
+		if _, err := PopulateConfigsFromMap(data); err == nil {
+			t.Errorf("expected error for %s", name)
+		}
+	}
+}
+
+func TestObjectLogSampling(t *testing.T) {
+	whitelist := "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]"
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist":       whitelist,
+		"objectLogEvery":  "10",
+		"objectLogLimit":  "100",
+		"objectLogWindow": "5s",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	expected := ObjectLogSampling{Every: 10, Limit: 100, Window: 5 * time.Second}
+	if sampling := meshsyncConfig.ObjectLogSampling; sampling != expected {
+		t.Errorf("expected object log sampling %+v, got %+v", expected, sampling)
+	}
+
+	invalid := []map[string]string{
+		{"objectLogEvery": "-1"},
+		{"objectLogLimit": "many"},
+		{"objectLogWindow": "0s"},
+		{"objectLogWindow": "1 minute"},
+	}
+	for _, data := range invalid {
+		data["whitelist"] = whitelist
+		if _, err := PopulateConfigsFromMap(data); err == nil {
+			t.Errorf("expected error for %v", data)
+		}
+	}
+}
+
+func TestRelationships(t *testing.T) {
+	podsAndServices := "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]"
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist":     podsAndServices,
+		"relationships": "[\"service-pod\"]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	if !reflect.DeepEqual(meshsyncConfig.Relationships, []string{RelationshipServicePod}) {
+		t.Errorf("expected the relationships [%s], got %v", RelationshipServicePod, meshsyncConfig.Relationships)
+	}
+
+	invalid := map[string]map[string]string{
+		"unknown relationship type": {"whitelist": podsAndServices, "relationships": "[\"pod-node\"]"},
+		"not a list":                {"whitelist": podsAndServices, "relationships": "service-pod"},
+		"target not watched": {
+			"whitelist":     "[{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]",
+			"relationships": "[\"service-pod\"]",
+		},
+		"source not watched": {"whitelist": podsAndServices, "relationships": "[\"ingress-service\"]"},
+	}
+	for name, data := range invalid {
+		if _, err := PopulateConfigsFromMap(data); err == nil {
+			t.Errorf("expected error for %s", name)
+		}
+	}
+}
+
+func TestLabelSelector(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"labelSelector\":\"app.kubernetes.io/managed-by=meshery, tier in (web,api)\"},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	for _, pipeline := range meshsyncConfig.Pipelines[LocalResourceKey] {
+		expected := ""
+		if pipeline.Name == "pods.v1." {
+			expected = "app.kubernetes.io/managed-by=meshery,tier in (api,web)"
+		}
+		if pipeline.LabelSelector != expected {
+			t.Errorf("expected the label selector %q for %s, got %q", expected, pipeline.Name, pipeline.LabelSelector)
+		}
+	}
+
+	for _, selector := range []string{"managed by=meshery", "tier in web", "!=meshery"} {
+		_, err := PopulateConfigsFromMap(map[string]string{
+			"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"labelSelector\":\"" + selector + "\"}]",
+		})
+		if err == nil {
+			t.Errorf("expected error for the label selector %q", selector)
+			continue
+		}
+		if codeOf(err) != ErrInitConfigCode {
+			t.Errorf("expected error code %s for the label selector %q, got %s", ErrInitConfigCode, selector, codeOf(err))
+		}
+	}
+}
+
+func TestAnnotationFilter(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"annotationFilter\":{\"meshery.io/managed\":\"true\"}},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	for _, pipeline := range meshsyncConfig.Pipelines[LocalResourceKey] {
+		var expected map[string]string
+		if pipeline.Name == "pods.v1." {
+			expected = map[string]string{"meshery.io/managed": "true"}
+		}
+		if !reflect.DeepEqual(pipeline.AnnotationFilter, expected) {
+			t.Errorf("expected the annotation filter %v for %s, got %v", expected, pipeline.Name, pipeline.AnnotationFilter)
+		}
+	}
+
+	for _, key := range []string{"", "managed by", "meshery.io/"} {
+		_, err := PopulateConfigsFromMap(map[string]string{
+			"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"annotationFilter\":{\"" + key + "\":\"true\"}}]",
+		})
+		if err == nil {
+			t.Errorf("expected error for the annotation key %q", key)
+			continue
+		}
+		if codeOf(err) != ErrInitConfigCode {
+			t.Errorf("expected error code %s for the annotation key %q, got %s", ErrInitConfigCode, key, codeOf(err))
+		}
+	}
+}
+
+func TestFieldSelector(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"fieldSelector\":\"status.phase=Running,spec.nodeName!=node-a\"},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	for _, pipeline := range meshsyncConfig.Pipelines[LocalResourceKey] {
+		expected := ""
+		if pipeline.Name == "pods.v1." {
+			expected = "spec.nodeName!=node-a,status.phase=Running"
+		}
+		if pipeline.FieldSelector != expected {
+			t.Errorf("expected the field selector %q for %s, got %q", expected, pipeline.Name, pipeline.FieldSelector)
+		}
+	}
+
+	for _, selector := range []string{
+		// not a selector
+		"status.phase",
+		// set based requirements are not supported by field selectors
+		"status.phase in (Running)",
+		// not selectable server-side
+		"spec.containers=web",
+		"metadata.labels=web",
+	} {
+		_, err := PopulateConfigsFromMap(map[string]string{
+			"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"fieldSelector\":\"" + selector + "\"}]",
+		})
+		if err == nil {
+			t.Errorf("expected error for the field selector %q", selector)
+			continue
+		}
+		if codeOf(err) != ErrInitConfigCode {
+			t.Errorf("expected error code %s for the field selector %q, got %s", ErrInitConfigCode, selector, codeOf(err))
+		}
+	}
+
+	// the fields of resources unknown to SelectableFields are left to the API server
+	if _, err := parseFieldSelector("certificates.v1.cert-manager.io", "spec.issuerRef.name=letsencrypt"); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+}
+
+func TestFieldDenylist(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist":     "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]",
+		"fieldDenylist": "[\"$..env\",\"$.metadata.annotations\"]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	expected := []string{"$..env", "$.metadata.annotations"}
+	if !reflect.DeepEqual(meshsyncConfig.FieldDenylist, expected) {
+		t.Errorf("expected the field denylist %v, got %v", expected, meshsyncConfig.FieldDenylist)
+	}
+
+	for _, denylist := range []string{"$..env", "[\"$.spec[\"]"} {
+		if _, err := PopulateConfigsFromMap(map[string]string{
+			"whitelist":     "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]",
+			"fieldDenylist": denylist,
+		}); err == nil {
+			t.Errorf("expected error for the field denylist %s", denylist)
+		}
+	}
+}
+
+func TestPollInterval(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"PollInterval\":\"5m\"},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	for _, pipeline := range meshsyncConfig.Pipelines[LocalResourceKey] {
+		expected := time.Duration(0)
+		if pipeline.Name == "pods.v1." {
+			expected = 5 * time.Minute
+		}
+		if pipeline.PollInterval != expected {
+			t.Errorf("expected the poll interval %s for %s, got %s", expected, pipeline.Name, pipeline.PollInterval)
+		}
+	}
+
+	for _, resource := range []string{
+		"{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"PollInterval\":\"often\"}",
+		"{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"PollInterval\":\"1s\"}",
+		"{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"PollInterval\":\"5m\",\"MaxWatchAge\":\"30m\"}",
+		"{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"PollInterval\":\"5m\",\"BackfillRevisions\":2}",
+	} {
+		_, err := PopulateConfigsFromMap(map[string]string{"whitelist": "[" + resource + "]"})
+		if err == nil {
+			t.Errorf("expected error for %s", resource)
+			continue
+		}
+		if codeOf(err) != ErrInitConfigCode {
+			t.Errorf("expected error code %s for %s, got %s", ErrInitConfigCode, resource, codeOf(err))
+		}
+	}
+}
+
+func TestResyncPeriod(t *testing.T) {
+	whitelist := "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"ResyncPeriod\":\"30s\"},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"],\"ResyncPeriod\":\"0\"},{\"Resource\":\"deployments.v1.apps\",\"Events\":[\"ADDED\"]}]"
+	testCases := []struct {
+		name     string
+		data     map[string]string
+		expected map[string]time.Duration
+	}{
+		{
+			name:     "no global resync",
+			data:     map[string]string{"whitelist": whitelist},
+			expected: map[string]time.Duration{"pods.v1.": 30 * time.Second, "services.v1.": 0, "deployments.v1.apps": 0},
+		},
+		{
+			name:     "global resync",
+			data:     map[string]string{"whitelist": whitelist, "resyncPeriod": "10m"},
+			expected: map[string]time.Duration{"pods.v1.": 30 * time.Second, "services.v1.": 0, "deployments.v1.apps": 10 * time.Minute},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			meshsyncConfig, err := PopulateConfigsFromMap(tc.data)
+			if err != nil {
+				t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+			}
+			for _, pipeline := range meshsyncConfig.Pipelines[LocalResourceKey] {
+				if expected := tc.expected[pipeline.Name]; pipeline.ResyncPeriod != expected {
+					t.Errorf("expected the resync period %s for %s, got %s", expected, pipeline.Name, pipeline.ResyncPeriod)
+				}
+			}
+		})
+	}
+
+	// resources watched in the blacklist mode resync with the global period
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{"blacklist": "[\"pods.v1.\"]", "resyncPeriod": "1h"})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	for _, pipeline := range meshsyncConfig.Pipelines[LocalResourceKey] {
+		if pipeline.ResyncPeriod != time.Hour {
+			t.Errorf("expected the global resync period for %s, got %s", pipeline.Name, pipeline.ResyncPeriod)
+		}
+	}
+
+	for _, data := range []map[string]string{
+		{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"ResyncPeriod\":\"often\"}]"},
+		{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"ResyncPeriod\":\"-30s\"}]"},
+		{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"ResyncPeriod\":\"100ms\"}]"},
+		{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]", "resyncPeriod": "often"},
+		{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]", "resyncPeriod": "-1m"},
+	} {
+		_, err := PopulateConfigsFromMap(data)
+		if err == nil {
+			t.Errorf("expected error for %v", data)
+			continue
+		}
+		if codeOf(err) != ErrInitConfigCode {
+			t.Errorf("expected error code %s for %v, got %s", ErrInitConfigCode, data, codeOf(err))
+		}
+	}
+}
+
+func TestPaused(t *testing.T) {
+	whitelist := "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"namespaces.v1.\",\"Events\":[\"ADDED\"]}]"
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{"whitelist": whitelist, "paused": "true"})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	if !meshsyncConfig.Paused {
+		t.Error("expected the config to be paused")
+	}
+	if meshsyncConfig.Pipelines == nil || len(meshsyncConfig.Pipelines) != 0 {
+		t.Errorf("expected no pipelines while paused, got %v", meshsyncConfig.Pipelines)
+	}
+	if len(meshsyncConfig.WhiteList) != 2 {
+		t.Errorf("expected the whitelist to be kept while paused, got %v", meshsyncConfig.WhiteList)
+	}
+	// the sinks of the pipelines are opened while paused
+	assertPipelineNames(t, LocalResourceKey, meshsyncConfig.PausedPipelines[LocalResourceKey], []string{"pods.v1."})
+	if registry := Pipelines[LocalResourceKey]; len(registry) <= 1 {
+		t.Errorf("expected the registry to be left intact, got %v", registry)
+	}
+
+	// resuming re-reads the same watch-list
+	meshsyncConfig, err = PopulateConfigsFromMap(map[string]string{"whitelist": whitelist, "paused": "false"})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	if meshsyncConfig.Paused {
+		t.Error("expected the config not to be paused")
+	}
+	assertPipelineNames(t, GlobalResourceKey, meshsyncConfig.Pipelines[GlobalResourceKey], []string{"namespaces.v1."})
+	assertPipelineNames(t, LocalResourceKey, meshsyncConfig.Pipelines[LocalResourceKey], []string{"pods.v1."})
+
+	// the lists are validated while paused
+	for _, data := range []map[string]string{
+		{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"pods.v1.\",\"Events\":[\"DELETED\"]}]", "paused": "true"},
+		{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"TOUCHED\"]}]", "paused": "true"},
+		{"paused": "true"},
+		{"whitelist": whitelist, "paused": "sometimes"},
+	} {
+		_, err := PopulateConfigsFromMap(data)
+		if err == nil {
+			t.Errorf("expected error for %v", data)
+			continue
+		}
+		if codeOf(err) != ErrInitConfigCode {
+			t.Errorf("expected error code %s for %v, got %s", ErrInitConfigCode, data, codeOf(err))
+		}
+	}
+}
+
+func TestWhiteListEvents(t *testing.T) {
+	testCases := []struct {
+		name      string
+		events    string
+		expected  []string
+		expectErr bool
+	}{
+		{name: "canonical", events: "[\"ADDED\",\"DELETED\"]", expected: []string{"ADDED", "DELETED"}},
+		{name: "case-insensitive", events: "[\"added\",\"Modified\"]", expected: []string{"ADDED", "MODIFIED"}},
+		{name: "duplicates", events: "[\"ADDED\",\"MODIFIED\",\"added\",\"ADDED\"]", expected: []string{"ADDED", "MODIFIED"}},
+		{name: "empty", events: "[]", expected: DefaultEvents},
+		{name: "omitted", expected: DefaultEvents},
+		{name: "typo", events: "[\"ADDED\",\"UDPATE\"]", expectErr: true},
+		{name: "meshkit event which is not emitted", events: "[\"RESYNC\"]", expectErr: true},
+		{name: "empty event", events: "[\"\"]", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			entry := "{\"Resource\":\"pods.v1.\"}"
+			if tc.events != "" {
+				entry = "{\"Resource\":\"pods.v1.\",\"Events\":" + tc.events + "}"
+			}
+			meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{"whitelist": "[" + entry + "]"})
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				if codeOf(err) != ErrInitConfigCode {
+					t.Errorf("expected error code %s, got %s", ErrInitConfigCode, codeOf(err))
+				}
+				if !strings.Contains(err.Error(), "pods.v1.") {
+					t.Errorf("expected the error to name the resource, got %q", err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			if events := meshsyncConfig.Pipelines[LocalResourceKey][0].Events; !reflect.DeepEqual(events, tc.expected) {
+				t.Errorf("expected the events %v, got %v", tc.expected, events)
+			}
+		})
+	}
+}
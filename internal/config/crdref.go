@@ -0,0 +1,59 @@
+package config
+
+import (
+	"os"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// CRDConfig locates the MeshSync Custom Resource
+type CRDConfig struct {
+	// Namespace and Name of the Custom Resource
+	Namespace string
+	Name      string
+	// Group, Version and Resource of its Custom Resource Definition
+	Group    string
+	Version  string
+	Resource string
+}
+
+// DefaultCRDConfig locates the Custom Resource of a default install
+var DefaultCRDConfig = CRDConfig{
+	Namespace: "meshery",
+	Name:      "meshery-meshsync",
+	Group:     "meshery.io",
+	Version:   "v1alpha1",
+	Resource:  "meshsyncs",
+}
+
+// environment variables overriding the fields of DefaultCRDConfig
+const (
+	CRNamespaceEnv = "MESHSYNC_CR_NAMESPACE"
+	CRNameEnv      = "MESHSYNC_CR_NAME"
+	CRGroupEnv     = "MESHSYNC_CR_GROUP"
+	CRVersionEnv   = "MESHSYNC_CR_VERSION"
+	CRResourceEnv  = "MESHSYNC_CR_RESOURCE"
+)
+
+// CRDConfigFromEnv returns DefaultCRDConfig with the fields set by the environment variables overridden,
+// f.e. MESHSYNC_CR_NAMESPACE for an install in another namespace
+func CRDConfigFromEnv() CRDConfig {
+	crdConfig := DefaultCRDConfig
+	for env, field := range map[string]*string{
+		CRNamespaceEnv: &crdConfig.Namespace,
+		CRNameEnv:      &crdConfig.Name,
+		CRGroupEnv:     &crdConfig.Group,
+		CRVersionEnv:   &crdConfig.Version,
+		CRResourceEnv:  &crdConfig.Resource,
+	} {
+		if value := os.Getenv(env); value != "" {
+			*field = value
+		}
+	}
+	return crdConfig
+}
+
+// GVR returns the group version resource of the Custom Resource
+func (c CRDConfig) GVR() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: c.Group, Version: c.Version, Resource: c.Resource}
+}
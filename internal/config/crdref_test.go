@@ -0,0 +1,271 @@
+package config
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meshery/meshkit/logger"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCRDConfigFromEnv(t *testing.T) {
+	if crdConfig := CRDConfigFromEnv(); crdConfig != DefaultCRDConfig {
+		t.Errorf("expected the default coordinates %+v without environment, got %+v", DefaultCRDConfig, crdConfig)
+	}
+
+	t.Setenv(CRNamespaceEnv, "platform")
+	t.Setenv(CRNameEnv, "cluster-sync")
+	expected := DefaultCRDConfig
+	expected.Namespace = "platform"
+	expected.Name = "cluster-sync"
+	if crdConfig := CRDConfigFromEnv(); crdConfig != expected {
+		t.Errorf("expected coordinates %+v, got %+v", expected, crdConfig)
+	}
+	if !isRequiredWrite(requiredWrite()) || requiredWrite().Namespace != "platform" {
+		t.Errorf("expected the patch of the Custom Resource in its configured namespace to be required, got %+v", requiredWrite())
+	}
+}
+
+func TestGetMeshsyncCRDConfigsFor(t *testing.T) {
+	crdConfig := CRDConfig{Namespace: "platform", Name: "cluster-sync", Group: "example.com", Version: "v1", Resource: "meshsyncs"}
+	cr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": crdConfig.Group + "/" + crdConfig.Version,
+		"kind":       "MeshSync",
+		"metadata":   map[string]interface{}{"name": crdConfig.Name, "namespace": crdConfig.Namespace},
+		"spec": map[string]interface{}{
+			"watch-list": map[string]interface{}{
+				"data": map[string]interface{}{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]"},
+			},
+		},
+	}}
+	dyClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		crdConfig.GVR():        "MeshSyncList",
+		DefaultCRDConfig.GVR(): "MeshSyncList",
+	}, cr)
+
+	meshsyncConfig, err := GetMeshsyncCRDConfigsFor(context.Background(), dyClient, crdConfig)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	assertPipelineNames(t, LocalResourceKey, meshsyncConfig.Pipelines[LocalResourceKey], []string{"pods.v1."})
+
+	if _, err := GetMeshsyncCRDConfigs(context.Background(), dyClient); err == nil {
+		t.Error("expected error for the Custom Resource missing at the default coordinates")
+	}
+}
+
+func TestPatchCRVersion(t *testing.T) {
+	cr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": DefaultCRDConfig.Group + "/" + DefaultCRDConfig.Version,
+		"kind":       "MeshSync",
+		"metadata":   map[string]interface{}{"name": DefaultCRDConfig.Name, "namespace": DefaultCRDConfig.Namespace},
+		"spec": map[string]interface{}{
+			"version": "v0.6.0",
+			"broker":  map[string]interface{}{"native": map[string]interface{}{"name": "meshery-broker"}},
+		},
+		"status": map[string]interface{}{"publishing-to": "meshery-broker:4222"},
+	}}
+	dyClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		DefaultCRDConfig.GVR(): "MeshSyncList",
+	}, cr)
+	crs := dyClient.Resource(DefaultCRDConfig.GVR()).Namespace(DefaultCRDConfig.Namespace)
+
+	// the operator updates the status between MeshSync reading the Custom Resource and patching it
+	dyClient.PrependReactor("patch", DefaultCRDConfig.Resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if patch := action.(k8stesting.PatchAction); patch.GetPatchType() != types.MergePatchType || patch.GetSubresource() != "" {
+			t.Errorf("expected a merge patch of the main resource, got a %s patch of %q", patch.GetPatchType(), patch.GetSubresource())
+		}
+		obj, err := dyClient.Tracker().Get(DefaultCRDConfig.GVR(), DefaultCRDConfig.Namespace, DefaultCRDConfig.Name)
+		if err != nil {
+			return true, nil, err
+		}
+		current := obj.(*unstructured.Unstructured).DeepCopy()
+		_ = unstructured.SetNestedField(current.Object, "meshery-broker:4223", "status", "publishing-to")
+		return false, nil, dyClient.Tracker().Update(DefaultCRDConfig.GVR(), current, DefaultCRDConfig.Namespace)
+	})
+
+	if err := patchCRVersion(context.Background(), dyClient, DefaultCRDConfig, "v0.7.0"); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	patched, err := crs.Get(context.Background(), DefaultCRDConfig.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"version": "v0.7.0",
+			"broker":  map[string]interface{}{"native": map[string]interface{}{"name": "meshery-broker"}},
+		},
+		"status": map[string]interface{}{"publishing-to": "meshery-broker:4223"},
+	}
+	got := map[string]interface{}{"spec": patched.Object["spec"], "status": patched.Object["status"]}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected only spec.version to be patched, got %v", got)
+	}
+}
+
+func TestPatchCRVersionWithoutSpec(t *testing.T) {
+	cr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": DefaultCRDConfig.Group + "/" + DefaultCRDConfig.Version,
+		"kind":       "MeshSync",
+		"metadata":   map[string]interface{}{"name": DefaultCRDConfig.Name, "namespace": DefaultCRDConfig.Namespace},
+		"status":     map[string]interface{}{"publishing-to": "meshery-broker:4222"},
+	}}
+	dyClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		DefaultCRDConfig.GVR(): "MeshSyncList",
+	}, cr)
+
+	if err := patchCRVersion(context.Background(), dyClient, DefaultCRDConfig, "v0.7.0"); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	patched, err := dyClient.Resource(DefaultCRDConfig.GVR()).Namespace(DefaultCRDConfig.Namespace).Get(context.Background(), DefaultCRDConfig.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"spec":   map[string]interface{}{"version": "v0.7.0"},
+		"status": map[string]interface{}{"publishing-to": "meshery-broker:4222"},
+	}
+	got := map[string]interface{}{"spec": patched.Object["spec"], "status": patched.Object["status"]}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected spec.version to be created, got %v", got)
+	}
+}
+
+func TestCRDFetchCancelled(t *testing.T) {
+	dyClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		DefaultCRDConfig.GVR(): "MeshSyncList",
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, errGet := GetMeshsyncCRD(ctx, dyClient)
+	_, errConfigs := GetMeshsyncCRDConfigs(ctx, dyClient)
+	errPatch := patchCRVersion(ctx, dyClient, DefaultCRDConfig, "v0.7.0")
+	for name, err := range map[string]error{"GetMeshsyncCRD": errGet, "GetMeshsyncCRDConfigs": errConfigs, "PatchCRVersion": errPatch} {
+		if err == nil {
+			t.Errorf("%s: expected error for the cancelled context", name)
+			continue
+		}
+		if codeOf(err) != ErrInitConfigCode {
+			t.Errorf("%s: expected error code %s, got %s", name, ErrInitConfigCode, codeOf(err))
+		}
+		if !strings.Contains(err.Error(), context.Canceled.Error()) {
+			t.Errorf("%s: expected the context error, got %s", name, err.Error())
+		}
+	}
+	if actions := dyClient.Actions(); len(actions) != 0 {
+		t.Errorf("expected no request to the API server, got %v", actions)
+	}
+}
+
+func TestConfigErrorsCarryTheCause(t *testing.T) {
+	dyClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		DefaultCRDConfig.GVR(): "MeshSyncList",
+	})
+	forbidden := apierrors.NewForbidden(DefaultCRDConfig.GVR().GroupResource(), DefaultCRDConfig.Name, nil)
+	dyClient.PrependReactor("patch", DefaultCRDConfig.Resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, forbidden
+	})
+	errPatch := patchCRVersion(context.Background(), dyClient, DefaultCRDConfig, "v0.7.0")
+
+	localConfig := LocalMeshsyncConfig
+	defer func() { LocalMeshsyncConfig = localConfig }()
+	LocalMeshsyncConfig = map[string]string{"whitelist": "not a watch-list"}
+	_, errLocal := GetMeshsyncCRDConfigsLocal()
+
+	for name, err := range map[string]error{"PatchCRVersion": errPatch, "GetMeshsyncCRDConfigsLocal": errLocal} {
+		if err == nil {
+			t.Errorf("%s: expected error", name)
+			continue
+		}
+		if codeOf(err) != ErrInitConfigCode {
+			t.Errorf("%s: expected error code %s, got %s", name, ErrInitConfigCode, codeOf(err))
+		}
+		if err.Error() == "" || strings.Contains(err.Error(), "Missing or outdated CRD") {
+			t.Errorf("%s: expected the error message to carry the cause, got %q", name, err.Error())
+		}
+	}
+	if !strings.Contains(errPatch.Error(), forbidden.Error()) {
+		t.Errorf("expected the error of the API server, got %q", errPatch.Error())
+	}
+}
+
+func TestGetMeshsyncCRDConfigsWithFallback(t *testing.T) {
+	log, err := logger.New("meshsync-test", logger.Options{Format: logger.SyslogLogFormat})
+	if err != nil {
+		t.Fatal(err)
+	}
+	localConfig := LocalMeshsyncConfig
+	defer func() { LocalMeshsyncConfig = localConfig }()
+	LocalMeshsyncConfig = map[string]string{"whitelist": "[{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]"}
+	retry := DefaultRetryOptions
+	defer func() { DefaultRetryOptions = retry }()
+	DefaultRetryOptions = RetryOptions{Retries: 2, BaseDelay: time.Millisecond}
+
+	cr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": DefaultCRDConfig.Group + "/" + DefaultCRDConfig.Version,
+		"kind":       "MeshSync",
+		"metadata":   map[string]interface{}{"name": DefaultCRDConfig.Name, "namespace": DefaultCRDConfig.Namespace},
+		"spec": map[string]interface{}{
+			"watch-list": map[string]interface{}{
+				"data": map[string]interface{}{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]"},
+			},
+		},
+	}}
+	newClient := func(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+		return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			DefaultCRDConfig.GVR(): "MeshSyncList",
+		}, objects...)
+	}
+
+	meshsyncConfig, err := GetMeshsyncCRDConfigsWithFallback(context.Background(), newClient(cr), log)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	assertPipelineNames(t, LocalResourceKey, meshsyncConfig.Pipelines[LocalResourceKey], []string{"pods.v1."})
+
+	// the Custom Resource, or its CRD, is not found
+	meshsyncConfig, err = GetMeshsyncCRDConfigsWithFallback(context.Background(), newClient(), log)
+	if err != nil {
+		t.Fatalf("expected the local configs, got error %s", err.Error())
+	}
+	assertPipelineNames(t, LocalResourceKey, meshsyncConfig.Pipelines[LocalResourceKey], []string{"services.v1."})
+
+	failures := map[string]error{
+		"forbidden":   apierrors.NewForbidden(DefaultCRDConfig.GVR().GroupResource(), DefaultCRDConfig.Name, nil),
+		"unavailable": apierrors.NewServiceUnavailable("connection refused"),
+	}
+	for name, failure := range failures {
+		dyClient := newClient(cr)
+		dyClient.PrependReactor("get", DefaultCRDConfig.Resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, failure
+		})
+		_, err := GetMeshsyncCRDConfigsWithFallback(context.Background(), dyClient, log)
+		if err == nil {
+			t.Errorf("%s: expected error instead of the local configs", name)
+			continue
+		}
+		if codeOf(err) != ErrInitConfigCode {
+			t.Errorf("%s: expected error code %s, got %s", name, ErrInitConfigCode, codeOf(err))
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := GetMeshsyncCRDConfigsWithFallback(ctx, newClient(), log); err == nil {
+		t.Error("expected error for the cancelled context instead of the local configs")
+	}
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// DefaultCRDWatchDebounce is how long WatchCRDConfig waits for further updates of the Custom Resource
+// before it resolves the configuration, so a series of edits restarts the pipelines once
+const DefaultCRDWatchDebounce = 2 * time.Second
+
+// CRDWatchOptions tune WatchCRDConfig
+type CRDWatchOptions struct {
+	// zero waits DefaultCRDWatchDebounce
+	Debounce time.Duration
+	// receives the errors of updates which do not resolve to a valid configuration,
+	// the previous configuration stays in effect
+	OnError func(err error)
+}
+
+// WatchCRDConfig watches the Custom Resource located by CRDConfigFromEnv with an informer and emits
+// the configuration it resolves to whenever its spec changes, until ctx is done and the channel is closed.
+// The Custom Resource found initially is not reported, it has been resolved when MeshSync started,
+// neither are updates resolving to the same configuration as the one emitted last.
+func WatchCRDConfig(ctx context.Context, dyClient dynamic.Interface, opts CRDWatchOptions) <-chan *MeshsyncConfig {
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultCRDWatchDebounce
+	}
+	crdConfig := CRDConfigFromEnv()
+	w := &crdWatcher{
+		dyClient: dyClient,
+		opts:     opts,
+		changed:  make(chan struct{}, 1),
+		configs:  make(chan *MeshsyncConfig),
+	}
+
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dyClient, 0, crdConfig.Namespace, func(options *metav1.ListOptions) {
+		options.FieldSelector = fields.OneTermEqualSelector("metadata.name", crdConfig.Name).String()
+	})
+	informer := factory.ForResource(crdConfig.GVR()).Informer()
+	_, _ = informer.AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			crd, ok := obj.(*unstructured.Unstructured)
+			if !ok || crd.GetName() != crdConfig.Name {
+				return
+			}
+			if isInInitialList {
+				w.setBaseline(ctx, crd)
+				return
+			}
+			// the Custom Resource has been recreated
+			w.update(crd)
+		},
+		UpdateFunc: func(oldObj, obj interface{}) {
+			oldCRD, okOld := oldObj.(*unstructured.Unstructured)
+			crd, ok := obj.(*unstructured.Unstructured)
+			if !okOld || !ok || crd.GetName() != crdConfig.Name {
+				return
+			}
+			// status updates do not change the configuration
+			if !reflect.DeepEqual(oldCRD.Object["spec"], crd.Object["spec"]) {
+				w.update(crd)
+			}
+		},
+	})
+
+	go w.run(ctx)
+	factory.Start(ctx.Done())
+	return w.configs
+}
+
+// crdWatcher debounces the updates of the Custom Resource and resolves the latest one
+type crdWatcher struct {
+	dyClient dynamic.Interface
+	opts     CRDWatchOptions
+
+	mu sync.Mutex
+	// the latest update of the Custom Resource, not resolved yet
+	latest *unstructured.Unstructured
+	// the configuration in effect
+	current *MeshsyncConfig
+
+	changed chan struct{}
+	configs chan *MeshsyncConfig
+}
+
+// setBaseline records the configuration of the Custom Resource MeshSync started with
+func (w *crdWatcher) setBaseline(ctx context.Context, crd *unstructured.Unstructured) {
+	meshsyncConfig, err := configsFromCRD(ctx, w.dyClient, crd)
+	if err != nil {
+		w.onError(err)
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.current = meshsyncConfig
+}
+
+func (w *crdWatcher) update(crd *unstructured.Unstructured) {
+	w.mu.Lock()
+	w.latest = crd
+	w.mu.Unlock()
+	select {
+	case w.changed <- struct{}{}:
+	default:
+	}
+}
+
+// run resolves the latest update once no further update arrived for the debounce period
+func (w *crdWatcher) run(ctx context.Context) {
+	defer close(w.configs)
+	timer := time.NewTimer(w.opts.Debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.changed:
+			timer.Reset(w.opts.Debounce)
+		case <-timer.C:
+			meshsyncConfig := w.resolve(ctx)
+			if meshsyncConfig == nil {
+				continue
+			}
+			select {
+			case w.configs <- meshsyncConfig:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// resolve returns the configuration of the latest update, nil if it is not valid or does not change anything
+func (w *crdWatcher) resolve(ctx context.Context) *MeshsyncConfig {
+	w.mu.Lock()
+	crd := w.latest
+	w.latest = nil
+	w.mu.Unlock()
+	if crd == nil {
+		return nil
+	}
+
+	meshsyncConfig, err := configsFromCRD(ctx, w.dyClient, crd)
+	if err != nil {
+		w.onError(err)
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if sameConfig(w.current, meshsyncConfig) {
+		return nil
+	}
+	w.current = meshsyncConfig
+	return meshsyncConfig
+}
+
+func (w *crdWatcher) onError(err error) {
+	if w.opts.OnError != nil {
+		w.opts.OnError(err)
+	}
+}
+
+// sameConfig reports whether both configurations run the same pipelines, global settings and listeners
+func sameConfig(a, b *MeshsyncConfig) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return ConfigFingerprint(a) == ConfigFingerprint(b) &&
+		reflect.DeepEqual(a.Listeners, b.Listeners) &&
+		reflect.DeepEqual(a.Source, b.Source)
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func watchedCR(version, whitelist string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": DefaultCRDConfig.Group + "/" + DefaultCRDConfig.Version,
+		"kind":       "MeshSync",
+		"metadata":   map[string]interface{}{"name": DefaultCRDConfig.Name, "namespace": DefaultCRDConfig.Namespace},
+		"spec": map[string]interface{}{
+			"version":    version,
+			"watch-list": map[string]interface{}{"data": map[string]interface{}{"whitelist": whitelist}},
+		},
+	}}
+}
+
+func whitelistOf(resource string) string {
+	return "[{\"Resource\":\"" + resource + "\",\"Events\":[\"ADDED\"]}]"
+}
+
+func TestWatchCRDConfig(t *testing.T) {
+	dyClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		DefaultCRDConfig.GVR(): "MeshSyncList",
+	}, watchedCR("v0.7.0", whitelistOf("pods.v1.")))
+	crs := dyClient.Resource(DefaultCRDConfig.GVR()).Namespace(DefaultCRDConfig.Namespace)
+
+	var mu sync.Mutex
+	var errs []error
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	configs := WatchCRDConfig(ctx, dyClient, CRDWatchOptions{
+		Debounce: 50 * time.Millisecond,
+		OnError: func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		},
+	})
+
+	// updates are missed until the informer watches
+	deadline := time.Now().Add(2 * time.Second)
+	for !watching(dyClient) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	update := func(cr *unstructured.Unstructured) {
+		t.Helper()
+		if _, err := crs.Update(context.Background(), cr, metav1.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectConfig := func(expected []string) {
+		t.Helper()
+		select {
+		case meshsyncConfig := <-configs:
+			assertPipelineNames(t, LocalResourceKey, meshsyncConfig.Pipelines[LocalResourceKey], expected)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected the configuration of %v", expected)
+		}
+	}
+	expectNoConfig := func(reason string) {
+		t.Helper()
+		select {
+		case meshsyncConfig := <-configs:
+			t.Errorf("expected no configuration for %s, got %v", reason, meshsyncConfig.Pipelines[LocalResourceKey])
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+
+	expectNoConfig("the initial Custom Resource")
+
+	// successive updates are debounced, the latest is emitted
+	update(watchedCR("v0.7.0", whitelistOf("services.v1.")))
+	update(watchedCR("v0.7.0", whitelistOf("deployments.v1.apps")))
+	expectConfig([]string{"deployments.v1.apps"})
+	expectNoConfig("the debounced update")
+
+	update(watchedCR("v0.7.1", whitelistOf("deployments.v1.apps")))
+	expectNoConfig("an update resolving to the same configuration")
+
+	update(watchedCR("v0.7.1", "not a watch-list"))
+	expectNoConfig("an invalid update")
+	mu.Lock()
+	if len(errs) != 1 {
+		t.Errorf("expected the invalid update to be reported, got %v", errs)
+	}
+	mu.Unlock()
+
+	// the previous configuration remains the one updates are compared with
+	update(watchedCR("v0.7.1", whitelistOf("deployments.v1.apps")))
+	expectNoConfig("an update restoring the configuration in effect")
+	update(watchedCR("v0.7.1", whitelistOf("pods.v1.")))
+	expectConfig([]string{"pods.v1."})
+
+	cancel()
+	select {
+	case _, ok := <-configs:
+		if ok {
+			t.Error("expected the channel to be closed")
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("expected the channel to be closed once the context is done")
+	}
+}
+
+func watching(dyClient *dynamicfake.FakeDynamicClient) bool {
+	for _, action := range dyClient.Actions() {
+		if action.GetVerb() == "watch" {
+			return true
+		}
+	}
+	return false
+}
@@ -14,6 +14,12 @@ var (
 
 	DefaultPublishingSubject = "meshery.meshsync.core"
 
+	// subject for MeshSync control events (sync progress, liveness, etc.)
+	DefaultControlSubject = "meshery.meshsync.control"
+
+	// subject for the liveness events, separate so consumers watch it without the other control events
+	DefaultLivenessSubject = "meshery.meshsync.liveness"
+
 	Pipelines = map[string]PipelineConfigs{
 		GlobalResourceKey: []PipelineConfig{
 			// Core Resources
@@ -385,5 +391,6 @@ var (
 		},
 	}
 
-	DefaultEvents = []string{"ADD", "UPDATE", "DELETE"}
+	// the events emitted by pipelines without events of their own, see EmittedEventTypes
+	DefaultEvents = []string{"ADDED", "MODIFIED", "DELETED"}
 )
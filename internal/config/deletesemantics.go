@@ -0,0 +1,28 @@
+package config
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/slices"
+)
+
+// when the DELETE event of an object held by finalizers is emitted, see ResourceConfig.DeleteSemantics
+const (
+	// once the object is removed from the cluster, after its finalizers completed
+	DeleteOnFinalRemoval = "onFinalRemoval"
+	// once the deletionTimestamp of the object is set, its later updates and final removal are not emitted
+	DeleteOnDeletionTimestamp = "onDeletionTimestamp"
+)
+
+var DeleteSemanticsValues = []string{DeleteOnFinalRemoval, DeleteOnDeletionTimestamp}
+
+// parseDeleteSemantics resolves the delete semantics of a resource, empty defaults to DeleteOnFinalRemoval
+func parseDeleteSemantics(resource, semantics string) (string, error) {
+	if semantics == "" {
+		return DeleteOnFinalRemoval, nil
+	}
+	if !slices.Contains(DeleteSemanticsValues, semantics) {
+		return "", fmt.Errorf("invalid DeleteSemantics %q for %s, expected one of %v", semantics, resource, DeleteSemanticsValues)
+	}
+	return semantics, nil
+}
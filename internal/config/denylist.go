@@ -0,0 +1,27 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/meshery/meshkit/utils"
+)
+
+// parseFieldDenylist reads the optional JSONPath expressions of the fields which must never leave the cluster,
+// f.e. ["$..env[?(@.name=='*_TOKEN')]"], they are stripped from the objects of every pipeline
+func parseFieldDenylist(data map[string]string) ([]string, error) {
+	raw, ok := data["fieldDenylist"]
+	if !ok || raw == "" {
+		return nil, nil
+	}
+
+	denylist := make([]string, 0)
+	if err := utils.Unmarshal(raw, &denylist); err != nil {
+		return nil, fmt.Errorf("invalid fieldDenylist: %w", err)
+	}
+	for _, expr := range denylist {
+		if _, err := ParseFieldPath(expr); err != nil {
+			return nil, fmt.Errorf("invalid fieldDenylist: %w", err)
+		}
+	}
+	return denylist, nil
+}
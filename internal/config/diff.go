@@ -0,0 +1,62 @@
+package config
+
+import (
+	"sort"
+)
+
+// DiffConfigs compares the resources both configurations watch, across both buckets, by name:
+// added are watched by newConfig only, removed by oldConfig only and changed by both with different sets of events,
+// f.e. to log what a reload of the Custom Resource changes.
+// The slices are sorted, a nil config watches nothing.
+func DiffConfigs(oldConfig, newConfig *MeshsyncConfig) (added, removed, changed []string) {
+	oldEvents := eventSetsByResource(oldConfig)
+	newEvents := eventSetsByResource(newConfig)
+
+	added, removed, changed = make([]string, 0), make([]string, 0), make([]string, 0)
+	for resource, events := range newEvents {
+		previous, ok := oldEvents[resource]
+		switch {
+		case !ok:
+			added = append(added, resource)
+		case !sameEventSet(previous, events):
+			changed = append(changed, resource)
+		}
+	}
+	for resource := range oldEvents {
+		if _, ok := newEvents[resource]; !ok {
+			removed = append(removed, resource)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(changed)
+	return added, removed, changed
+}
+
+// eventSetsByResource returns the events each resource is watched for, whichever bucket it is in
+func eventSetsByResource(cfg *MeshsyncConfig) map[string]map[string]struct{} {
+	sets := make(map[string]map[string]struct{})
+	for _, resource := range cfg.EffectiveResources() {
+		events, ok := sets[resource.Resource]
+		if !ok {
+			events = make(map[string]struct{}, len(resource.Events))
+			sets[resource.Resource] = events
+		}
+		for _, event := range resource.Events {
+			events[event] = struct{}{}
+		}
+	}
+	return sets
+}
+
+func sameEventSet(a, b map[string]struct{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for event := range a {
+		if _, ok := b[event]; !ok {
+			return false
+		}
+	}
+	return true
+}
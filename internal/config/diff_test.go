@@ -0,0 +1,44 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffConfigs(t *testing.T) {
+	old, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\",\"MODIFIED\"]},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"secrets.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"namespaces.v1.\",\"Events\":[\"ADDED\"]}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	updated, err := PopulateConfigsFromMap(map[string]string{
+		// the events of pods.v1. are reordered only
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"MODIFIED\",\"ADDED\"]},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\",\"DELETED\"]},{\"Resource\":\"namespaces.v1.\",\"Events\":[\"ADDED\",\"DELETED\"]},{\"Resource\":\"nodes.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"configmaps.v1.\",\"Events\":[\"ADDED\"]}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+
+	added, removed, changed := DiffConfigs(old, updated)
+	if expected := []string{"configmaps.v1.", "nodes.v1."}; !reflect.DeepEqual(added, expected) {
+		t.Errorf("expected the added resources %v, got %v", expected, added)
+	}
+	if expected := []string{"secrets.v1."}; !reflect.DeepEqual(removed, expected) {
+		t.Errorf("expected the removed resources %v, got %v", expected, removed)
+	}
+	// across both buckets
+	if expected := []string{"namespaces.v1.", "services.v1."}; !reflect.DeepEqual(changed, expected) {
+		t.Errorf("expected the changed resources %v, got %v", expected, changed)
+	}
+
+	added, removed, changed = DiffConfigs(updated, updated)
+	if len(added) != 0 || len(removed) != 0 || len(changed) != 0 {
+		t.Errorf("expected no differences between the same configs, got %v added, %v removed, %v changed", added, removed, changed)
+	}
+
+	added, removed, changed = DiffConfigs(nil, old)
+	if expected := []string{"namespaces.v1.", "pods.v1.", "secrets.v1.", "services.v1."}; !reflect.DeepEqual(added, expected) || len(removed) != 0 || len(changed) != 0 {
+		t.Errorf("expected every resource of the config to be added to a nil config, got %v added, %v removed, %v changed", added, removed, changed)
+	}
+}
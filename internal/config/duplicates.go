@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// normalizeResourceName tolerates the case and the surrounding whitespace of a resource named in the watch-list,
+// f.e. "Pods " names "pods"
+func normalizeResourceName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// normalizeWhiteList normalizes the names of the resources whitelisted by name, patterns are kept as given
+func normalizeWhiteList(whitelist []ResourceConfig) {
+	for i := range whitelist {
+		if !isPattern(whitelist[i].Match) {
+			whitelist[i].Resource = normalizeResourceName(whitelist[i].Resource)
+		}
+	}
+}
+
+// validateNoDuplicates rejects resources listed more than once, once their names are resolved,
+// only the first entry would take effect. A resource registered in both buckets may be whitelisted
+// once per bucket, see Scope.
+func validateNoDuplicates(meshsyncConfig *MeshsyncConfig) error {
+	scopes := make(map[string][]string)
+	for _, rc := range meshsyncConfig.WhiteList {
+		for _, scope := range scopes[rc.Resource] {
+			if scope == "" || rc.Scope == "" || scope == rc.Scope {
+				return fmt.Errorf("invalid whitelist: %s given more than once", rc.Resource)
+			}
+		}
+		scopes[rc.Resource] = append(scopes[rc.Resource], rc.Scope)
+	}
+
+	excluded := make(map[string]bool, len(meshsyncConfig.BlackList))
+	for _, name := range meshsyncConfig.BlackList {
+		if excluded[name] {
+			return fmt.Errorf("invalid blacklist: %s given more than once", name)
+		}
+		excluded[name] = true
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDuplicateResources(t *testing.T) {
+	testCases := []struct {
+		name      string
+		data      map[string]string
+		duplicate string
+	}{
+		{
+			name:      "whitelisted twice",
+			data:      map[string]string{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"pods.v1.\",\"Events\":[\"DELETED\"]}]"},
+			duplicate: "pods.v1.",
+		},
+		{
+			name:      "whitelisted by case and whitespace variants",
+			data:      map[string]string{"whitelist": "[{\"Resource\":\"Pods\",\"Events\":[\"ADDED\"]},{\"Resource\":\"pods \",\"Events\":[\"DELETED\"]}]"},
+			duplicate: "pods.v1.",
+		},
+		{
+			name:      "whitelisted by name and alias",
+			data:      map[string]string{"whitelist": "[{\"Resource\":\"deployments.v1.apps\",\"Events\":[\"ADDED\"]},{\"Resource\":\"deployments.apps\",\"Events\":[\"ADDED\"]}]"},
+			duplicate: "deployments.v1.apps",
+		},
+		{
+			name:      "blacklisted twice",
+			data:      map[string]string{"blacklist": "[\"secrets.v1.\",\" Secrets\"]"},
+			duplicate: "secrets.v1.",
+		},
+		{
+			name: "listed once",
+			data: map[string]string{"whitelist": "[{\"Resource\":\" Pods\",\"Events\":[\"ADDED\"]},{\"Resource\":\"services\",\"Events\":[\"ADDED\"]}]"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			meshsyncConfig, err := PopulateConfigsFromMap(tc.data)
+			if tc.duplicate == "" {
+				if err != nil {
+					t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+				}
+				assertPipelineNames(t, LocalResourceKey, meshsyncConfig.Pipelines[LocalResourceKey], []string{"pods.v1.", "services.v1."})
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if codeOf(err) != ErrInitConfigCode || !strings.Contains(err.Error(), tc.duplicate+" given more than once") {
+				t.Errorf("expected %s to be reported as duplicate, got %s", tc.duplicate, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"sort"
+)
+
+// EffectiveResource is a resource MeshSync watches once the defaults, the whitelist
+// and the blacklist are resolved
+type EffectiveResource struct {
+	Resource string `json:"resource" yaml:"resource"`
+	// GlobalResourceKey or LocalResourceKey
+	Scope  string   `json:"scope" yaml:"scope"`
+	Events []string `json:"events" yaml:"events"`
+}
+
+// EffectiveResources returns every watched resource of both buckets, sorted by resource then scope,
+// so the result does not depend on the order the pipelines were resolved in.
+// It is empty for a nil config.
+func (c *MeshsyncConfig) EffectiveResources() []EffectiveResource {
+	resources := make([]EffectiveResource, 0)
+	if c == nil {
+		return resources
+	}
+	for _, scope := range []string{GlobalResourceKey, LocalResourceKey} {
+		for _, pc := range c.Pipelines[scope] {
+			resources = append(resources, EffectiveResource{
+				Resource: pc.Name,
+				Scope:    scope,
+				Events:   append([]string{}, pc.Events...),
+			})
+		}
+	}
+	sort.SliceStable(resources, func(i, j int) bool {
+		if resources[i].Resource != resources[j].Resource {
+			return resources[i].Resource < resources[j].Resource
+		}
+		return resources[i].Scope < resources[j].Scope
+	})
+	return resources
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEffectiveResources(t *testing.T) {
+	whitelists := []string{
+		"[{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"namespaces.v1.\",\"Events\":[\"ADDED\",\"DELETED\"]},{\"Resource\":\"pods.v1.\",\"Events\":[\"MODIFIED\"]}]",
+		"[{\"Resource\":\"pods.v1.\",\"Events\":[\"MODIFIED\"]},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"namespaces.v1.\",\"Events\":[\"ADDED\",\"DELETED\"]}]",
+	}
+	expected := []EffectiveResource{
+		{Resource: "namespaces.v1.", Scope: GlobalResourceKey, Events: []string{"ADDED", "DELETED"}},
+		{Resource: "pods.v1.", Scope: LocalResourceKey, Events: []string{"MODIFIED"}},
+		{Resource: "services.v1.", Scope: LocalResourceKey, Events: []string{"ADDED"}},
+	}
+	for _, whitelist := range whitelists {
+		meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{"whitelist": whitelist})
+		if err != nil {
+			t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+		}
+		if resources := meshsyncConfig.EffectiveResources(); !reflect.DeepEqual(resources, expected) {
+			t.Errorf("expected the effective resources %v for %s, got %v", expected, whitelist, resources)
+		}
+	}
+
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"blacklist": "[\"pods.v1.\",\"secrets.v1.\"]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	resources := meshsyncConfig.EffectiveResources()
+	for i, resource := range resources {
+		if resource.Resource == "pods.v1." || resource.Resource == "secrets.v1." {
+			t.Errorf("expected the blacklisted %s not to be watched", resource.Resource)
+		}
+		if i > 0 && resources[i-1].Resource > resource.Resource {
+			t.Errorf("expected the effective resources to be sorted, got %s before %s", resources[i-1].Resource, resource.Resource)
+		}
+	}
+
+	var empty *MeshsyncConfig
+	if resources := empty.EffectiveResources(); len(resources) != 0 {
+		t.Errorf("expected no effective resources for a nil config, got %v", resources)
+	}
+}
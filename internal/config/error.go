@@ -1,13 +1,57 @@
 package config
 
 import (
+	stderrors "errors"
+	"strings"
+
 	"github.com/meshery/meshkit/errors"
 )
 
 const (
-	ErrInitConfigCode = "1000"
+	ErrInitConfigCode     = "1000"
+	ErrWritePermittedCode = "1017"
 )
 
+// meshkitError is the meshkit error type, aliased so it can be embedded next to its Error method
+type meshkitError = errors.Error
+
+// causedError is a meshkit error which unwraps to the error it was created from,
+// so errors.Is and errors.As reach the cause, f.e. a NotFound error of the API server
+type causedError struct {
+	*meshkitError
+	cause error
+}
+
+func (e *causedError) Unwrap() error { return e.cause }
+
+// As retrieves the meshkit error, f.e. for MeshkitError
+func (e *causedError) As(target interface{}) bool {
+	if t, ok := target.(**errors.Error); ok {
+		*t = e.meshkitError
+		return true
+	}
+	return false
+}
+
+// ErrInitConfig carries the text of err in its descriptions, so the error message
+// reports what failed rather than a generic cause
 func ErrInitConfig(err error) error {
-	return errors.New(ErrInitConfigCode, errors.Alert, []string{"Error while initializing MeshSync configuration. ", err.Error()}, []string{"Missing or outdated CRD. "}, []string{"Missing or outdated CRD."}, []string{"Confirm that meshsyncs custom resource is present in the cluster."})
+	return &causedError{
+		meshkitError: errors.New(ErrInitConfigCode, errors.Alert, []string{"Error while initializing MeshSync configuration. ", err.Error()}, []string{err.Error()}, []string{"Missing or outdated CRD."}, []string{"Confirm that meshsyncs custom resource is present in the cluster."}),
+		cause:        err,
+	}
+}
+
+// MeshkitError returns the meshkit error err is or wraps, or err itself. errors.GetCode
+// and the meshkit logger only read the code of a meshkit error, not of one wrapping it
+func MeshkitError(err error) error {
+	var meshkitErr *errors.Error
+	if stderrors.As(err, &meshkitErr) {
+		return meshkitErr
+	}
+	return err
+}
+
+func ErrWritePermitted(permitted []string) error {
+	return errors.New(ErrWritePermittedCode, errors.Alert, []string{"MeshSync is permitted to modify watched resources: ", strings.Join(permitted, ", ")}, []string{"The RBAC role bound to MeshSync grants more than read access."}, []string{"The role of the MeshSync service account includes mutating verbs."}, []string{"Restrict the role of the MeshSync service account to get, list and watch, plus patch on its own meshsyncs custom resource."})
 }
@@ -0,0 +1,57 @@
+package config
+
+import (
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/meshery/meshkit/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// codeOf returns the code of the meshkit error err is or wraps
+func codeOf(err error) string {
+	return errors.GetCode(MeshkitError(err))
+}
+
+func TestErrInitConfigUnwraps(t *testing.T) {
+	errSentinel := stderrors.New("sentinel")
+	if err := ErrInitConfig(errSentinel); !stderrors.Is(err, errSentinel) {
+		t.Errorf("expected errors.Is to reach the cause through %v", err)
+	}
+	if err := ErrInitConfig(fmt.Errorf("loading the watch-list: %w", errSentinel)); !stderrors.Is(err, errSentinel) {
+		t.Errorf("expected errors.Is to reach the wrapped sentinel error through %v", err)
+	}
+
+	notFound := apierrors.NewNotFound(DefaultCRDConfig.GVR().GroupResource(), DefaultCRDConfig.Name)
+	err := ErrInitConfig(notFound)
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected the NotFound error of the API server to be reachable through %v", err)
+	}
+	if stderrors.Unwrap(err) != notFound {
+		t.Errorf("expected errors.Unwrap to return the cause, got %v", stderrors.Unwrap(err))
+	}
+}
+
+func TestErrInitConfigCarriesTheCause(t *testing.T) {
+	notFound := apierrors.NewNotFound(DefaultCRDConfig.GVR().GroupResource(), DefaultCRDConfig.Name)
+	err := ErrInitConfig(notFound)
+
+	if code := codeOf(err); code != ErrInitConfigCode {
+		t.Errorf("expected error code %s, got %s", ErrInitConfigCode, code)
+	}
+	if code := codeOf(fmt.Errorf("reloading: %w", err)); code != ErrInitConfigCode {
+		t.Errorf("expected error code %s of the wrapped error, got %s", ErrInitConfigCode, code)
+	}
+	if err.Error() != notFound.Error() {
+		t.Errorf("expected the error message %q, got %q", notFound.Error(), err.Error())
+	}
+	var meshkitErr *errors.Error
+	if !stderrors.As(err, &meshkitErr) {
+		t.Fatalf("expected a meshkit error, got %T", err)
+	}
+	if expected := []string{"Error while initializing MeshSync configuration. ", notFound.Error()}; !reflect.DeepEqual(meshkitErr.ShortDescription, expected) {
+		t.Errorf("expected the short description %q, got %q", expected, meshkitErr.ShortDescription)
+	}
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshkit/utils"
+	"golang.org/x/exp/slices"
+)
+
+// EmittedEventTypes are the event types pipelines emit
+var EmittedEventTypes = []string{string(broker.Add), string(broker.Update), string(broker.Delete)}
+
+// parseEventTypeMapping reads the optional mapping of emitted event types to the consumer's vocabulary,
+// f.e. {"ADDED":"CREATE","MODIFIED":"UPDATE","DELETED":"DELETE"}
+func parseEventTypeMapping(data map[string]string) (map[string]string, error) {
+	raw, ok := data["eventTypeMapping"]
+	if !ok || raw == "" {
+		return nil, nil
+	}
+
+	mapping := make(map[string]string)
+	if err := utils.Unmarshal(raw, &mapping); err != nil {
+		return nil, fmt.Errorf("invalid eventTypeMapping: %w", err)
+	}
+
+	mappedTo := make(map[string]string, len(mapping))
+	for from, to := range mapping {
+		if !isEmittedEventType(from) {
+			return nil, fmt.Errorf("invalid eventTypeMapping: unknown event type %q, expected one of %v", from, EmittedEventTypes)
+		}
+		if to == "" {
+			return nil, fmt.Errorf("invalid eventTypeMapping: %s is mapped to an empty value", from)
+		}
+		if other, ok := mappedTo[to]; ok {
+			return nil, fmt.Errorf("invalid eventTypeMapping: both %s and %s are mapped to %s", other, from, to)
+		}
+		mappedTo[to] = from
+	}
+	return mapping, nil
+}
+
+// validateEventTypeMappingCoverage ensures every event type the pipelines emit is remapped,
+// consumers would not understand the ones left out
+func validateEventTypeMappingCoverage(mapping map[string]string, pipelines map[string]PipelineConfigs) error {
+	if mapping == nil {
+		return nil
+	}
+
+	uncovered := make(map[string]bool)
+	for _, configs := range pipelines {
+		for _, pc := range configs {
+			for _, event := range pc.Events {
+				if _, ok := mapping[event]; !ok && isEmittedEventType(event) {
+					uncovered[event] = true
+				}
+			}
+		}
+	}
+	if len(uncovered) == 0 {
+		return nil
+	}
+
+	missing := make([]string, 0, len(uncovered))
+	for event := range uncovered {
+		missing = append(missing, event)
+	}
+	sort.Strings(missing)
+	return fmt.Errorf("invalid eventTypeMapping: event types %v are emitted but not mapped", missing)
+}
+
+// EmittedEventType returns the event type sinks emit for evtype, remapped by EventTypeMapping if configured
+func (pc PipelineConfig) EmittedEventType(evtype broker.EventType) broker.EventType {
+	if mapped, ok := pc.EventTypeMapping[string(evtype)]; ok {
+		return broker.EventType(mapped)
+	}
+	return evtype
+}
+
+func isEmittedEventType(event string) bool {
+	for _, emitted := range EmittedEventTypes {
+		if emitted == event {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeEvents validates the events of a resource against EmittedEventTypes, case-insensitively,
+// and returns them in their canonical casing without duplicates. Resources without events emit the defaults.
+func normalizeEvents(resource string, events []string, defaults []string) ([]string, error) {
+	if len(events) == 0 {
+		return append([]string{}, defaults...), nil
+	}
+	normalized := make([]string, 0, len(events))
+	for _, event := range events {
+		canonical := strings.ToUpper(strings.TrimSpace(event))
+		if !isEmittedEventType(canonical) {
+			return nil, fmt.Errorf("invalid event %q for %s, expected one of %v", event, resource, EmittedEventTypes)
+		}
+		if !slices.Contains(normalized, canonical) {
+			normalized = append(normalized, canonical)
+		}
+	}
+	return normalized, nil
+}
+
+// parseDefaultEvents reads the optional events of the resources given none, f.e. ["ADDED","DELETED"],
+// replacing DefaultEvents
+func parseDefaultEvents(data map[string]string) ([]string, error) {
+	raw, ok := data["defaultEvents"]
+	if !ok || raw == "" {
+		return nil, nil
+	}
+
+	events := make([]string, 0)
+	if err := utils.Unmarshal(raw, &events); err != nil {
+		return nil, fmt.Errorf("invalid defaultEvents: %w", err)
+	}
+	if len(events) == 0 {
+		return nil, errors.New("invalid defaultEvents: no events given")
+	}
+	return normalizeEvents("defaultEvents", events, nil)
+}
+
+// defaultEvents returns the events of the resources given none
+func (c *MeshsyncConfig) defaultEvents() []string {
+	if len(c.DefaultEvents) > 0 {
+		return c.DefaultEvents
+	}
+	return DefaultEvents
+}
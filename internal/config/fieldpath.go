@@ -0,0 +1,170 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FieldPath is a parsed JSONPath expression selecting fields of an object, f.e. the fields to mask.
+// The supported subset is
+//
+//	.key or ['key']      map entries, the key may be a glob pattern, f.e. .data.*_TOKEN
+//	[*] or [n]           all list items or the n-th one
+//	[?(@.field=='glob')] list items whose field matches the glob pattern
+//	..                   the following segment at any depth
+//
+// f.e. $..env[?(@.name=='*_TOKEN')].value selects the values of all env vars ending in _TOKEN.
+type FieldPath []FieldSegment
+
+// FieldSegment selects either map entries by Key or list items by Item
+type FieldSegment struct {
+	// Descendant applies the segment at any depth below the current node
+	Descendant bool
+	// Key is the glob pattern of the selected map keys
+	Key string
+	// Item selects list items, nil for map segments
+	Item *FieldItemSelector
+}
+
+// FieldItemSelector selects list items by Index, negative for all,
+// and by the value of Field matching the glob Pattern, if Field is set
+type FieldItemSelector struct {
+	Index   int
+	Field   string
+	Pattern string
+}
+
+// MatchesKey reports whether the map entry with the given key is selected
+func (s FieldSegment) MatchesKey(key string) bool {
+	return s.Item == nil && globMatch(s.Key, key)
+}
+
+// MatchesItem reports whether the i-th item of a list is selected
+func (s FieldSegment) MatchesItem(i int, item interface{}) bool {
+	if s.Item == nil {
+		return false
+	}
+	if s.Item.Index >= 0 && s.Item.Index != i {
+		return false
+	}
+	if s.Item.Field == "" {
+		return true
+	}
+	fields, ok := item.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	value, ok := fields[s.Item.Field].(string)
+	return ok && globMatch(s.Item.Pattern, value)
+}
+
+// ParseFieldPath parses a JSONPath expression of the supported subset, see FieldPath
+func ParseFieldPath(expr string) (FieldPath, error) {
+	rest := strings.TrimPrefix(strings.TrimSpace(expr), "$")
+	if rest == "" {
+		return nil, fmt.Errorf("empty field path %q", expr)
+	}
+	if rest[0] != '.' && rest[0] != '[' {
+		// the root may be omitted, f.e. spec.containers
+		rest = "." + rest
+	}
+
+	path := make(FieldPath, 0)
+	for rest != "" {
+		segment := FieldSegment{}
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			segment.Descendant = true
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+		case strings.HasPrefix(rest, "["):
+		default:
+			return nil, fmt.Errorf("invalid field path %q: expected . or [ at %q", expr, rest)
+		}
+
+		if strings.HasPrefix(rest, "[") {
+			end := closingBracket(rest)
+			if end < 0 {
+				return nil, fmt.Errorf("invalid field path %q: unterminated [ at %q", expr, rest)
+			}
+			if err := parseBracket(rest[1:end], &segment); err != nil {
+				return nil, fmt.Errorf("invalid field path %q: %w", expr, err)
+			}
+			rest = rest[end+1:]
+		} else {
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid field path %q: missing key at %q", expr, rest)
+			}
+			segment.Key = rest[:end]
+			rest = rest[end:]
+		}
+		path = append(path, segment)
+	}
+	return path, nil
+}
+
+// closingBracket returns the index of the ] closing the [ s starts with, ignoring quoted brackets
+func closingBracket(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '\'' || s[i] == '"':
+			quote = s[i]
+		case s[i] == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+func parseBracket(content string, segment *FieldSegment) error {
+	content = strings.TrimSpace(content)
+	switch {
+	case content == "*":
+		segment.Item = &FieldItemSelector{Index: -1}
+	case strings.HasPrefix(content, "?(") && strings.HasSuffix(content, ")"):
+		field, pattern, ok := strings.Cut(content[2:len(content)-1], "==")
+		field = strings.TrimSpace(field)
+		if !ok || !strings.HasPrefix(field, "@.") || len(field) == 2 {
+			return fmt.Errorf("unsupported filter %q, expected ?(@.field=='pattern')", content)
+		}
+		value, err := unquote(strings.TrimSpace(pattern))
+		if err != nil {
+			return err
+		}
+		segment.Item = &FieldItemSelector{Index: -1, Field: field[2:], Pattern: value}
+	case strings.HasPrefix(content, "'") || strings.HasPrefix(content, "\""):
+		key, err := unquote(content)
+		if err != nil {
+			return err
+		}
+		if key == "" {
+			return fmt.Errorf("empty key in [%s]", content)
+		}
+		segment.Key = key
+	default:
+		index, err := strconv.Atoi(content)
+		if err != nil || index < 0 {
+			return fmt.Errorf("unsupported selector [%s]", content)
+		}
+		segment.Item = &FieldItemSelector{Index: index}
+	}
+	return nil
+}
+
+func unquote(s string) (string, error) {
+	if len(s) < 2 || (s[0] != '\'' && s[0] != '"') || s[len(s)-1] != s[0] {
+		return "", fmt.Errorf("expected a quoted string, got %s", s)
+	}
+	return s[1 : len(s)-1], nil
+}
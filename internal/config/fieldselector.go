@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"golang.org/x/exp/slices"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// metadataSelectableFields are the fields every resource can be selected by
+var metadataSelectableFields = []string{"metadata.name", "metadata.namespace"}
+
+// SelectableFields are the fields the API server selects the objects of these built-in resources by,
+// besides metadataSelectableFields, keyed by resource and group. Only few fields are selectable server-side,
+// a field selector on any other field of these resources is refused by the API server.
+// Resources missing from here, f.e. custom resources declaring selectableFields, are not validated.
+var SelectableFields = map[schema.GroupResource][]string{
+	{Resource: "pods"}: {
+		"spec.nodeName", "spec.restartPolicy", "spec.schedulerName", "spec.serviceAccountName", "spec.hostNetwork",
+		"status.phase", "status.podIP", "status.podIPs", "status.nominatedNodeName",
+	},
+	{Resource: "events"}: {
+		"involvedObject.kind", "involvedObject.namespace", "involvedObject.name", "involvedObject.uid",
+		"involvedObject.apiVersion", "involvedObject.resourceVersion", "involvedObject.fieldPath",
+		"reason", "reportingComponent", "source", "type",
+	},
+	{Resource: "namespaces"}:                            {"status.phase"},
+	{Resource: "nodes"}:                                 {"spec.unschedulable"},
+	{Resource: "secrets"}:                               {"type"},
+	{Resource: "replicationcontrollers"}:                {"status.replicas"},
+	{Group: "apps", Resource: "replicasets"}:            {"status.replicas"},
+	{Group: "batch", Resource: "jobs"}:                  {"status.successful"},
+	{Group: "apps", Resource: "deployments"}:            nil,
+	{Group: "apps", Resource: "statefulsets"}:           nil,
+	{Group: "apps", Resource: "daemonsets"}:             nil,
+	{Resource: "configmaps"}:                            nil,
+	{Resource: "persistentvolumeclaims"}:                nil,
+	{Resource: "persistentvolumes"}:                     nil,
+	{Group: "networking.k8s.io", Resource: "ingresses"}: nil,
+}
+
+// parseFieldSelector validates the field selector the resource is watched with
+// and returns it in its canonical form, empty selects all objects.
+// The fields of the built-in resources are checked against SelectableFields.
+func parseFieldSelector(resource, selector string) (string, error) {
+	if selector == "" {
+		return "", nil
+	}
+	parsed, err := fields.ParseSelector(selector)
+	if err != nil {
+		return "", fmt.Errorf("invalid fieldSelector for %s: %w", resource, err)
+	}
+	if parsed.Empty() {
+		return "", fmt.Errorf("invalid fieldSelector for %s: %q selects no field", resource, selector)
+	}
+
+	gvr, _ := schema.ParseResourceArg(resource)
+	if gvr != nil {
+		if selectable, ok := SelectableFields[gvr.GroupResource()]; ok {
+			selectable = append(append([]string{}, metadataSelectableFields...), selectable...)
+			for _, requirement := range parsed.Requirements() {
+				if !slices.Contains(selectable, requirement.Field) {
+					sort.Strings(selectable)
+					return "", fmt.Errorf("invalid fieldSelector for %s: %s is not selectable server-side, expected one of %v", resource, requirement.Field, selectable)
+				}
+			}
+		}
+	}
+	return parsed.String(), nil
+}
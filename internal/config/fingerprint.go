@@ -0,0 +1,26 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// ConfigFingerprint returns a stable hash of the resolved pipelines and global settings, so instances which
+// loaded the same configuration report the same fingerprint, whichever watch-list they resolved it from.
+// It is empty for a nil config.
+func ConfigFingerprint(cfg *MeshsyncConfig) string {
+	if cfg == nil {
+		return ""
+	}
+	// map keys are marshalled in sorted order and the pipelines in registry order
+	data, err := json.Marshal(struct {
+		Pipelines map[string]PipelineConfigs
+		Settings  GlobalSettings
+	}{cfg.Pipelines, cfg.GlobalSettings})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
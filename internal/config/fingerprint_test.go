@@ -0,0 +1,44 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestConfigFingerprint(t *testing.T) {
+	fingerprint := func(data map[string]string) string {
+		t.Helper()
+		meshsyncConfig, err := PopulateConfigsFromMap(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ConfigFingerprint(meshsyncConfig)
+	}
+
+	base := fingerprint(map[string]string{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]"})
+	if base == "" {
+		t.Fatal("expected a fingerprint")
+	}
+
+	if same := fingerprint(map[string]string{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]"}); same != base {
+		t.Errorf("expected identical configs to have the fingerprint %s, got %s", base, same)
+	}
+	if reordered := fingerprint(map[string]string{"whitelist": "[{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]"}); reordered != base {
+		t.Errorf("expected the order of the whitelist not to change the fingerprint %s, got %s", base, reordered)
+	}
+
+	changes := map[string]map[string]string{
+		"events":         {"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\",\"DELETED\"]},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]"},
+		"resource":       {"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]"},
+		"setting":        {"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]", "emitStatus": "false"},
+		"global setting": {"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]", "envelopeVersion": "v1"},
+	}
+	for name, data := range changes {
+		if changed := fingerprint(data); changed == base {
+			t.Errorf("expected a changed %s to change the fingerprint %s", name, base)
+		}
+	}
+
+	if empty := ConfigFingerprint(nil); empty != "" {
+		t.Errorf("expected no fingerprint without config, got %s", empty)
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import "strings"
+
+// glob patterns support '*' matching any sequence of characters
+// and '?' matching exactly one character
+
+func isGlobPattern(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?")
+}
+
+// globMatch reports whether name matches the glob pattern
+func globMatch(pattern, name string) bool {
+	return globsOverlap(pattern, name)
+}
+
+// globsOverlap reports whether there is at least one name matched by both patterns,
+// a pattern without wildcards is a pattern matching only itself
+func globsOverlap(a, b string) bool {
+	memo := make(map[[2]int]bool)
+	var overlap func(i, j int) bool
+	overlap = func(i, j int) bool {
+		key := [2]int{i, j}
+		if result, ok := memo[key]; ok {
+			return result
+		}
+
+		var result bool
+		switch {
+		case i == len(a) && j == len(b):
+			result = true
+		case i < len(a) && a[i] == '*':
+			// star matches nothing or absorbs one more character of b
+			result = overlap(i+1, j) || (j < len(b) && overlap(i, j+1))
+		case j < len(b) && b[j] == '*':
+			result = overlap(i, j+1) || (i < len(a) && overlap(i+1, j))
+		case i == len(a) || j == len(b):
+			result = false
+		case a[i] == '?' || b[j] == '?' || a[i] == b[j]:
+			result = overlap(i+1, j+1)
+		}
+
+		memo[key] = result
+		return result
+	}
+	return overlap(0, 0)
+}
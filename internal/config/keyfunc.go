@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// key functions sinks use to identify objects
+const (
+	KeyFuncUID            = "uid"
+	KeyFuncNamespacedName = "namespace/name"
+	KeyFuncLabelPrefix    = "label:"
+)
+
+// validateKeyFunc checks the key function spec of a resource,
+// an empty spec stands for the default (uid)
+func validateKeyFunc(resource, keyFunc, fallback string) error {
+	if err := validateKeyFuncSpec(keyFunc); err != nil {
+		return fmt.Errorf("invalid key function for %s: %w", resource, err)
+	}
+	if err := validateKeyFuncSpec(fallback); err != nil {
+		return fmt.Errorf("invalid key function fallback for %s: %w", resource, err)
+	}
+	if strings.HasPrefix(fallback, KeyFuncLabelPrefix) {
+		// a label may be missing as well, the fallback must always yield a key
+		return fmt.Errorf("invalid key function fallback for %s: label based fallback is not supported", resource)
+	}
+	return nil
+}
+
+func validateKeyFuncSpec(spec string) error {
+	switch {
+	case spec == "", spec == KeyFuncUID, spec == KeyFuncNamespacedName:
+		return nil
+	case strings.HasPrefix(spec, KeyFuncLabelPrefix):
+		if strings.TrimPrefix(spec, KeyFuncLabelPrefix) == "" {
+			return fmt.Errorf("label key is missing in %q", spec)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown key function %q", spec)
+}
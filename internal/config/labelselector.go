@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// parseLabelSelector validates the label selector the resource is watched with
+// and returns it in its canonical form, empty selects all objects
+func parseLabelSelector(resource, selector string) (string, error) {
+	if selector == "" {
+		return "", nil
+	}
+	parsed, err := metav1.ParseToLabelSelector(selector)
+	if err != nil {
+		return "", fmt.Errorf("invalid labelSelector for %s: %w", resource, err)
+	}
+	canonical, err := metav1.LabelSelectorAsSelector(parsed)
+	if err != nil {
+		return "", fmt.Errorf("invalid labelSelector for %s: %w", resource, err)
+	}
+	return canonical.String(), nil
+}
+
+// validateAnnotationFilter ensures the keys of the annotation filter are valid annotation keys
+func validateAnnotationFilter(resource string, filter map[string]string) error {
+	keys := make([]string, 0, len(filter))
+	for key := range filter {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid annotationFilter for %s: key %q: %s", resource, key, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+)
+
+// LintIssue describes a watch-list construct which is valid but likely unintended
+type LintIssue struct {
+	// the whitelist entries involved
+	Resources []string `json:"resources" yaml:"resources"`
+	// known resources (from the pipelines registry) affected by the issue
+	Matches []string `json:"matches,omitempty" yaml:"matches,omitempty"`
+	// events the affected resources are watched for once the issue is resolved
+	Events  []string `json:"events,omitempty" yaml:"events,omitempty"`
+	Message string   `json:"message" yaml:"message"`
+}
+
+func (i LintIssue) String() string {
+	return i.Message
+}
+
+// Lint inspects the whitelist for overlapping glob patterns:
+// two entries overlap when some resource name is matched by both of them.
+// Overlaps are resolved by watching the resource for the union of the entries' events.
+func (c *MeshsyncConfig) Lint() []LintIssue {
+	return lintWhiteList(c.WhiteList, Pipelines)
+}
+
+func lintWhiteList(whitelist []ResourceConfig, registry map[string]PipelineConfigs) []LintIssue {
+	issues := make([]LintIssue, 0)
+	for i := 0; i < len(whitelist); i++ {
+		for j := i + 1; j < len(whitelist); j++ {
+			a, b := whitelist[i], whitelist[j]
+			if !isGlobPattern(a.Resource) && !isGlobPattern(b.Resource) {
+				// plain duplicates are not a glob overlap
+				continue
+			}
+			if !globsOverlap(a.Resource, b.Resource) {
+				continue
+			}
+
+			events := unionEvents(a.Events, b.Events)
+			issues = append(issues, LintIssue{
+				Resources: []string{a.Resource, b.Resource},
+				Matches:   registryMatches(registry, a.Resource, b.Resource),
+				Events:    events,
+				Message: fmt.Sprintf(
+					"whitelist entries %q and %q overlap, matching resources are watched for the union of their events %v",
+					a.Resource,
+					b.Resource,
+					events,
+				),
+			})
+		}
+	}
+	return issues
+}
+
+// registryMatches returns names of registered pipelines matched by all the patterns
+func registryMatches(registry map[string]PipelineConfigs, patterns ...string) []string {
+	matches := make([]string, 0)
+	for _, key := range []string{GlobalResourceKey, LocalResourceKey} {
+		for _, pc := range registry[key] {
+			matchesAll := true
+			for _, pattern := range patterns {
+				if !globMatch(pattern, pc.Name) {
+					matchesAll = false
+					break
+				}
+			}
+			if matchesAll {
+				matches = append(matches, pc.Name)
+			}
+		}
+	}
+	return matches
+}
+
+// unionEvents merges event lists preserving the order of first appearance
+func unionEvents(lists ...[]string) []string {
+	seen := make(map[string]struct{})
+	result := make([]string, 0)
+	for _, events := range lists {
+		for _, event := range events {
+			if _, ok := seen[event]; ok {
+				continue
+			}
+			seen[event] = struct{}{}
+			result = append(result, event)
+		}
+	}
+	return result
+}
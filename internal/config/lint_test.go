@@ -0,0 +1,57 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGlobsOverlap(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected bool
+	}{
+		{a: "*.apps", b: "deployments.*.apps", expected: true},
+		{a: "*.apps", b: "*.batch", expected: false},
+		{a: "pods.v1.", b: "pods.v1.", expected: true},
+		{a: "pods.v?.", b: "pods.v1.", expected: true},
+		{a: "pods.v?.", b: "pods.v12.", expected: false},
+		{a: "*", b: "anything", expected: true},
+		{a: "*.networking.k8s.io", b: "ingress*", expected: true},
+	}
+
+	for _, tc := range testCases {
+		if result := globsOverlap(tc.a, tc.b); result != tc.expected {
+			t.Errorf("globsOverlap(%q, %q) expected %t, got %t", tc.a, tc.b, tc.expected, result)
+		}
+		if result := globsOverlap(tc.b, tc.a); result != tc.expected {
+			t.Errorf("globsOverlap(%q, %q) expected %t, got %t", tc.b, tc.a, tc.expected, result)
+		}
+	}
+}
+
+func TestLintOverlappingGlobs(t *testing.T) {
+	meshsyncConfig := &MeshsyncConfig{
+		WhiteList: []ResourceConfig{
+			{Resource: "*.v1.apps", Events: []string{"ADDED"}},
+			{Resource: "deployments.*.apps", Events: []string{"MODIFIED", "ADDED"}},
+			{Resource: "*.v1.batch", Events: []string{"DELETED"}},
+			{Resource: "pods.v1.", Events: []string{"ADDED"}},
+		},
+	}
+
+	issues := meshsyncConfig.Lint()
+	if len(issues) != 1 {
+		t.Fatalf("expected 1 lint issue, got %d: %v", len(issues), issues)
+	}
+
+	issue := issues[0]
+	if !reflect.DeepEqual(issue.Resources, []string{"*.v1.apps", "deployments.*.apps"}) {
+		t.Errorf("unexpected overlapping resources %v", issue.Resources)
+	}
+	if !reflect.DeepEqual(issue.Matches, []string{"deployments.v1.apps"}) {
+		t.Errorf("unexpected matches %v", issue.Matches)
+	}
+	if !reflect.DeepEqual(issue.Events, []string{"ADDED", "MODIFIED"}) {
+		t.Errorf("expected overlap resolved to the union of events, got %v", issue.Events)
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+)
+
+// MaskedValue replaces the values of masked fields
+const MaskedValue = "***MASKED***"
+
+// RedactedValue replaces the values of the data of Secrets, and of ConfigMaps if they are redacted
+const RedactedValue = "***REDACTED***"
+
+// MaskPath is a parsed JSONPath expression selecting the fields to mask, see FieldPath
+type MaskPath = FieldPath
+
+// MaskSegment is a segment of a MaskPath, see FieldSegment
+type MaskSegment = FieldSegment
+
+// MaskItemSelector selects the list items of a MaskSegment, see FieldItemSelector
+type MaskItemSelector = FieldItemSelector
+
+// ParseMaskPath parses a JSONPath expression of the supported subset, see MaskPath
+func ParseMaskPath(expr string) (MaskPath, error) {
+	return ParseFieldPath(expr)
+}
+
+// validateMask checks the mask paths of a resource
+func validateMask(resource string, mask []string) error {
+	for _, expr := range mask {
+		if _, err := ParseMaskPath(expr); err != nil {
+			return fmt.Errorf("invalid Mask for %s: %w", resource, err)
+		}
+	}
+	return nil
+}
+
+// validateDropPaths checks the paths of the fields dropped from the objects of a resource
+func validateDropPaths(resource string, paths []string) error {
+	for _, expr := range paths {
+		if _, err := ParseFieldPath(expr); err != nil {
+			return fmt.Errorf("invalid DropPaths for %s: %w", resource, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMaskPath(t *testing.T) {
+	testCases := []struct {
+		expr      string
+		expected  MaskPath
+		expectErr bool
+	}{
+		{
+			expr:     "$.data.*_TOKEN",
+			expected: MaskPath{{Key: "data"}, {Key: "*_TOKEN"}},
+		},
+		{
+			expr: "$.spec.containers[*].env[?(@.name=='*_TOKEN')].value",
+			expected: MaskPath{
+				{Key: "spec"},
+				{Key: "containers"},
+				{Item: &MaskItemSelector{Index: -1}},
+				{Key: "env"},
+				{Item: &MaskItemSelector{Index: -1, Field: "name", Pattern: "*_TOKEN"}},
+				{Key: "value"},
+			},
+		},
+		{
+			expr:     "$..env[2][\"value\"]",
+			expected: MaskPath{{Key: "env", Descendant: true}, {Item: &MaskItemSelector{Index: 2}}, {Key: "value"}},
+		},
+		{
+			expr:     "metadata.annotations['example.com/token']",
+			expected: MaskPath{{Key: "metadata"}, {Key: "annotations"}, {Key: "example.com/token"}},
+		},
+		{expr: "", expectErr: true},
+		{expr: "$", expectErr: true},
+		{expr: "$.spec..", expectErr: true},
+		{expr: "$.spec[", expectErr: true},
+		{expr: "$.spec[-1]", expectErr: true},
+		{expr: "$.spec[?(@.name!='a')]", expectErr: true},
+		{expr: "$.spec[?(@.name==a)]", expectErr: true},
+		{expr: "$.spec['']", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		path, err := ParseMaskPath(tc.expr)
+		if tc.expectErr {
+			if err == nil {
+				t.Errorf("expected error for %q", tc.expr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error for %q: %s", tc.expr, err.Error())
+			continue
+		}
+		if !reflect.DeepEqual(path, tc.expected) {
+			t.Errorf("expected %v for %q, got %v", tc.expected, tc.expr, path)
+		}
+	}
+}
+
+func TestMask(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"Mask\":[\"$..env[?(@.name=='*_TOKEN')].value\"]},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	for _, pipeline := range meshsyncConfig.Pipelines[LocalResourceKey] {
+		var expected []string
+		if pipeline.Name == "pods.v1." {
+			expected = []string{"$..env[?(@.name=='*_TOKEN')].value"}
+		}
+		if !reflect.DeepEqual(pipeline.Mask, expected) {
+			t.Errorf("expected mask %v for %s, got %v", expected, pipeline.Name, pipeline.Mask)
+		}
+	}
+
+	if _, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"Mask\":[\"$.spec[\"]}]",
+	}); err == nil {
+		t.Error("expected error for an invalid Mask")
+	}
+}
+
+func TestDropPaths(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"DropPaths\":[\"$.spec.volumes\"]},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	for _, pipeline := range meshsyncConfig.Pipelines[LocalResourceKey] {
+		var expected []string
+		if pipeline.Name == "pods.v1." {
+			expected = []string{"$.spec.volumes"}
+		}
+		if !reflect.DeepEqual(pipeline.DropPaths, expected) {
+			t.Errorf("expected drop paths %v for %s, got %v", expected, pipeline.Name, pipeline.DropPaths)
+		}
+	}
+
+	if _, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"DropPaths\":[\"$.spec[\"]}]",
+	}); err == nil {
+		t.Error("expected error for invalid DropPaths")
+	}
+}
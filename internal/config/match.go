@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+
+	"golang.org/x/exp/slices"
+)
+
+// how the Resource of a whitelist or blacklist entry matches the names of the registered pipelines
+const (
+	// the Resource is the name of a single resource, see resourceNames
+	MatchExact = "exact"
+	// the Resource is a glob pattern, f.e. "*.networking.k8s.io"
+	MatchGlob = "glob"
+	// the Resource is a regular expression matching whole names, f.e. "(ingresses|gateways)\..*"
+	MatchRegex = "regex"
+)
+
+var MatchModes = []string{MatchExact, MatchGlob, MatchRegex}
+
+// matchingResources returns the names of the registered pipelines matching the pattern, in registry order.
+// Resources registered in both buckets are returned once.
+func matchingResources(resource, match string, registry map[string]PipelineConfigs) ([]string, error) {
+	var matches func(name string) bool
+	switch match {
+	case MatchGlob:
+		matches = func(name string) bool { return globMatch(resource, name) }
+	case MatchRegex:
+		re, err := regexp.Compile("^(?:" + resource + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", resource, err)
+		}
+		matches = re.MatchString
+	default:
+		return nil, fmt.Errorf("invalid Match %q for %s, expected one of %v", match, resource, MatchModes)
+	}
+
+	names := make([]string, 0)
+	for _, bucket := range []string{GlobalResourceKey, LocalResourceKey} {
+		for _, pc := range registry[bucket] {
+			if matches(pc.Name) && !slices.Contains(names, pc.Name) {
+				names = append(names, pc.Name)
+			}
+		}
+	}
+	return names, nil
+}
+
+// isPattern reports whether the entry matches resources by pattern rather than by name
+func isPattern(match string) bool {
+	return match != "" && match != MatchExact
+}
+
+// expandWhiteList replaces the entries matching resources by pattern with an entry per matched resource.
+// Resources whitelisted by name keep their own entry, a resource matched by several patterns takes the first.
+func expandWhiteList(whitelist []ResourceConfig, registry map[string]PipelineConfigs) ([]ResourceConfig, error) {
+	expanded := make([]ResourceConfig, 0, len(whitelist))
+	aliases := newResourceNames(registry)
+	named := make(map[string]bool)
+	for _, rc := range whitelist {
+		if !isPattern(rc.Match) {
+			// bare and group qualified names are resolved later on, their errors are reported then
+			name, _ := aliases.resolve(rc.Resource)
+			named[name] = true
+		}
+	}
+
+	for _, rc := range whitelist {
+		if !isPattern(rc.Match) {
+			expanded = append(expanded, rc)
+			continue
+		}
+		if rc.Group != "" || rc.Version != "" {
+			return nil, fmt.Errorf("invalid resource %s: Group and Version are not supported with Match %s", rc.Resource, rc.Match)
+		}
+		names, err := matchingResources(rc.Resource, rc.Match, registry)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if named[name] || (rc.Scope != "" && !registered(registry[rc.Scope], name)) {
+				continue
+			}
+			named[name] = true
+			entry := rc
+			entry.Resource = name
+			entry.Match = ""
+			expanded = append(expanded, entry)
+		}
+	}
+	return expanded, nil
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestMatchModes(t *testing.T) {
+	registry := map[string]PipelineConfigs{
+		GlobalResourceKey: {
+			{Name: "namespaces.v1.", PublishTo: "meshery.meshsync.core"},
+			{Name: "ingressclasses.v1.networking.k8s.io", PublishTo: "meshery.meshsync.core"},
+		},
+		LocalResourceKey: {
+			{Name: "pods.v1.", PublishTo: "meshery.meshsync.core"},
+			{Name: "ingresses.v1.networking.k8s.io", PublishTo: "meshery.meshsync.core"},
+			{Name: "networkpolicies.v1.networking.k8s.io", PublishTo: "meshery.meshsync.core"},
+			{Name: "gateways.v1.gateway.networking.k8s.io", PublishTo: "meshery.meshsync.core"},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		data           map[string]string
+		expectErr      bool
+		expectedGlobal map[string][]string
+		expectedLocal  map[string][]string
+	}{
+		{
+			name:          "exact",
+			data:          map[string]string{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"Match\":\"exact\"}]"},
+			expectedLocal: map[string][]string{"pods.v1.": {"ADDED"}},
+		},
+		{
+			name:           "glob matching several resources",
+			data:           map[string]string{"whitelist": "[{\"Resource\":\"*.networking.k8s.io\",\"Events\":[\"ADDED\"],\"Match\":\"glob\"}]"},
+			expectedGlobal: map[string][]string{"ingressclasses.v1.networking.k8s.io": {"ADDED"}},
+			expectedLocal: map[string][]string{
+				"ingresses.v1.networking.k8s.io":        {"ADDED"},
+				"networkpolicies.v1.networking.k8s.io":  {"ADDED"},
+				"gateways.v1.gateway.networking.k8s.io": {"ADDED"},
+			},
+		},
+		{
+			name:          "glob with scope",
+			data:          map[string]string{"whitelist": "[{\"Resource\":\"ingress*\",\"Events\":[\"ADDED\"],\"Match\":\"glob\",\"Scope\":\"local\"}]"},
+			expectedLocal: map[string][]string{"ingresses.v1.networking.k8s.io": {"ADDED"}},
+		},
+		{
+			name: "regex with a resource named explicitly",
+			data: map[string]string{"whitelist": "[{\"Resource\":\"(ingresses|networkpolicies)\\\\.v1\\\\.networking\\\\.k8s\\\\.io\",\"Events\":[\"ADDED\"],\"Match\":\"regex\"},{\"Resource\":\"ingresses.v1.networking.k8s.io\",\"Events\":[\"DELETED\"]}]"},
+			expectedLocal: map[string][]string{
+				"ingresses.v1.networking.k8s.io":       {"DELETED"},
+				"networkpolicies.v1.networking.k8s.io": {"ADDED"},
+			},
+		},
+		{
+			name:          "regex matching whole names only",
+			data:          map[string]string{"whitelist": "[{\"Resource\":\"gateway\",\"Events\":[\"ADDED\"],\"Match\":\"regex\"},{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]"},
+			expectedLocal: map[string][]string{"pods.v1.": {"ADDED"}},
+		},
+		{
+			name:           "blacklisted glob",
+			data:           map[string]string{"blacklist": "[{\"Resource\":\"*networking.k8s.io\",\"Match\":\"glob\"}]"},
+			expectedGlobal: map[string][]string{"namespaces.v1.": DefaultEvents},
+			expectedLocal:  map[string][]string{"pods.v1.": DefaultEvents},
+		},
+		{
+			name:           "blacklisted regex with events",
+			data:           map[string]string{"blacklist": "[\"namespaces.v1.\",{\"Resource\":\".*\\\\.networking\\\\.k8s\\\\.io\",\"Match\":\"regex\",\"Events\":[\"ADDED\"]},\"ingressclasses.v1.networking.k8s.io\"]"},
+			expectedGlobal: nil,
+			expectedLocal: map[string][]string{
+				"pods.v1.":                              DefaultEvents,
+				"ingresses.v1.networking.k8s.io":        {"ADDED"},
+				"networkpolicies.v1.networking.k8s.io":  {"ADDED"},
+				"gateways.v1.gateway.networking.k8s.io": {"ADDED"},
+			},
+		},
+		{
+			name:      "invalid regex",
+			data:      map[string]string{"whitelist": "[{\"Resource\":\"(ingresses\",\"Events\":[\"ADDED\"],\"Match\":\"regex\"}]"},
+			expectErr: true,
+		},
+		{
+			name:      "invalid blacklisted regex",
+			data:      map[string]string{"blacklist": "[{\"Resource\":\"[\",\"Match\":\"regex\"}]"},
+			expectErr: true,
+		},
+		{
+			name:      "unknown match mode",
+			data:      map[string]string{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"Match\":\"fuzzy\"}]"},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			meshsyncConfig, err := populateConfigsFromRegistry(tc.data, registry)
+			if tc.expectErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			assertMatchedEvents(t, GlobalResourceKey, meshsyncConfig.Pipelines[GlobalResourceKey], tc.expectedGlobal)
+			assertMatchedEvents(t, LocalResourceKey, meshsyncConfig.Pipelines[LocalResourceKey], tc.expectedLocal)
+		})
+	}
+}
+
+func assertMatchedEvents(t *testing.T, label string, pipelines PipelineConfigs, expected map[string][]string) {
+	t.Helper()
+	if len(pipelines) == 0 && len(expected) == 0 {
+		return
+	}
+	assertPipelineEvents(t, label, pipelines, expected)
+}
+
+func TestInvalidRegexError(t *testing.T) {
+	_, err := populateConfigsFromRegistry(map[string]string{
+		"whitelist": "[{\"Resource\":\"(ingresses\",\"Events\":[\"ADDED\"],\"Match\":\"regex\"}]",
+	}, map[string]PipelineConfigs{LocalResourceKey: {{Name: "ingresses.v1.networking.k8s.io"}}})
+	if codeOf(err) != ErrInitConfigCode {
+		t.Errorf("expected error code %s, got %s", ErrInitConfigCode, codeOf(err))
+	}
+}
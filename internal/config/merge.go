@@ -0,0 +1,65 @@
+package config
+
+import (
+	"errors"
+
+	"golang.org/x/exp/slices"
+)
+
+// MergeConfigs layers the overlay, f.e. the watch-list of a team, on top of the base, f.e. the platform
+// watch-list, and returns a fresh config, neither input is modified.
+// The pipelines are united by resource name within the global and the local bucket:
+//   - a resource of the overlay only is added,
+//   - a resource of both keeps the pipeline of the base, watched for the Events of the overlay,
+//   - an overlay pipeline without Events, i.e. watching the resource for nothing, removes the resource
+//     from both buckets.
+//
+// Listeners are united by name, the overlay winning. The global settings are the ones of the base,
+// they apply to the pipelines the overlay added as well. A paused base or overlay pauses the merged config.
+func MergeConfigs(base, overlay *MeshsyncConfig) (*MeshsyncConfig, error) {
+	if base == nil {
+		return nil, ErrInitConfig(errors.New("base config is nil"))
+	}
+	merged := *base
+	merged.Pipelines = make(map[string]PipelineConfigs, len(base.Pipelines))
+	for bucket, pipelines := range base.Pipelines {
+		merged.Pipelines[bucket] = append(PipelineConfigs{}, pipelines...)
+	}
+	merged.Listeners = make(map[string]ListenerConfig, len(base.Listeners))
+	for name, listener := range base.Listeners {
+		merged.Listeners[name] = listener
+	}
+	if overlay == nil || base.Paused {
+		return &merged, nil
+	}
+
+	removed := make(map[string]bool)
+	for _, bucket := range []string{GlobalResourceKey, LocalResourceKey} {
+		for _, pc := range overlay.Pipelines[bucket] {
+			if len(pc.Events) == 0 {
+				removed[pc.Name] = true
+				continue
+			}
+			pipelines := merged.Pipelines[bucket]
+			if idx := slices.IndexFunc(pipelines, func(c PipelineConfig) bool { return c.Name == pc.Name }); idx != -1 {
+				pipelines[idx].Events = append([]string{}, pc.Events...)
+				continue
+			}
+			merged.Pipelines[bucket] = append(pipelines, pc)
+		}
+	}
+	for bucket, pipelines := range merged.Pipelines {
+		merged.Pipelines[bucket] = slices.DeleteFunc(pipelines, func(c PipelineConfig) bool { return removed[c.Name] })
+	}
+	for name, listener := range overlay.Listeners {
+		merged.Listeners[name] = listener
+	}
+
+	if overlay.Paused {
+		merged.pause()
+	}
+	if err := validateRelationships(merged.Relationships, merged.Pipelines); err != nil {
+		return nil, ErrInitConfig(err)
+	}
+	return &merged, nil
+}
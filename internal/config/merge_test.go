@@ -0,0 +1,82 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeConfigs(t *testing.T) {
+	base, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist":       "[{\"Resource\":\"namespaces.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"Namespaces\":[\"prod\"]},{\"Resource\":\"secrets.v1.\",\"Events\":[\"ADDED\"]}]",
+		"envelopeVersion": "v1",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	overlay, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\",\"DELETED\"]},{\"Resource\":\"services.v1.\",\"Events\":[\"MODIFIED\"]},{\"Resource\":\"nodes.v1.\",\"Events\":[\"ADDED\"]}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	// secrets.v1. is removed by watching it for nothing
+	overlay.Pipelines[GlobalResourceKey] = append(overlay.Pipelines[GlobalResourceKey], PipelineConfig{Name: "secrets.v1."})
+	baseFingerprint, overlayFingerprint := ConfigFingerprint(base), ConfigFingerprint(overlay)
+
+	merged, err := MergeConfigs(base, overlay)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	expected := []EffectiveResource{
+		// added
+		{Resource: "namespaces.v1.", Scope: GlobalResourceKey, Events: []string{"ADDED"}},
+		{Resource: "nodes.v1.", Scope: GlobalResourceKey, Events: []string{"ADDED"}},
+		// overridden
+		{Resource: "pods.v1.", Scope: LocalResourceKey, Events: []string{"ADDED", "DELETED"}},
+		{Resource: "services.v1.", Scope: LocalResourceKey, Events: []string{"MODIFIED"}},
+	}
+	if resources := merged.EffectiveResources(); !reflect.DeepEqual(resources, expected) {
+		t.Errorf("expected the merged resources %v, got %v", expected, resources)
+	}
+	for _, pipelines := range merged.Pipelines {
+		for _, pipeline := range pipelines {
+			if pipeline.Name == "pods.v1." && !reflect.DeepEqual(pipeline.Namespaces, []string{"prod"}) {
+				t.Errorf("expected the overridden pipeline to keep the namespaces of the base, got %v", pipeline.Namespaces)
+			}
+		}
+	}
+	if merged.EnvelopeVersion != "v1" {
+		t.Errorf("expected the envelope version of the base, got %q", merged.EnvelopeVersion)
+	}
+
+	if ConfigFingerprint(base) != baseFingerprint || ConfigFingerprint(overlay) != overlayFingerprint {
+		t.Error("expected the inputs not to be modified")
+	}
+}
+
+func TestMergeConfigsKeepsRelationshipsValid(t *testing.T) {
+	base, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist":     "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]",
+		"relationships": "[\"service-pod\"]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	overlay := &MeshsyncConfig{Pipelines: map[string]PipelineConfigs{LocalResourceKey: {{Name: "pods.v1."}}}}
+
+	_, err = MergeConfigs(base, overlay)
+	if err == nil {
+		t.Fatal("expected error for removing a resource a relationship requires")
+	}
+	if codeOf(err) != ErrInitConfigCode {
+		t.Errorf("expected error code %s, got %s", ErrInitConfigCode, codeOf(err))
+	}
+
+	merged, err := MergeConfigs(base, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if !reflect.DeepEqual(merged.EffectiveResources(), base.EffectiveResources()) {
+		t.Errorf("expected the base resources without overlay, got %v", merged.EffectiveResources())
+	}
+}
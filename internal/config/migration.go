@@ -0,0 +1,58 @@
+package config
+
+// MigrationReport lists the resolved pipelines, keyed by resource key (global or local),
+// that a watch-list gains or loses when the pipelines registry changes
+type MigrationReport struct {
+	Added   map[string]PipelineConfigs `json:"added" yaml:"added"`
+	Removed map[string]PipelineConfigs `json:"removed" yaml:"removed"`
+}
+
+// IsEmpty reports whether the registry change has no effect on the watched resources
+func (r *MigrationReport) IsEmpty() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0
+}
+
+// MigrationImpact resolves the same watch-list against the old and the new pipelines registry
+// and reports which pipelines are newly watched or no longer watched,
+// so the difference is attributable purely to the registry change (f.e. a MeshSync upgrade)
+func MigrationImpact(oldRegistry, newRegistry map[string]PipelineConfigs, watchList map[string]string) (*MigrationReport, error) {
+	oldConfig, err := populateConfigsFromRegistry(watchList, oldRegistry)
+	if err != nil {
+		return nil, err
+	}
+	newConfig, err := populateConfigsFromRegistry(watchList, newRegistry)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &MigrationReport{
+		Added:   make(map[string]PipelineConfigs),
+		Removed: make(map[string]PipelineConfigs),
+	}
+	for _, key := range []string{GlobalResourceKey, LocalResourceKey} {
+		if added := pipelinesDifference(newConfig.Pipelines[key], oldConfig.Pipelines[key]); len(added) > 0 {
+			report.Added[key] = added
+		}
+		if removed := pipelinesDifference(oldConfig.Pipelines[key], newConfig.Pipelines[key]); len(removed) > 0 {
+			report.Removed[key] = removed
+		}
+	}
+
+	return report, nil
+}
+
+// pipelinesDifference returns the pipelines of a which are not present (by name) in b
+func pipelinesDifference(a, b PipelineConfigs) PipelineConfigs {
+	names := make(map[string]struct{}, len(b))
+	for _, pc := range b {
+		names[pc.Name] = struct{}{}
+	}
+
+	result := make(PipelineConfigs, 0)
+	for _, pc := range a {
+		if _, ok := names[pc.Name]; !ok {
+			result = append(result, pc)
+		}
+	}
+	return result
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestMigrationImpact(t *testing.T) {
+	oldRegistry := map[string]PipelineConfigs{
+		GlobalResourceKey: {
+			{Name: "namespaces.v1.", PublishTo: DefaultPublishingSubject},
+		},
+		LocalResourceKey: {
+			{Name: "pods.v1.", PublishTo: DefaultPublishingSubject},
+			{Name: "services.v1.", PublishTo: DefaultPublishingSubject},
+		},
+	}
+	newRegistry := map[string]PipelineConfigs{
+		GlobalResourceKey: {
+			{Name: "namespaces.v1.", PublishTo: DefaultPublishingSubject},
+		},
+		LocalResourceKey: {
+			{Name: "pods.v1.", PublishTo: DefaultPublishingSubject},
+			{Name: "services.v1.", PublishTo: DefaultPublishingSubject},
+			{Name: "deployments.v1.apps", PublishTo: DefaultPublishingSubject},
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		oldRegistry     map[string]PipelineConfigs
+		newRegistry     map[string]PipelineConfigs
+		watchList       map[string]string
+		expectedAdded   []string
+		expectedRemoved []string
+	}{
+		{
+			name:          "blacklist picks up a resource added to the registry",
+			oldRegistry:   oldRegistry,
+			newRegistry:   newRegistry,
+			watchList:     map[string]string{"blacklist": "[\"services.v1.\"]"},
+			expectedAdded: []string{"deployments.v1.apps"},
+		},
+		{
+			name:            "blacklist loses a resource removed from the registry",
+			oldRegistry:     newRegistry,
+			newRegistry:     oldRegistry,
+			watchList:       map[string]string{"blacklist": "[\"services.v1.\"]"},
+			expectedRemoved: []string{"deployments.v1.apps"},
+		},
+		{
+			name:        "whitelist not naming the resource is unaffected",
+			oldRegistry: oldRegistry,
+			newRegistry: newRegistry,
+			watchList:   map[string]string{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]"},
+		},
+		{
+			name:          "whitelist naming the resource starts watching it",
+			oldRegistry:   oldRegistry,
+			newRegistry:   newRegistry,
+			watchList:     map[string]string{"whitelist": "[{\"Resource\":\"deployments.v1.apps\",\"Events\":[\"ADDED\"]}]"},
+			expectedAdded: []string{"deployments.v1.apps"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report, err := MigrationImpact(tc.oldRegistry, tc.newRegistry, tc.watchList)
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			if len(report.Added[GlobalResourceKey]) != 0 || len(report.Removed[GlobalResourceKey]) != 0 {
+				t.Errorf("expected no global changes, got %+v", report)
+			}
+			assertPipelineNames(t, "added", report.Added[LocalResourceKey], tc.expectedAdded)
+			assertPipelineNames(t, "removed", report.Removed[LocalResourceKey], tc.expectedRemoved)
+			if report.IsEmpty() != (len(tc.expectedAdded) == 0 && len(tc.expectedRemoved) == 0) {
+				t.Errorf("unexpected IsEmpty result for %+v", report)
+			}
+		})
+	}
+}
+
+func assertPipelineNames(t *testing.T, label string, pipelines PipelineConfigs, expected []string) {
+	t.Helper()
+	if len(pipelines) != len(expected) {
+		t.Fatalf("expected %d %s pipelines, got %d", len(expected), label, len(pipelines))
+	}
+	for i, name := range expected {
+		if pipelines[i].Name != name {
+			t.Errorf("expected %s pipeline %s, got %s", label, name, pipelines[i].Name)
+		}
+	}
+}
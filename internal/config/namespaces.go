@@ -0,0 +1,231 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/meshery/meshkit/utils"
+	"golang.org/x/exp/slices"
+)
+
+// informer topologies of pipelines scoped to namespaces
+const (
+	// a single informer watches all namespaces, objects of other namespaces are filtered out
+	NamespaceStrategyAll = "all"
+	// an informer per namespace watches only the namespaces of the pipeline
+	NamespaceStrategyPerNamespace = "perNamespace"
+	// an informer per namespace unless the pipeline has more namespaces than the threshold
+	NamespaceStrategyAuto = "auto"
+)
+
+var NamespaceStrategies = []string{NamespaceStrategyAll, NamespaceStrategyPerNamespace, NamespaceStrategyAuto}
+
+// DefaultNamespaceStrategyThreshold is the most namespaces the auto strategy watches one by one,
+// beyond it the cost of the watches outweighs listing objects of unrelated namespaces
+const DefaultNamespaceStrategyThreshold = 10
+
+// parseNamespaceStrategy reads the informer topology setting and its threshold
+func parseNamespaceStrategy(data map[string]string, meshsyncConfig *MeshsyncConfig) error {
+	meshsyncConfig.NamespaceStrategy = NamespaceStrategyAll
+	if strategy := data["namespaceStrategy"]; strategy != "" {
+		if !slices.Contains(NamespaceStrategies, strategy) {
+			return ErrInitConfig(fmt.Errorf("invalid namespaceStrategy value %q, expected one of %v", strategy, NamespaceStrategies))
+		}
+		meshsyncConfig.NamespaceStrategy = strategy
+	}
+
+	meshsyncConfig.NamespaceStrategyThreshold = DefaultNamespaceStrategyThreshold
+	if err := parseIntSetting(data, "namespaceStrategyThreshold", &meshsyncConfig.NamespaceStrategyThreshold); err != nil {
+		return err
+	}
+	if meshsyncConfig.NamespaceStrategyThreshold < 0 {
+		return ErrInitConfig(fmt.Errorf("invalid namespaceStrategyThreshold value %d: must not be negative", meshsyncConfig.NamespaceStrategyThreshold))
+	}
+	return nil
+}
+
+// WatchedNamespaces returns the namespaces the pipeline watches one by one under the settings,
+// nil when a single informer watches all namespaces.
+// With NamespacedClients the pipelines watch their namespaces one by one whatever the strategy.
+func (pc PipelineConfig) WatchedNamespaces(settings GlobalSettings) []string {
+	if len(pc.Namespaces) == 0 {
+		return nil
+	}
+	if settings.NamespacedClients {
+		return pc.Namespaces
+	}
+	switch settings.NamespaceStrategy {
+	case NamespaceStrategyPerNamespace:
+		return pc.Namespaces
+	case NamespaceStrategyAuto:
+		if len(pc.Namespaces) <= settings.NamespaceStrategyThreshold {
+			return pc.Namespaces
+		}
+	}
+	return nil
+}
+
+// parseNamespaceScopes reads the optional namespaces the pipelines of resources are scoped to, keyed by resource,
+// f.e. {"pods.v1.":["default","prod"]}. It is how resources watched in the blacklist mode get scoped.
+func parseNamespaceScopes(data map[string]string) (map[string][]string, error) {
+	raw, ok := data["namespaces"]
+	if !ok || raw == "" {
+		return nil, nil
+	}
+
+	scopes := make(map[string][]string)
+	if err := utils.Unmarshal(raw, &scopes); err != nil {
+		return nil, fmt.Errorf("invalid namespaces: %w", err)
+	}
+	for resource, namespaces := range scopes {
+		if len(namespaces) == 0 {
+			return nil, fmt.Errorf("invalid namespaces: no namespace given for %s", resource)
+		}
+		if err := validateNamespaces(resource, namespaces); err != nil {
+			return nil, err
+		}
+	}
+	return scopes, nil
+}
+
+// validateNamespaces rejects empty namespaces, which would scope the pipeline to cluster scoped objects only
+func validateNamespaces(resource string, namespaces []string) error {
+	for _, namespace := range namespaces {
+		if namespace == "" {
+			return fmt.Errorf("invalid namespaces: empty namespace given for %s", resource)
+		}
+	}
+	return nil
+}
+
+// validateBucketNamespaces rejects pipelines of global resources scoped to namespaces, their objects have none
+func validateBucketNamespaces(bucket string, pc PipelineConfig) error {
+	if bucket == GlobalResourceKey && len(pc.Namespaces) > 0 {
+		return fmt.Errorf("invalid Namespaces for %s: %s resources are cluster scoped", pc.Name, GlobalResourceKey)
+	}
+	return nil
+}
+
+// applyNamespaceScopes scopes the pipelines to their namespaces,
+// every scoped resource must be watched or the scope would silently be lost
+func applyNamespaceScopes(scopes map[string][]string, pipelines map[string]PipelineConfigs) error {
+	if scopes == nil {
+		return nil
+	}
+
+	unknown := make(map[string]bool, len(scopes))
+	for resource := range scopes {
+		unknown[resource] = true
+	}
+	for _, configs := range pipelines {
+		for i := range configs {
+			if namespaces, ok := scopes[configs[i].Name]; ok {
+				if len(configs[i].Namespaces) > 0 {
+					return fmt.Errorf("invalid namespaces: %s is scoped by its whitelist entry already", configs[i].Name)
+				}
+				configs[i].Namespaces = namespaces
+				delete(unknown, configs[i].Name)
+			}
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	missing := make([]string, 0, len(unknown))
+	for resource := range unknown {
+		missing = append(missing, resource)
+	}
+	sort.Strings(missing)
+	return fmt.Errorf("invalid namespaces: resources %v are not watched", missing)
+}
+
+// parseExcludeNamespaces reads the optional namespaces no pipeline reports objects of, f.e. ["kube-system"]
+func parseExcludeNamespaces(data map[string]string) ([]string, error) {
+	raw, ok := data["excludeNamespaces"]
+	if !ok || raw == "" {
+		return nil, nil
+	}
+
+	excluded := make([]string, 0)
+	if err := utils.Unmarshal(raw, &excluded); err != nil {
+		return nil, fmt.Errorf("invalid excludeNamespaces: %w", err)
+	}
+	for _, namespace := range excluded {
+		if namespace == "" {
+			return nil, fmt.Errorf("invalid excludeNamespaces: empty namespace given")
+		}
+	}
+	return excluded, nil
+}
+
+// applyExcludeNamespaces removes the excluded namespaces from the namespaces the pipelines are scoped to,
+// the pipelines watching all namespaces drop the objects of the excluded ones instead.
+// A pipeline left with no namespace is an error, it would watch all namespaces instead of none.
+func applyExcludeNamespaces(excluded []string, pipelines map[string]PipelineConfigs) error {
+	if len(excluded) == 0 {
+		return nil
+	}
+	for _, configs := range pipelines {
+		for i := range configs {
+			if len(configs[i].Namespaces) == 0 {
+				configs[i].ExcludeNamespaces = excluded
+				continue
+			}
+			included := make([]string, 0, len(configs[i].Namespaces))
+			for _, namespace := range configs[i].Namespaces {
+				if !slices.Contains(excluded, namespace) {
+					included = append(included, namespace)
+				}
+			}
+			if len(included) == 0 {
+				return fmt.Errorf("invalid excludeNamespaces: every namespace %s is scoped to is excluded", configs[i].Name)
+			}
+			configs[i].Namespaces = included
+		}
+	}
+	return nil
+}
+
+// validateNamespacedClients ensures nothing needs cluster-wide list and watch permissions when the pipelines
+// are watched through namespaced clients: every pipeline must be scoped to namespaces, and the namespaces
+// and the owners of Pods, which the namespace settings and rollups are resolved from, cannot be watched
+func validateNamespacedClients(meshsyncConfig *MeshsyncConfig) error {
+	if !meshsyncConfig.NamespacedClients {
+		return nil
+	}
+
+	clusterWide := make([]string, 0)
+	rollups := make([]string, 0)
+	for bucket, pipelines := range meshsyncConfig.Pipelines {
+		for _, pc := range pipelines {
+			if bucket == GlobalResourceKey || len(pc.Namespaces) == 0 {
+				clusterWide = append(clusterWide, pc.Name)
+			}
+			if pc.Rollup {
+				rollups = append(rollups, pc.Name)
+			}
+		}
+	}
+	if len(clusterWide) > 0 {
+		sort.Strings(clusterWide)
+		return fmt.Errorf("invalid namespacedClients: pipelines %v are cluster scoped, every pipeline must be scoped to namespaces", clusterWide)
+	}
+	if len(rollups) > 0 {
+		sort.Strings(rollups)
+		return fmt.Errorf("invalid namespacedClients: pipelines %v roll up Pods, which watches their owners cluster-wide", rollups)
+	}
+	for _, setting := range []struct {
+		name    string
+		enabled bool
+	}{
+		{name: "strictNamespaces", enabled: meshsyncConfig.StrictNamespaces},
+		{name: "namespaceMetadata", enabled: meshsyncConfig.NamespaceMetadata},
+		{name: "tenantLabel", enabled: meshsyncConfig.TenantLabel != ""},
+	} {
+		if setting.enabled {
+			return fmt.Errorf("invalid namespacedClients: %s requires to list the cluster scoped namespaces", setting.name)
+		}
+	}
+	return nil
+}
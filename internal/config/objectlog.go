@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultObjectLogWindow is the window ObjectLogSampling.Limit applies to unless configured
+const DefaultObjectLogWindow = time.Second
+
+// ObjectLogSampling thins out the lines logged per processed object, f.e. "Received ADD event for: ...",
+// which flood the logs during the initial sync of large clusters. It applies to every pipeline on its own.
+type ObjectLogSampling struct {
+	// Every logs one in every Every lines, zero or one logs all of them
+	Every int `json:"every,omitempty" yaml:"every,omitempty"`
+	// Limit is the most lines logged per Window, zero logs any number of them
+	Limit  int           `json:"limit,omitempty" yaml:"limit,omitempty"`
+	Window time.Duration `json:"window,omitempty" yaml:"window,omitempty"`
+}
+
+// Enabled reports whether any line is left out
+func (s ObjectLogSampling) Enabled() bool {
+	return s.Every > 1 || s.Limit > 0
+}
+
+// parseObjectLogSampling reads the optional objectLogEvery, objectLogLimit and objectLogWindow settings
+func parseObjectLogSampling(data map[string]string) (ObjectLogSampling, error) {
+	sampling := ObjectLogSampling{Window: DefaultObjectLogWindow}
+	if err := parseIntSetting(data, "objectLogEvery", &sampling.Every); err != nil {
+		return sampling, err
+	}
+	if err := parseIntSetting(data, "objectLogLimit", &sampling.Limit); err != nil {
+		return sampling, err
+	}
+	if sampling.Every < 0 || sampling.Limit < 0 {
+		return sampling, ErrInitConfig(fmt.Errorf("invalid object log sampling every %d, limit %d: must not be negative", sampling.Every, sampling.Limit))
+	}
+	if window := data["objectLogWindow"]; window != "" {
+		parsed, err := time.ParseDuration(window)
+		if err != nil {
+			return sampling, ErrInitConfig(fmt.Errorf("invalid objectLogWindow value %q: %w", window, err))
+		}
+		if parsed <= 0 {
+			return sampling, ErrInitConfig(fmt.Errorf("invalid objectLogWindow value %q: must be positive", window))
+		}
+		sampling.Window = parsed
+	}
+	return sampling, nil
+}
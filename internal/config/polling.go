@@ -0,0 +1,21 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// MinPollInterval is the shortest PollInterval, polling more often costs more than watching
+const MinPollInterval = 10 * time.Second
+
+// parsePollInterval parses the interval a resource is listed on instead of being watched
+func parsePollInterval(resource, interval string) (time.Duration, error) {
+	pollInterval, err := time.ParseDuration(interval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid PollInterval for %s: %w", resource, err)
+	}
+	if pollInterval < MinPollInterval {
+		return 0, fmt.Errorf("invalid PollInterval for %s: must be at least %s", resource, MinPollInterval)
+	}
+	return pollInterval, nil
+}
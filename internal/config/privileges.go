@@ -0,0 +1,108 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	authorizationclient "k8s.io/client-go/kubernetes/typed/authorization/v1"
+)
+
+// how a startup check finding write permissions is handled
+const (
+	ReadOnlyCheckOff  = ""
+	ReadOnlyCheckWarn = "warn"
+	ReadOnlyCheckFail = "fail"
+)
+
+var ReadOnlyCheckModes = []string{ReadOnlyCheckOff, ReadOnlyCheckWarn, ReadOnlyCheckFail}
+
+// MutatingVerbs are the verbs MeshSync must not be permitted on the resources it watches
+var MutatingVerbs = []string{"create", "update", "patch", "delete"}
+
+// requiredWrite returns the write MeshSync needs, PatchCRVersion patches its own custom resource
+func requiredWrite() authorizationv1.ResourceAttributes {
+	crdConfig := CRDConfigFromEnv()
+	return authorizationv1.ResourceAttributes{
+		Namespace: crdConfig.Namespace,
+		Verb:      "patch",
+		Group:     crdConfig.Group,
+		Resource:  crdConfig.Resource,
+	}
+}
+
+// ValidateReadOnly proves least privilege by reviewing whether the mutating verbs are permitted
+// on the resources of the pipelines, cluster wide and in the namespaces they are scoped to.
+// The patch of MeshSync's own custom resource is not reported.
+func ValidateReadOnly(ctx context.Context, pipelines map[string]PipelineConfigs, client authorizationclient.SelfSubjectAccessReviewInterface) error {
+	reviewed := make(map[authorizationv1.ResourceAttributes]bool)
+	permitted := make([]string, 0)
+	for _, configs := range pipelines {
+		for _, pc := range configs {
+			gvr, _ := schema.ParseResourceArg(pc.Name)
+			if gvr == nil {
+				return ErrInitConfig(fmt.Errorf("invalid resource %s", pc.Name))
+			}
+
+			for _, ns := range append([]string{metav1.NamespaceAll}, pc.Namespaces...) {
+				for _, verb := range MutatingVerbs {
+					attributes := authorizationv1.ResourceAttributes{
+						Namespace: ns,
+						Verb:      verb,
+						Group:     gvr.Group,
+						Version:   gvr.Version,
+						Resource:  gvr.Resource,
+					}
+					if reviewed[attributes] || isRequiredWrite(attributes) {
+						continue
+					}
+					reviewed[attributes] = true
+
+					allowed, err := accessAllowed(ctx, client, attributes)
+					if err != nil {
+						return ErrInitConfig(err)
+					}
+					if allowed {
+						permitted = append(permitted, describeAccess(pc.Name, attributes))
+					}
+				}
+			}
+		}
+	}
+	if len(permitted) > 0 {
+		sort.Strings(permitted)
+		return ErrWritePermitted(permitted)
+	}
+	return nil
+}
+
+func accessAllowed(ctx context.Context, client authorizationclient.SelfSubjectAccessReviewInterface, attributes authorizationv1.ResourceAttributes) (bool, error) {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attributes},
+	}
+	result, err := client.Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false, fmt.Errorf("unable to review %s access to %s: %w", attributes.Verb, attributes.Resource, err)
+	}
+	return result.Status.Allowed, nil
+}
+
+// isRequiredWrite reports whether the access is covered by the write MeshSync needs,
+// cluster wide access is broader than it hence not covered
+func isRequiredWrite(attributes authorizationv1.ResourceAttributes) bool {
+	requiredWrite := requiredWrite()
+	return attributes.Namespace == requiredWrite.Namespace &&
+		attributes.Verb == requiredWrite.Verb &&
+		attributes.Group == requiredWrite.Group &&
+		attributes.Resource == requiredWrite.Resource
+}
+
+func describeAccess(resource string, attributes authorizationv1.ResourceAttributes) string {
+	if attributes.Namespace == metav1.NamespaceAll {
+		return fmt.Sprintf("%s %s", attributes.Verb, resource)
+	}
+	return fmt.Sprintf("%s %s in %s", attributes.Verb, resource, attributes.Namespace)
+}
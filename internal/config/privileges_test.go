@@ -0,0 +1,70 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/meshery/meshkit/errors"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newFakeAuthorizer returns a client permitting exactly the granted "verb resource namespace" accesses
+func newFakeAuthorizer(granted ...string) *fake.Clientset {
+	client := fake.NewClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attributes := review.Spec.ResourceAttributes
+		access := strings.TrimSpace(attributes.Verb + " " + attributes.Resource + " " + attributes.Namespace)
+		for _, g := range granted {
+			if g == access {
+				review.Status.Allowed = true
+			}
+		}
+		return true, review, nil
+	})
+	return client
+}
+
+func TestValidateReadOnly(t *testing.T) {
+	pipelines := map[string]PipelineConfigs{
+		GlobalResourceKey: {{Name: "meshsyncs.v1alpha1.meshery.io", Namespaces: []string{"meshery"}}},
+		LocalResourceKey:  {{Name: "pods.v1."}, {Name: "deployments.v1.apps", Namespaces: []string{"prod"}}},
+	}
+
+	testCases := []struct {
+		name              string
+		granted           []string
+		expectedPermitted string
+	}{
+		{name: "read only"},
+		{name: "patch of the own custom resource", granted: []string{"patch meshsyncs meshery"}},
+		{name: "cluster wide write", granted: []string{"delete pods"}, expectedPermitted: "delete pods.v1."},
+		{name: "namespaced write", granted: []string{"update deployments prod"}, expectedPermitted: "update deployments.v1.apps in prod"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeAuthorizer(tc.granted...)
+			err := ValidateReadOnly(context.Background(), pipelines, client.AuthorizationV1().SelfSubjectAccessReviews())
+			if tc.expectedPermitted == "" {
+				if err != nil {
+					t.Errorf("unexpected error %s", errors.GetSDescription(err))
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected the write permission to be flagged")
+			}
+			if codeOf(err) != ErrWritePermittedCode {
+				t.Errorf("expected error code %s, got %s", ErrWritePermittedCode, codeOf(err))
+			}
+			if description := errors.GetSDescription(err); !strings.Contains(description, tc.expectedPermitted) {
+				t.Errorf("expected %q to be flagged, got %s", tc.expectedPermitted, description)
+			}
+		})
+	}
+}
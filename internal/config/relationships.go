@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/meshery/meshkit/utils"
+	"golang.org/x/exp/slices"
+)
+
+// relationship types MeshSync computes between the objects of watched resources
+const (
+	// a Service selects the Pods of its namespace matching its selector
+	RelationshipServicePod = "service-pod"
+	// an Ingress routes to the Services of its namespace its backends name
+	RelationshipIngressService = "ingress-service"
+)
+
+var RelationshipTypes = []string{RelationshipServicePod, RelationshipIngressService}
+
+// RelationshipEnds are the resources of the source and the target of a relationship type
+type RelationshipEnds struct {
+	Source string
+	Target string
+}
+
+// RelationshipResources are the ends of every relationship type,
+// both resources must be watched for the relationship to be computed
+var RelationshipResources = map[string]RelationshipEnds{
+	RelationshipServicePod:     {Source: "services.v1.", Target: PodsResource},
+	RelationshipIngressService: {Source: "ingresses.v1.networking.k8s.io", Target: "services.v1."},
+}
+
+// parseRelationships reads the optional list of relationship types to compute, f.e. ["service-pod"]
+func parseRelationships(data map[string]string) ([]string, error) {
+	raw, ok := data["relationships"]
+	if !ok || raw == "" {
+		return nil, nil
+	}
+
+	relationships := make([]string, 0)
+	if err := utils.Unmarshal(raw, &relationships); err != nil {
+		return nil, fmt.Errorf("invalid relationships: %w", err)
+	}
+	for _, relationship := range relationships {
+		if !slices.Contains(RelationshipTypes, relationship) {
+			return nil, fmt.Errorf("invalid relationships: unknown relationship type %q, expected one of %v", relationship, RelationshipTypes)
+		}
+	}
+	return relationships, nil
+}
+
+// validateRelationships ensures the resources at both ends of every relationship are watched,
+// the relationships are computed from the caches of their pipelines
+func validateRelationships(relationships []string, pipelines map[string]PipelineConfigs) error {
+	watched := func(resource string) bool {
+		for _, configs := range pipelines {
+			if slices.ContainsFunc(configs, func(pc PipelineConfig) bool { return pc.Name == resource }) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, relationship := range relationships {
+		ends := RelationshipResources[relationship]
+		for _, resource := range []string{ends.Source, ends.Target} {
+			if !watched(resource) {
+				return fmt.Errorf("invalid relationships: %s requires %s to be watched", relationship, resource)
+			}
+		}
+	}
+	return nil
+}
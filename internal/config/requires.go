@@ -0,0 +1,66 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// validateRequires checks the resources a pipeline requires are fully qualified, f.e. "gateways.v1.networking.istio.io"
+func validateRequires(resource string, requires []string) error {
+	for _, required := range requires {
+		gvr, _ := schema.ParseResourceArg(required)
+		if gvr == nil {
+			return fmt.Errorf("invalid Requires %q for %s: expected resource.version.group", required, resource)
+		}
+	}
+	return nil
+}
+
+// ActivePipelines returns the pipelines whose required resources are all served by the cluster,
+// and the names of the inactive ones. Discovery is queried at most once per group version,
+// pipelines whose requirements fail to be discovered are inactive and the errors are returned along.
+func ActivePipelines(pipelines map[string]PipelineConfigs, client discovery.DiscoveryInterface) (map[string]PipelineConfigs, []string, error) {
+	served := make(map[schema.GroupVersion]map[string]bool)
+	var errs []error
+	isServed := func(required string) bool {
+		// validated when the config is resolved
+		gvr, _ := schema.ParseResourceArg(required)
+		if gvr == nil {
+			return false
+		}
+		gv := gvr.GroupVersion()
+		if _, ok := served[gv]; !ok {
+			resources, err := servedResources(client, gv)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			served[gv] = resources
+		}
+		return served[gv][gvr.Resource]
+	}
+
+	active := make(map[string]PipelineConfigs, len(pipelines))
+	inactive := make([]string, 0)
+	for key, configs := range pipelines {
+		active[key] = make(PipelineConfigs, 0, len(configs))
+	configs:
+		for _, pc := range configs {
+			for _, required := range pc.Requires {
+				if !isServed(required) {
+					inactive = append(inactive, pc.Name)
+					continue configs
+				}
+			}
+			active[key] = append(active[key], pc)
+		}
+	}
+	sort.Strings(inactive)
+	if len(errs) > 0 {
+		return active, inactive, ErrInitConfig(errors.Join(errs...))
+	}
+	return active, inactive, nil
+}
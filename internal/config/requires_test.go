@@ -0,0 +1,95 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func pipelineNames(pipelines map[string]PipelineConfigs) []string {
+	names := make([]string, 0)
+	for _, key := range []string{GlobalResourceKey, LocalResourceKey} {
+		for _, pc := range pipelines[key] {
+			names = append(names, pc.Name)
+		}
+	}
+	return names
+}
+
+// requiresRegistry registers Istio's virtual services next to the core resources
+var requiresRegistry = map[string]PipelineConfigs{
+	GlobalResourceKey: {
+		{Name: "virtualservices.v1beta1.networking.istio.io", PublishTo: "meshery.meshsync.core"},
+	},
+	LocalResourceKey: {
+		{Name: "pods.v1.", PublishTo: "meshery.meshsync.core"},
+	},
+}
+
+func TestActivePipelines(t *testing.T) {
+	meshsyncConfig, err := populateConfigsFromRegistry(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"virtualservices.v1beta1.networking.istio.io\",\"Events\":[\"ADDED\"],\"Requires\":[\"virtualservices.v1beta1.networking.istio.io\",\"destinationrules.v1beta1.networking.istio.io\"]}]",
+	}, requiresRegistry)
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	discovery := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{
+		Resources: []*metav1.APIResourceList{
+			{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "pods"}}},
+			{GroupVersion: "networking.istio.io/v1beta1", APIResources: []metav1.APIResource{{Name: "virtualservices"}}},
+		},
+	}}
+
+	// the CRD of destination rules is not installed
+	active, inactive, err := ActivePipelines(meshsyncConfig.Pipelines, discovery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := pipelineNames(active); !reflect.DeepEqual(names, []string{"pods.v1."}) {
+		t.Errorf("expected only the unconditional pipeline to be active, got %v", names)
+	}
+	if !reflect.DeepEqual(inactive, []string{"virtualservices.v1beta1.networking.istio.io"}) {
+		t.Errorf("expected the conditional pipeline to be inactive, got %v", inactive)
+	}
+
+	// the CRD of destination rules appears
+	discovery.Resources[1].APIResources = append(discovery.Resources[1].APIResources, metav1.APIResource{Name: "destinationrules"})
+	active, inactive, err = ActivePipelines(meshsyncConfig.Pipelines, discovery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := pipelineNames(active); !reflect.DeepEqual(names, []string{"virtualservices.v1beta1.networking.istio.io", "pods.v1."}) {
+		t.Errorf("expected both pipelines to be active, got %v", names)
+	}
+	if len(inactive) != 0 {
+		t.Errorf("expected no inactive pipeline, got %v", inactive)
+	}
+}
+
+func TestRequires(t *testing.T) {
+	meshsyncConfig, err := populateConfigsFromRegistry(map[string]string{
+		"whitelist": "[{\"Resource\":\"virtualservices.v1beta1.networking.istio.io\",\"Events\":[\"ADDED\"],\"Requires\":[\"gateways.v1beta1.networking.istio.io\"]}]",
+	}, requiresRegistry)
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	requires := meshsyncConfig.Pipelines[GlobalResourceKey][0].Requires
+	if !reflect.DeepEqual(requires, []string{"gateways.v1beta1.networking.istio.io"}) {
+		t.Errorf("expected the required resources, got %v", requires)
+	}
+
+	// conditional pipelines are not reported as not served
+	discovery := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}}
+	if err := ValidateServedResources(meshsyncConfig, discovery); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+
+	if _, err := populateConfigsFromRegistry(map[string]string{
+		"whitelist": "[{\"Resource\":\"virtualservices.v1beta1.networking.istio.io\",\"Events\":[\"ADDED\"],\"Requires\":[\"gateways\"]}]",
+	}, requiresRegistry); err == nil {
+		t.Error("expected error for a required resource which is not fully qualified")
+	}
+}
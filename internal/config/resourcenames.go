@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// resourceNames resolves the names the watch-list refers to resources by to the names of the registered pipelines.
+// Besides the pipeline name, f.e. "ingresses.v1.networking.k8s.io", a resource may be named by its plural,
+// f.e. "ingresses", or its plural qualified with its group, f.e. "ingresses.networking.k8s.io",
+// as long as the name matches a single pipeline.
+type resourceNames map[string][]string
+
+func newResourceNames(registry map[string]PipelineConfigs) resourceNames {
+	names := make(resourceNames)
+	add := func(alias, name string) {
+		for _, existing := range names[alias] {
+			if existing == name {
+				// registered in both buckets
+				return
+			}
+		}
+		names[alias] = append(names[alias], name)
+	}
+	for _, pipelines := range registry {
+		for _, pc := range pipelines {
+			gvr, _ := schema.ParseResourceArg(pc.Name)
+			if gvr == nil {
+				continue
+			}
+			add(gvr.Resource, pc.Name)
+			if gvr.Group != "" {
+				add(gvr.Resource+"."+gvr.Group, pc.Name)
+			}
+		}
+	}
+	return names
+}
+
+// resolve returns the pipeline name the resource is named by, names matching no pipeline are returned as they are.
+// A plural served by more than one group or version is ambiguous and has to be qualified.
+func (n resourceNames) resolve(resource string) (string, error) {
+	// pipeline names are no aliases, they have a version
+	candidates, ok := n[resource]
+	if !ok {
+		return resource, nil
+	}
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+	sorted := append([]string{}, candidates...)
+	sort.Strings(sorted)
+	if strings.Contains(resource, ".") {
+		return "", fmt.Errorf("resource %s is ambiguous, it is served in the versions %v, use the full name", resource, sorted)
+	}
+	return "", fmt.Errorf("resource %s is ambiguous, it is served as %v, qualify it with its group, f.e. %s", resource, sorted, qualifiedName(sorted[0]))
+}
+
+// qualifiedName returns the plural of the pipeline qualified with its group
+func qualifiedName(name string) string {
+	gvr, _ := schema.ParseResourceArg(name)
+	if gvr == nil || gvr.Group == "" {
+		return name
+	}
+	return gvr.Resource + "." + gvr.Group
+}
+
+// resolveResourceNames replaces the names of the whitelisted and blacklisted resources by their pipeline names,
+// see resourceNames
+func resolveResourceNames(meshsyncConfig *MeshsyncConfig, registry map[string]PipelineConfigs) error {
+	names := newResourceNames(registry)
+
+	for i := range meshsyncConfig.WhiteList {
+		resource, qualified, err := meshsyncConfig.WhiteList[i].pipelineName()
+		if err != nil {
+			return err
+		}
+		if !qualified {
+			resource, err = names.resolve(resource)
+			if err != nil {
+				return err
+			}
+		}
+		meshsyncConfig.WhiteList[i].Resource = resource
+	}
+	for i := range meshsyncConfig.BlackList {
+		resource, err := names.resolve(meshsyncConfig.BlackList[i])
+		if err != nil {
+			return err
+		}
+		meshsyncConfig.BlackList[i] = resource
+	}
+	if len(meshsyncConfig.BlackListEvents) > 0 {
+		events := make(map[string][]string, len(meshsyncConfig.BlackListEvents))
+		for name, resourceEvents := range meshsyncConfig.BlackListEvents {
+			resource, err := names.resolve(name)
+			if err != nil {
+				return err
+			}
+			if _, ok := events[resource]; ok {
+				return fmt.Errorf("invalid blacklist: %s given more than once", resource)
+			}
+			events[resource] = resourceEvents
+		}
+		meshsyncConfig.BlackListEvents = events
+	}
+	return nil
+}
+
+// pipelineName returns the pipeline name of a resource given by Group and Version, f.e. "certificates.v1.cert-manager.io",
+// and reports whether it was given so, otherwise Resource is returned to be resolved
+func (rc ResourceConfig) pipelineName() (string, bool, error) {
+	if rc.Group == "" && rc.Version == "" {
+		return rc.Resource, false, nil
+	}
+	switch {
+	case rc.Version == "":
+		return "", false, fmt.Errorf("invalid resource %s: Group given without Version", rc.Resource)
+	case rc.Resource == "" || strings.Contains(rc.Resource, "."):
+		return "", false, fmt.Errorf("invalid resource %q: expected the plural of the resource along with Group and Version", rc.Resource)
+	}
+	return rc.Resource + "." + rc.Version + "." + rc.Group, true, nil
+}
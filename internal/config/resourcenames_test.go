@@ -0,0 +1,95 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestResourceNames(t *testing.T) {
+	registry := map[string]PipelineConfigs{
+		GlobalResourceKey: {
+			{Name: "namespaces.v1.", PublishTo: "meshery.meshsync.core"},
+		},
+		LocalResourceKey: {
+			{Name: "pods.v1.", PublishTo: "meshery.meshsync.core"},
+			{Name: "ingresses.v1.networking.k8s.io", PublishTo: "meshery.meshsync.core"},
+			{Name: "ingresses.v1beta1.extensions", PublishTo: "meshery.meshsync.core"},
+			{Name: "widgets.v1.example.com", PublishTo: "meshery.meshsync.core"},
+			{Name: "widgets.v1beta1.example.com", PublishTo: "meshery.meshsync.core"},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		data          map[string]string
+		expectErr     bool
+		expectedLocal []string
+	}{
+		{
+			name:          "pipeline name",
+			data:          map[string]string{"whitelist": "[{\"Resource\":\"ingresses.v1beta1.extensions\",\"Events\":[\"ADDED\"]}]"},
+			expectedLocal: []string{"ingresses.v1beta1.extensions"},
+		},
+		{
+			name:          "qualified name",
+			data:          map[string]string{"whitelist": "[{\"Resource\":\"ingresses.networking.k8s.io\",\"Events\":[\"ADDED\"]}]"},
+			expectedLocal: []string{"ingresses.v1.networking.k8s.io"},
+		},
+		{
+			name:          "unambiguous bare name",
+			data:          map[string]string{"whitelist": "[{\"Resource\":\"pods\",\"Events\":[\"ADDED\"]}]"},
+			expectedLocal: []string{"pods.v1."},
+		},
+		{
+			name:      "ambiguous bare name",
+			data:      map[string]string{"whitelist": "[{\"Resource\":\"ingresses\",\"Events\":[\"ADDED\"]}]"},
+			expectErr: true,
+		},
+		{
+			name:      "qualified name served in two versions",
+			data:      map[string]string{"whitelist": "[{\"Resource\":\"widgets.example.com\",\"Events\":[\"ADDED\"]}]"},
+			expectErr: true,
+		},
+		{
+			name:          "qualified name blacklisted",
+			data:          map[string]string{"blacklist": "[\"ingresses.extensions\",\"widgets.v1.example.com\",\"widgets.v1beta1.example.com\"]"},
+			expectedLocal: []string{"pods.v1.", "ingresses.v1.networking.k8s.io"},
+		},
+		{
+			name:      "ambiguous bare name blacklisted",
+			data:      map[string]string{"blacklist": "[\"ingresses\"]"},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			meshsyncConfig, err := populateConfigsFromRegistry(tc.data, registry)
+			if tc.expectErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			assertPipelineNames(t, LocalResourceKey, meshsyncConfig.Pipelines[LocalResourceKey], tc.expectedLocal)
+		})
+	}
+}
+
+func TestResolveAmbiguousResourceName(t *testing.T) {
+	names := newResourceNames(map[string]PipelineConfigs{
+		LocalResourceKey: {
+			{Name: "ingresses.v1.networking.k8s.io"},
+			{Name: "ingresses.v1beta1.extensions"},
+		},
+	})
+	expected := "resource ingresses is ambiguous, it is served as [ingresses.v1.networking.k8s.io ingresses.v1beta1.extensions], qualify it with its group, f.e. ingresses.networking.k8s.io"
+	if _, err := names.resolve("ingresses"); err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+	if resource, err := names.resolve("ingresses.extensions"); err != nil || resource != "ingresses.v1beta1.extensions" {
+		t.Errorf("expected ingresses.v1beta1.extensions, got %s (%v)", resource, err)
+	}
+}
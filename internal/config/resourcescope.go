@@ -0,0 +1,150 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/meshery/meshkit/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// Scopes, if set, routes the whitelisted resources missing from Pipelines, f.e. custom resources,
+// to the global or the local bucket by whether discovery reports them cluster scoped or namespaced.
+// Without it only those given by Group and Version are watched, see withUnregisteredResources.
+var Scopes *ScopeDiscovery
+
+// ScopeDiscovery looks up whether resources are namespaced, querying discovery once per group version
+// serving them. The resources not served are looked up again, f.e. once the CRD serving them is installed.
+type ScopeDiscovery struct {
+	client discovery.DiscoveryInterface
+
+	mu sync.Mutex
+	// the namespaced flag of the resources by group version
+	namespaced map[schema.GroupVersion]map[string]bool
+}
+
+func NewScopeDiscovery(client discovery.DiscoveryInterface) *ScopeDiscovery {
+	return &ScopeDiscovery{
+		client:     client,
+		namespaced: make(map[schema.GroupVersion]map[string]bool),
+	}
+}
+
+// BucketOf returns the bucket of the resource, f.e. "certificates.v1.cert-manager.io",
+// LocalResourceKey if it is namespaced and GlobalResourceKey otherwise.
+// It reports false if the cluster does not serve the resource.
+func (s *ScopeDiscovery) BucketOf(resource string) (string, bool, error) {
+	gvr, _ := schema.ParseResourceArg(resource)
+	if gvr == nil {
+		return "", false, fmt.Errorf("invalid resource %s", resource)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	gv := gvr.GroupVersion()
+	namespaced, served := s.namespaced[gv][gvr.Resource]
+	if !served {
+		resources, err := namespacedResources(s.client, gv)
+		if err != nil {
+			return "", false, err
+		}
+		if len(resources) > 0 {
+			s.namespaced[gv] = resources
+		}
+		if namespaced, served = resources[gvr.Resource]; !served {
+			return "", false, nil
+		}
+	}
+	if namespaced {
+		return LocalResourceKey, true, nil
+	}
+	return GlobalResourceKey, true, nil
+}
+
+// namespacedResources returns the namespaced flag of the resources served in the group version
+func namespacedResources(client discovery.DiscoveryInterface, gv schema.GroupVersion) (map[string]bool, error) {
+	resources := make(map[string]bool)
+	list, err := client.ServerResourcesForGroupVersion(gv.String())
+	if apierrors.IsNotFound(err) {
+		return resources, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to discover resources of %s: %w", gv.String(), err)
+	}
+	for _, resource := range list.APIResources {
+		resources[resource.Name] = resource.Namespaced
+	}
+	return resources, nil
+}
+
+// withUnregisteredResources returns the registry along with the whitelisted resources it misses:
+//   - resources given by Group and Version are added to the bucket of their Scope, or the one discovery
+//     places them in, or the local bucket, whether or not the cluster serves them,
+//   - resources given by their pipeline name, f.e. "certificates.v1.cert-manager.io", are added
+//     to the bucket discovery places them in, if scopes is set and the cluster serves them.
+//
+// Other resources missing from the registry are left out, the registry itself is not modified.
+func withUnregisteredResources(data map[string]string, registry map[string]PipelineConfigs, scopes *ScopeDiscovery) (map[string]PipelineConfigs, error) {
+	whitelist := make([]ResourceConfig, 0)
+	if raw := data["whitelist"]; raw == "" || utils.Unmarshal(raw, &whitelist) != nil {
+		// a malformed whitelist is reported when the config is resolved
+		return registry, nil
+	}
+
+	names := newResourceNames(registry)
+	extended := copyRegistry(registry)
+	for _, rc := range whitelist {
+		if isPattern(rc.Match) {
+			continue
+		}
+		name, qualified, err := rc.pipelineName()
+		if err != nil {
+			// reported when the config is resolved
+			continue
+		}
+		if !qualified {
+			if name, err = names.resolve(normalizeResourceName(name)); err != nil {
+				continue
+			}
+		}
+		if registered(extended[GlobalResourceKey], name) || registered(extended[LocalResourceKey], name) {
+			continue
+		}
+		if gvr, _ := schema.ParseResourceArg(name); gvr == nil {
+			continue
+		}
+
+		bucket := ""
+		switch {
+		case qualified && (rc.Scope == GlobalResourceKey || rc.Scope == LocalResourceKey):
+			bucket = rc.Scope
+		case qualified && rc.Scope != "":
+			// reported when the config is resolved
+			continue
+		case scopes != nil:
+			if bucket, _, err = scopes.BucketOf(name); err != nil {
+				return nil, err
+			}
+		}
+		if bucket == "" {
+			if !qualified {
+				// not served, there is nothing to watch
+				continue
+			}
+			bucket = LocalResourceKey
+		}
+		extended[bucket] = append(extended[bucket], PipelineConfig{Name: name, PublishTo: DefaultPublishingSubject})
+	}
+	return extended, nil
+}
+
+// copyRegistry returns a copy of the registry whose buckets can be appended to
+func copyRegistry(registry map[string]PipelineConfigs) map[string]PipelineConfigs {
+	copied := make(map[string]PipelineConfigs, len(registry))
+	for bucket, pipelines := range registry {
+		copied[bucket] = append(PipelineConfigs{}, pipelines...)
+	}
+	return copied
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestDiscoveredResourceScopes(t *testing.T) {
+	discovery := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{
+		Resources: []*metav1.APIResourceList{
+			{GroupVersion: "cert-manager.io/v1", APIResources: []metav1.APIResource{
+				{Name: "certificates", Namespaced: true},
+				{Name: "clusterissuers", Namespaced: false},
+			}},
+		},
+	}}
+	defer func() { Scopes = nil }()
+	Scopes = NewScopeDiscovery(discovery)
+
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}," +
+			"{\"Resource\":\"certificates.v1.cert-manager.io\",\"Events\":[\"ADDED\"]}," +
+			"{\"Resource\":\"clusterissuers.v1.cert-manager.io\",\"Events\":[\"ADDED\"]}," +
+			"{\"Resource\":\"issuers.v1.cert-manager.io\",\"Events\":[\"ADDED\"]}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+
+	expected := map[string]string{
+		"pods.v1.":                          LocalResourceKey,
+		"certificates.v1.cert-manager.io":   LocalResourceKey,
+		"clusterissuers.v1.cert-manager.io": GlobalResourceKey,
+	}
+	buckets := make(map[string]string)
+	for bucket, pipelines := range meshsyncConfig.Pipelines {
+		for _, pc := range pipelines {
+			buckets[pc.Name] = bucket
+		}
+	}
+	for name, bucket := range expected {
+		if buckets[name] != bucket {
+			t.Errorf("expected %s in the %s bucket, got %q", name, bucket, buckets[name])
+		}
+	}
+	// not served by the cluster
+	if bucket, ok := buckets["issuers.v1.cert-manager.io"]; ok {
+		t.Errorf("expected issuers not to be watched, got it in the %s bucket", bucket)
+	}
+	if _, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"certificates.v1.cert-manager.io\",\"Events\":[\"ADDED\"]}]",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// the group version is discovered once for the resources it serves, again for the one it does not serve
+	if count := len(discovery.Actions()); count != 2 {
+		t.Errorf("expected 2 discovery requests, got %d", count)
+	}
+	// the registry is not extended
+	if registered(Pipelines[LocalResourceKey], "certificates.v1.cert-manager.io") {
+		t.Error("expected the registry not to be modified")
+	}
+}
+
+func TestScopeDiscoveryDoesNotCacheUnservedResources(t *testing.T) {
+	discovery := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}}
+	scopes := NewScopeDiscovery(discovery)
+
+	if _, served, err := scopes.BucketOf("certificates.v1.cert-manager.io"); err != nil || served {
+		t.Fatalf("expected certificates not to be served before the CRD is installed, got served %t, error %v", served, err)
+	}
+
+	// the CRD is installed
+	discovery.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "cert-manager.io/v1", APIResources: []metav1.APIResource{{Name: "certificates", Namespaced: true}}},
+	}
+	bucket, served, err := scopes.BucketOf("certificates.v1.cert-manager.io")
+	if err != nil || !served || bucket != LocalResourceKey {
+		t.Errorf("expected certificates in the %s bucket once served, got %q, served %t, error %v", LocalResourceKey, bucket, served, err)
+	}
+}
+
+func TestQualifiedResources(t *testing.T) {
+	meshsyncConfig, err := PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"certificates\",\"Group\":\"cert-manager.io\",\"Version\":\"v1\",\"Events\":[\"ADDED\",\"DELETED\"]}," +
+			"{\"Resource\":\"clusterissuers\",\"Group\":\"cert-manager.io\",\"Version\":\"v1\",\"Scope\":\"global\"}]",
+	})
+	if err != nil {
+		t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+	}
+	expected := map[string]PipelineConfigs{
+		LocalResourceKey:  {{Name: "certificates.v1.cert-manager.io", PublishTo: DefaultPublishingSubject, Events: []string{"ADDED", "DELETED"}}},
+		GlobalResourceKey: {{Name: "clusterissuers.v1.cert-manager.io", PublishTo: DefaultPublishingSubject, Events: DefaultEvents}},
+	}
+	for bucket, pipelines := range expected {
+		actual := meshsyncConfig.Pipelines[bucket]
+		if len(actual) != 1 || actual[0].Name != pipelines[0].Name || actual[0].PublishTo != pipelines[0].PublishTo ||
+			!reflect.DeepEqual(actual[0].Events, pipelines[0].Events) {
+			t.Errorf("expected the %s pipelines %+v, got %+v", bucket, pipelines, actual)
+		}
+	}
+
+	invalid := map[string]string{
+		"Group without Version": "[{\"Resource\":\"certificates\",\"Group\":\"cert-manager.io\"}]",
+		"qualified Resource":    "[{\"Resource\":\"certificates.v1.cert-manager.io\",\"Group\":\"cert-manager.io\",\"Version\":\"v1\"}]",
+		"pattern":               "[{\"Resource\":\"cert*\",\"Match\":\"glob\",\"Group\":\"cert-manager.io\",\"Version\":\"v1\"}]",
+		"unknown Scope":         "[{\"Resource\":\"certificates\",\"Group\":\"cert-manager.io\",\"Version\":\"v1\",\"Scope\":\"cluster\"}]",
+	}
+	for name, whitelist := range invalid {
+		if _, err := PopulateConfigsFromMap(map[string]string{"whitelist": whitelist}); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
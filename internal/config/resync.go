@@ -0,0 +1,27 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// MinResyncPeriod is the shortest ResyncPeriod, the informers raise shorter ones to it
+const MinResyncPeriod = time.Second
+
+// ResyncCheckPeriod is the default resync period of the informers, i.e. how often they check
+// whether a pipeline is due to resync. The pipelines register their handlers with their own ResyncPeriod,
+// zero never resyncs, an informer lowers its check period to shorter ones.
+const ResyncCheckPeriod = 10 * time.Second
+
+// parseResyncPeriod parses the period the cached objects of a resource are replayed to its pipeline on,
+// "0" never resyncs
+func parseResyncPeriod(resource, period string) (time.Duration, error) {
+	resyncPeriod, err := time.ParseDuration(period)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ResyncPeriod for %s: %w", resource, err)
+	}
+	if resyncPeriod < 0 || (resyncPeriod > 0 && resyncPeriod < MinResyncPeriod) {
+		return 0, fmt.Errorf("invalid ResyncPeriod for %s: must be zero or at least %s", resource, MinResyncPeriod)
+	}
+	return resyncPeriod, nil
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+// the retries of the requests for the Custom Resource, f.e. while the API server of a starting cluster
+// is briefly unavailable: with the defaults they span about 15s
+const (
+	DefaultRetries        = 5
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	// the delay between two retries never grows beyond
+	maxRetryDelay = 30 * time.Second
+)
+
+// RetryOptions bound the retries of a request failing with a transient error
+type RetryOptions struct {
+	// the retries after the first attempt, zero does not retry
+	Retries int
+	// the delay before the first retry, doubled with every further one
+	BaseDelay time.Duration
+}
+
+// DefaultRetryOptions are the retry options of a default install
+var DefaultRetryOptions = RetryOptions{Retries: DefaultRetries, BaseDelay: DefaultRetryBaseDelay}
+
+// Validate rejects negative retries and delays
+func (o RetryOptions) Validate() error {
+	if o.Retries < 0 {
+		return ErrInitConfig(fmt.Errorf("invalid retries %d: must not be negative", o.Retries))
+	}
+	if o.BaseDelay < 0 {
+		return ErrInitConfig(fmt.Errorf("invalid retry base delay %s: must not be negative", o.BaseDelay))
+	}
+	return nil
+}
+
+// isTransient reports whether the request failed for a reason likely gone on the next attempt:
+// a timeout, a refused or reset connection, throttling or a server error.
+// A missing or forbidden resource, or a rejected request, is not retried.
+func isTransient(err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case apierrors.IsNotFound(err), apierrors.IsForbidden(err), apierrors.IsUnauthorized(err),
+		apierrors.IsBadRequest(err), apierrors.IsInvalid(err), apierrors.IsMethodNotSupported(err):
+		return false
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err), apierrors.IsTooManyRequests(err),
+		apierrors.IsInternalError(err), apierrors.IsServiceUnavailable(err), apierrors.IsUnexpectedServerError(err):
+		return true
+	case utilnet.IsConnectionRefused(err), utilnet.IsConnectionReset(err), utilnet.IsProbableEOF(err):
+		return true
+	}
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		return status.Status().Code >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// withRetry calls request until it succeeds, fails with an error which is not transient,
+// or the retries are used up, and returns its last error.
+// A cancelled or expired ctx ends the retries with its error.
+func withRetry(ctx context.Context, options RetryOptions, request func() error) error {
+	delay := options.BaseDelay
+	for attempt := 0; ; attempt++ {
+		err := request()
+		if err == nil || attempt >= options.Retries || !isTransient(err) || ctx.Err() != nil {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		delay = min(2*delay, maxRetryDelay)
+	}
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newFlakyCRDClient returns a client whose first failures requests for the Custom Resource fail with err,
+// and the number of requests made so far
+func newFlakyCRDClient(failures int, err error) (*dynamicfake.FakeDynamicClient, func() int) {
+	cr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": DefaultCRDConfig.Group + "/" + DefaultCRDConfig.Version,
+		"kind":       "MeshSync",
+		"metadata":   map[string]interface{}{"name": DefaultCRDConfig.Name, "namespace": DefaultCRDConfig.Namespace},
+		"spec": map[string]interface{}{
+			"watch-list": map[string]interface{}{
+				"data": map[string]interface{}{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]"},
+			},
+		},
+	}}
+	dyClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		DefaultCRDConfig.GVR(): "MeshSyncList",
+	}, cr)
+	requests := 0
+	dyClient.PrependReactor("get", DefaultCRDConfig.Resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		requests++
+		if requests <= failures {
+			return true, nil, err
+		}
+		return false, nil, nil
+	})
+	return dyClient, func() int { return requests }
+}
+
+func TestGetMeshsyncCRDRetriesTransientErrors(t *testing.T) {
+	gr := DefaultCRDConfig.GVR().GroupResource()
+	connectionRefused := &url.Error{Op: "Get", URL: "https://10.96.0.1:443", Err: &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}}
+	testCases := []struct {
+		name     string
+		err      error
+		retries  int
+		failures int
+		// the requests made and whether the Custom Resource is returned in the end
+		expectedRequests int
+		expectedFound    bool
+	}{
+		{name: "service unavailable", err: apierrors.NewServiceUnavailable("starting up"), retries: 5, failures: 3, expectedRequests: 4, expectedFound: true},
+		{name: "connection refused", err: connectionRefused, retries: 5, failures: 2, expectedRequests: 3, expectedFound: true},
+		{name: "timeout", err: apierrors.NewTimeoutError("request timed out", 1), retries: 5, failures: 1, expectedRequests: 2, expectedFound: true},
+		{name: "throttled", err: apierrors.NewTooManyRequests("throttled", 1), retries: 5, failures: 1, expectedRequests: 2, expectedFound: true},
+		{name: "internal error", err: apierrors.NewInternalError(errors.New("etcd leader changed")), retries: 5, failures: 1, expectedRequests: 2, expectedFound: true},
+		{name: "retries used up", err: apierrors.NewServiceUnavailable("starting up"), retries: 2, failures: 5, expectedRequests: 3},
+		{name: "no retries", err: apierrors.NewServiceUnavailable("starting up"), retries: 0, failures: 1, expectedRequests: 1},
+		{name: "not found", err: apierrors.NewNotFound(gr, DefaultCRDConfig.Name), retries: 5, failures: 5, expectedRequests: 1},
+		{name: "forbidden", err: apierrors.NewForbidden(gr, DefaultCRDConfig.Name, errors.New("no RBAC")), retries: 5, failures: 5, expectedRequests: 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dyClient, requests := newFlakyCRDClient(tc.failures, tc.err)
+			retry := RetryOptions{Retries: tc.retries, BaseDelay: time.Millisecond}
+			crd, err := GetMeshsyncCRDWithRetry(context.Background(), dyClient, DefaultCRDConfig, retry)
+			if tc.expectedFound && (err != nil || crd == nil) {
+				t.Errorf("expected the Custom Resource, got error %v", err)
+			}
+			if !tc.expectedFound && err == nil {
+				t.Error("expected error")
+			}
+			if requests() != tc.expectedRequests {
+				t.Errorf("expected %d requests, got %d", tc.expectedRequests, requests())
+			}
+		})
+	}
+
+	// the configs are resolved from the Custom Resource got after the retries
+	dyClient, _ := newFlakyCRDClient(2, apierrors.NewServiceUnavailable("starting up"))
+	meshsyncConfig, err := GetMeshsyncCRDConfigsWithRetry(context.Background(), dyClient, DefaultCRDConfig, RetryOptions{Retries: 3, BaseDelay: time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	assertPipelineNames(t, LocalResourceKey, meshsyncConfig.Pipelines[LocalResourceKey], []string{"pods.v1."})
+}
+
+func TestGetMeshsyncCRDRetryRespectsContext(t *testing.T) {
+	dyClient, requests := newFlakyCRDClient(100, apierrors.NewServiceUnavailable("starting up"))
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	_, err := GetMeshsyncCRDWithRetry(ctx, dyClient, DefaultCRDConfig, RetryOptions{Retries: 100, BaseDelay: time.Hour})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline of the context, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("expected the retries to end with the context, took %s", elapsed)
+	}
+	if requests() != 1 {
+		t.Errorf("expected no retry after the context expired, got %d requests", requests())
+	}
+}
+
+func TestRetryOptionsValidate(t *testing.T) {
+	if err := DefaultRetryOptions.Validate(); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+	for _, options := range []RetryOptions{{Retries: -1}, {BaseDelay: -time.Second}} {
+		if err := options.Validate(); err == nil {
+			t.Errorf("expected error for %+v", options)
+		}
+	}
+}
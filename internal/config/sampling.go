@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// SamplingConfig samples the events of a resource per object.
+// Objects matching the label selector are emitted at Rate, all others at DefaultRate.
+// Rates range from 0 (drop all) to 1 (emit all), an omitted rate emits all.
+type SamplingConfig struct {
+	Selector    string   `json:"selector" yaml:"selector"`
+	Rate        *float64 `json:"rate,omitempty" yaml:"rate,omitempty"`
+	DefaultRate *float64 `json:"default-rate,omitempty" yaml:"default-rate,omitempty"`
+}
+
+// MatchRate returns the sample rate of objects matching the selector
+func (s SamplingConfig) MatchRate() float64 {
+	return rateOrAll(s.Rate)
+}
+
+// NonMatchRate returns the sample rate of objects not matching the selector
+func (s SamplingConfig) NonMatchRate() float64 {
+	return rateOrAll(s.DefaultRate)
+}
+
+func rateOrAll(rate *float64) float64 {
+	if rate == nil {
+		return 1
+	}
+	return *rate
+}
+
+func validateSampling(resource string, sampling *SamplingConfig) error {
+	if sampling == nil {
+		return nil
+	}
+	if _, err := labels.Parse(sampling.Selector); err != nil {
+		return fmt.Errorf("invalid sampling selector for %s: %w", resource, err)
+	}
+	for _, rate := range []float64{sampling.MatchRate(), sampling.NonMatchRate()} {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("invalid sample rate %v for %s: must be between 0 and 1", rate, resource)
+		}
+	}
+	return nil
+}
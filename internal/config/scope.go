@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ambiguousResources returns the resources registered in both the global and the local bucket,
+// watching them as registered would create two pipelines for a single GVR
+func ambiguousResources(registry map[string]PipelineConfigs) map[string]bool {
+	global := make(map[string]bool, len(registry[GlobalResourceKey]))
+	for _, pc := range registry[GlobalResourceKey] {
+		global[pc.Name] = true
+	}
+
+	ambiguous := make(map[string]bool)
+	for _, pc := range registry[LocalResourceKey] {
+		if global[pc.Name] {
+			ambiguous[pc.Name] = true
+		}
+	}
+	return ambiguous
+}
+
+// validateWhiteListScopes ensures every whitelisted resource resolves to exactly one bucket.
+// An explicit Scope wins, a resource registered in both buckets without one is an error.
+func validateWhiteListScopes(whitelist []ResourceConfig, registry map[string]PipelineConfigs) error {
+	ambiguous := ambiguousResources(registry)
+	for _, rc := range whitelist {
+		switch rc.Scope {
+		case "":
+			if ambiguous[rc.Resource] {
+				return fmt.Errorf("resource %s is registered as both %s and %s, set Scope to select one", rc.Resource, GlobalResourceKey, LocalResourceKey)
+			}
+		case GlobalResourceKey, LocalResourceKey:
+			if !registered(registry[rc.Scope], rc.Resource) {
+				return fmt.Errorf("resource %s is not registered as %s", rc.Resource, rc.Scope)
+			}
+		default:
+			return fmt.Errorf("invalid Scope %q for %s, expected %s or %s", rc.Scope, rc.Resource, GlobalResourceKey, LocalResourceKey)
+		}
+	}
+	return nil
+}
+
+// validateBlackListScopes rejects resources registered in both buckets which are neither blacklisted nor whitelisted,
+// there is no way to pick a scope for them other than whitelisting
+func validateBlackListScopes(blacklist []string, whitelist []ResourceConfig, registry map[string]PipelineConfigs) error {
+	resolved := make(map[string]bool, len(blacklist)+len(whitelist))
+	for _, name := range blacklist {
+		resolved[name] = true
+	}
+	for _, rc := range whitelist {
+		resolved[rc.Resource] = true
+	}
+
+	conflicts := make([]string, 0)
+	for name := range ambiguousResources(registry) {
+		if !resolved[name] {
+			conflicts = append(conflicts, name)
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	sort.Strings(conflicts)
+	return fmt.Errorf("resources %v are registered as both %s and %s, blacklist them or whitelist them with a Scope", conflicts, GlobalResourceKey, LocalResourceKey)
+}
+
+// inScope reports whether the resource config applies to the pipelines of the given bucket
+func (rc ResourceConfig) inScope(bucket string) bool {
+	return rc.Scope == "" || rc.Scope == bucket
+}
+
+func registered(pipelines PipelineConfigs, name string) bool {
+	for _, pc := range pipelines {
+		if pc.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDuplicateResourceAcrossBuckets(t *testing.T) {
+	registry := map[string]PipelineConfigs{
+		GlobalResourceKey: {
+			{Name: "widgets.v1.example.com", PublishTo: "meshery.meshsync.core"},
+			{Name: "namespaces.v1.", PublishTo: "meshery.meshsync.core"},
+		},
+		LocalResourceKey: {
+			{Name: "widgets.v1.example.com", PublishTo: "meshery.meshsync.core"},
+			{Name: "pods.v1.", PublishTo: "meshery.meshsync.core"},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		data           map[string]string
+		expectErr      bool
+		expectedGlobal []string
+		expectedLocal  []string
+	}{
+		{
+			name:      "whitelisted without scope",
+			data:      map[string]string{"whitelist": "[{\"Resource\":\"widgets.v1.example.com\",\"Events\":[\"ADDED\"]}]"},
+			expectErr: true,
+		},
+		{
+			name:           "whitelisted with global scope",
+			data:           map[string]string{"whitelist": "[{\"Resource\":\"widgets.v1.example.com\",\"Events\":[\"ADDED\"],\"Scope\":\"global\"}]"},
+			expectedGlobal: []string{"widgets.v1.example.com"},
+		},
+		{
+			name:          "whitelisted with local scope",
+			data:          map[string]string{"whitelist": "[{\"Resource\":\"widgets.v1.example.com\",\"Events\":[\"ADDED\"],\"Scope\":\"local\"},{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]"},
+			expectedLocal: []string{"widgets.v1.example.com", "pods.v1."},
+		},
+		{
+			name:      "scope not matching the registry",
+			data:      map[string]string{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"Scope\":\"global\"}]"},
+			expectErr: true,
+		},
+		{
+			name:      "unknown scope",
+			data:      map[string]string{"whitelist": "[{\"Resource\":\"widgets.v1.example.com\",\"Events\":[\"ADDED\"],\"Scope\":\"cluster\"}]"},
+			expectErr: true,
+		},
+		{
+			name:      "not blacklisted",
+			data:      map[string]string{"blacklist": "[\"pods.v1.\"]"},
+			expectErr: true,
+		},
+		{
+			name:           "blacklisted",
+			data:           map[string]string{"blacklist": "[\"widgets.v1.example.com\"]"},
+			expectedGlobal: []string{"namespaces.v1."},
+			expectedLocal:  []string{"pods.v1."},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			meshsyncConfig, err := populateConfigsFromRegistry(tc.data, registry)
+			if tc.expectErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			assertPipelineNames(t, GlobalResourceKey, meshsyncConfig.Pipelines[GlobalResourceKey], tc.expectedGlobal)
+			assertPipelineNames(t, LocalResourceKey, meshsyncConfig.Pipelines[LocalResourceKey], tc.expectedLocal)
+		})
+	}
+}
+
+func TestWhiteListOverridesBlackList(t *testing.T) {
+	registry := map[string]PipelineConfigs{
+		GlobalResourceKey: {
+			{Name: "widgets.v1.example.com", PublishTo: "meshery.meshsync.core"},
+			{Name: "namespaces.v1.", PublishTo: "meshery.meshsync.core"},
+			{Name: "nodes.v1.", PublishTo: "meshery.meshsync.core"},
+		},
+		LocalResourceKey: {
+			{Name: "widgets.v1.example.com", PublishTo: "meshery.meshsync.core"},
+			{Name: "pods.v1.", PublishTo: "meshery.meshsync.core"},
+			{Name: "services.v1.", PublishTo: "meshery.meshsync.core"},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		data           map[string]string
+		expectErr      bool
+		expectedGlobal map[string][]string
+		expectedLocal  map[string][]string
+	}{
+		{
+			name: "whitelisted and blacklisted",
+			data: map[string]string{
+				"blacklist": "[\"widgets.v1.example.com\",\"nodes.v1.\",\"pods.v1.\"]",
+				"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"nodes.v1.\",\"Events\":[\"DELETED\"]}]",
+			},
+			expectedGlobal: map[string][]string{"namespaces.v1.": DefaultEvents, "nodes.v1.": {"DELETED"}},
+			expectedLocal:  map[string][]string{"pods.v1.": {"ADDED"}, "services.v1.": DefaultEvents},
+		},
+		{
+			name: "whitelisted overrides of resources not blacklisted",
+			data: map[string]string{
+				"blacklist": "[\"widgets.v1.example.com\",\"namespaces.v1.\"]",
+				"whitelist": "[{\"Resource\":\"services.v1.\",\"Events\":[\"MODIFIED\"]}]",
+			},
+			expectedGlobal: map[string][]string{"nodes.v1.": DefaultEvents},
+			expectedLocal:  map[string][]string{"pods.v1.": DefaultEvents, "services.v1.": {"MODIFIED"}},
+		},
+		{
+			name: "ambiguous resource whitelisted with global scope",
+			data: map[string]string{
+				"blacklist": "[\"nodes.v1.\"]",
+				"whitelist": "[{\"Resource\":\"widgets.v1.example.com\",\"Events\":[\"ADDED\"],\"Scope\":\"global\"}]",
+			},
+			expectedGlobal: map[string][]string{"widgets.v1.example.com": {"ADDED"}, "namespaces.v1.": DefaultEvents},
+			expectedLocal:  map[string][]string{"pods.v1.": DefaultEvents, "services.v1.": DefaultEvents},
+		},
+		{
+			name: "ambiguous resource whitelisted with local scope",
+			data: map[string]string{
+				"blacklist": "[\"pods.v1.\"]",
+				"whitelist": "[{\"Resource\":\"widgets.v1.example.com\",\"Events\":[\"MODIFIED\"],\"Scope\":\"local\"}]",
+			},
+			expectedGlobal: map[string][]string{"namespaces.v1.": DefaultEvents, "nodes.v1.": DefaultEvents},
+			expectedLocal:  map[string][]string{"widgets.v1.example.com": {"MODIFIED"}, "services.v1.": DefaultEvents},
+		},
+		{
+			name:      "ambiguous resource neither blacklisted nor whitelisted",
+			data:      map[string]string{"blacklist": "[\"pods.v1.\"]", "whitelist": "[{\"Resource\":\"services.v1.\",\"Events\":[\"ADDED\"]}]"},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			meshsyncConfig, err := populateConfigsFromRegistry(tc.data, registry)
+			if tc.expectErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			assertPipelineEvents(t, GlobalResourceKey, meshsyncConfig.Pipelines[GlobalResourceKey], tc.expectedGlobal)
+			assertPipelineEvents(t, LocalResourceKey, meshsyncConfig.Pipelines[LocalResourceKey], tc.expectedLocal)
+		})
+	}
+}
+
+func assertPipelineEvents(t *testing.T, label string, pipelines PipelineConfigs, expected map[string][]string) {
+	t.Helper()
+	events := make(map[string][]string, len(pipelines))
+	for _, pc := range pipelines {
+		events[pc.Name] = pc.Events
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected %s pipeline events %v, got %v", label, expected, events)
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// ValidateServedResources checks that the cluster serves every explicitly whitelisted resource,
+// so a resource which is not installed (f.e. a missing CRD) is reported when the config is resolved
+// instead of failing cryptically once its watch starts.
+// The blacklist mode watches the whole registry, most of which is optional, hence is not checked.
+func ValidateServedResources(meshsyncConfig *MeshsyncConfig, client discovery.DiscoveryInterface) error {
+	if len(meshsyncConfig.WhiteList) == 0 {
+		return nil
+	}
+
+	served := make(map[schema.GroupVersion]map[string]bool)
+	missing := make([]string, 0)
+	for _, pipelines := range meshsyncConfig.Pipelines {
+		for _, pc := range pipelines {
+			if len(pc.Requires) > 0 {
+				// conditional pipelines run only once their requirements, usually the resource itself, are served
+				continue
+			}
+			gvr, _ := schema.ParseResourceArg(pc.Name)
+			if gvr == nil {
+				return ErrInitConfig(fmt.Errorf("invalid resource %s", pc.Name))
+			}
+
+			gv := gvr.GroupVersion()
+			if _, ok := served[gv]; !ok {
+				resources, err := servedResources(client, gv)
+				if err != nil {
+					return ErrInitConfig(err)
+				}
+				served[gv] = resources
+			}
+			if !served[gv][gvr.Resource] {
+				missing = append(missing, pc.Name)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return ErrInitConfig(fmt.Errorf("resources %v are not served by the cluster", missing))
+	}
+	return nil
+}
+
+// servedResources returns the names of the resources served in the group version
+func servedResources(client discovery.DiscoveryInterface, gv schema.GroupVersion) (map[string]bool, error) {
+	resources := make(map[string]bool)
+	list, err := client.ServerResourcesForGroupVersion(gv.String())
+	if apierrors.IsNotFound(err) {
+		return resources, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to discover resources of %s: %w", gv.String(), err)
+	}
+	for _, resource := range list.APIResources {
+		resources[resource.Name] = true
+	}
+	return resources, nil
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestValidateServedResources(t *testing.T) {
+	discovery := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{
+		Resources: []*metav1.APIResourceList{
+			{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "pods"}, {Name: "services"}}},
+			{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{{Name: "deployments"}}},
+		},
+	}}
+
+	testCases := []struct {
+		name        string
+		data        map[string]string
+		missingName string
+	}{
+		{
+			name: "served",
+			data: map[string]string{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"deployments.v1.apps\",\"Events\":[\"ADDED\"]}]"},
+		},
+		{
+			name:        "resource not served",
+			data:        map[string]string{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"statefulsets.v1.apps\",\"Events\":[\"ADDED\"]}]"},
+			missingName: "statefulsets.v1.apps",
+		},
+		{
+			name:        "group version not served",
+			data:        map[string]string{"whitelist": "[{\"Resource\":\"ingresses.v1.networking.k8s.io\",\"Events\":[\"ADDED\"]}]"},
+			missingName: "ingresses.v1.networking.k8s.io",
+		},
+		{
+			name: "blacklist is not checked",
+			data: map[string]string{"blacklist": "[\"pods.v1.\"]"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			meshsyncConfig, err := PopulateConfigsFromMap(tc.data)
+			if err != nil {
+				t.Fatalf("Meshsync config not well deserialized got %s", err.Error())
+			}
+			err = ValidateServedResources(meshsyncConfig, discovery)
+			if tc.missingName == "" {
+				if err != nil {
+					t.Errorf("unexpected error %s", err.Error())
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error for resource which is not served")
+			}
+			if description := err.Error(); !strings.Contains(description, tc.missingName) {
+				t.Errorf("expected error naming %s, got %s", tc.missingName, description)
+			}
+		})
+	}
+}
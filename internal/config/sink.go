@@ -0,0 +1,281 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slices"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// sink URI schemes supported out of the box
+const (
+	SinkSchemeNATS = "nats"
+	SinkSchemeFile = "file"
+	// webhooks, tuned by the query parameters of the URI, see SinkWebhookSettings
+	SinkSchemeHTTP  = "http"
+	SinkSchemeHTTPS = "https"
+)
+
+// SinkURIValidator checks that a sink URI carries everything needed to open the sink
+type SinkURIValidator func(uri *url.URL) error
+
+// SinkRegistry knows the sink URI schemes, sink URIs are validated against it when the config is parsed
+type SinkRegistry struct {
+	mu         sync.RWMutex
+	validators map[string]SinkURIValidator
+}
+
+func NewSinkRegistry() *SinkRegistry {
+	return &SinkRegistry{
+		validators: make(map[string]SinkURIValidator),
+	}
+}
+
+// Register adds a sink scheme, registering a scheme again replaces its validator
+func (r *SinkRegistry) Register(scheme string, validator SinkURIValidator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.validators[scheme] = validator
+}
+
+// Schemes returns the registered schemes in alphabetical order
+func (r *SinkRegistry) Schemes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	schemes := make([]string, 0, len(r.validators))
+	for scheme := range r.validators {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// Validate parses the sink URI and checks it with the validator of its scheme
+func (r *SinkRegistry) Validate(uri string) (*url.URL, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sink URI %q: %w", uri, err)
+	}
+
+	r.mu.RLock()
+	validator, ok := r.validators[u.Scheme]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported sink URI %q, supported schemes are %v", uri, r.Schemes())
+	}
+	if validator != nil {
+		if err := validator(u); err != nil {
+			return nil, fmt.Errorf("invalid sink URI %q: %w", uri, err)
+		}
+	}
+	if _, err := SinkQueueSettings(u); err != nil {
+		return nil, fmt.Errorf("invalid sink URI %q: %w", uri, err)
+	}
+	if _, err := SinkMaxInflightBytes(u); err != nil {
+		return nil, fmt.Errorf("invalid sink URI %q: %w", uri, err)
+	}
+	return u, nil
+}
+
+// Sinks is the registry the watch-list is validated against
+var Sinks = newDefaultSinkRegistry()
+
+func newDefaultSinkRegistry() *SinkRegistry {
+	r := NewSinkRegistry()
+	// nats://host:port
+	r.Register(SinkSchemeNATS, func(u *url.URL) error {
+		if u.Host == "" {
+			return fmt.Errorf("broker host is missing")
+		}
+		return nil
+	})
+	// file:///path/to/snapshot.yaml or file:relative/snapshot.yaml
+	r.Register(SinkSchemeFile, func(u *url.URL) error {
+		if SinkFilePath(u) == "" {
+			return fmt.Errorf("file path is missing")
+		}
+		return nil
+	})
+	// http(s)://host/path?batch=10&flush=1s&retries=3&timeout=5s
+	webhook := func(u *url.URL) error {
+		if u.Host == "" {
+			return fmt.Errorf("webhook host is missing")
+		}
+		_, err := SinkWebhookSettings(u)
+		return err
+	}
+	r.Register(SinkSchemeHTTP, webhook)
+	r.Register(SinkSchemeHTTPS, webhook)
+	return r
+}
+
+// WebhookSettings tune delivery to a webhook sink
+type WebhookSettings struct {
+	// events posted per request
+	BatchSize int
+	// how long a partial batch waits for more events
+	FlushInterval time.Duration
+	// retries of a request failing with a transport error or a 5xx status
+	MaxRetries int
+	// timeout of a single request
+	Timeout time.Duration
+}
+
+// webhook query parameters, they are not part of the URL the events are posted to
+const (
+	webhookBatchParam   = "batch"
+	webhookFlushParam   = "flush"
+	webhookRetriesParam = "retries"
+	webhookTimeoutParam = "timeout"
+)
+
+var DefaultWebhookSettings = WebhookSettings{
+	BatchSize:     1,
+	FlushInterval: time.Second,
+	MaxRetries:    3,
+	Timeout:       10 * time.Second,
+}
+
+// SinkWebhookSettings returns the settings of a webhook sink URI, parameters not set keep their defaults
+func SinkWebhookSettings(u *url.URL) (WebhookSettings, error) {
+	settings := DefaultWebhookSettings
+	query := u.Query()
+
+	ints := map[string]*int{
+		webhookBatchParam:   &settings.BatchSize,
+		webhookRetriesParam: &settings.MaxRetries,
+	}
+	for param, target := range ints {
+		if !query.Has(param) {
+			continue
+		}
+		value, err := strconv.Atoi(query.Get(param))
+		if err != nil || value < 0 {
+			return settings, fmt.Errorf("%s must be a non-negative integer, got %q", param, query.Get(param))
+		}
+		*target = value
+	}
+
+	durations := map[string]*time.Duration{
+		webhookFlushParam:   &settings.FlushInterval,
+		webhookTimeoutParam: &settings.Timeout,
+	}
+	for param, target := range durations {
+		if !query.Has(param) {
+			continue
+		}
+		value, err := time.ParseDuration(query.Get(param))
+		if err != nil || value < 0 {
+			return settings, fmt.Errorf("%s must be a non-negative duration, got %q", param, query.Get(param))
+		}
+		*target = value
+	}
+
+	return settings, nil
+}
+
+// SinkWebhookURL returns the URL events are posted to, i.e. the sink URI without the webhook parameters
+func SinkWebhookURL(u *url.URL) string {
+	target := *u
+	query := target.Query()
+	for _, param := range []string{webhookBatchParam, webhookFlushParam, webhookRetriesParam, webhookTimeoutParam, queueDirParam, queueMaxParam, queueOverflowParam, maxInflightParam} {
+		query.Del(param)
+	}
+	target.RawQuery = query.Encode()
+	return target.String()
+}
+
+// SinkFilePath returns the path of a file sink URI
+func SinkFilePath(u *url.URL) string {
+	if u.Opaque != "" {
+		return u.Opaque
+	}
+	return u.Path
+}
+
+// what a full disk-backed queue does with a new event
+const (
+	// the write waits until the sink has caught up
+	QueueOverflowBlock = "block"
+	// the new event is dropped
+	QueueOverflowDropNewest = "drop-newest"
+	// the oldest undelivered events are dropped to make room
+	QueueOverflowDropOldest = "drop-oldest"
+)
+
+var QueueOverflowPolicies = []string{QueueOverflowBlock, QueueOverflowDropNewest, QueueOverflowDropOldest}
+
+// QueueSettings configure the disk-backed queue between the pipelines and a sink,
+// which persists the events until the sink confirms them and replays them on restart
+type QueueSettings struct {
+	// directory holding the queue files, empty writes to the sink directly
+	Dir string
+	// bytes of undelivered events kept on disk
+	MaxBytes int64
+	// one of QueueOverflowPolicies
+	Overflow string
+}
+
+// queue query parameters, f.e. ?queue=/var/lib/meshsync/broker&queue-max=64Mi&queue-overflow=drop-oldest,
+// they apply to every sink scheme
+const (
+	queueDirParam      = "queue"
+	queueMaxParam      = "queue-max"
+	queueOverflowParam = "queue-overflow"
+)
+
+var DefaultQueueSettings = QueueSettings{
+	MaxBytes: 64 << 20,
+	Overflow: QueueOverflowBlock,
+}
+
+// SinkQueueSettings returns the queue settings of a sink URI, parameters not set keep their defaults
+func SinkQueueSettings(u *url.URL) (QueueSettings, error) {
+	settings := DefaultQueueSettings
+	query := u.Query()
+	settings.Dir = query.Get(queueDirParam)
+
+	if query.Has(queueMaxParam) {
+		// a quantity, f.e. 64Mi
+		value, err := resource.ParseQuantity(query.Get(queueMaxParam))
+		if err != nil || value.Value() <= 0 {
+			return settings, fmt.Errorf("%s must be a positive quantity of bytes, got %q", queueMaxParam, query.Get(queueMaxParam))
+		}
+		settings.MaxBytes = value.Value()
+	}
+	if query.Has(queueOverflowParam) {
+		settings.Overflow = query.Get(queueOverflowParam)
+		if !slices.Contains(QueueOverflowPolicies, settings.Overflow) {
+			return settings, fmt.Errorf("%s must be one of %v, got %q", queueOverflowParam, QueueOverflowPolicies, settings.Overflow)
+		}
+	}
+	if settings.Dir == "" && (query.Has(queueMaxParam) || query.Has(queueOverflowParam)) {
+		return settings, fmt.Errorf("%s is missing the directory of the queue", queueDirParam)
+	}
+
+	return settings, nil
+}
+
+// maxInflightParam bounds the bytes of the events written to a sink which it has not delivered yet,
+// f.e. ?max-inflight=8Mi, it applies to every sink scheme
+const maxInflightParam = "max-inflight"
+
+// SinkMaxInflightBytes returns the most bytes of serialized events in flight to the sink of the URI at once,
+// zero is unbounded
+func SinkMaxInflightBytes(u *url.URL) (int64, error) {
+	query := u.Query()
+	if !query.Has(maxInflightParam) {
+		return 0, nil
+	}
+	// a quantity, f.e. 8Mi
+	value, err := resource.ParseQuantity(query.Get(maxInflightParam))
+	if err != nil || value.Value() <= 0 {
+		return 0, fmt.Errorf("%s must be a positive quantity of bytes, got %q", maxInflightParam, query.Get(maxInflightParam))
+	}
+	return value.Value(), nil
+}
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"fmt"
+	"time"
+
 	"golang.org/x/exp/slices"
 )
 
@@ -8,6 +11,7 @@ const (
 	ServerKey         = "server-config"
 	PipelineNameKey   = "meshsync-pipeline"
 	ResourcesKey      = "resources"
+	GlobalSettingsKey = "global-settings"
 	GlobalResourceKey = "global"
 	LocalResourceKey  = "local"
 	ListenersKey      = "listeners"
@@ -50,6 +54,115 @@ type PipelineConfig struct {
 	Name      string   `json:"name" yaml:"name"`
 	PublishTo string   `json:"publish-to" yaml:"publish-to"`
 	Events    []string `json:"events" yaml:"events"`
+	// TenantLabel is the namespace label whose value partitions the subject
+	// per tenant, empty disables partitioning
+	TenantLabel string `json:"tenant-label,omitempty" yaml:"tenant-label,omitempty"`
+	// TenantFallbackSubject receives objects whose namespace has no tenant label
+	TenantFallbackSubject string `json:"tenant-fallback-subject,omitempty" yaml:"tenant-fallback-subject,omitempty"`
+	// StripStatus removes the status subtree from objects before they are emitted
+	StripStatus bool `json:"strip-status,omitempty" yaml:"strip-status,omitempty"`
+	// KeyFunc selects how sinks derive the key of an object, see KeyFuncUID and friends
+	KeyFunc string `json:"key-func,omitempty" yaml:"key-func,omitempty"`
+	// KeyFuncFallback is used when KeyFunc yields no key, f.e. the label is missing
+	KeyFuncFallback string `json:"key-func-fallback,omitempty" yaml:"key-func-fallback,omitempty"`
+	// MaxWatchAge forces the watch to be re-established (from the last resourceVersion)
+	// after this duration, zero keeps the watch open as long as the API server allows
+	MaxWatchAge time.Duration `json:"max-watch-age,omitempty" yaml:"max-watch-age,omitempty"`
+	// PollInterval lists the resource on this interval and emits the differences to the previous list
+	// instead of watching it, zero watches the resource
+	PollInterval time.Duration `json:"poll-interval,omitempty" yaml:"poll-interval,omitempty"`
+	// ResyncPeriod replays the cached objects to the pipeline's handlers every period, zero never resyncs.
+	// It re-lists nothing from the API server, the replayed objects carry no newer resourceVersion
+	// hence are not emitted again.
+	ResyncPeriod time.Duration `json:"resync-period,omitempty" yaml:"resync-period,omitempty"`
+	// Sampling emits only a share of the objects, nil emits all
+	Sampling *SamplingConfig `json:"sampling,omitempty" yaml:"sampling,omitempty"`
+	// Sink is the URI of the sink the pipeline writes to instead of the global one, see SinkRegistry
+	Sink string `json:"sink,omitempty" yaml:"sink,omitempty"`
+	// CompressCache keeps the objects MeshSync tracks itself compressed in memory, trading CPU for memory
+	CompressCache bool `json:"compress-cache,omitempty" yaml:"compress-cache,omitempty"`
+	// NewObjectsOnly suppresses ADDED events of objects created before MeshSync started,
+	// their later MODIFIED and DELETED events are still emitted
+	NewObjectsOnly bool `json:"new-objects-only,omitempty" yaml:"new-objects-only,omitempty"`
+	// GenerationChangeOnly emits MODIFIED only when metadata.generation increased, i.e. the spec changed,
+	// objects of resources without generation are not affected
+	GenerationChangeOnly bool `json:"generation-change-only,omitempty" yaml:"generation-change-only,omitempty"`
+	// EventTypeMapping is the global one, set by the pipeline on the config of each event it writes
+	// so sinks remap the event type only where they serialize it, see EmittedEventType
+	EventTypeMapping map[string]string `json:"event-type-mapping,omitempty" yaml:"event-type-mapping,omitempty"`
+	// BulkDeleteThreshold is the number of deletes within BulkDeleteWindow which make a bulk deletion,
+	// further deletes are throttled to as many per window, zero disables the detection
+	BulkDeleteThreshold int           `json:"bulk-delete-threshold,omitempty" yaml:"bulk-delete-threshold,omitempty"`
+	BulkDeleteWindow    time.Duration `json:"bulk-delete-window,omitempty" yaml:"bulk-delete-window,omitempty"`
+	// BulkDeleteSummary replaces the throttled deletes with a single summary event
+	BulkDeleteSummary bool `json:"bulk-delete-summary,omitempty" yaml:"bulk-delete-summary,omitempty"`
+	// StaleAfter marks the pipeline stale and stops its emission once its watch
+	// has been disconnected for this duration, zero never marks it stale
+	StaleAfter time.Duration `json:"stale-after,omitempty" yaml:"stale-after,omitempty"`
+	// StaleNotify emits a control event when the pipeline turns stale and when it recovers
+	StaleNotify bool `json:"stale-notify,omitempty" yaml:"stale-notify,omitempty"`
+	// SingletonInterval coalesces the MODIFIED events of each object to at most one per interval,
+	// for singleton resources with constant reconcile churn, zero emits every update
+	SingletonInterval time.Duration `json:"singleton-interval,omitempty" yaml:"singleton-interval,omitempty"`
+	// WasmModule is the path of a WebAssembly module transforming the objects, see pipeline.WasmTransformer
+	WasmModule string `json:"wasm-module,omitempty" yaml:"wasm-module,omitempty"`
+	// WasmTimeout and WasmMaxMemoryMiB bound every call of the module
+	WasmTimeout      time.Duration `json:"wasm-timeout,omitempty" yaml:"wasm-timeout,omitempty"`
+	WasmMaxMemoryMiB int           `json:"wasm-max-memory-mib,omitempty" yaml:"wasm-max-memory-mib,omitempty"`
+	// PruneDefaults removes the fields equal to the defaults of the resource's OpenAPI schema
+	PruneDefaults bool `json:"prune-defaults,omitempty" yaml:"prune-defaults,omitempty"`
+	// SummaryInterval emits a summary of the object counts every interval, zero emits none
+	SummaryInterval time.Duration `json:"summary-interval,omitempty" yaml:"summary-interval,omitempty"`
+	// SummaryOnly emits the summaries instead of the events of the individual objects
+	SummaryOnly bool `json:"summary-only,omitempty" yaml:"summary-only,omitempty"`
+	// ContainerFields emits MODIFIED events of Pods only when one of these fields of a container changes,
+	// f.e. "image" or "restartCount", empty emits every update
+	ContainerFields []string `json:"container-fields,omitempty" yaml:"container-fields,omitempty"`
+	// Namespaces scopes the pipeline to objects of these namespaces, empty emits objects of all namespaces.
+	// Cluster scoped objects are not affected.
+	Namespaces []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+	// ExcludeNamespaces drops the objects of these namespaces from a pipeline watching all namespaces,
+	// see MeshsyncConfig.ExcludeNamespaces. Cluster scoped objects are not affected.
+	ExcludeNamespaces []string `json:"exclude-namespaces,omitempty" yaml:"exclude-namespaces,omitempty"`
+	// Mask replaces the values of the fields selected by these JSONPath expressions with MaskedValue,
+	// see MaskPath for the supported syntax
+	Mask []string `json:"mask,omitempty" yaml:"mask,omitempty"`
+	// DropPaths removes the fields selected by these JSONPath expressions from the emitted objects,
+	// unlike Mask the keys are removed as well, see FieldPath for the supported syntax
+	DropPaths []string `json:"drop-paths,omitempty" yaml:"drop-paths,omitempty"`
+	// IdentityPath is a JSONPath expression selecting the identity singleton updates are coalesced by,
+	// objects it selects no value of are identified by their UID
+	IdentityPath string `json:"identity-path,omitempty" yaml:"identity-path,omitempty"`
+	// CacheTrim lists the parts of the objects removed before they enter the informer cache,
+	// see CacheTrimFields. DELETE events carry the trimmed objects as well.
+	CacheTrim []string `json:"cache-trim,omitempty" yaml:"cache-trim,omitempty"`
+	// MetadataLabels and MetadataAnnotations emit MODIFIED events only when the value of one of these keys
+	// changes, is added or removed, empty emits every update
+	MetadataLabels      []string `json:"metadata-labels,omitempty" yaml:"metadata-labels,omitempty"`
+	MetadataAnnotations []string `json:"metadata-annotations,omitempty" yaml:"metadata-annotations,omitempty"`
+	// DeleteSemantics selects when the DELETE event of an object held by finalizers is emitted,
+	// see DeleteOnFinalRemoval and DeleteOnDeletionTimestamp
+	DeleteSemantics string `json:"delete-semantics,omitempty" yaml:"delete-semantics,omitempty"`
+	// RetainedObjects is the number of the most recently seen objects whose last known state is kept,
+	// so DELETE events the informer lost the object of still carry it, zero keeps none
+	RetainedObjects int `json:"retained-objects,omitempty" yaml:"retained-objects,omitempty"`
+	// Requires lists the resources which must be served for the pipeline to run, see ActivePipelines
+	Requires []string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	// Rollup emits a rollup of the ready and total Pods per top-level owner whenever it changes
+	Rollup bool `json:"rollup,omitempty" yaml:"rollup,omitempty"`
+	// RollupOnly emits the rollups instead of the events of the individual Pods
+	RollupOnly bool `json:"rollup-only,omitempty" yaml:"rollup-only,omitempty"`
+	// BackfillRevisions replays the changes of the last revisions before the initial list,
+	// from the watch cache of the API server, zero replays none
+	BackfillRevisions int `json:"backfill-revisions,omitempty" yaml:"backfill-revisions,omitempty"`
+	// LabelSelector restricts the list and watch of the resource to the objects it selects, empty watches all objects
+	LabelSelector string `json:"label-selector,omitempty" yaml:"label-selector,omitempty"`
+	// AnnotationFilter emits only the objects carrying all of these annotations with these values,
+	// empty emits all objects. It is applied client-side, the objects are listed, watched and cached regardless.
+	AnnotationFilter map[string]string `json:"annotation-filter,omitempty" yaml:"annotation-filter,omitempty"`
+	// FieldSelector restricts the list and watch of the resource to the objects whose fields it selects,
+	// empty watches all objects
+	FieldSelector string `json:"field-selector,omitempty" yaml:"field-selector,omitempty"`
 }
 
 type ListenerConfigs []ListenerConfig
@@ -68,10 +181,549 @@ type MeshsyncConfig struct {
 	Pipelines map[string]PipelineConfigs `json:"pipeline-configs,omitempty" yaml:"pipeline-configs,omitempty"`
 	Listeners map[string]ListenerConfig  `json:"listener-config,omitempty" yaml:"listener-config,omitempty"`
 	WhiteList []ResourceConfig           `json:"resource-configs" yaml:"resource-configs"`
+
+	// events of resources not excluded by the blacklist, keyed by resource, see BlackListEntry
+	BlackListEvents map[string][]string `json:"blacklist-events,omitempty" yaml:"blacklist-events,omitempty"`
+	// events of the other resources not excluded by the blacklist, the default events if empty
+	BlackListDefaultEvents []string `json:"blacklist-default-events,omitempty" yaml:"blacklist-default-events,omitempty"`
+	// events of the resources given none, the package DefaultEvents if empty
+	DefaultEvents []string `json:"default-events,omitempty" yaml:"default-events,omitempty"`
+
+	// subject partitioning by the tenant label of the object's namespace
+	TenantLabel           string `json:"tenant-label,omitempty" yaml:"tenant-label,omitempty"`
+	TenantFallbackSubject string `json:"tenant-fallback-subject,omitempty" yaml:"tenant-fallback-subject,omitempty"`
+
+	// whether the status subtree is emitted, applies to every pipeline unless overridden per resource
+	EmitStatus bool `json:"emit-status" yaml:"emit-status"`
+
+	// settings which apply to all pipelines alike rather than per resource
+	GlobalSettings
+
+	// how often the cached objects are replayed to the pipelines without ResyncPeriod of their own,
+	// zero, the default, never resyncs
+	ResyncPeriod time.Duration `json:"resync-period,omitempty" yaml:"resync-period,omitempty"`
+
+	// resource to the namespaces its pipeline is scoped to, see PipelineConfig.Namespaces
+	Namespaces map[string][]string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+
+	// namespaces no pipeline reports objects of, f.e. kube-system, removed from the namespaces pipelines are scoped to
+	ExcludeNamespaces []string `json:"exclude-namespaces,omitempty" yaml:"exclude-namespaces,omitempty"`
+
+	// whether the watching is paused, f.e. during maintenance, without deleting the Custom Resource:
+	// the watch-list is still parsed and validated but resolves to no pipelines
+	Paused bool `json:"paused,omitempty" yaml:"paused,omitempty"`
+	// the pipelines the watch-list resolves to once resumed, nil unless paused
+	PausedPipelines map[string]PipelineConfigs `json:"-" yaml:"-"`
+
+	// the ConfigMap the watch-list was read from, nil when it was inline in the Custom Resource
+	Source *ConfigMapRef `json:"-" yaml:"-"`
+}
+
+// GlobalSettings are the settings of the watch-list which apply to all pipelines alike, see pipeline.New
+type GlobalSettings struct {
+	// schema version of the emitted event envelope, see model.SchemaVersions
+	EnvelopeVersion string `json:"envelope-version,omitempty" yaml:"envelope-version,omitempty"`
+
+	// whether a marker is emitted once the initial snapshot of all pipelines is complete
+	SnapshotCompleteMarker bool `json:"snapshot-complete-marker,omitempty" yaml:"snapshot-complete-marker,omitempty"`
+	// whether a manifest of the objects of the initial snapshot is emitted once it is complete
+	SnapshotManifest bool `json:"snapshot-manifest,omitempty" yaml:"snapshot-manifest,omitempty"`
+
+	// whether namespaced objects are enriched with the metadata of their namespace,
+	// costs a namespaces informer and larger events
+	NamespaceMetadata bool `json:"namespace-metadata,omitempty" yaml:"namespace-metadata,omitempty"`
+
+	// emitted event type to the value consumers receive instead, see EmittedEventTypes
+	EventTypeMapping map[string]string `json:"event-type-mapping,omitempty" yaml:"event-type-mapping,omitempty"`
+
+	// whether objects are emitted in a canonical, byte-level diffable form, costs CPU
+	CanonicalJSON bool `json:"canonical-json,omitempty" yaml:"canonical-json,omitempty"`
+
+	// whether the normalized health of the objects of kinds with a health evaluator is attached to the envelope
+	ComputeHealth bool `json:"compute-health,omitempty" yaml:"compute-health,omitempty"`
+
+	// whether the values of the data of ConfigMaps are redacted, the ones of Secrets always are
+	RedactConfigMaps bool `json:"redact-configmaps,omitempty" yaml:"redact-configmaps,omitempty"`
+
+	// how many informers run their initial list at once, bounds the LIST load on the API server at startup,
+	// zero starts all informers at once
+	MaxConcurrentInitializing int `json:"max-concurrent-initializing,omitempty" yaml:"max-concurrent-initializing,omitempty"`
+
+	// the upper bound of the random delay before the informers start, so replicas started at once,
+	// f.e. after a node drain, spread their initial lists, zero starts right away
+	StartupJitter time.Duration `json:"startup-jitter,omitempty" yaml:"startup-jitter,omitempty"`
+	// how far apart the replicas sharing the startup lease of the namespace start their informers,
+	// on top of the jitter, zero does not coordinate the replicas
+	StartupStagger time.Duration `json:"startup-stagger,omitempty" yaml:"startup-stagger,omitempty"`
+
+	// how often a liveness event is emitted on DefaultLivenessSubject, zero emits none
+	LivenessInterval time.Duration `json:"liveness-interval,omitempty" yaml:"liveness-interval,omitempty"`
+
+	// how the lines logged per processed object are sampled, see ObjectLogSampling
+	ObjectLogSampling ObjectLogSampling `json:"object-log-sampling,omitempty" yaml:"object-log-sampling,omitempty"`
+
+	// informer topology of pipelines scoped to namespaces, see NamespaceStrategies
+	NamespaceStrategy          string `json:"namespace-strategy,omitempty" yaml:"namespace-strategy,omitempty"`
+	NamespaceStrategyThreshold int    `json:"namespace-strategy-threshold,omitempty" yaml:"namespace-strategy-threshold,omitempty"`
+
+	// whether pipelines scoped to namespaces which do not exist fail to start instead of being reported
+	StrictNamespaces bool `json:"strict-namespaces,omitempty" yaml:"strict-namespaces,omitempty"`
+
+	// whether all pipelines are watched through namespaced clients, for installs granted a Role
+	// in each watched namespace instead of a ClusterRole, see validateNamespacedClients
+	NamespacedClients bool `json:"namespaced-clients,omitempty" yaml:"namespaced-clients,omitempty"`
+
+	// relationship types emitted between the objects of the watched resources, see RelationshipTypes
+	Relationships []string `json:"relationships,omitempty" yaml:"relationships,omitempty"`
+
+	// JSONPath expressions of the fields which never leave the cluster, stripped from the objects of every pipeline
+	// whatever their resource configuration
+	FieldDenylist []string `json:"field-denylist,omitempty" yaml:"field-denylist,omitempty"`
 }
 
 // Watched Resource configuration
 type ResourceConfig struct {
 	Resource string
 	Events   []string
+	// the group and version of a resource missing from Pipelines, f.e. the CRD of an operator,
+	// Resource is its plural then, f.e. {"Resource":"certificates","Group":"cert-manager.io","Version":"v1"}
+	Group   string `json:",omitempty" yaml:",omitempty"`
+	Version string `json:",omitempty" yaml:",omitempty"`
+	// overrides the global EmitStatus for this resource when set
+	EmitStatus *bool `json:",omitempty" yaml:",omitempty"`
+	// how sinks key objects of this resource: "uid" (default), "namespace/name" or "label:<key>",
+	// the broker appends the key to the subject only if set
+	KeyFunc string `json:",omitempty" yaml:",omitempty"`
+	// key function used when a label based KeyFunc finds no label, defaults to "uid"
+	KeyFuncFallback string `json:",omitempty" yaml:",omitempty"`
+	// duration string (f.e. "30m") after which the watch is re-established
+	MaxWatchAge string `json:",omitempty" yaml:",omitempty"`
+	// duration string (f.e. "5m"), lists the resource on this interval instead of watching it
+	PollInterval string `json:",omitempty" yaml:",omitempty"`
+	// duration string (f.e. "30s") the cached objects are replayed to the pipeline on,
+	// overrides the global resyncPeriod, "0" never resyncs
+	ResyncPeriod string `json:",omitempty" yaml:",omitempty"`
+	// "global" or "local", selects the bucket for resources registered in both,
+	// and the bucket of a resource given by Group and Version, see Scopes
+	Scope string `json:",omitempty" yaml:",omitempty"`
+	// how Resource matches the registered resources, see MatchModes, defaults to "exact".
+	// A pattern matching several resources whitelists each of them with the settings of this entry.
+	Match string `json:",omitempty" yaml:",omitempty"`
+	// namespaces the pipeline is scoped to, empty watches all namespaces, not supported for global resources
+	Namespaces []string `json:",omitempty" yaml:",omitempty"`
+	// watches only the objects matching the label selector, f.e. "app.kubernetes.io/managed-by=meshery"
+	LabelSelector string `json:",omitempty" yaml:",omitempty"`
+	// emits only the objects carrying all of these annotations with these values, f.e. {"meshery.io/track":"true"}.
+	// Annotations are not selectable server-side: unlike LabelSelector this is a post-list filter applied
+	// before publishing, every object of the resource is still listed, watched and cached.
+	// An object losing a matching annotation is no longer emitted, no DELETE event is emitted for it.
+	AnnotationFilter map[string]string `json:",omitempty" yaml:",omitempty"`
+	// watches only the objects whose fields match the field selector, f.e. "status.phase=Running".
+	// Only few fields are selectable server-side, see SelectableFields.
+	FieldSelector string `json:",omitempty" yaml:",omitempty"`
+	// samples objects by label selector, f.e. to keep all production objects but few dev ones
+	Sampling *SamplingConfig `json:",omitempty" yaml:",omitempty"`
+	// sink URI (f.e. "nats://broker:4222" or "file:///tmp/events.yaml"), defaults to the global sink
+	Sink string `json:",omitempty" yaml:",omitempty"`
+	// compress the tracked objects of this resource in memory, for many large objects (f.e. CRDs)
+	CompressCache bool `json:",omitempty" yaml:",omitempty"`
+	// skip ADDED events of objects which existed before MeshSync started
+	NewObjectsOnly bool `json:",omitempty" yaml:",omitempty"`
+	// skip MODIFIED events which leave metadata.generation unchanged, f.e. status and metadata updates
+	GenerationChangeOnly bool `json:",omitempty" yaml:",omitempty"`
+	// remove the fields the API server defaulted, so the objects reflect what was configured
+	PruneDefaults bool `json:",omitempty" yaml:",omitempty"`
+	// JSONPath expressions of fields whose values are masked, f.e. "$..env[?(@.name=='*_TOKEN')].value"
+	Mask []string `json:",omitempty" yaml:",omitempty"`
+	// JSONPath expressions of fields removed from the emitted objects, f.e. "$.spec.caBundle"
+	DropPaths []string `json:",omitempty" yaml:",omitempty"`
+	// JSONPath expression of the identity updates are coalesced by instead of the UID, f.e. "$.spec.hostname",
+	// requires Singleton
+	IdentityPath string `json:",omitempty" yaml:",omitempty"`
+	// parts of the objects not kept in memory at all, f.e. "managedFields", see CacheTrimFields
+	CacheTrim []string `json:",omitempty" yaml:",omitempty"`
+	// "onFinalRemoval" (default) or "onDeletionTimestamp", when DELETE is emitted for objects with finalizers
+	DeleteSemantics string `json:",omitempty" yaml:",omitempty"`
+	// number of recently seen objects kept to enrich DELETE events arriving without their object
+	RetainedObjects int `json:",omitempty" yaml:",omitempty"`
+	// resources which must be served for the resource to be watched, f.e. "gateways.v1.networking.istio.io",
+	// re-evaluated whenever CRDs change
+	Requires []string `json:",omitempty" yaml:",omitempty"`
+	// number of resourceVersions before the initial list whose changes are emitted ahead of it,
+	// skipped if the API server no longer has them, see MaxBackfillRevisions
+	BackfillRevisions int `json:",omitempty" yaml:",omitempty"`
+	// throttles DELETE storms, f.e. when a namespace is deleted
+	BulkDelete *BulkDeleteConfig `json:",omitempty" yaml:",omitempty"`
+	// stops emission while the watch of this resource is disconnected for too long
+	Staleness *StalenessConfig `json:",omitempty" yaml:",omitempty"`
+	// treats the resource as a singleton, f.e. an operator's cluster-wide configuration, and coalesces its updates
+	Singleton *SingletonConfig `json:",omitempty" yaml:",omitempty"`
+	// transforms the objects with a WebAssembly module
+	Wasm *WasmConfig `json:",omitempty" yaml:",omitempty"`
+	// emits updates of Pods only when the monitored container fields change
+	ContainerChanges *ContainerChangesConfig `json:",omitempty" yaml:",omitempty"`
+	// emits updates only when the monitored labels or annotations change, f.e. ownership labels
+	MetadataChanges *MetadataChangesConfig `json:",omitempty" yaml:",omitempty"`
+	// emits periodic summaries of the object counts, f.e. for dashboards
+	Summary *SummaryConfig `json:",omitempty" yaml:",omitempty"`
+	// emits rollups of the Pods per owner, f.e. per Deployment, for dashboards
+	Rollup *RollupConfig `json:",omitempty" yaml:",omitempty"`
+}
+
+// BulkDeleteConfig detects bulk deletions: once more than Threshold objects are deleted within Window,
+// the remaining DELETE events are emitted at most Threshold per Window,
+// or, with Summary, dropped in favour of a single summary event when the bulk deletion is over
+type BulkDeleteConfig struct {
+	Threshold int    `json:"threshold" yaml:"threshold"`
+	Window    string `json:"window" yaml:"window"`
+	Summary   bool   `json:"summary,omitempty" yaml:"summary,omitempty"`
+}
+
+func (c BulkDeleteConfig) applyTo(pc PipelineConfig) (PipelineConfig, error) {
+	if c.Threshold <= 0 {
+		return pc, fmt.Errorf("invalid bulk delete threshold for %s: must be positive", pc.Name)
+	}
+	window, err := time.ParseDuration(c.Window)
+	if err != nil {
+		return pc, fmt.Errorf("invalid bulk delete window for %s: %w", pc.Name, err)
+	}
+	if window <= 0 {
+		return pc, fmt.Errorf("invalid bulk delete window for %s: must be positive", pc.Name)
+	}
+	pc.BulkDeleteThreshold = c.Threshold
+	pc.BulkDeleteWindow = window
+	pc.BulkDeleteSummary = c.Summary
+	return pc, nil
+}
+
+// StalenessConfig marks the pipeline stale once its watch has been disconnected for After,
+// emission is skipped until the watch reconnects as the cache no longer reflects the cluster
+type StalenessConfig struct {
+	After string `json:"after" yaml:"after"`
+	// emit control events when the pipeline turns stale and when it recovers
+	Notify bool `json:"notify,omitempty" yaml:"notify,omitempty"`
+}
+
+func (c StalenessConfig) applyTo(pc PipelineConfig) (PipelineConfig, error) {
+	after, err := time.ParseDuration(c.After)
+	if err != nil {
+		return pc, fmt.Errorf("invalid staleness duration for %s: %w", pc.Name, err)
+	}
+	if after <= 0 {
+		return pc, fmt.Errorf("invalid staleness duration for %s: must be positive", pc.Name)
+	}
+	pc.StaleAfter = after
+	pc.StaleNotify = c.Notify
+	return pc, nil
+}
+
+// SingletonConfig hints that the resource has a single object, a CR reconciled by an operator,
+// whose MODIFIED events are emitted at most once per Interval, the latest update wins
+type SingletonConfig struct {
+	Interval string `json:"interval" yaml:"interval"`
+}
+
+func (c SingletonConfig) applyTo(pc PipelineConfig) (PipelineConfig, error) {
+	interval, err := time.ParseDuration(c.Interval)
+	if err != nil {
+		return pc, fmt.Errorf("invalid singleton interval for %s: %w", pc.Name, err)
+	}
+	if interval <= 0 {
+		return pc, fmt.Errorf("invalid singleton interval for %s: must be positive", pc.Name)
+	}
+	pc.SingletonInterval = interval
+	return pc, nil
+}
+
+// defaults bounding the calls of a WebAssembly module
+const (
+	DefaultWasmTimeout      = 100 * time.Millisecond
+	DefaultWasmMaxMemoryMiB = 16
+)
+
+// WasmConfig transforms the objects with the transform function of a WebAssembly module,
+// each call is aborted after Timeout and may use at most MaxMemoryMiB
+type WasmConfig struct {
+	Module       string `json:"module" yaml:"module"`
+	Timeout      string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	MaxMemoryMiB int    `json:"maxMemoryMiB,omitempty" yaml:"maxMemoryMiB,omitempty"`
+}
+
+func (c WasmConfig) applyTo(pc PipelineConfig) (PipelineConfig, error) {
+	if c.Module == "" {
+		return pc, fmt.Errorf("invalid wasm config for %s: module is missing", pc.Name)
+	}
+	timeout := DefaultWasmTimeout
+	if c.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(c.Timeout); err != nil {
+			return pc, fmt.Errorf("invalid wasm timeout for %s: %w", pc.Name, err)
+		}
+		if timeout <= 0 {
+			return pc, fmt.Errorf("invalid wasm timeout for %s: must be positive", pc.Name)
+		}
+	}
+	maxMemoryMiB := DefaultWasmMaxMemoryMiB
+	if c.MaxMemoryMiB < 0 {
+		return pc, fmt.Errorf("invalid wasm memory limit for %s: must not be negative", pc.Name)
+	}
+	if c.MaxMemoryMiB > 0 {
+		maxMemoryMiB = c.MaxMemoryMiB
+	}
+	pc.WasmModule = c.Module
+	pc.WasmTimeout = timeout
+	pc.WasmMaxMemoryMiB = maxMemoryMiB
+	return pc, nil
+}
+
+// PodsResource is the only resource ContainerChangesConfig applies to
+const PodsResource = "pods.v1."
+
+// DefaultContainerFields are the container fields monitored when none are configured
+var DefaultContainerFields = []string{"image", "restartCount", "ready"}
+
+// ContainerChangesConfig emits MODIFIED events of Pods only when one of Fields changes
+// in the spec or status of any container, init containers included,
+// or when containers are added or removed
+type ContainerChangesConfig struct {
+	Fields []string `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+func (c ContainerChangesConfig) applyTo(pc PipelineConfig) (PipelineConfig, error) {
+	if pc.Name != PodsResource {
+		return pc, fmt.Errorf("invalid container changes config for %s: only supported for %s", pc.Name, PodsResource)
+	}
+	fields := c.Fields
+	if len(fields) == 0 {
+		fields = DefaultContainerFields
+	}
+	for _, field := range fields {
+		if field == "" || field == "name" {
+			return pc, fmt.Errorf("invalid container field %q for %s", field, pc.Name)
+		}
+	}
+	pc.ContainerFields = fields
+	return pc, nil
+}
+
+// MetadataChangesConfig emits MODIFIED events only when the value of one of the Labels or Annotations
+// changes, is added or removed
+type MetadataChangesConfig struct {
+	Labels      []string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Annotations []string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+func (c MetadataChangesConfig) applyTo(pc PipelineConfig) (PipelineConfig, error) {
+	if len(c.Labels) == 0 && len(c.Annotations) == 0 {
+		return pc, fmt.Errorf("invalid metadata changes config for %s: no labels or annotations to monitor", pc.Name)
+	}
+	for _, key := range append(append([]string{}, c.Labels...), c.Annotations...) {
+		if key == "" {
+			return pc, fmt.Errorf("invalid metadata key %q for %s", key, pc.Name)
+		}
+	}
+	pc.MetadataLabels = c.Labels
+	pc.MetadataAnnotations = c.Annotations
+	return pc, nil
+}
+
+// SummaryConfig emits a summary of the resource's objects every Interval,
+// counted in total, by namespace and by status.phase where the objects have one.
+// With Only, the summaries replace the events of the individual objects.
+type SummaryConfig struct {
+	Interval string `json:"interval" yaml:"interval"`
+	Only     bool   `json:"only,omitempty" yaml:"only,omitempty"`
+}
+
+func (c SummaryConfig) applyTo(pc PipelineConfig) (PipelineConfig, error) {
+	interval, err := time.ParseDuration(c.Interval)
+	if err != nil {
+		return pc, fmt.Errorf("invalid summary interval for %s: %w", pc.Name, err)
+	}
+	if interval <= 0 {
+		return pc, fmt.Errorf("invalid summary interval for %s: must be positive", pc.Name)
+	}
+	pc.SummaryInterval = interval
+	pc.SummaryOnly = c.Only
+	return pc, nil
+}
+
+// RollupConfig emits a rollup of the ready and total Pods per top-level owner, f.e. per Deployment,
+// whenever one of its Pods changes. With Only, the rollups replace the events of the individual Pods.
+type RollupConfig struct {
+	Only bool `json:"only,omitempty" yaml:"only,omitempty"`
+}
+
+func (c RollupConfig) applyTo(pc PipelineConfig) (PipelineConfig, error) {
+	if pc.Name != PodsResource {
+		return pc, fmt.Errorf("invalid rollup config for %s: only supported for %s", pc.Name, PodsResource)
+	}
+	pc.Rollup = true
+	pc.RollupOnly = c.Only
+	return pc, nil
+}
+
+// applyTo resolves the pipeline for this resource configuration
+func (rc ResourceConfig) applyTo(pc PipelineConfig, meshsyncConfig *MeshsyncConfig) (PipelineConfig, error) {
+	events, err := normalizeEvents(rc.Resource, rc.Events, meshsyncConfig.defaultEvents())
+	if err != nil {
+		return pc, err
+	}
+	pc.Events = events
+
+	emitStatus := meshsyncConfig.EmitStatus
+	if rc.EmitStatus != nil {
+		emitStatus = *rc.EmitStatus
+	}
+	pc.StripStatus = !emitStatus
+
+	pc.KeyFunc = rc.KeyFunc
+	pc.KeyFuncFallback = rc.KeyFuncFallback
+	pc.Sampling = rc.Sampling
+
+	labelSelector, err := parseLabelSelector(rc.Resource, rc.LabelSelector)
+	if err != nil {
+		return pc, err
+	}
+	pc.LabelSelector = labelSelector
+	if err := validateAnnotationFilter(rc.Resource, rc.AnnotationFilter); err != nil {
+		return pc, err
+	}
+	pc.AnnotationFilter = rc.AnnotationFilter
+	fieldSelector, err := parseFieldSelector(pc.Name, rc.FieldSelector)
+	if err != nil {
+		return pc, err
+	}
+	pc.FieldSelector = fieldSelector
+	pc.Sink = rc.Sink
+	pc.CompressCache = rc.CompressCache
+	pc.NewObjectsOnly = rc.NewObjectsOnly
+	pc.GenerationChangeOnly = rc.GenerationChangeOnly
+	pc.PruneDefaults = rc.PruneDefaults
+
+	if err := validateMask(rc.Resource, rc.Mask); err != nil {
+		return pc, err
+	}
+	pc.Mask = rc.Mask
+
+	if err := validateNamespaces(rc.Resource, rc.Namespaces); err != nil {
+		return pc, err
+	}
+	pc.Namespaces = rc.Namespaces
+
+	if err := validateDropPaths(rc.Resource, rc.DropPaths); err != nil {
+		return pc, err
+	}
+	pc.DropPaths = rc.DropPaths
+
+	deleteSemantics, err := parseDeleteSemantics(rc.Resource, rc.DeleteSemantics)
+	if err != nil {
+		return pc, err
+	}
+	pc.DeleteSemantics = deleteSemantics
+
+	if err := validateRequires(rc.Resource, rc.Requires); err != nil {
+		return pc, err
+	}
+	pc.Requires = rc.Requires
+
+	if err := validateBackfill(rc.Resource, rc.BackfillRevisions); err != nil {
+		return pc, err
+	}
+	pc.BackfillRevisions = rc.BackfillRevisions
+
+	if rc.MaxWatchAge != "" {
+		maxWatchAge, err := time.ParseDuration(rc.MaxWatchAge)
+		if err != nil {
+			return pc, fmt.Errorf("invalid MaxWatchAge for %s: %w", rc.Resource, err)
+		}
+		if maxWatchAge < 0 {
+			return pc, fmt.Errorf("invalid MaxWatchAge for %s: must not be negative", rc.Resource)
+		}
+		pc.MaxWatchAge = maxWatchAge
+	}
+
+	pc.ResyncPeriod = meshsyncConfig.ResyncPeriod
+	if rc.ResyncPeriod != "" {
+		resyncPeriod, err := parseResyncPeriod(rc.Resource, rc.ResyncPeriod)
+		if err != nil {
+			return pc, err
+		}
+		pc.ResyncPeriod = resyncPeriod
+	}
+
+	if rc.PollInterval != "" {
+		pollInterval, err := parsePollInterval(rc.Resource, rc.PollInterval)
+		if err != nil {
+			return pc, err
+		}
+		if pc.MaxWatchAge > 0 || pc.BackfillRevisions > 0 {
+			return pc, fmt.Errorf("invalid PollInterval for %s: a polled resource is not watched, MaxWatchAge and BackfillRevisions do not apply", rc.Resource)
+		}
+		pc.PollInterval = pollInterval
+	}
+
+	for _, nested := range rc.nestedConfigs() {
+		var err error
+		if pc, err = nested.applyTo(pc); err != nil {
+			return pc, err
+		}
+	}
+
+	pc.CacheTrim = rc.CacheTrim
+	if err := validateCacheTrim(pc); err != nil {
+		return pc, err
+	}
+
+	if rc.RetainedObjects < 0 {
+		return pc, fmt.Errorf("invalid RetainedObjects for %s: must not be negative", rc.Resource)
+	}
+	pc.RetainedObjects = rc.RetainedObjects
+
+	if rc.IdentityPath != "" {
+		if _, err := ParseFieldPath(rc.IdentityPath); err != nil {
+			return pc, fmt.Errorf("invalid IdentityPath for %s: %w", rc.Resource, err)
+		}
+		if pc.SingletonInterval <= 0 {
+			return pc, fmt.Errorf("invalid IdentityPath for %s: updates are coalesced by identity only for a Singleton", rc.Resource)
+		}
+		pc.IdentityPath = rc.IdentityPath
+	}
+
+	return pc, nil
+}
+
+// nestedConfig is an optional group of settings resolving onto the pipeline
+type nestedConfig interface {
+	applyTo(pc PipelineConfig) (PipelineConfig, error)
+}
+
+// nestedConfigs returns the nested configurations which are set
+func (rc ResourceConfig) nestedConfigs() []nestedConfig {
+	nested := make([]nestedConfig, 0)
+	if rc.BulkDelete != nil {
+		nested = append(nested, *rc.BulkDelete)
+	}
+	if rc.Staleness != nil {
+		nested = append(nested, *rc.Staleness)
+	}
+	if rc.Singleton != nil {
+		nested = append(nested, *rc.Singleton)
+	}
+	if rc.Wasm != nil {
+		nested = append(nested, *rc.Wasm)
+	}
+	if rc.ContainerChanges != nil {
+		nested = append(nested, *rc.ContainerChanges)
+	}
+	if rc.MetadataChanges != nil {
+		nested = append(nested, *rc.MetadataChanges)
+	}
+	if rc.Summary != nil {
+		nested = append(nested, *rc.Summary)
+	}
+	if rc.Rollup != nil {
+		nested = append(nested, *rc.Rollup)
+	}
+	return nested
 }
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// the condition of the Custom Resource reporting the health of its watch-list
+const (
+	ReadyCondition = "Ready"
+	// reasons of the ready condition
+	ReasonWatchListValid   = "WatchListValid"
+	ReasonWatchListInvalid = "WatchListInvalid"
+)
+
+// ValidationResult is the outcome of resolving a watch-list into pipelines
+type ValidationResult struct {
+	// the number of pipelines the watch-list resolved to
+	Pipelines int
+	// whether the watching is paused, the valid watch-list resolving to no pipelines then
+	Paused bool
+	// the lint issues of the watch-list, valid but likely unintended, see MeshsyncConfig.Lint
+	Warnings []string
+	// the error the watch-list was rejected with, nil when it is valid
+	Err error
+}
+
+// NewValidationResult returns the result of resolving meshsyncConfig, err being the error it failed with
+func NewValidationResult(meshsyncConfig *MeshsyncConfig, err error) ValidationResult {
+	result := ValidationResult{Warnings: make([]string, 0), Err: err}
+	if err != nil || meshsyncConfig == nil {
+		return result
+	}
+	result.Paused = meshsyncConfig.Paused
+	for _, pipelines := range meshsyncConfig.Pipelines {
+		result.Pipelines += len(pipelines)
+	}
+	for _, issue := range meshsyncConfig.Lint() {
+		result.Warnings = append(result.Warnings, issue.String())
+	}
+	return result
+}
+
+// condition returns the ready condition reporting the result
+func (r ValidationResult) condition(generation int64) metav1.Condition {
+	if r.Err != nil {
+		return metav1.Condition{
+			Type:               ReadyCondition,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: generation,
+			Reason:             ReasonWatchListInvalid,
+			Message:            r.Err.Error(),
+		}
+	}
+	message := fmt.Sprintf("the watch-list resolved to %d pipelines", r.Pipelines)
+	if r.Paused {
+		message = "the watch-list is valid, watching is paused"
+	}
+	return metav1.Condition{
+		Type:               ReadyCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             ReasonWatchListValid,
+		Message:            message,
+	}
+}
+
+// WriteValidationStatus reports the result in the status of the Custom Resource located by CRDConfigFromEnv,
+// so `kubectl get meshsync` reflects the health of the watch-list
+func WriteValidationStatus(ctx context.Context, dyClient dynamic.Interface, result ValidationResult) error {
+	return writeValidationStatus(ctx, dyClient, CRDConfigFromEnv(), result)
+}
+
+// writeValidationStatus merge patches the pipeline count, the warnings and the ready condition
+// into the status subresource. The other conditions of the Custom Resource are kept,
+// the transition time of the ready condition only changes along with its status.
+// A Custom Resource Definition without status subresource is not an error, there is nothing to report to.
+func writeValidationStatus(ctx context.Context, dyClient dynamic.Interface, crdConfig CRDConfig, result ValidationResult) error {
+	if err := ctx.Err(); err != nil {
+		return ErrInitConfig(err)
+	}
+	client := dyClient.Resource(crdConfig.GVR()).Namespace(crdConfig.Namespace)
+	cr, err := client.Get(ctx, crdConfig.Name, metav1.GetOptions{})
+	if err != nil {
+		return ErrInitConfig(fmt.Errorf("unable to update MeshSync status: %w", contextErr(ctx, err)))
+	}
+
+	conditions, err := conditionsOf(cr)
+	if err != nil {
+		return ErrInitConfig(fmt.Errorf("unable to update MeshSync status: %w", err))
+	}
+	meta.SetStatusCondition(&conditions, result.condition(cr.GetGeneration()))
+	warnings := result.Warnings
+	if warnings == nil {
+		warnings = make([]string, 0)
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"pipelines":  result.Pipelines,
+			"warnings":   warnings,
+			"conditions": conditions,
+		},
+	})
+	if err != nil {
+		return ErrInitConfig(fmt.Errorf("unable to update MeshSync status: %w", err))
+	}
+
+	_, err = client.Patch(ctx, crdConfig.Name, types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+	if apierrors.IsNotFound(err) && ctx.Err() == nil {
+		// the Custom Resource exists, hence its definition lacks the status subresource
+		return nil
+	}
+	if err != nil {
+		return ErrInitConfig(fmt.Errorf("unable to update MeshSync status: %w", contextErr(ctx, err)))
+	}
+	return nil
+}
+
+// conditionsOf returns the status conditions of the Custom Resource
+func conditionsOf(cr *unstructured.Unstructured) ([]metav1.Condition, error) {
+	raw, ok, err := unstructured.NestedSlice(cr.Object, "status", "conditions")
+	if err != nil || !ok {
+		return make([]metav1.Condition, 0), err
+	}
+	conditions := make([]metav1.Condition, 0, len(raw))
+	for _, item := range raw {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid status condition %v", item)
+		}
+		var condition metav1.Condition
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(fields, &condition); err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions, nil
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newStatusTestClient() *dynamicfake.FakeDynamicClient {
+	cr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": DefaultCRDConfig.Group + "/" + DefaultCRDConfig.Version,
+		"kind":       "MeshSync",
+		"metadata": map[string]interface{}{
+			"name":       DefaultCRDConfig.Name,
+			"namespace":  DefaultCRDConfig.Namespace,
+			"generation": int64(3),
+		},
+		"status": map[string]interface{}{
+			"publishing-to": "meshery-broker:4222",
+			"conditions": []interface{}{
+				map[string]interface{}{
+					"type":               "BrokerConnected",
+					"status":             "True",
+					"reason":             "Connected",
+					"message":            "",
+					"lastTransitionTime": "2024-01-01T00:00:00Z",
+				},
+			},
+		},
+	}}
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		DefaultCRDConfig.GVR(): "MeshSyncList",
+	}, cr)
+}
+
+func TestNewValidationResult(t *testing.T) {
+	meshsyncConfig := &MeshsyncConfig{
+		WhiteList: []ResourceConfig{
+			{Resource: "*.v1.apps", Events: []string{"ADDED"}},
+			{Resource: "deployments.*.apps", Events: []string{"MODIFIED"}},
+		},
+		Pipelines: map[string]PipelineConfigs{
+			LocalResourceKey: {{Name: "deployments.v1.apps"}, {Name: "replicasets.v1.apps"}},
+		},
+	}
+	result := NewValidationResult(meshsyncConfig, nil)
+	if result.Pipelines != 2 || result.Err != nil {
+		t.Errorf("expected 2 pipelines and no error, got %+v", result)
+	}
+	if expected := []string{meshsyncConfig.Lint()[0].Message}; !reflect.DeepEqual(result.Warnings, expected) {
+		t.Errorf("expected the lint issues %v as warnings, got %v", expected, result.Warnings)
+	}
+
+	invalid := errors.New("invalid whitelist")
+	if result := NewValidationResult(nil, invalid); result.Pipelines != 0 || result.Err != invalid {
+		t.Errorf("expected the error and no pipelines, got %+v", result)
+	}
+}
+
+func TestWriteValidationStatus(t *testing.T) {
+	dyClient := newStatusTestClient()
+	crs := dyClient.Resource(DefaultCRDConfig.GVR()).Namespace(DefaultCRDConfig.Namespace)
+
+	result := ValidationResult{Pipelines: 2, Warnings: []string{"whitelist entries overlap"}}
+	if err := writeValidationStatus(context.Background(), dyClient, DefaultCRDConfig, result); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	for _, action := range dyClient.Actions() {
+		if patch, ok := action.(k8stesting.PatchAction); ok {
+			if patch.GetPatchType() != types.MergePatchType || patch.GetSubresource() != "status" {
+				t.Errorf("expected a merge patch of the status subresource, got a %s patch of %q", patch.GetPatchType(), patch.GetSubresource())
+			}
+		}
+	}
+
+	cr, err := crs.Get(context.Background(), DefaultCRDConfig.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pipelines, _, _ := unstructured.NestedInt64(cr.Object, "status", "pipelines"); pipelines != 2 {
+		t.Errorf("expected 2 pipelines in the status, got %d", pipelines)
+	}
+	if warnings, _, _ := unstructured.NestedStringSlice(cr.Object, "status", "warnings"); !reflect.DeepEqual(warnings, result.Warnings) {
+		t.Errorf("expected the warnings %v in the status, got %v", result.Warnings, warnings)
+	}
+	if publishingTo, _, _ := unstructured.NestedString(cr.Object, "status", "publishing-to"); publishingTo != "meshery-broker:4222" {
+		t.Errorf("expected the other status fields to be kept, got publishing-to %q", publishingTo)
+	}
+
+	conditions, err := conditionsOf(cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !meta.IsStatusConditionTrue(conditions, "BrokerConnected") {
+		t.Errorf("expected the other conditions to be kept, got %+v", conditions)
+	}
+	ready := meta.FindStatusCondition(conditions, ReadyCondition)
+	if ready == nil || ready.Status != metav1.ConditionTrue || ready.Reason != ReasonWatchListValid || ready.ObservedGeneration != 3 {
+		t.Fatalf("expected the ready condition to be true for generation 3, got %+v", ready)
+	}
+
+	// the watch-list turns invalid
+	result = ValidationResult{Err: errors.New("invalid whitelist: pods.v1. given more than once")}
+	if err := writeValidationStatus(context.Background(), dyClient, DefaultCRDConfig, result); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	cr, err = crs.Get(context.Background(), DefaultCRDConfig.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conditions, err = conditionsOf(cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ready = meta.FindStatusCondition(conditions, ReadyCondition)
+	if ready == nil || ready.Status != metav1.ConditionFalse || ready.Reason != ReasonWatchListInvalid || ready.Message != result.Err.Error() {
+		t.Errorf("expected the ready condition to be false with the error, got %+v", ready)
+	}
+	if warnings, ok, _ := unstructured.NestedStringSlice(cr.Object, "status", "warnings"); ok && len(warnings) > 0 {
+		t.Errorf("expected the warnings to be cleared, got %v", warnings)
+	}
+}
+
+func TestWriteValidationStatusWithoutSubresource(t *testing.T) {
+	dyClient := newStatusTestClient()
+	dyClient.PrependReactor("patch", DefaultCRDConfig.Resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "status" {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewNotFound(DefaultCRDConfig.GVR().GroupResource(), DefaultCRDConfig.Name)
+	})
+	if err := writeValidationStatus(context.Background(), dyClient, DefaultCRDConfig, ValidationResult{Pipelines: 1}); err != nil {
+		t.Errorf("expected no error without status subresource, got %s", err.Error())
+	}
+
+	missing := DefaultCRDConfig
+	missing.Name = "missing"
+	if err := writeValidationStatus(context.Background(), dyClient, missing, ValidationResult{Pipelines: 1}); err == nil {
+		t.Error("expected error for the missing Custom Resource")
+	}
+}
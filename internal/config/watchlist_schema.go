@@ -0,0 +1,265 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// watchListSchemas returns the JSON schemas of the lists of the watch-list by their key.
+// They are derived from the types the lists unmarshal into, so every field of ResourceConfig
+// is known to the schema and every other key is rejected.
+var watchListSchemas = sync.OnceValues(func() (map[string]*gojsonschema.Schema, error) {
+	blackListEntry := schemaOf(reflect.TypeOf(BlackListEntry{}))
+	// an entry is either the name of the excluded resource or an object, see BlackListEntry.UnmarshalJSON
+	blackListEntry["type"] = []interface{}{"string", "object"}
+	lists := map[string]map[string]interface{}{
+		"whitelist": {"type": []interface{}{"array", "null"}, "items": schemaOf(reflect.TypeOf(ResourceConfig{}))},
+		"blacklist": {"type": []interface{}{"array", "null"}, "items": blackListEntry},
+	}
+
+	schemas := make(map[string]*gojsonschema.Schema, len(lists))
+	for key, list := range lists {
+		schema, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(list))
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema of the %s: %w", key, err)
+		}
+		schemas[key] = schema
+	}
+	return schemas, nil
+})
+
+// schemaOf returns the JSON schema of the values encoding/json unmarshals into t.
+// Object keys match the fields case-insensitively, like encoding/json does.
+func schemaOf(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := schemaOf(t.Elem())
+		schema["type"] = []interface{}{schema["type"], "null"}
+		return schema
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": []interface{}{"array", "null"}, "items": schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": []interface{}{"object", "null"}, "additionalProperties": schemaOf(t.Elem())}
+	case reflect.Struct:
+		fields := make(map[string]interface{}, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := jsonFieldName(field)
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			fields["^(?i:"+regexp.QuoteMeta(name)+")$"] = schemaOf(field.Type)
+		}
+		return map[string]interface{}{"type": "object", "patternProperties": fields, "additionalProperties": false}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// jsonFieldName returns the key of the field in JSON, its name unless the json tag renames it
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// ValidateWatchList checks the whitelist and the blacklist of the watch-list against their JSON schema,
+// before they are unmarshalled, so hand-edited lists fail with errors naming the line, the field
+// and the offending value instead of the first unmarshal error. All errors are reported at once.
+func ValidateWatchList(data map[string]string) error {
+	schemas, err := watchListSchemas()
+	if err != nil {
+		return ErrInitConfig(err)
+	}
+
+	errs := make([]error, 0)
+	for _, key := range []string{"whitelist", "blacklist"} {
+		document := data[key]
+		if document == "" {
+			continue
+		}
+		errs = append(errs, validateList(key, document, schemas[key])...)
+	}
+	if len(errs) > 0 {
+		return ErrInitConfig(errors.Join(errs...))
+	}
+	return nil
+}
+
+// validateList returns the schema violations of the list, or its syntax error
+func validateList(key, document string, schema *gojsonschema.Schema) []error {
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(document), &parsed); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line, column := lineColumn(document, int(syntaxErr.Offset))
+			return []error{fmt.Errorf("invalid %s: line %d column %d: %w", key, line, column, err)}
+		}
+		return []error{fmt.Errorf("invalid %s: %w", key, err)}
+	}
+	result, err := schema.Validate(gojsonschema.NewGoLoader(parsed))
+	if err != nil {
+		return []error{fmt.Errorf("invalid %s: %w", key, err)}
+	}
+	if result.Valid() {
+		return nil
+	}
+
+	offsets := jsonOffsetsOf(document)
+	type violation struct {
+		offset  int
+		message string
+	}
+	violations := make([]violation, 0, len(result.Errors()))
+	for _, resultErr := range result.Errors() {
+		field, offset := offsets.locate(resultErr)
+		message := fmt.Sprintf("%s: %s", field, resultErr.Description())
+		if resultErr.Type() != "additional_property_not_allowed" {
+			message += ", got " + truncatedJSON(resultErr.Value())
+		}
+		if offset >= 0 {
+			line, column := lineColumn(document, offset)
+			message = fmt.Sprintf("line %d column %d: %s", line, column, message)
+		}
+		violations = append(violations, violation{offset: offset, message: message})
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].offset != violations[j].offset {
+			return violations[i].offset < violations[j].offset
+		}
+		return violations[i].message < violations[j].message
+	})
+
+	errs := make([]error, 0, len(violations))
+	for _, v := range violations {
+		errs = append(errs, fmt.Errorf("invalid %s: %s", key, v.message))
+	}
+	return errs
+}
+
+// truncatedJSON returns the value as JSON, shortened for the error message
+func truncatedJSON(value interface{}) string {
+	const maxLength = 60
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	if len(encoded) > maxLength {
+		return string(encoded[:maxLength]) + "..."
+	}
+	return string(encoded)
+}
+
+// jsonOffsets are the byte offsets of the values and object keys of a JSON document by their path,
+// in the notation of the schema errors, f.e. "0.Events.1"
+type jsonOffsets struct {
+	values map[string]int
+	keys   map[string]int
+}
+
+const rootPath = "(root)"
+
+// jsonOffsetsOf records the offsets of the valid JSON document
+func jsonOffsetsOf(document string) jsonOffsets {
+	offsets := jsonOffsets{values: make(map[string]int), keys: make(map[string]int)}
+	decoder := json.NewDecoder(strings.NewReader(document))
+	start := func() int {
+		offset := int(decoder.InputOffset())
+		for offset < len(document) && strings.ContainsRune(" \t\r\n,:", rune(document[offset])) {
+			offset++
+		}
+		return offset
+	}
+
+	var walk func(path string) error
+	walk = func(path string) error {
+		offsets.values[path] = start()
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'):
+			for decoder.More() {
+				keyOffset := start()
+				key, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				child := joinPath(path, fmt.Sprint(key))
+				offsets.keys[child] = keyOffset
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+			_, err = decoder.Token()
+		case json.Delim('['):
+			for i := 0; decoder.More(); i++ {
+				if err := walk(joinPath(path, strconv.Itoa(i))); err != nil {
+					return err
+				}
+			}
+			_, err = decoder.Token()
+		}
+		return err
+	}
+	// the document has been parsed already, the offsets recorded before an error are kept regardless
+	_ = walk(rootPath)
+	return offsets
+}
+
+// locate returns the path of the field the schema error is about and its offset, -1 if unknown.
+// Unknown keys are located at the key, every other error at the offending value.
+func (o jsonOffsets) locate(resultErr gojsonschema.ResultError) (string, int) {
+	field := resultErr.Field()
+	if resultErr.Type() == "additional_property_not_allowed" {
+		property, _ := resultErr.Details()["property"].(string)
+		field = joinPath(field, property)
+		if offset, ok := o.keys[field]; ok {
+			return field, offset
+		}
+		return field, -1
+	}
+	if offset, ok := o.values[field]; ok {
+		return field, offset
+	}
+	return field, -1
+}
+
+func joinPath(path, element string) string {
+	if path == rootPath {
+		return element
+	}
+	return path + "." + element
+}
+
+// lineColumn converts the byte offset into the document to its 1-based line and column
+func lineColumn(document string, offset int) (int, int) {
+	if offset > len(document) {
+		offset = len(document)
+	}
+	preceding := document[:offset]
+	line := strings.Count(preceding, "\n") + 1
+	column := offset - strings.LastIndex(preceding, "\n")
+	return line, column
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateWatchList(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     map[string]string
+		expected []string
+	}{
+		{
+			name:     "event of the wrong type",
+			data:     map[string]string{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\",5]}]"},
+			expected: []string{"invalid whitelist: line 1 column 43: 0.Events.1: Invalid type. Expected: string, given: integer, got 5"},
+		},
+		{
+			name:     "unknown key",
+			data:     map[string]string{"whitelist": "[\n  {\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]},\n  {\"Resource\":\"services.v1.\",\"Evnts\":[\"ADDED\"]}\n]"},
+			expected: []string{"invalid whitelist: line 3 column 30: 1.Evnts: Additional property Evnts is not allowed"},
+		},
+		{
+			name:     "nested field of the wrong type",
+			data:     map[string]string{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"],\"BulkDelete\":{\"threshold\":\"100\",\"window\":\"1m\"}}]"},
+			expected: []string{"invalid whitelist: line 1 column 70: 0.BulkDelete.threshold: Invalid type. Expected: integer, given: string, got \"100\""},
+		},
+		{
+			name:     "list of the wrong type",
+			data:     map[string]string{"whitelist": "{\"Resource\":\"pods.v1.\"}"},
+			expected: []string{"invalid whitelist: line 1 column 1: (root): Invalid type"},
+		},
+		{
+			name:     "blacklist entry of the wrong type",
+			data:     map[string]string{"blacklist": "[\"pods.v1.\",3,{\"Resource\":\"secrets.v1.\",\"Foo\":true}]"},
+			expected: []string{"invalid blacklist: line 1 column 13: 1: Invalid type. Expected: [string,object], given: integer, got 3", "2.Foo: Additional property Foo is not allowed"},
+		},
+		{
+			name:     "syntax error",
+			data:     map[string]string{"whitelist": "[{\"Resource\":\"pods.v1.\",]"},
+			expected: []string{"invalid whitelist: line 1 column 26: invalid character ']'"},
+		},
+		{
+			name: "errors of both lists",
+			data: map[string]string{
+				"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":\"ADDED\"}]",
+				"blacklist": "[{\"Resource\":\"secrets.v1.\",\"Match\":1}]",
+			},
+			expected: []string{"0.Events: Invalid type", "0.Match: Invalid type"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateWatchList(tc.data)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if codeOf(err) != ErrInitConfigCode {
+				t.Errorf("expected error code %s, got %s", ErrInitConfigCode, codeOf(err))
+			}
+			for _, expected := range tc.expected {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("expected the error to mention %q, got %q", expected, err.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestValidateWatchListAcceptsValidLists(t *testing.T) {
+	for _, data := range []map[string]string{
+		LocalMeshsyncConfig,
+		{"whitelist": "[{\"resource\":\"pods.v1.\",\"events\":[\"ADDED\"],\"EmitStatus\":null,\"Sampling\":{\"selector\":\"env=dev\",\"rate\":0.1}}]"},
+		{"blacklist": "[\"pods.v1.\",{\"Events\":[\"ADDED\"]},{\"Resource\":\"secrets.v1.\",\"Events\":[\"DELETED\"]}]"},
+		{"whitelist": "", "blacklist": ""},
+	} {
+		if err := ValidateWatchList(data); err != nil {
+			t.Errorf("unexpected error %s for %v", err.Error(), data)
+		}
+	}
+}
+
+func TestSchemaValidationPrecedesSemanticChecks(t *testing.T) {
+	// unmarshalled as is, the entry would be rejected for its missing Resource rather than the misspelled key
+	_, err := PopulateConfigsFromMap(map[string]string{"whitelist": "[{\"Rsource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]"})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if expected := "0.Rsource: Additional property Rsource is not allowed"; !strings.Contains(err.Error(), expected) {
+		t.Errorf("expected the error to mention %q, got %q", expected, err.Error())
+	}
+}
@@ -1,18 +1,24 @@
 package output
 
 import (
+	"strings"
+
 	"github.com/meshery/meshkit/broker"
 	"github.com/meshery/meshsync/internal/config"
 	"github.com/meshery/meshsync/pkg/model"
 )
 
 type BrokerWriter struct {
-	br broker.Handler
+	br              broker.Handler
+	controlSubject  string
+	livenessSubject string
 }
 
 func NewBrokerWriter(br broker.Handler) *BrokerWriter {
 	return &BrokerWriter{
-		br: br,
+		br:              br,
+		controlSubject:  config.DefaultControlSubject,
+		livenessSubject: config.DefaultLivenessSubject,
 	}
 }
 
@@ -21,12 +27,37 @@ func (s *BrokerWriter) Write(
 	evtype broker.EventType,
 	config config.PipelineConfig,
 ) error {
-	return s.br.Publish(
-		config.PublishTo,
+	err := s.br.Publish(
+		keyedSubject(obj, config),
 		&broker.Message{
 			ObjectType: broker.MeshSync,
-			EventType:  evtype,
+			EventType:  config.EmittedEventType(evtype),
 			Object:     obj,
 		},
 	)
+	// the connection to the broker may recover
+	return Retryable(err)
+}
+
+// keyedSubject returns the subject the object is published to: the subject of the pipeline,
+// followed by the key of the object as its last token if the pipeline configures a key function,
+// f.e. for streams keeping the latest message per subject
+func keyedSubject(obj model.KubernetesResource, config config.PipelineConfig) string {
+	if config.KeyFunc == "" {
+		return config.PublishTo
+	}
+	key := KeyFuncFor(config)(obj)
+	if key == "" {
+		return config.PublishTo
+	}
+	return config.PublishTo + "." + SubjectToken(key)
+}
+
+// subjectTokenReplacer replaces the token separators, wildcards and spaces of a subject token
+var subjectTokenReplacer = strings.NewReplacer(".", "_", "*", "_", ">", "_", " ", "_")
+
+// SubjectToken makes a value safe to be used as a single subject token,
+// i.e. it must not contain token separators or wildcards
+func SubjectToken(value string) string {
+	return subjectTokenReplacer.Replace(value)
 }
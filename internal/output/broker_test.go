@@ -0,0 +1,84 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+)
+
+// recordingBroker records the published messages, the other methods are not used by the writer
+type recordingBroker struct {
+	broker.Handler
+	subjects []string
+	messages []*broker.Message
+}
+
+func (b *recordingBroker) Publish(subject string, message *broker.Message) error {
+	b.subjects = append(b.subjects, subject)
+	b.messages = append(b.messages, message)
+	return nil
+}
+
+func TestBrokerWriterRemapsEventTypes(t *testing.T) {
+	br := &recordingBroker{}
+	w := NewBrokerWriter(br)
+
+	pods := config.PipelineConfig{
+		Name:             "pods.v1.",
+		PublishTo:        "meshery.meshsync.core",
+		EventTypeMapping: map[string]string{"ADDED": "CREATE", "DELETED": "DELETE"},
+	}
+	pod := model.KubernetesResource{Kind: "Pod"}
+	for _, evtype := range []broker.EventType{broker.Add, broker.Update, broker.Delete} {
+		if err := w.Write(pod, evtype, pods); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []broker.EventType{"CREATE", broker.Update, "DELETE"}
+	for i, message := range br.messages {
+		if message.EventType != expected[i] {
+			t.Errorf("expected event type %s, got %s", expected[i], message.EventType)
+		}
+	}
+}
+
+func TestBrokerWriterKeyedSubject(t *testing.T) {
+	pod := model.KubernetesResource{
+		Kind: "Pod",
+		KubernetesResourceMeta: &model.KubernetesResourceObjectMeta{
+			Name:      "web",
+			Namespace: "default",
+			UID:       "6f1c",
+			Labels:    []*model.KubernetesKeyValue{{Key: "app.kubernetes.io/name", Value: "web.frontend"}},
+		},
+	}
+	cases := []struct {
+		name     string
+		config   config.PipelineConfig
+		expected string
+	}{
+		{name: "no key function", config: config.PipelineConfig{PublishTo: "meshery.meshsync.core"}, expected: "meshery.meshsync.core"},
+		{name: "uid", config: config.PipelineConfig{PublishTo: "meshery.meshsync.core", KeyFunc: config.KeyFuncUID}, expected: "meshery.meshsync.core.6f1c"},
+		{name: "namespace/name", config: config.PipelineConfig{PublishTo: "meshery.meshsync.core", KeyFunc: config.KeyFuncNamespacedName}, expected: "meshery.meshsync.core.default/web"},
+		{
+			name:     "label",
+			config:   config.PipelineConfig{PublishTo: "meshery.meshsync.core", KeyFunc: config.KeyFuncLabelPrefix + "app.kubernetes.io/name"},
+			expected: "meshery.meshsync.core.web_frontend",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			br := &recordingBroker{}
+			if err := NewBrokerWriter(br).Write(pod, broker.Add, tc.config); err != nil {
+				t.Fatal(err)
+			}
+			if br.subjects[0] != tc.expected {
+				t.Errorf("expected subject %s, got %s", tc.expected, br.subjects[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/meshery/meshsync/pkg/model"
+)
+
+// ManifestEntry identifies an emitted object by its resource and key,
+// the checksum allows consumers to verify they received it unaltered
+type ManifestEntry struct {
+	Resource string `json:"resource" yaml:"resource"`
+	Key      string `json:"key" yaml:"key"`
+	Checksum string `json:"checksum" yaml:"checksum"`
+}
+
+// ChecksumPrefix names the algorithm of checksums
+const ChecksumPrefix = "sha256:"
+
+// Checksum returns the checksum of an emitted object: the SHA-256 of its JSON encoding without the envelope,
+// which carries per event data such as trace context
+func Checksum(obj model.KubernetesResource) (string, error) {
+	obj.Envelope = nil
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return ChecksumPrefix + hex.EncodeToString(sum[:]), nil
+}
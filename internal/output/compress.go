@@ -0,0 +1,34 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+
+	"github.com/meshery/meshsync/pkg/model"
+)
+
+// compressObject returns the gzip compressed JSON representation of the object.
+// JSON is what the outputs serialize objects to as well, so nothing they emit gets lost.
+func compressObject(obj model.KubernetesResource) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(obj); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompressObject(data []byte) (model.KubernetesResource, error) {
+	obj := model.KubernetesResource{}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return obj, err
+	}
+	defer zr.Close()
+	err = json.NewDecoder(zr).Decode(&obj)
+	return obj, err
+}
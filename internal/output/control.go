@@ -0,0 +1,197 @@
+package output
+
+import (
+	"errors"
+	"time"
+
+	"github.com/meshery/meshkit/broker"
+)
+
+// messages of this type carry MeshSync control events instead of kubernetes resources
+var ControlObjectType broker.ObjectType = "meshsync-control"
+
+// control event types
+var (
+	// initial sync of a single pipeline's informer has completed
+	PipelineSyncedEvent broker.EventType = "PIPELINE-SYNCED"
+	// initial sync of all pipelines has completed, the snapshot is complete
+	SnapshotCompleteEvent broker.EventType = "SNAPSHOT-COMPLETE"
+	// a bulk deletion of a pipeline's objects is over, carries the number of DELETE events left out
+	BulkDeleteEvent broker.EventType = "BULK-DELETE"
+	// a pipeline's watch has been disconnected for too long, its events are skipped until it recovers
+	PipelineStaleEvent broker.EventType = "PIPELINE-STALE"
+	// a stale pipeline's watch has reconnected
+	PipelineRecoveredEvent broker.EventType = "PIPELINE-RECOVERED"
+	// periodic object counts of a resource, in total, by namespace and by phase
+	ResourceSummaryEvent broker.EventType = "RESOURCE-SUMMARY"
+	// the key and checksum of every object of the initial snapshot, follows SNAPSHOT-COMPLETE
+	SnapshotManifestEvent broker.EventType = "SNAPSHOT-MANIFEST"
+	// the ready and total Pods of an owner, f.e. a Deployment, whenever they change
+	ResourceRollupEvent broker.EventType = "RESOURCE-ROLLUP"
+	// the fingerprint of the configuration the pipelines run with, see config.ConfigFingerprint
+	ConfigFingerprintEvent broker.EventType = "CONFIG-FINGERPRINT"
+	// a relationship between two objects, f.e. a Service selecting a Pod, has been found
+	RelationshipAddedEvent broker.EventType = "RELATIONSHIP-ADDED"
+	// a relationship between two objects no longer holds, one of them changed or is gone
+	RelationshipRemovedEvent broker.EventType = "RELATIONSHIP-REMOVED"
+	// MeshSync is alive, emitted every interval whether or not the resources change
+	LivenessEvent broker.EventType = "LIVENESS"
+)
+
+// ControlEvent informs consumers about MeshSync's own state,
+// as opposed to the state of the watched resources
+type ControlEvent struct {
+	Type     broker.EventType `json:"type" yaml:"type"`
+	Resource string           `json:"resource,omitempty" yaml:"resource,omitempty"`
+	Count    int              `json:"count" yaml:"count"`
+	// object count per resource, for events covering more than one resource
+	Resources map[string]int `json:"resources,omitempty" yaml:"resources,omitempty"`
+	// object count per namespace and per status.phase, for summaries of a resource
+	Namespaces map[string]int `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+	Phases     map[string]int `json:"phases,omitempty" yaml:"phases,omitempty"`
+	// the objects of the initial snapshot, for the snapshot manifest
+	Manifest []ManifestEntry `json:"manifest,omitempty" yaml:"manifest,omitempty"`
+	// the owner the Pods of a rollup belong to, Count is the number of its Pods
+	Owner *OwnerRef `json:"owner,omitempty" yaml:"owner,omitempty"`
+	// the number of ready Pods, for rollups
+	Ready int `json:"ready,omitempty" yaml:"ready,omitempty"`
+	// the fingerprint of the resolved configuration
+	Fingerprint string `json:"fingerprint,omitempty" yaml:"fingerprint,omitempty"`
+	// the relationship found or gone, for relationship events
+	Relationship *Relationship `json:"relationship,omitempty" yaml:"relationship,omitempty"`
+	// the MeshSync process and how long it has been running, for liveness events
+	InstanceID    string    `json:"instance_id,omitempty" yaml:"instance_id,omitempty"`
+	UptimeSeconds float64   `json:"uptime_seconds,omitempty" yaml:"uptime_seconds,omitempty"`
+	Timestamp     time.Time `json:"timestamp" yaml:"timestamp"`
+}
+
+// OwnerRef identifies the owner of the Pods of a rollup
+type OwnerRef struct {
+	APIVersion string `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string `json:"kind" yaml:"kind"`
+	Namespace  string `json:"namespace" yaml:"namespace"`
+	Name       string `json:"name" yaml:"name"`
+}
+
+// Relationship is an edge of type Type, see config.RelationshipTypes, from the Source object to the Target object
+type Relationship struct {
+	Type   string    `json:"type" yaml:"type"`
+	Source ObjectRef `json:"source" yaml:"source"`
+	Target ObjectRef `json:"target" yaml:"target"`
+}
+
+// ObjectRef identifies an object at one end of a relationship
+type ObjectRef struct {
+	APIVersion string `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string `json:"kind" yaml:"kind"`
+	Namespace  string `json:"namespace" yaml:"namespace"`
+	Name       string `json:"name" yaml:"name"`
+}
+
+func NewControlEvent(evtype broker.EventType, resource string, count int) ControlEvent {
+	return ControlEvent{
+		Type:      evtype,
+		Resource:  resource,
+		Count:     count,
+		Timestamp: time.Now(),
+	}
+}
+
+// NewSnapshotCompleteEvent returns the marker of a complete snapshot
+// with the object counts per resource and their total
+func NewSnapshotCompleteEvent(counts map[string]int) ControlEvent {
+	event := NewControlEvent(SnapshotCompleteEvent, "", 0)
+	event.Resources = counts
+	for _, count := range counts {
+		event.Count += count
+	}
+	return event
+}
+
+// NewSnapshotManifestEvent returns the manifest of the objects of the initial snapshot
+func NewSnapshotManifestEvent(entries []ManifestEntry) ControlEvent {
+	event := NewControlEvent(SnapshotManifestEvent, "", len(entries))
+	event.Manifest = entries
+	return event
+}
+
+// NewResourceRollupEvent returns the rollup of the ready and total Pods of the owner
+func NewResourceRollupEvent(resource string, owner OwnerRef, ready, total int) ControlEvent {
+	event := NewControlEvent(ResourceRollupEvent, resource, total)
+	event.Owner = &owner
+	event.Ready = ready
+	return event
+}
+
+// NewConfigFingerprintEvent returns the fingerprint of the configuration the pipelines run with
+func NewConfigFingerprintEvent(fingerprint string) ControlEvent {
+	event := NewControlEvent(ConfigFingerprintEvent, "", 0)
+	event.Fingerprint = fingerprint
+	return event
+}
+
+// NewRelationshipEvent returns the event of the relationship found or gone, evtype is one of
+// RelationshipAddedEvent and RelationshipRemovedEvent, resource the pipeline of the source
+func NewRelationshipEvent(evtype broker.EventType, resource string, relationship Relationship) ControlEvent {
+	event := NewControlEvent(evtype, resource, 0)
+	event.Relationship = &relationship
+	return event
+}
+
+// NewLivenessEvent returns the liveness event of the MeshSync instance running for uptime
+func NewLivenessEvent(instanceID string, uptime time.Duration) ControlEvent {
+	event := NewControlEvent(LivenessEvent, "", 0)
+	event.InstanceID = instanceID
+	event.UptimeSeconds = uptime.Seconds()
+	return event
+}
+
+// ControlWriter is implemented by the outputs which are able to deliver control events;
+// outputs which do not implement it (f.e. the snapshot file) silently skip them
+type ControlWriter interface {
+	WriteControl(event ControlEvent) error
+}
+
+// WriteControl delivers the event to w if it supports control events
+func WriteControl(w Writer, event ControlEvent) error {
+	cw, ok := w.(ControlWriter)
+	if !ok {
+		return nil
+	}
+	return cw.WriteControl(event)
+}
+
+func (p *Processor) WriteControl(event ControlEvent) error {
+	return WriteControl(p.output, event)
+}
+
+func (w *CompositeWriter) WriteControl(event ControlEvent) error {
+	errs := make([]error, 0, len(w.writersPool))
+
+	for _, writer := range w.writersPool {
+		if err := WriteControl(writer, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+func (s *BrokerWriter) WriteControl(event ControlEvent) error {
+	subject := s.controlSubject
+	if event.Type == LivenessEvent {
+		subject = s.livenessSubject
+	}
+	return s.br.Publish(
+		subject,
+		&broker.Message{
+			ObjectType: ControlObjectType,
+			EventType:  event.Type,
+			Object:     event,
+		},
+	)
+}
@@ -0,0 +1,395 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+)
+
+// files of a disk-backed queue within its directory
+const (
+	// the events, one JSON record per line, appended in order
+	diskQueueLogFile = "events.log"
+	// the sequence number of the last event the sink confirmed
+	diskQueueAckFile = "ack"
+	// the log is compacted once its acknowledged records take more than this many bytes
+	// and more than the pending ones
+	diskQueueCompactBytes = 1 << 20
+)
+
+// ErrQueueFull is returned by DiskQueue.Write when the queue drops the new event
+var ErrQueueFull = errors.New("disk-backed queue is full")
+
+// DiskQueueOptions configure a DiskQueue, the zero value keeps any number of events
+// and redelivers them right away
+type DiskQueueOptions struct {
+	// bytes of undelivered events kept on disk, zero is unbounded
+	MaxBytes int64
+	// what Write does when the queue is full, one of config.QueueOverflowPolicies, empty blocks
+	Overflow string
+	// how long the queue waits before redelivering an event the sink failed with a retryable error
+	RetryBackoff time.Duration
+	// receives the errors of deliveries in the background
+	OnError func(err error)
+}
+
+// diskQueueRecord is an event as persisted in the log
+type diskQueueRecord struct {
+	Seq       uint64                   `json:"seq"`
+	EventType broker.EventType         `json:"event_type"`
+	Key       string                   `json:"key,omitempty"`
+	Object    model.KubernetesResource `json:"object"`
+	Config    config.PipelineConfig    `json:"config"`
+	// bytes of the record in the log, including the newline
+	size int64
+}
+
+// DiskQueue sits between the pipelines and a sink: a write persists the event and returns,
+// the queue delivers the events to the sink in order in the background and acknowledges
+// every event only after the sink confirmed it. Events which have not been acknowledged
+// when the queue is closed, or MeshSync stops, are replayed once the queue is opened again,
+// so the sink receives every event at least once.
+// Control events are passed through to the sink, they describe the state of the running MeshSync.
+type DiskQueue struct {
+	sink Writer
+	dir  string
+	opts DiskQueueOptions
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	log  *os.File
+	// the records not acknowledged yet, in order
+	pending      []diskQueueRecord
+	pendingBytes int64
+	// bytes of the log, including the acknowledged records not compacted yet
+	logBytes int64
+	nextSeq  uint64
+	closed   bool
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// OpenDiskQueue opens the queue in dir, creating it if needed, and starts delivering to sink,
+// beginning with the events a previous run left unacknowledged
+func OpenDiskQueue(dir string, sink Writer, opts DiskQueueOptions) (*DiskQueue, error) {
+	if opts.Overflow == "" {
+		opts.Overflow = config.QueueOverflowBlock
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	q := &DiskQueue{
+		sink: sink,
+		dir:  dir,
+		opts: opts,
+		done: make(chan struct{}),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+
+	q.wg.Add(1)
+	go q.deliver()
+	return q, nil
+}
+
+// load reads the records left unacknowledged and opens the log for appending
+func (q *DiskQueue) load() error {
+	acked, err := q.readAck()
+	if err != nil {
+		return err
+	}
+	q.nextSeq = acked + 1
+
+	path := filepath.Join(q.dir, diskQueueLogFile)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var valid int64
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, '\n')
+		if end < 0 {
+			// a record torn by a crash in the middle of a write, it has not been written successfully
+			break
+		}
+		record := diskQueueRecord{}
+		if err := json.Unmarshal(data[:end], &record); err != nil {
+			return fmt.Errorf("corrupt record in %s at offset %d: %w", path, valid, err)
+		}
+		record.size = int64(end + 1)
+		valid += record.size
+		data = data[end+1:]
+		if record.Seq <= acked {
+			continue
+		}
+		q.pending = append(q.pending, record)
+		q.pendingBytes += record.size
+		q.nextSeq = record.Seq + 1
+	}
+
+	q.log, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	if err := q.log.Truncate(valid); err != nil {
+		_ = q.log.Close()
+		return err
+	}
+	if _, err := q.log.Seek(valid, 0); err != nil {
+		_ = q.log.Close()
+		return err
+	}
+	q.logBytes = valid
+	return nil
+}
+
+func (q *DiskQueue) readAck() (uint64, error) {
+	data, err := os.ReadFile(filepath.Join(q.dir, diskQueueAckFile))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// writeAck records seq as acknowledged, replacing the file so a crash leaves either the old or the new value
+func (q *DiskQueue) writeAck(seq uint64) error {
+	path := filepath.Join(q.dir, diskQueueAckFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatUint(seq, 10)), 0o640); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Write persists the event, it is delivered to the sink in the background.
+// When the queue is full, it waits, drops the event returning ErrQueueFull
+// or drops the oldest events, depending on the overflow policy.
+func (q *DiskQueue) Write(
+	obj model.KubernetesResource,
+	evtype broker.EventType,
+	config config.PipelineConfig,
+) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return errors.New("disk-backed queue is closed")
+	}
+
+	record := diskQueueRecord{
+		Seq:       q.nextSeq,
+		EventType: evtype,
+		Key:       KeyFuncFor(config)(obj),
+		Object:    obj,
+		Config:    config,
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	record.size = int64(len(line))
+
+	if err := q.makeRoom(record.size); err != nil {
+		return err
+	}
+	if _, err := q.log.Write(line); err != nil {
+		return err
+	}
+	q.nextSeq++
+	q.logBytes += record.size
+	q.pending = append(q.pending, record)
+	q.pendingBytes += record.size
+	q.cond.Broadcast()
+	return nil
+}
+
+// makeRoom applies the overflow policy until size more bytes fit, must be called with the lock held
+func (q *DiskQueue) makeRoom(size int64) error {
+	if q.opts.MaxBytes <= 0 {
+		return nil
+	}
+	if size > q.opts.MaxBytes {
+		return fmt.Errorf("event of %d bytes exceeds the disk-backed queue of %d bytes", size, q.opts.MaxBytes)
+	}
+	for q.pendingBytes+size > q.opts.MaxBytes {
+		switch q.opts.Overflow {
+		case config.QueueOverflowDropNewest:
+			return ErrQueueFull
+		case config.QueueOverflowDropOldest:
+			if err := q.ack(q.pending[0].Seq); err != nil {
+				return err
+			}
+		default:
+			q.cond.Wait()
+			if q.closed {
+				return errors.New("disk-backed queue is closed")
+			}
+		}
+	}
+	return nil
+}
+
+// WriteControl passes the event through to the sink
+func (q *DiskQueue) WriteControl(event ControlEvent) error {
+	return WriteControl(q.sink, event)
+}
+
+// Pending returns the number of events not acknowledged by the sink yet
+func (q *DiskQueue) Pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// deliver writes the pending events to the sink in order until the queue is closed
+func (q *DiskQueue) deliver() {
+	defer q.wg.Done()
+	for {
+		q.mu.Lock()
+		for len(q.pending) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if q.closed {
+			q.mu.Unlock()
+			return
+		}
+		record := q.pending[0]
+		q.mu.Unlock()
+
+		err := q.send(record)
+		if err != nil && IsRetryable(err) {
+			q.onError(err)
+			select {
+			case <-q.done:
+				return
+			case <-time.After(q.opts.RetryBackoff):
+			}
+			continue
+		}
+		if err != nil {
+			// the sink will not accept the event when redelivered either
+			q.onError(err)
+		}
+
+		q.mu.Lock()
+		if errAck := q.ack(record.Seq); errAck != nil {
+			q.onError(errAck)
+		}
+		q.mu.Unlock()
+	}
+}
+
+// send writes the record to the sink, a sink which batches events has to flush them to confirm
+func (q *DiskQueue) send(record diskQueueRecord) error {
+	if err := q.sink.Write(record.Object, record.EventType, record.Config); err != nil {
+		return err
+	}
+	if flusher, ok := q.sink.(interface{ Flush() error }); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// ack acknowledges the oldest pending record if it is seq, it may have been dropped meanwhile.
+// Once every record is acknowledged the log is truncated, must be called with the lock held.
+func (q *DiskQueue) ack(seq uint64) error {
+	if len(q.pending) == 0 || q.pending[0].Seq != seq {
+		return nil
+	}
+	if err := q.writeAck(seq); err != nil {
+		return err
+	}
+	q.pendingBytes -= q.pending[0].size
+	q.pending = q.pending[1:]
+	q.cond.Broadcast()
+
+	if len(q.pending) == 0 {
+		if err := q.log.Truncate(0); err != nil {
+			return err
+		}
+		if _, err := q.log.Seek(0, 0); err != nil {
+			return err
+		}
+		q.logBytes = 0
+		return nil
+	}
+	if acked := q.logBytes - q.pendingBytes; acked > diskQueueCompactBytes && acked > q.pendingBytes {
+		return q.compact()
+	}
+	return nil
+}
+
+// compact rewrites the log with the pending records only, must be called with the lock held
+func (q *DiskQueue) compact() error {
+	path := filepath.Join(q.dir, diskQueueLogFile)
+	tmp, err := os.OpenFile(path+".tmp", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	var size int64
+	for _, record := range q.pending {
+		line, err := json.Marshal(record)
+		if err != nil {
+			_ = tmp.Close()
+			return err
+		}
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			_ = tmp.Close()
+			return err
+		}
+		size += int64(len(line) + 1)
+	}
+	if err := w.Flush(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	_ = q.log.Close()
+	q.log = tmp
+	q.logBytes = size
+	return nil
+}
+
+func (q *DiskQueue) onError(err error) {
+	if q.opts.OnError != nil {
+		q.opts.OnError(err)
+	}
+}
+
+// Close stops the delivery, the events not acknowledged yet remain on disk
+func (q *DiskQueue) Close() error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	close(q.done)
+	q.cond.Broadcast()
+	q.mu.Unlock()
+
+	q.wg.Wait()
+	return q.log.Close()
+}
@@ -0,0 +1,254 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+)
+
+// queueSink records the names of the objects written to it, failing while err is set
+type queueSink struct {
+	mu        sync.Mutex
+	err       error
+	delivered chan string
+	subjects  []string
+}
+
+func newQueueSink(err error) *queueSink {
+	return &queueSink{err: err, delivered: make(chan string, 10)}
+}
+
+func (s *queueSink) Write(obj model.KubernetesResource, evtype broker.EventType, config config.PipelineConfig) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.subjects = append(s.subjects, config.PublishTo)
+	s.delivered <- obj.KubernetesResourceMeta.Name
+	return nil
+}
+
+func (s *queueSink) succeed() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = nil
+}
+
+// receive waits for count deliveries
+func (s *queueSink) receive(t *testing.T, count int) []string {
+	t.Helper()
+	names := make([]string, 0, count)
+	for len(names) < count {
+		select {
+		case name := <-s.delivered:
+			names = append(names, name)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected %d deliveries, got %v", count, names)
+		}
+	}
+	return names
+}
+
+// assertNoDelivery checks that nothing more is delivered
+func (s *queueSink) assertNoDelivery(t *testing.T) {
+	t.Helper()
+	select {
+	case name := <-s.delivered:
+		t.Errorf("expected no more deliveries, got %s", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+var queuePipeline = config.PipelineConfig{Name: "pods.v1.", PublishTo: "meshery.meshsync.core"}
+
+func TestDiskQueueReplaysUnacknowledgedEvents(t *testing.T) {
+	dir := t.TempDir()
+	unavailable := Retryable(errors.New("broker unavailable"))
+
+	q, err := OpenDiskQueue(dir, newQueueSink(unavailable), DiskQueueOptions{RetryBackoff: time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	for _, name := range []string{"pod-1", "pod-2", "pod-3"} {
+		if err := q.Write(webhookPod(name), broker.Add, queuePipeline); err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+	}
+	if pending := q.Pending(); pending != 3 {
+		t.Errorf("expected 3 pending events, got %d", pending)
+	}
+	// MeshSync restarts
+	if err := q.Close(); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	sink := newQueueSink(nil)
+	q, err = OpenDiskQueue(dir, sink, DiskQueueOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if err := q.Write(webhookPod("pod-4"), broker.Add, queuePipeline); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	expected := []string{"pod-1", "pod-2", "pod-3", "pod-4"}
+	if names := sink.receive(t, 4); !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected the unacknowledged events to be replayed first, got %v", names)
+	}
+	sink.mu.Lock()
+	for _, subject := range sink.subjects {
+		if subject != queuePipeline.PublishTo {
+			t.Errorf("expected the pipeline config to be replayed, got subject %q", subject)
+		}
+	}
+	sink.mu.Unlock()
+	if err := q.Close(); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	// the acknowledged events are not replayed
+	sink = newQueueSink(nil)
+	q, err = OpenDiskQueue(dir, sink, DiskQueueOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	defer q.Close()
+	sink.assertNoDelivery(t)
+}
+
+func TestDiskQueuePersistsKeys(t *testing.T) {
+	dir := t.TempDir()
+	q, err := OpenDiskQueue(dir, newQueueSink(Retryable(errors.New("broker unavailable"))), DiskQueueOptions{RetryBackoff: time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	pipeline := queuePipeline
+	pipeline.KeyFunc = config.KeyFuncNamespacedName
+	if err := q.Write(webhookPod("pod-1"), broker.Add, pipeline); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if err := q.Close(); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, diskQueueLogFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var record diskQueueRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("invalid record %s: %v", data, err)
+	}
+	if record.Key != "default/pod-1" {
+		t.Errorf("expected the record to carry the key of the object, got %q", record.Key)
+	}
+}
+
+func TestDiskQueueDropsNonRetryableEvents(t *testing.T) {
+	sink := newQueueSink(errors.New("object too large"))
+	var mu sync.Mutex
+	var errs []error
+	q, err := OpenDiskQueue(t.TempDir(), sink, DiskQueueOptions{OnError: func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	defer q.Close()
+
+	if err := q.Write(webhookPod("pod-1"), broker.Add, queuePipeline); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for q.Pending() > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if pending := q.Pending(); pending != 0 {
+		t.Errorf("expected the event to be dropped, got %d pending", pending)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(errs) != 1 {
+		t.Errorf("expected the error to be reported once, got %v", errs)
+	}
+}
+
+func TestDiskQueueOverflow(t *testing.T) {
+	// room for two events of the same size
+	line, err := json.Marshal(diskQueueRecord{Seq: 1, EventType: broker.Add, Object: webhookPod("pod-1"), Config: queuePipeline})
+	if err != nil {
+		t.Fatal(err)
+	}
+	maxBytes := int64(len(line)+1)*2 + int64(len(line))/2
+
+	testCases := []struct {
+		overflow   string
+		expectErr  error
+		delivered  []string
+		blockWrite bool
+	}{
+		{overflow: config.QueueOverflowDropNewest, expectErr: ErrQueueFull, delivered: []string{"pod-1", "pod-2"}},
+		{overflow: config.QueueOverflowDropOldest, delivered: []string{"pod-2", "pod-3"}},
+		{overflow: config.QueueOverflowBlock, delivered: []string{"pod-1", "pod-2", "pod-3"}, blockWrite: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.overflow, func(t *testing.T) {
+			dir := t.TempDir()
+			sink := newQueueSink(Retryable(errors.New("broker unavailable")))
+			q, err := OpenDiskQueue(dir, sink, DiskQueueOptions{
+				MaxBytes:     maxBytes,
+				Overflow:     tc.overflow,
+				RetryBackoff: 10 * time.Millisecond,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			defer q.Close()
+
+			for _, name := range []string{"pod-1", "pod-2"} {
+				if err := q.Write(webhookPod(name), broker.Add, queuePipeline); err != nil {
+					t.Fatalf("unexpected error %s", err.Error())
+				}
+			}
+
+			written := make(chan error, 1)
+			go func() {
+				written <- q.Write(webhookPod("pod-3"), broker.Add, queuePipeline)
+			}()
+			if tc.blockWrite {
+				select {
+				case err := <-written:
+					t.Fatalf("expected the write to wait for room, it returned %v", err)
+				case <-time.After(50 * time.Millisecond):
+				}
+				sink.succeed()
+			}
+			select {
+			case err := <-written:
+				if !errors.Is(err, tc.expectErr) {
+					t.Errorf("expected error %v, got %v", tc.expectErr, err)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("expected the write to return")
+			}
+
+			sink.succeed()
+			if names := sink.receive(t, len(tc.delivered)); !reflect.DeepEqual(names, tc.delivered) {
+				t.Errorf("expected %v to be delivered, got %v", tc.delivered, names)
+			}
+			sink.assertNoDelivery(t)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package output
+
+import (
+	"sync"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+)
+
+// Event is what in-process subscribers receive for every written object
+type Event struct {
+	Object    model.KubernetesResource
+	EventType broker.EventType
+	// name of the pipeline (resource) which produced the event
+	Pipeline string
+}
+
+// EventFilter selects the events a subscriber receives, nil selects all
+type EventFilter func(Event) bool
+
+// DefaultFanOutBufferSize is the number of events buffered per subscriber before it is considered stalled
+const DefaultFanOutBufferSize = 256
+
+// FanOutWriter delivers written objects to in-process subscribers.
+// Every subscriber has a buffer of its own, a subscriber not keeping up is evicted and its channel closed
+// once its buffer is full, instead of slowing down the pipelines or other subscribers.
+type FanOutWriter struct {
+	bufferSize int
+
+	mu          sync.RWMutex
+	nextID      int
+	subscribers map[int]*subscriber
+}
+
+type subscriber struct {
+	filter EventFilter
+	ch     chan Event
+	cancel func()
+}
+
+// NewFanOutWriter returns a writer buffering up to bufferSize events per subscriber, see DefaultFanOutBufferSize
+func NewFanOutWriter(bufferSize int) *FanOutWriter {
+	if bufferSize < 1 {
+		bufferSize = DefaultFanOutBufferSize
+	}
+	return &FanOutWriter{
+		bufferSize:  bufferSize,
+		subscribers: make(map[int]*subscriber),
+	}
+}
+
+// Subscribe registers a subscriber receiving the events matching filter,
+// the returned function cancels the subscription and closes the channel
+func (w *FanOutWriter) Subscribe(filter EventFilter) (<-chan Event, func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	id := w.nextID
+	w.nextID++
+	s := &subscriber{
+		filter: filter,
+		ch:     make(chan Event, w.bufferSize),
+	}
+	var once sync.Once
+	s.cancel = func() {
+		once.Do(func() {
+			// no delivery is pending once the lock is held, the channel can be closed
+			w.mu.Lock()
+			delete(w.subscribers, id)
+			w.mu.Unlock()
+			close(s.ch)
+		})
+	}
+	w.subscribers[id] = s
+	return s.ch, s.cancel
+}
+
+func (w *FanOutWriter) Write(
+	obj model.KubernetesResource,
+	evtype broker.EventType,
+	config config.PipelineConfig,
+) error {
+	event := Event{
+		Object:    obj,
+		EventType: config.EmittedEventType(evtype),
+		Pipeline:  config.Name,
+	}
+
+	stalled := make([]*subscriber, 0)
+	w.mu.RLock()
+	for _, s := range w.subscribers {
+		if s.filter != nil && !s.filter(event) {
+			continue
+		}
+		select {
+		case s.ch <- event:
+		default:
+			// the subscriber does not keep up, never block the pipelines on it
+			stalled = append(stalled, s)
+		}
+	}
+	w.mu.RUnlock()
+
+	for _, s := range stalled {
+		s.cancel()
+	}
+	return nil
+}
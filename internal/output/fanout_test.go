@@ -0,0 +1,89 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+)
+
+func TestFanOutWriter(t *testing.T) {
+	w := NewFanOutWriter(0)
+
+	all, cancelAll := w.Subscribe(nil)
+	pods, cancelPods := w.Subscribe(func(e Event) bool { return e.Pipeline == "pods.v1." })
+
+	drain := func(ch <-chan Event) <-chan []Event {
+		result := make(chan []Event, 1)
+		go func() {
+			events := make([]Event, 0)
+			for e := range ch {
+				events = append(events, e)
+			}
+			result <- events
+		}()
+		return result
+	}
+	allReceived := drain(all)
+	podsReceived := drain(pods)
+
+	write := func(pipeline string) {
+		if err := w.Write(model.KubernetesResource{Kind: pipeline}, broker.Add, config.PipelineConfig{Name: pipeline}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("pods.v1.")
+	write("services.v1.")
+	write("pods.v1.")
+
+	cancelPods()
+	// cancelled subscriber does not receive further events, and does not block the others
+	write("pods.v1.")
+	cancelAll()
+
+	podEvents := <-podsReceived
+	if len(podEvents) != 2 {
+		t.Errorf("expected filtered subscriber to receive 2 events, got %d", len(podEvents))
+	}
+	for _, e := range podEvents {
+		if e.Pipeline != "pods.v1." || e.EventType != broker.Add {
+			t.Errorf("unexpected event %+v", e)
+		}
+	}
+
+	if allEvents := <-allReceived; len(allEvents) != 4 {
+		t.Errorf("expected unfiltered subscriber to receive 4 events, got %d", len(allEvents))
+	}
+}
+
+func TestFanOutWriterEvictsStalledSubscriber(t *testing.T) {
+	w := NewFanOutWriter(2)
+	stalled, cancelStalled := w.Subscribe(nil)
+	defer cancelStalled()
+	live, cancelLive := w.Subscribe(nil)
+	defer cancelLive()
+
+	for i := 0; i < 3; i++ {
+		if err := w.Write(model.KubernetesResource{Kind: "Pod"}, broker.Add, config.PipelineConfig{Name: "pods.v1."}); err != nil {
+			t.Fatal(err)
+		}
+		// the live subscriber keeps up
+		<-live
+	}
+
+	received := 0
+	for range stalled {
+		received++
+	}
+	if received != 2 {
+		t.Errorf("expected the stalled subscriber to receive its 2 buffered events before its channel closes, got %d", received)
+	}
+	if err := w.Write(model.KubernetesResource{Kind: "Pod"}, broker.Add, config.PipelineConfig{Name: "pods.v1."}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-live; !ok {
+		t.Error("expected the live subscriber to keep its subscription")
+	}
+}
@@ -0,0 +1,107 @@
+package output
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// sinkInflightBytes is the size of the events written to each sink which it has not delivered yet
+var sinkInflightBytes = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "meshsync_sink_inflight_bytes",
+	Help: "Bytes of serialized events written to the sink which it has not delivered yet, by sink.",
+}, []string{"sink"})
+
+// AckingWriter is a sink returning from Write before the event is delivered, f.e. a batching one.
+// WriteAcked writes the event from its JSON encoding data, so the sink does not encode the object again,
+// and calls ack once the event is delivered or dropped, or if WriteAcked fails.
+type AckingWriter interface {
+	Writer
+	WriteAcked(obj model.KubernetesResource, data json.RawMessage, evtype broker.EventType, config config.PipelineConfig, ack func()) error
+}
+
+// InflightLimiter bounds the bytes of the events in flight to a sink: pipelines writing concurrently
+// are blocked while the serialized size of the events the sink has not delivered yet would exceed
+// the limit. An AckingWriter holds the bytes until it acknowledges the event, any other sink
+// until it returns from Write. This bounds the memory large objects take, where a bound on the number of events would not.
+// An event larger than the limit is written once no other event is in flight.
+// Control events are small and passed through.
+type InflightLimiter struct {
+	sink     Writer
+	maxBytes int64
+	gauge    prometheus.Gauge
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	inflight int64
+}
+
+// NewInflightLimiter returns a writer limiting the in-flight bytes of the sink,
+// name labels its meshsync_sink_inflight_bytes metric
+func NewInflightLimiter(sink Writer, name string, maxBytes int64) *InflightLimiter {
+	l := &InflightLimiter{
+		sink:     sink,
+		maxBytes: maxBytes,
+		gauge:    sinkInflightBytes.WithLabelValues(name),
+	}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+func (l *InflightLimiter) Write(
+	obj model.KubernetesResource,
+	evtype broker.EventType,
+	config config.PipelineConfig,
+) error {
+	// JSON is what the sinks serialize objects to
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	size := int64(len(data))
+
+	l.acquire(size)
+	if sink, ok := l.sink.(AckingWriter); ok {
+		var once sync.Once
+		return sink.WriteAcked(obj, data, evtype, config, func() {
+			once.Do(func() { l.release(size) })
+		})
+	}
+	defer l.release(size)
+	return l.sink.Write(obj, evtype, config)
+}
+
+func (l *InflightLimiter) WriteControl(event ControlEvent) error {
+	return WriteControl(l.sink, event)
+}
+
+// InflightBytes returns the bytes of the events in flight to the sink
+func (l *InflightLimiter) InflightBytes() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inflight
+}
+
+// acquire waits until size more bytes fit within the limit
+func (l *InflightLimiter) acquire(size int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.inflight > 0 && l.inflight+size > l.maxBytes {
+		l.cond.Wait()
+	}
+	l.inflight += size
+	l.gauge.Add(float64(size))
+}
+
+func (l *InflightLimiter) release(size int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inflight -= size
+	l.gauge.Sub(float64(size))
+	l.cond.Broadcast()
+}
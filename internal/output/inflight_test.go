@@ -0,0 +1,173 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// gatedSink holds every write until it is released
+type gatedSink struct {
+	entered chan string
+	release chan struct{}
+}
+
+func (s *gatedSink) Write(obj model.KubernetesResource, evtype broker.EventType, config config.PipelineConfig) error {
+	s.entered <- obj.KubernetesResourceMeta.Name
+	<-s.release
+	return nil
+}
+
+func largeObject(name string, size int) model.KubernetesResource {
+	return model.KubernetesResource{
+		Kind:                   "ConfigMap",
+		KubernetesResourceMeta: &model.KubernetesResourceObjectMeta{Name: name},
+		Data:                   strings.Repeat("x", size),
+	}
+}
+
+func TestInflightLimiterGatesLargePayloads(t *testing.T) {
+	sink := &gatedSink{entered: make(chan string, 10), release: make(chan struct{})}
+	size := func(obj model.KubernetesResource) int64 {
+		data, _ := json.Marshal(obj)
+		return int64(len(data))
+	}
+	objects := []model.KubernetesResource{largeObject("cm-1", 64<<10), largeObject("cm-2", 64<<10), largeObject("cm-3", 64<<10)}
+	// room for two of the objects
+	limiter := NewInflightLimiter(sink, "test-gates", 2*size(objects[0])+1)
+
+	var wg sync.WaitGroup
+	for _, obj := range objects {
+		wg.Add(1)
+		go func(obj model.KubernetesResource) {
+			defer wg.Done()
+			if err := limiter.Write(obj, broker.Add, config.PipelineConfig{Name: "configmaps.v1."}); err != nil {
+				t.Errorf("unexpected error %s", err.Error())
+			}
+		}(obj)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-sink.entered:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected 2 writes to be in flight, got %d", i)
+		}
+	}
+	select {
+	case name := <-sink.entered:
+		t.Errorf("expected %s to wait for room within the limit", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+	expected := 2 * size(objects[0])
+	if inflight := limiter.InflightBytes(); inflight != expected {
+		t.Errorf("expected %d bytes in flight, got %d", expected, inflight)
+	}
+	if metric := testutil.ToFloat64(sinkInflightBytes.WithLabelValues("test-gates")); metric != float64(expected) {
+		t.Errorf("expected the metric to report %d bytes in flight, got %f", expected, metric)
+	}
+
+	// a returning write makes room for the waiting one
+	sink.release <- struct{}{}
+	select {
+	case <-sink.entered:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the waiting write to be let through")
+	}
+	close(sink.release)
+	wg.Wait()
+
+	if inflight := limiter.InflightBytes(); inflight != 0 {
+		t.Errorf("expected no bytes in flight, got %d", inflight)
+	}
+	if metric := testutil.ToFloat64(sinkInflightBytes.WithLabelValues("test-gates")); metric != 0 {
+		t.Errorf("expected the metric to report no bytes in flight, got %f", metric)
+	}
+}
+
+func TestInflightLimiterLetsOversizedEventsThroughAlone(t *testing.T) {
+	sink := &gatedSink{entered: make(chan string, 10), release: make(chan struct{})}
+	limiter := NewInflightLimiter(sink, "test-oversized", 1<<10)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = limiter.Write(largeObject("cm-large", 8<<10), broker.Add, config.PipelineConfig{Name: "configmaps.v1."})
+	}()
+	select {
+	case <-sink.entered:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the event larger than the limit to be written while nothing else is in flight")
+	}
+
+	go func() {
+		_ = limiter.Write(largeObject("cm-small", 10), broker.Add, config.PipelineConfig{Name: "configmaps.v1."})
+	}()
+	select {
+	case name := <-sink.entered:
+		t.Errorf("expected %s to wait for the oversized event", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(sink.release)
+	<-done
+	select {
+	case <-sink.entered:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the waiting write to be let through")
+	}
+}
+
+func TestInflightLimiterHoldsBytesUntilWebhookPosts(t *testing.T) {
+	release := make(chan struct{})
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		recorder.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sink := NewWebhookSink(ctx, server.URL, WebhookOptions{})
+	limiter := NewInflightLimiter(sink, "test-webhook", 1<<20)
+
+	obj := largeObject("cm-1", 1<<10)
+	data, _ := json.Marshal(obj)
+	if err := limiter.Write(obj, broker.Add, config.PipelineConfig{Name: "configmaps.v1."}); err != nil {
+		t.Fatal(err)
+	}
+	// the sink returned, but the event is not posted yet
+	if inflight := limiter.InflightBytes(); inflight != int64(len(data)) {
+		t.Errorf("expected %d bytes in flight until the event is posted, got %d", len(data), inflight)
+	}
+
+	close(release)
+	if err := sink.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if inflight := limiter.InflightBytes(); inflight != 0 {
+		t.Errorf("expected no bytes in flight once the event is posted, got %d", inflight)
+	}
+	payloads := recorder.payloads(t)
+	if len(payloads) != 1 || len(payloads[0].Events) != 1 {
+		t.Fatalf("expected a single event to be posted, got %+v", payloads)
+	}
+	posted, _ := json.Marshal(payloads[0].Events[0].Object)
+	var expected interface{}
+	_ = json.Unmarshal(data, &expected)
+	want, _ := json.Marshal(expected)
+	if string(posted) != string(want) {
+		t.Errorf("expected the encoded object to be posted, got %s", posted)
+	}
+}
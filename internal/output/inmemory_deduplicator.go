@@ -39,10 +39,9 @@ func (w *InMemoryDeduplicatorWriter) Write(
 		uid = obj.KubernetesResourceMeta.UID
 	}
 
-	entity := &inMemoryDeduplicatorContainer{
-		obj:    obj,
-		evtype: evtype,
-		config: config,
+	entity, err := newInMemoryDeduplicatorContainer(obj, evtype, config)
+	if err != nil {
+		return err
 	}
 
 	if uid != "" {
@@ -58,13 +57,13 @@ func (w *InMemoryDeduplicatorWriter) Flush() error {
 	errs := make([]error, 0, len(w.storage)+len(w.storageIfNoMetaUid))
 
 	for _, v := range w.storage {
-		if err := w.realWritter.Write(v.obj, v.evtype, v.config); err != nil {
+		if err := v.writeTo(w.realWritter); err != nil {
 			errs = append(errs, err)
 		}
 	}
 
 	for _, v := range w.storageIfNoMetaUid {
-		if err := w.realWritter.Write(v.obj, v.evtype, v.config); err != nil {
+		if err := v.writeTo(w.realWritter); err != nil {
 			errs = append(errs, err)
 		}
 	}
@@ -80,4 +79,41 @@ type inMemoryDeduplicatorContainer struct {
 	obj    model.KubernetesResource
 	evtype broker.EventType
 	config config.PipelineConfig
+	// the compressed object, used instead of obj when the pipeline compresses its cache
+	compressed []byte
+}
+
+func newInMemoryDeduplicatorContainer(
+	obj model.KubernetesResource,
+	evtype broker.EventType,
+	config config.PipelineConfig,
+) (*inMemoryDeduplicatorContainer, error) {
+	entity := &inMemoryDeduplicatorContainer{
+		evtype: evtype,
+		config: config,
+	}
+	if !config.CompressCache {
+		entity.obj = obj
+		return entity, nil
+	}
+
+	compressed, err := compressObject(obj)
+	if err != nil {
+		return nil, err
+	}
+	entity.compressed = compressed
+	return entity, nil
+}
+
+// writeTo writes the full object
+func (c *inMemoryDeduplicatorContainer) writeTo(w Writer) error {
+	obj := c.obj
+	if c.compressed != nil {
+		var err error
+		obj, err = decompressObject(c.compressed)
+		if err != nil {
+			return err
+		}
+	}
+	return w.Write(obj, c.evtype, c.config)
 }
@@ -0,0 +1,99 @@
+package output
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+)
+
+func TestInMemoryDeduplicatorKeysByUID(t *testing.T) {
+	br := &recordingBroker{}
+	w := NewInMemoryDeduplicatorWriter(NewBrokerWriter(br))
+	pc := config.PipelineConfig{Name: "pods.v1.", PublishTo: "meshery.meshsync.core", KeyFunc: config.KeyFuncLabelPrefix + "app"}
+	pod := func(name, version string) model.KubernetesResource {
+		return model.KubernetesResource{
+			Kind: "Pod",
+			KubernetesResourceMeta: &model.KubernetesResourceObjectMeta{
+				Name:            name,
+				UID:             name,
+				ResourceVersion: version,
+				Labels:          []*model.KubernetesKeyValue{{Key: "app", Value: "web"}},
+			},
+		}
+	}
+
+	for _, obj := range []model.KubernetesResource{pod("web-a", "1"), pod("web-b", "1"), pod("web-a", "2")} {
+		if err := w.Write(obj, broker.Update, pc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the objects sharing the key of their messages are kept apart
+	versions := make(map[string]string)
+	for i, message := range br.messages {
+		obj := message.Object.(model.KubernetesResource)
+		versions[obj.KubernetesResourceMeta.Name] = obj.KubernetesResourceMeta.ResourceVersion
+		if br.subjects[i] != "meshery.meshsync.core.web" {
+			t.Errorf("expected the subject keyed by the label, got %s", br.subjects[i])
+		}
+	}
+	if expected := map[string]string{"web-a": "2", "web-b": "1"}; !reflect.DeepEqual(versions, expected) {
+		t.Errorf("expected the latest version of each object, got %v", versions)
+	}
+}
+
+func TestInMemoryDeduplicatorCompressedCache(t *testing.T) {
+	crd := func(uid, version string) model.KubernetesResource {
+		return model.KubernetesResource{
+			APIVersion: "apiextensions.k8s.io/v1",
+			Kind:       "CustomResourceDefinition",
+			KubernetesResourceMeta: &model.KubernetesResourceObjectMeta{
+				Name:            uid,
+				UID:             uid,
+				ResourceVersion: version,
+			},
+			Spec: &model.KubernetesResourceSpec{Attribute: strings.Repeat(`{"type":"object"}`, 1000)},
+		}
+	}
+
+	for _, compress := range []bool{false, true} {
+		recorder := &pipelineRecorder{}
+		w := NewInMemoryDeduplicatorWriter(recorder)
+		pc := config.PipelineConfig{Name: "customresourcedefinitions.v1.apiextensions.k8s.io", CompressCache: compress}
+
+		for _, obj := range []model.KubernetesResource{crd("a", "1"), crd("b", "1"), crd("a", "2")} {
+			if err := w.Write(obj, broker.Update, pc); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if compress {
+			stored := w.storage["a"]
+			if stored.compressed == nil || stored.obj.KubernetesResourceMeta != nil {
+				t.Error("expected only the compressed object to be kept")
+			}
+			if len(stored.compressed) >= len(crd("a", "2").Spec.Attribute) {
+				t.Errorf("expected compressed object smaller than its spec, got %d bytes", len(stored.compressed))
+			}
+		}
+
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		sort.Slice(recorder.objects, func(i, j int) bool {
+			return recorder.objects[i].KubernetesResourceMeta.UID < recorder.objects[j].KubernetesResourceMeta.UID
+		})
+		expected := []model.KubernetesResource{crd("a", "2"), crd("b", "1")}
+		if !reflect.DeepEqual(recorder.objects, expected) {
+			t.Errorf("compress %t: expected the latest version of each object in full, got %+v", compress, recorder.objects)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package output
+
+import (
+	"strings"
+
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+)
+
+// KeyFunc derives the key sinks put on the messages of an object, f.e. the last token of the broker subject,
+// empty string means the object has no key. Keys need not be unique, unlike UIDs several objects may share one.
+type KeyFunc func(obj model.KubernetesResource) string
+
+func UIDKeyFunc(obj model.KubernetesResource) string {
+	if obj.KubernetesResourceMeta == nil {
+		return ""
+	}
+	return obj.KubernetesResourceMeta.UID
+}
+
+func NamespacedNameKeyFunc(obj model.KubernetesResource) string {
+	if obj.KubernetesResourceMeta == nil || obj.KubernetesResourceMeta.Name == "" {
+		return ""
+	}
+	if obj.KubernetesResourceMeta.Namespace == "" {
+		return obj.KubernetesResourceMeta.Name
+	}
+	return obj.KubernetesResourceMeta.Namespace + "/" + obj.KubernetesResourceMeta.Name
+}
+
+// LabelKeyFunc keys objects by the value of the label,
+// objects without the label are keyed by fallback
+func LabelKeyFunc(label string, fallback KeyFunc) KeyFunc {
+	return func(obj model.KubernetesResource) string {
+		if obj.KubernetesResourceMeta != nil {
+			for _, l := range obj.KubernetesResourceMeta.Labels {
+				if l != nil && l.Key == label && l.Value != "" {
+					return l.Value
+				}
+			}
+		}
+		if fallback == nil {
+			return ""
+		}
+		return fallback(obj)
+	}
+}
+
+// KeyFuncFor returns the key function configured for the pipeline, defaults to UIDKeyFunc.
+// Specs are validated when the config is loaded, unknown ones fall back to the default.
+func KeyFuncFor(pc config.PipelineConfig) KeyFunc {
+	return keyFuncForSpec(pc.KeyFunc, keyFuncForSpec(pc.KeyFuncFallback, UIDKeyFunc))
+}
+
+func keyFuncForSpec(spec string, fallback KeyFunc) KeyFunc {
+	switch {
+	case spec == config.KeyFuncUID:
+		return UIDKeyFunc
+	case spec == config.KeyFuncNamespacedName:
+		return NamespacedNameKeyFunc
+	case strings.HasPrefix(spec, config.KeyFuncLabelPrefix):
+		return LabelKeyFunc(strings.TrimPrefix(spec, config.KeyFuncLabelPrefix), fallback)
+	}
+	return fallback
+}
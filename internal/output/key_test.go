@@ -0,0 +1,56 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+)
+
+func TestKeyFuncFor(t *testing.T) {
+	labeled := model.KubernetesResource{
+		KubernetesResourceMeta: &model.KubernetesResourceObjectMeta{
+			UID:       "uid-1",
+			Name:      "web",
+			Namespace: "default",
+			Labels: []*model.KubernetesKeyValue{
+				{Kind: model.KindLabel, Key: "app.kubernetes.io/instance", Value: "web-1"},
+			},
+		},
+	}
+	unlabeled := model.KubernetesResource{
+		KubernetesResourceMeta: &model.KubernetesResourceObjectMeta{
+			UID:  "uid-2",
+			Name: "node-1",
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		config   config.PipelineConfig
+		obj      model.KubernetesResource
+		expected string
+	}{
+		{name: "default is uid", config: config.PipelineConfig{}, obj: labeled, expected: "uid-1"},
+		{name: "uid", config: config.PipelineConfig{KeyFunc: config.KeyFuncUID}, obj: labeled, expected: "uid-1"},
+		{name: "namespace/name", config: config.PipelineConfig{KeyFunc: config.KeyFuncNamespacedName}, obj: labeled, expected: "default/web"},
+		{name: "namespace/name of cluster scoped object", config: config.PipelineConfig{KeyFunc: config.KeyFuncNamespacedName}, obj: unlabeled, expected: "node-1"},
+		{name: "label", config: config.PipelineConfig{KeyFunc: "label:app.kubernetes.io/instance"}, obj: labeled, expected: "web-1"},
+		{name: "missing label falls back to uid", config: config.PipelineConfig{KeyFunc: "label:app.kubernetes.io/instance"}, obj: unlabeled, expected: "uid-2"},
+		{
+			name:     "missing label uses configured fallback",
+			config:   config.PipelineConfig{KeyFunc: "label:app.kubernetes.io/instance", KeyFuncFallback: config.KeyFuncNamespacedName},
+			obj:      unlabeled,
+			expected: "node-1",
+		},
+		{name: "no metadata", config: config.PipelineConfig{}, obj: model.KubernetesResource{}, expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if key := KeyFuncFor(tc.config)(tc.obj); key != tc.expected {
+				t.Errorf("expected key %q, got %q", tc.expected, key)
+			}
+		})
+	}
+}
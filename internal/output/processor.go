@@ -4,6 +4,7 @@ import (
 	"github.com/meshery/meshkit/broker"
 	"github.com/meshery/meshsync/internal/config"
 	"github.com/meshery/meshsync/pkg/model"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Processor struct {
@@ -18,6 +19,30 @@ func (p *Processor) SetOutput(output Writer) {
 	p.output = output
 }
 
+// AddOutput makes the processor write to output in addition to the already set one
+func (p *Processor) AddOutput(output Writer) {
+	if p.output == nil {
+		p.output = output
+		return
+	}
+	p.output = NewCompositeWriter(p.output, output)
+}
+
+// RouteToSinks makes the processor write the objects of pipelines with their own sink
+// to the writer of that sink, the already set output remains the fallback
+func (p *Processor) RouteToSinks(sinks map[string]Writer) {
+	if len(sinks) == 0 {
+		return
+	}
+	p.output = NewRoutingWriter(p.output, sinks)
+}
+
+// TraceWith makes the processor create a span per emitted event with the given provider
+// and propagate the trace context to consumers, see TracingWriter
+func (p *Processor) TraceWith(tp trace.TracerProvider) {
+	p.output = NewTracingWriter(p.output, tp)
+}
+
 func (p *Processor) Write(
 	obj model.KubernetesResource,
 	evtype broker.EventType,
@@ -0,0 +1,130 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+)
+
+// DefaultReplaySortWindow is the number of events a Replayer sorting by resourceVersion buffers
+const DefaultReplaySortWindow = 1000
+
+// ReplayRecord is an event of a recorded stream, one JSON record per line,
+// the records of the log of a disk-backed queue can be replayed too
+type ReplayRecord struct {
+	EventType broker.EventType         `json:"event_type"`
+	Object    model.KubernetesResource `json:"object"`
+	Config    config.PipelineConfig    `json:"config"`
+}
+
+// ReplayOptions configure a Replayer, the zero value replays the events as recorded
+type ReplayOptions struct {
+	// orders the events of every object by resourceVersion, f.e. to fix a capture recorded out of order
+	SortByResourceVersion bool
+	// events buffered for sorting, an event recorded further than this from its place is replayed
+	// out of order, DefaultReplaySortWindow if zero
+	SortWindow int
+}
+
+// Replayer writes the events of a recorded NDJSON stream to a sink
+type Replayer struct {
+	sink Writer
+	opts ReplayOptions
+}
+
+func NewReplayer(sink Writer, opts ReplayOptions) *Replayer {
+	if opts.SortWindow <= 0 {
+		opts.SortWindow = DefaultReplaySortWindow
+	}
+	return &Replayer{sink: sink, opts: opts}
+}
+
+// Replay writes the events of the recording to the sink and returns the number of events written.
+// When sorting, the events of every object are ordered by (UID, resourceVersion) within the window,
+// see nextReplayed.
+func (r *Replayer) Replay(recording io.Reader) (int, error) {
+	window := 1
+	if r.opts.SortByResourceVersion {
+		window = r.opts.SortWindow
+	}
+
+	buffered := make([]ReplayRecord, 0, window)
+	replayed := 0
+	// replay writes the buffered events until keep are left
+	replay := func(keep int) error {
+		for len(buffered) > keep {
+			// the oldest event takes the place of the one replayed instead, both belong to the same object
+			i := nextReplayed(buffered)
+			record := buffered[i]
+			buffered[i] = buffered[0]
+			buffered = buffered[1:]
+			if err := r.sink.Write(record.Object, record.EventType, record.Config); err != nil {
+				return err
+			}
+			replayed++
+		}
+		return nil
+	}
+
+	reader := bufio.NewReader(recording)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return replayed, err
+		}
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			record := ReplayRecord{}
+			if errDecode := json.Unmarshal(data, &record); errDecode != nil {
+				return replayed, fmt.Errorf("corrupt record at line %d of the recording: %w", line, errDecode)
+			}
+			buffered = append(buffered, record)
+			if errReplay := replay(window - 1); errReplay != nil {
+				return replayed, errReplay
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+	return replayed, replay(0)
+}
+
+// nextReplayed returns the index of the buffered event to replay next. The object of the oldest event
+// goes first, with its buffered event of the oldest resourceVersion, so the events of an object take
+// the places its events were recorded at. Events without a UID or a numeric resourceVersion keep their order.
+func nextReplayed(records []ReplayRecord) int {
+	uid := uidOf(records[0].Object)
+	if uid == "" {
+		return 0
+	}
+	next := 0
+	for i := 1; i < len(records); i++ {
+		if uidOf(records[i].Object) == uid && resourceVersionBefore(records[i].Object, records[next].Object) {
+			next = i
+		}
+	}
+	return next
+}
+
+func uidOf(obj model.KubernetesResource) string {
+	if obj.KubernetesResourceMeta == nil {
+		return ""
+	}
+	return obj.KubernetesResourceMeta.UID
+}
+
+// resourceVersionBefore reports whether a carries an older resourceVersion than b,
+// the versions are opaque to clients but numeric on etcd-backed API servers
+func resourceVersionBefore(a, b model.KubernetesResource) bool {
+	va, errA := strconv.ParseUint(a.KubernetesResourceMeta.ResourceVersion, 10, 64)
+	vb, errB := strconv.ParseUint(b.KubernetesResourceMeta.ResourceVersion, 10, 64)
+	return errA == nil && errB == nil && va < vb
+}
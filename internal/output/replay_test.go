@@ -0,0 +1,108 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+)
+
+// shuffledRecording records versions 1 to versions of every object, shuffled
+func shuffledRecording(t *testing.T, uids []string, versions int) ([]ReplayRecord, *bytes.Buffer) {
+	t.Helper()
+	records := make([]ReplayRecord, 0, len(uids)*versions)
+	for _, uid := range uids {
+		for version := 1; version <= versions; version++ {
+			records = append(records, ReplayRecord{
+				EventType: broker.Update,
+				Object: model.KubernetesResource{KubernetesResourceMeta: &model.KubernetesResourceObjectMeta{
+					Name:            uid,
+					UID:             uid,
+					ResourceVersion: strconv.Itoa(version),
+				}},
+				Config: config.PipelineConfig{Name: "pods.v1."},
+			})
+		}
+	}
+	rand.New(rand.NewSource(1)).Shuffle(len(records), func(i, j int) {
+		records[i], records[j] = records[j], records[i]
+	})
+
+	recording := &bytes.Buffer{}
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recording.Write(append(line, '\n'))
+	}
+	return records, recording
+}
+
+// versionsOf returns the replayed resourceVersions of every object, in the order they were replayed
+func versionsOf(objects []model.KubernetesResource) map[string][]string {
+	versions := make(map[string][]string)
+	for _, obj := range objects {
+		versions[obj.KubernetesResourceMeta.UID] = append(versions[obj.KubernetesResourceMeta.UID], obj.KubernetesResourceMeta.ResourceVersion)
+	}
+	return versions
+}
+
+func TestReplayerSortsByResourceVersion(t *testing.T) {
+	uids := []string{"pod-a", "pod-b", "pod-c"}
+	records, recording := shuffledRecording(t, uids, 5)
+
+	sink := &pipelineRecorder{}
+	replayed, err := NewReplayer(sink, ReplayOptions{SortByResourceVersion: true, SortWindow: len(records)}).Replay(recording)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if replayed != len(records) {
+		t.Fatalf("expected %d events to be replayed, got %d", len(records), replayed)
+	}
+	expected := []string{"1", "2", "3", "4", "5"}
+	for uid, versions := range versionsOf(sink.objects) {
+		if !reflect.DeepEqual(versions, expected) {
+			t.Errorf("expected the versions %v of %s, got %v", expected, uid, versions)
+		}
+	}
+	// the events of an object take the places its events were recorded at
+	for i, obj := range sink.objects {
+		if obj.KubernetesResourceMeta.UID != records[i].Object.KubernetesResourceMeta.UID {
+			t.Errorf("expected an event of %s at %d, got %s", records[i].Object.KubernetesResourceMeta.UID, i, obj.KubernetesResourceMeta.UID)
+		}
+	}
+}
+
+func TestReplayerReplaysAsRecorded(t *testing.T) {
+	records, recording := shuffledRecording(t, []string{"pod-a", "pod-b"}, 5)
+
+	sink := &pipelineRecorder{}
+	if _, err := NewReplayer(sink, ReplayOptions{}).Replay(recording); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	recorded := make([]model.KubernetesResource, 0, len(records))
+	for _, record := range records {
+		recorded = append(recorded, record.Object)
+	}
+	if !reflect.DeepEqual(sink.objects, recorded) {
+		t.Errorf("expected the events as recorded, got %v", versionsOf(sink.objects))
+	}
+}
+
+func TestReplayerRejectsCorruptRecords(t *testing.T) {
+	sink := &pipelineRecorder{}
+	replayed, err := NewReplayer(sink, ReplayOptions{}).Replay(bytes.NewBufferString("{\"event_type\":\"ADDED\",\"object\":{}}\n{\"event_type\":"))
+	if err == nil {
+		t.Fatal("expected the corrupt record to be rejected")
+	}
+	if replayed != 1 {
+		t.Errorf("expected the record before the corrupt one to be replayed, got %d", replayed)
+	}
+}
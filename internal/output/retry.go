@@ -0,0 +1,40 @@
+package output
+
+import (
+	"errors"
+)
+
+// retryableError marks an error as transient
+type retryableError struct {
+	err error
+}
+
+func (e retryableError) Error() string   { return e.err.Error() }
+func (e retryableError) Unwrap() error   { return e.err }
+func (e retryableError) Retryable() bool { return true }
+
+// Retryable marks err as transient: the event may be written successfully when processed again,
+// f.e. while the broker is unavailable. Writers and transformers may as well return errors
+// implementing Retryable() bool themselves, any other error drops the event.
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return retryableError{err: err}
+}
+
+// IsRetryable reports whether err or an error it wraps implements Retryable() bool returning true.
+// Joined errors, f.e. of several writers, are retryable only if every one of them is.
+func IsRetryable(err error) bool {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs := joined.Unwrap()
+		for _, e := range errs {
+			if !IsRetryable(e) {
+				return false
+			}
+		}
+		return len(errs) > 0
+	}
+	var r interface{ Retryable() bool }
+	return errors.As(err, &r) && r.Retryable()
+}
@@ -0,0 +1,34 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIsRetryable(t *testing.T) {
+	transient := Retryable(errors.New("broker unavailable"))
+	permanent := errors.New("object too large")
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "retryable", err: transient, expected: true},
+		{name: "wrapped retryable", err: fmt.Errorf("writing: %w", transient), expected: true},
+		{name: "not retryable", err: permanent, expected: false},
+		{name: "all joined retryable", err: errors.Join(transient, Retryable(errors.New("timeout"))), expected: true},
+		{name: "some joined not retryable", err: errors.Join(transient, permanent), expected: false},
+		{name: "nil", err: nil, expected: false},
+	}
+
+	for _, tc := range testCases {
+		if retryable := IsRetryable(tc.err); retryable != tc.expected {
+			t.Errorf("%s: expected retryable %t, got %t", tc.name, tc.expected, retryable)
+		}
+	}
+	if Retryable(nil) != nil {
+		t.Error("expected nil to stay nil")
+	}
+}
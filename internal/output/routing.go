@@ -0,0 +1,53 @@
+package output
+
+import (
+	"errors"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+)
+
+// RoutingWriter writes the objects of pipelines which configure their own sink
+// to the writer opened for that sink, all other objects to the fallback writer
+type RoutingWriter struct {
+	fallback Writer
+	sinks    map[string]Writer
+}
+
+// NewRoutingWriter returns a writer routing by the pipeline sink URI, sinks are keyed by their URI
+func NewRoutingWriter(fallback Writer, sinks map[string]Writer) *RoutingWriter {
+	return &RoutingWriter{
+		fallback: fallback,
+		sinks:    sinks,
+	}
+}
+
+func (w *RoutingWriter) Write(
+	obj model.KubernetesResource,
+	evtype broker.EventType,
+	config config.PipelineConfig,
+) error {
+	return w.writerFor(config).Write(obj, evtype, config)
+}
+
+func (w *RoutingWriter) writerFor(config config.PipelineConfig) Writer {
+	if sink, ok := w.sinks[config.Sink]; ok && config.Sink != "" {
+		return sink
+	}
+	return w.fallback
+}
+
+// WriteControl delivers control events to every sink, consumers of each of them track sync progress
+func (w *RoutingWriter) WriteControl(event ControlEvent) error {
+	errs := make([]error, 0, len(w.sinks)+1)
+	if err := WriteControl(w.fallback, event); err != nil {
+		errs = append(errs, err)
+	}
+	for _, sink := range w.sinks {
+		if err := WriteControl(sink, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
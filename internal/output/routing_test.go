@@ -0,0 +1,71 @@
+package output
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+)
+
+// pipelineRecorder records the objects, their pipelines and the control events written to it
+type pipelineRecorder struct {
+	objects   []model.KubernetesResource
+	pipelines []string
+	controls  []broker.EventType
+}
+
+func (r *pipelineRecorder) Write(obj model.KubernetesResource, evtype broker.EventType, config config.PipelineConfig) error {
+	r.objects = append(r.objects, obj)
+	r.pipelines = append(r.pipelines, config.Name)
+	return nil
+}
+
+func (r *pipelineRecorder) WriteControl(event ControlEvent) error {
+	r.controls = append(r.controls, event.Type)
+	return nil
+}
+
+func TestRoutingWriter(t *testing.T) {
+	global := &pipelineRecorder{}
+	nats := &pipelineRecorder{}
+	fileSink := &pipelineRecorder{}
+	w := NewRoutingWriter(global, map[string]Writer{
+		"nats://team-a:4222":      nats,
+		"file:///tmp/events.yaml": fileSink,
+	})
+
+	pipelines := []config.PipelineConfig{
+		{Name: "deployments.v1.apps", Sink: "nats://team-a:4222"},
+		{Name: "events.v1.", Sink: "file:///tmp/events.yaml"},
+		{Name: "pods.v1."},
+		// not opened, f.e. added at runtime
+		{Name: "services.v1.", Sink: "nats://team-b:4222"},
+	}
+	for _, pc := range pipelines {
+		if err := w.Write(model.KubernetesResource{}, broker.Add, pc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := map[*pipelineRecorder][]string{
+		nats:     {"deployments.v1.apps"},
+		fileSink: {"events.v1."},
+		global:   {"pods.v1.", "services.v1."},
+	}
+	for recorder, pipelines := range expected {
+		if !reflect.DeepEqual(recorder.pipelines, pipelines) {
+			t.Errorf("expected pipelines %v, got %v", pipelines, recorder.pipelines)
+		}
+	}
+
+	if err := WriteControl(w, NewControlEvent(PipelineSyncedEvent, "pods.v1.", 1)); err != nil {
+		t.Fatal(err)
+	}
+	for _, recorder := range []*pipelineRecorder{global, nats, fileSink} {
+		if len(recorder.controls) != 1 {
+			t.Errorf("expected the control event to reach every sink, got %d", len(recorder.controls))
+		}
+	}
+}
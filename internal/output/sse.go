@@ -0,0 +1,217 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+	"golang.org/x/exp/slices"
+)
+
+// DefaultSSEBufferSize is the number of events buffered per client before it is considered stalled
+const DefaultSSEBufferSize = 256
+
+// SSEEvent is the compact form of an event streamed to clients, the event type is the name of the server-sent event
+type SSEEvent struct {
+	Resource  string                    `json:"resource"`
+	Namespace string                    `json:"namespace,omitempty"`
+	Name      string                    `json:"name"`
+	UID       string                    `json:"uid,omitempty"`
+	Object    *model.KubernetesResource `json:"object,omitempty"`
+}
+
+// SSEWriter streams the written objects as server-sent events to the connected HTTP clients.
+// Clients select events with the repeatable query params "resource" (pipeline name, f.e. "pods.v1.")
+// and "namespace". Every client has a buffer of its own, a client not keeping up is disconnected
+// once its buffer is full instead of slowing down the pipelines or other clients.
+type SSEWriter struct {
+	bufferSize int
+
+	mu      sync.RWMutex
+	nextID  int
+	clients map[int]*sseClient
+}
+
+type sseClient struct {
+	resources  []string
+	namespaces []string
+	messages   chan []byte
+	// closed when the client is disconnected for being stalled
+	stalled chan struct{}
+	once    sync.Once
+	// aborts a write blocked on the stalled client
+	abort func()
+}
+
+// disconnect makes ServeHTTP return, even if it is blocked writing to the client
+func (c *sseClient) disconnect() {
+	c.once.Do(func() {
+		close(c.stalled)
+		c.abort()
+	})
+}
+
+// NewSSEWriter returns a writer buffering up to bufferSize events per client, see DefaultSSEBufferSize
+func NewSSEWriter(bufferSize int) *SSEWriter {
+	if bufferSize < 1 {
+		bufferSize = DefaultSSEBufferSize
+	}
+	return &SSEWriter{
+		bufferSize: bufferSize,
+		clients:    make(map[int]*sseClient),
+	}
+}
+
+func (w *SSEWriter) Write(
+	obj model.KubernetesResource,
+	evtype broker.EventType,
+	config config.PipelineConfig,
+) error {
+	event := SSEEvent{Resource: config.Name}
+	if evtype != broker.Delete {
+		// consumers only need the identity of a deleted object
+		event.Object = &obj
+	}
+	if obj.KubernetesResourceMeta != nil {
+		event.Namespace = obj.KubernetesResourceMeta.Namespace
+		event.Name = obj.KubernetesResourceMeta.Name
+		event.UID = obj.KubernetesResourceMeta.UID
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	message := sseMessage(string(config.EmittedEventType(evtype)), data)
+
+	w.deliver(func(c *sseClient) bool { return c.matches(event) }, message)
+	return nil
+}
+
+// WriteControl streams the control event to every client, regardless of its filters
+func (w *SSEWriter) WriteControl(event ControlEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	w.deliver(func(*sseClient) bool { return true }, sseMessage(string(event.Type), data))
+	return nil
+}
+
+func (w *SSEWriter) deliver(matches func(*sseClient) bool, message []byte) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	for _, c := range w.clients {
+		if !matches(c) {
+			continue
+		}
+		select {
+		case c.messages <- message:
+		default:
+			// the client does not keep up, never block the pipelines on it
+			c.disconnect()
+		}
+	}
+}
+
+// ServeHTTP streams the events matching the query params until the client goes away or stalls
+func (w *SSEWriter) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		http.Error(rw, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query()
+	rc := http.NewResponseController(rw)
+	c := &sseClient{
+		resources:  splitQueryValues(query["resource"]),
+		namespaces: splitQueryValues(query["namespace"]),
+		messages:   make(chan []byte, w.bufferSize),
+		stalled:    make(chan struct{}),
+		abort:      func() { _ = rc.SetWriteDeadline(time.Now()) },
+	}
+	id := w.add(c)
+	defer w.remove(id)
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("Connection", "keep-alive")
+	rw.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-c.stalled:
+			return
+		case message := <-c.messages:
+			select {
+			case <-c.stalled:
+				// the remaining buffer is of no use, the client missed events
+				return
+			default:
+			}
+			if _, err := rw.Write(message); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// Clients returns the number of connected clients
+func (w *SSEWriter) Clients() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return len(w.clients)
+}
+
+func (w *SSEWriter) add(c *sseClient) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	id := w.nextID
+	w.nextID++
+	w.clients[id] = c
+	return id
+}
+
+func (w *SSEWriter) remove(id int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.clients, id)
+}
+
+func (c *sseClient) matches(event SSEEvent) bool {
+	if len(c.resources) > 0 && !slices.Contains(c.resources, event.Resource) {
+		return false
+	}
+	if len(c.namespaces) > 0 && !slices.Contains(c.namespaces, event.Namespace) {
+		return false
+	}
+	return true
+}
+
+// sseMessage formats a server-sent event, data must not contain newlines which compact JSON never does
+func sseMessage(event string, data []byte) []byte {
+	return []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", event, data))
+}
+
+// splitQueryValues accepts both repeated params and comma separated values
+func splitQueryValues(values []string) []string {
+	split := make([]string, 0, len(values))
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v != "" {
+				split = append(split, v)
+			}
+		}
+	}
+	return split
+}
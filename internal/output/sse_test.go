@@ -0,0 +1,221 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+)
+
+type sseMessageRecord struct {
+	event string
+	data  string
+}
+
+// readSSE collects the messages of the stream until it ends
+func readSSE(t *testing.T, resp *http.Response, messages chan<- sseMessageRecord) {
+	t.Helper()
+	scanner := bufio.NewScanner(resp.Body)
+	var current sseMessageRecord
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			current.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			current.data = strings.TrimPrefix(line, "data: ")
+		case line == "":
+			messages <- current
+			current = sseMessageRecord{}
+		}
+	}
+	close(messages)
+}
+
+func waitForSSEClients(t *testing.T, w *SSEWriter, count int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for w.Clients() != count {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d clients, got %d", count, w.Clients())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func ssePod(namespace, name string) model.KubernetesResource {
+	return model.KubernetesResource{
+		Kind:                   "Pod",
+		KubernetesResourceMeta: &model.KubernetesResourceObjectMeta{Name: name, Namespace: namespace, UID: namespace + "/" + name},
+	}
+}
+
+func TestSSEWriterFiltersEvents(t *testing.T) {
+	w := NewSSEWriter(0)
+	server := httptest.NewServer(w)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?resource=pods.v1.&namespace=default,prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if contentType := resp.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("expected an event stream, got %s", contentType)
+	}
+	messages := make(chan sseMessageRecord, 10)
+	go readSSE(t, resp, messages)
+	waitForSSEClients(t, w, 1)
+
+	pods := config.PipelineConfig{Name: "pods.v1."}
+	_ = w.Write(ssePod("kube-system", "ignored"), broker.Add, pods)
+	_ = w.Write(ssePod("default", "web"), broker.Add, pods)
+	_ = w.Write(ssePod("default", "svc"), broker.Add, config.PipelineConfig{Name: "services.v1."})
+	_ = w.Write(ssePod("prod", "api"), broker.Delete, pods)
+	_ = w.WriteControl(NewControlEvent(SnapshotCompleteEvent, "", 2))
+
+	expected := []struct {
+		event string
+		name  string
+	}{
+		{event: string(broker.Add), name: "web"},
+		{event: string(broker.Delete), name: "api"},
+	}
+	for _, e := range expected {
+		message := <-messages
+		var event SSEEvent
+		if err := json.Unmarshal([]byte(message.data), &event); err != nil {
+			t.Fatalf("invalid data %s: %v", message.data, err)
+		}
+		if message.event != e.event || event.Name != e.name || event.Resource != "pods.v1." {
+			t.Errorf("expected %s of %s, got %s of %+v", e.event, e.name, message.event, event)
+		}
+		if (event.Object == nil) != (e.event == string(broker.Delete)) {
+			t.Errorf("expected the object with every event but deletes, got %+v", event)
+		}
+	}
+	if message := <-messages; message.event != string(SnapshotCompleteEvent) {
+		t.Errorf("expected the control event regardless of the filters, got %s", message.event)
+	}
+}
+
+func TestSSEWriterRemapsEventTypes(t *testing.T) {
+	w := NewSSEWriter(0)
+	server := httptest.NewServer(w)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	messages := make(chan sseMessageRecord, 10)
+	go readSSE(t, resp, messages)
+	waitForSSEClients(t, w, 1)
+
+	pods := config.PipelineConfig{
+		Name:             "pods.v1.",
+		EventTypeMapping: map[string]string{"ADDED": "CREATE", "MODIFIED": "UPDATE", "DELETED": "DELETE"},
+	}
+	_ = w.Write(ssePod("default", "web"), broker.Add, pods)
+	_ = w.Write(ssePod("default", "web"), broker.Delete, pods)
+
+	for _, expected := range []string{"CREATE", "DELETE"} {
+		message := <-messages
+		var event SSEEvent
+		if err := json.Unmarshal([]byte(message.data), &event); err != nil {
+			t.Fatalf("invalid data %s: %v", message.data, err)
+		}
+		if message.event != expected {
+			t.Errorf("expected the remapped event %s, got %s", expected, message.event)
+		}
+		// the object is left out of deletes by the original event type
+		if (event.Object == nil) != (expected == "DELETE") {
+			t.Errorf("expected the object with every event but deletes, got %s with %+v", message.event, event)
+		}
+	}
+}
+
+// stalledResponseWriter blocks every write of an event until the write deadline is set
+type stalledResponseWriter struct {
+	header   http.Header
+	deadline chan struct{}
+	once     sync.Once
+}
+
+func (w *stalledResponseWriter) Header() http.Header { return w.header }
+func (w *stalledResponseWriter) WriteHeader(int)     {}
+func (w *stalledResponseWriter) Flush()              {}
+
+func (w *stalledResponseWriter) Write([]byte) (int, error) {
+	<-w.deadline
+	return 0, errors.New("write deadline exceeded")
+}
+
+func (w *stalledResponseWriter) SetWriteDeadline(time.Time) error {
+	w.once.Do(func() { close(w.deadline) })
+	return nil
+}
+
+func TestSSEWriterDisconnectsStalledClient(t *testing.T) {
+	bufferSize := 2
+	w := NewSSEWriter(bufferSize)
+	server := httptest.NewServer(w)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	messages := make(chan sseMessageRecord, 100)
+	go readSSE(t, resp, messages)
+
+	stalled := &stalledResponseWriter{header: http.Header{}, deadline: make(chan struct{})}
+	stalledDone := make(chan struct{})
+	go func() {
+		w.ServeHTTP(stalled, httptest.NewRequest(http.MethodGet, "/", nil))
+		close(stalledDone)
+	}()
+	waitForSSEClients(t, w, 2)
+
+	written := make(chan struct{})
+	events := bufferSize + 5
+	go func() {
+		for i := 0; i < events; i++ {
+			_ = w.Write(ssePod("default", "web"), broker.Update, config.PipelineConfig{Name: "pods.v1."})
+			// give the healthy client the chance to keep up
+			time.Sleep(time.Millisecond)
+		}
+		close(written)
+	}()
+
+	select {
+	case <-written:
+	case <-time.After(5 * time.Second):
+		t.Fatal("a stalled client blocked the writer")
+	}
+	select {
+	case <-stalledDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the stalled client was not disconnected")
+	}
+
+	for i := 0; i < events; i++ {
+		select {
+		case <-messages:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected the healthy client to receive %d events, got %d", events, i)
+		}
+	}
+	waitForSSEClients(t, w, 1)
+}
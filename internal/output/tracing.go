@@ -0,0 +1,77 @@
+package output
+
+import (
+	"context"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/meshery/meshsync"
+
+// TracingWriter creates a span per emitted event and injects its context into the event envelope,
+// consumers continue the trace by extracting the W3C trace context from it.
+// Events pinned to an envelope version without trace context are traced but carry no context.
+type TracingWriter struct {
+	output     Writer
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+func NewTracingWriter(output Writer, tp trace.TracerProvider) *TracingWriter {
+	return &TracingWriter{
+		output:     output,
+		tracer:     tp.Tracer(tracerName),
+		propagator: propagation.TraceContext{},
+	}
+}
+
+func (w *TracingWriter) Write(
+	obj model.KubernetesResource,
+	evtype broker.EventType,
+	config config.PipelineConfig,
+) error {
+	attributes := []attribute.KeyValue{
+		attribute.String("meshsync.pipeline", config.Name),
+		attribute.String("meshsync.event_type", string(evtype)),
+		attribute.String("k8s.kind", obj.Kind),
+	}
+	if meta := obj.KubernetesResourceMeta; meta != nil {
+		attributes = append(attributes,
+			attribute.String("k8s.namespace", meta.Namespace),
+			attribute.String("k8s.name", meta.Name),
+		)
+	}
+	ctx, span := w.tracer.Start(
+		context.Background(),
+		"meshsync.emit",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attributes...),
+	)
+	defer span.End()
+
+	if obj.Envelope != nil && model.SchemaVersionIncludes(obj.Envelope.SchemaVersion, model.SchemaVersionV4) {
+		carrier := propagation.MapCarrier{}
+		w.propagator.Inject(ctx, carrier)
+		// the envelope may be shared with other writers
+		envelope := *obj.Envelope
+		envelope.TraceContext = carrier
+		obj.Envelope = &envelope
+	}
+
+	if err := w.output.Write(obj, evtype, config); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	return nil
+}
+
+func (w *TracingWriter) WriteControl(event ControlEvent) error {
+	return WriteControl(w.output, event)
+}
@@ -0,0 +1,73 @@
+package output
+
+import (
+	"context"
+	"testing"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingWriterInjectsTraceContext(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	recorder := &pipelineRecorder{}
+	w := NewTracingWriter(recorder, tp)
+
+	envelope := (&model.Envelope{}).Versioned("")
+	obj := model.KubernetesResource{
+		Kind:                   "Pod",
+		KubernetesResourceMeta: &model.KubernetesResourceObjectMeta{Name: "pod-a", Namespace: "default"},
+		Envelope:               envelope,
+	}
+	if err := w.Write(obj, broker.Add, config.PipelineConfig{Name: "pods.v1."}); err != nil {
+		t.Fatal(err)
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("expected a span per emitted event, got %d", len(ended))
+	}
+	if ended[0].SpanKind() != trace.SpanKindProducer {
+		t.Errorf("expected a producer span, got %s", ended[0].SpanKind())
+	}
+
+	emitted := recorder.objects[0].Envelope
+	if emitted == nil || emitted.TraceContext["traceparent"] == "" {
+		t.Fatalf("expected the trace context in the envelope, got %+v", emitted)
+	}
+	// consumers continue the trace from the envelope
+	ctx := propagation.TraceContext{}.Extract(context.Background(), propagation.MapCarrier(emitted.TraceContext))
+	remote := trace.SpanContextFromContext(ctx)
+	if remote.TraceID() != ended[0].SpanContext().TraceID() || remote.SpanID() != ended[0].SpanContext().SpanID() {
+		t.Errorf("expected the context of span %s, got %s", ended[0].SpanContext().SpanID(), remote.SpanID())
+	}
+	if envelope.TraceContext != nil {
+		t.Error("the envelope of the written object must not be mutated")
+	}
+}
+
+func TestTracingWriterRespectsPinnedEnvelopeVersion(t *testing.T) {
+	for _, version := range []string{model.SchemaVersionV1, model.SchemaVersionV2, model.SchemaVersionV3} {
+		spans := tracetest.NewSpanRecorder()
+		recorder := &pipelineRecorder{}
+		w := NewTracingWriter(recorder, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+
+		obj := model.KubernetesResource{Kind: "Pod", Envelope: (&model.Envelope{}).Versioned(version)}
+		if err := w.Write(obj, broker.Add, config.PipelineConfig{Name: "pods.v1."}); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(spans.Ended()) != 1 {
+			t.Errorf("version %s: expected the event to be traced, got %d spans", version, len(spans.Ended()))
+		}
+		if emitted := recorder.objects[0].Envelope; emitted != nil && emitted.TraceContext != nil {
+			t.Errorf("version %s: expected no trace context, got %v", version, emitted.TraceContext)
+		}
+	}
+}
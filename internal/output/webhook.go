@@ -0,0 +1,323 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+)
+
+// WebhookSignatureHeader carries the HMAC-SHA256 of the request body as "sha256=<hex>"
+const WebhookSignatureHeader = "X-MeshSync-Signature"
+
+// DefaultWebhookQueueSize is the number of batches waiting to be posted before the sink rejects events
+const DefaultWebhookQueueSize = 64
+
+// ErrWebhookQueueFull is returned for the events of a batch the webhook does not keep up with
+var ErrWebhookQueueFull = errors.New("webhook queue is full")
+
+// WebhookOptions configure a WebhookSink, the zero value posts every event on its own without retries
+type WebhookOptions struct {
+	// bearer token sent in the Authorization header, empty sends none
+	Token string
+	// key signing the body, see WebhookSignatureHeader, empty signs nothing
+	HMACKey []byte
+	// events per request, up to 1 posts every event on its own
+	BatchSize int
+	// how long a partial batch waits for more events
+	FlushInterval time.Duration
+	// retries of a request failing with a transport error or a 5xx status,
+	// the backoff doubles with every retry
+	MaxRetries   int
+	RetryBackoff time.Duration
+	// timeout of a single request, zero waits as long as the context allows
+	Timeout time.Duration
+	// batches waiting to be posted, see DefaultWebhookQueueSize
+	QueueSize int
+	// receives the errors of the batches posted in the background
+	OnError func(err error)
+	Client  *http.Client
+}
+
+// WebhookEvent is an event as posted to the webhook, mirroring the broker message
+type WebhookEvent struct {
+	Subject    string            `json:"subject,omitempty"`
+	ObjectType broker.ObjectType `json:"object_type"`
+	EventType  broker.EventType  `json:"event_type"`
+	Key        string            `json:"key,omitempty"`
+	Object     interface{}       `json:"object"`
+}
+
+// WebhookPayload is the body of every request
+type WebhookPayload struct {
+	Events []WebhookEvent `json:"events"`
+}
+
+// WebhookSink posts the events as JSON to an HTTP endpoint. The batches are posted in order
+// by a background goroutine, so a slow or failing endpoint does not block the pipelines:
+// once the queue of batches is full the events are rejected with ErrWebhookQueueFull.
+type WebhookSink struct {
+	ctx  context.Context
+	url  string
+	opts WebhookOptions
+
+	mu    sync.Mutex
+	batch []WebhookEvent
+	acks  []func()
+	timer *time.Timer
+
+	queue chan webhookBatch
+}
+
+// webhookBatch is a batch queued for posting, done receives the outcome if not nil
+type webhookBatch struct {
+	events []WebhookEvent
+	acks   []func()
+	done   chan error
+}
+
+// ack acknowledges the events of the batch written by WriteAcked
+func (b webhookBatch) ack() {
+	for _, ack := range b.acks {
+		ack()
+	}
+}
+
+// NewWebhookSink returns a sink posting to url, cancelling ctx aborts pending requests and retries
+// and stops posting
+func NewWebhookSink(ctx context.Context, url string, opts WebhookOptions) *WebhookSink {
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	if opts.BatchSize < 1 {
+		opts.BatchSize = 1
+	}
+	if opts.QueueSize < 1 {
+		opts.QueueSize = DefaultWebhookQueueSize
+	}
+	s := &WebhookSink{
+		ctx:   ctx,
+		url:   url,
+		opts:  opts,
+		queue: make(chan webhookBatch, opts.QueueSize),
+	}
+	go s.run()
+	return s
+}
+
+func (s *WebhookSink) Write(
+	obj model.KubernetesResource,
+	evtype broker.EventType,
+	config config.PipelineConfig,
+) error {
+	return s.add(WebhookEvent{
+		Subject:    config.PublishTo,
+		ObjectType: broker.MeshSync,
+		EventType:  config.EmittedEventType(evtype),
+		Key:        KeyFuncFor(config)(obj),
+		Object:     obj,
+	}, nil)
+}
+
+// WriteAcked posts data as the object of the event and calls ack once its batch is posted or dropped
+func (s *WebhookSink) WriteAcked(
+	obj model.KubernetesResource,
+	data json.RawMessage,
+	evtype broker.EventType,
+	config config.PipelineConfig,
+	ack func(),
+) error {
+	return s.add(WebhookEvent{
+		Subject:    config.PublishTo,
+		ObjectType: broker.MeshSync,
+		EventType:  config.EmittedEventType(evtype),
+		Key:        KeyFuncFor(config)(obj),
+		Object:     data,
+	}, ack)
+}
+
+func (s *WebhookSink) WriteControl(event ControlEvent) error {
+	return s.add(WebhookEvent{
+		ObjectType: ControlObjectType,
+		EventType:  event.Type,
+		Object:     event,
+	}, nil)
+}
+
+// add batches the event, a full batch is queued for posting right away.
+// ack is called once the batch is posted or dropped, nil acknowledges nothing.
+func (s *WebhookSink) add(event WebhookEvent, ack func()) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.batch = append(s.batch, event)
+	if ack != nil {
+		s.acks = append(s.acks, ack)
+	}
+	if len(s.batch) >= s.opts.BatchSize {
+		return s.enqueue()
+	}
+	if s.timer == nil {
+		s.timer = time.AfterFunc(s.opts.FlushInterval, s.flushInBackground)
+	}
+	return nil
+}
+
+func (s *WebhookSink) flushInBackground() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.enqueue(); err != nil && s.opts.OnError != nil {
+		s.opts.OnError(err)
+	}
+}
+
+// enqueue hands the batch to the background goroutine without waiting,
+// must be called with the lock held so batches are queued in order
+func (s *WebhookSink) enqueue() error {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if len(s.batch) == 0 {
+		return nil
+	}
+	batch := webhookBatch{events: s.batch, acks: s.acks}
+	s.batch = nil
+	s.acks = nil
+	select {
+	case s.queue <- batch:
+		return nil
+	default:
+		batch.ack()
+		return fmt.Errorf("dropping %d events for %s: %w", len(batch.events), s.url, ErrWebhookQueueFull)
+	}
+}
+
+// Flush posts the pending events and waits until the queued batches are posted,
+// it returns the error of the pending events, the queued batches report theirs to OnError
+func (s *WebhookSink) Flush() error {
+	s.mu.Lock()
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	batch := webhookBatch{events: s.batch, acks: s.acks, done: make(chan error, 1)}
+	s.batch = nil
+	s.acks = nil
+	s.mu.Unlock()
+
+	select {
+	case s.queue <- batch:
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+	select {
+	case err := <-batch.done:
+		return err
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+}
+
+// run posts the queued batches in order until ctx is done
+func (s *WebhookSink) run() {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case batch := <-s.queue:
+			err := s.flush(batch.events)
+			batch.ack()
+			if batch.done != nil {
+				batch.done <- err
+			} else if err != nil && s.opts.OnError != nil {
+				s.opts.OnError(err)
+			}
+		}
+	}
+}
+
+// flush posts the events of a batch
+func (s *WebhookSink) flush(events []WebhookEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(WebhookPayload{Events: events})
+	if err != nil {
+		return err
+	}
+	return s.post(body)
+}
+
+// post sends the body, retrying transport errors and 5xx responses
+func (s *WebhookSink) post(body []byte) error {
+	backoff := s.opts.RetryBackoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		retry, err = s.send(body)
+		if err == nil || !retry || attempt >= s.opts.MaxRetries {
+			return err
+		}
+		select {
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// send makes a single request and reports whether a failure is worth retrying
+func (s *WebhookSink) send(body []byte) (bool, error) {
+	ctx := s.ctx
+	if s.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.opts.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.opts.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.opts.Token)
+	}
+	if len(s.opts.HMACKey) > 0 {
+		req.Header.Set(WebhookSignatureHeader, WebhookSignature(s.opts.HMACKey, body))
+	}
+
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		// retry unless the sink is closed
+		return s.ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return true, fmt.Errorf("webhook %s responded %s", s.url, resp.Status)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return false, fmt.Errorf("webhook %s responded %s", s.url, resp.Status)
+	}
+	return false, nil
+}
+
+// WebhookSignature returns the value of WebhookSignatureHeader for the body
+func WebhookSignature(key, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,319 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+)
+
+// webhookRecorder answers with the queued status codes, then with 200
+type webhookRecorder struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *webhookRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	r.mu.Unlock()
+	w.WriteHeader(status)
+}
+
+func (r *webhookRecorder) payloads(t *testing.T) []WebhookPayload {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	payloads := make([]WebhookPayload, 0, len(r.bodies))
+	for _, body := range r.bodies {
+		var payload WebhookPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Fatalf("invalid payload %s: %v", body, err)
+		}
+		payloads = append(payloads, payload)
+	}
+	return payloads
+}
+
+func webhookPod(name string) model.KubernetesResource {
+	return model.KubernetesResource{
+		Kind:                   "Pod",
+		KubernetesResourceMeta: &model.KubernetesResourceObjectMeta{Name: name, Namespace: "default"},
+	}
+}
+
+func TestWebhookSinkPostsSignedPayload(t *testing.T) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	key := []byte("secret")
+	sink := NewWebhookSink(context.Background(), server.URL, WebhookOptions{Token: "token", HMACKey: key})
+	if err := sink.Write(webhookPod("pod-a"), broker.Add, config.PipelineConfig{PublishTo: "meshery.meshsync.core"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recorder.requests) != 1 {
+		t.Fatalf("expected a request per event, got %d", len(recorder.requests))
+	}
+	req := recorder.requests[0]
+	if req.Method != http.MethodPost || req.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("expected a JSON POST, got %s %s", req.Method, req.Header.Get("Content-Type"))
+	}
+	if auth := req.Header.Get("Authorization"); auth != "Bearer token" {
+		t.Errorf("expected the bearer token, got %q", auth)
+	}
+	if signature := req.Header.Get(WebhookSignatureHeader); signature != WebhookSignature(key, recorder.bodies[0]) {
+		t.Errorf("expected the body to be signed, got %q", signature)
+	}
+
+	payloads := recorder.payloads(t)
+	if len(payloads[0].Events) != 1 {
+		t.Fatalf("expected a single event, got %+v", payloads[0])
+	}
+	event := payloads[0].Events[0]
+	if event.EventType != broker.Add || event.ObjectType != broker.MeshSync || event.Subject != "meshery.meshsync.core" {
+		t.Errorf("unexpected event %+v", event)
+	}
+	object, _ := json.Marshal(event.Object)
+	var pod model.KubernetesResource
+	if err := json.Unmarshal(object, &pod); err != nil || pod.KubernetesResourceMeta.Name != "pod-a" {
+		t.Errorf("expected the object to be posted, got %s", object)
+	}
+}
+
+func TestWebhookSinkKeysEvents(t *testing.T) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	sink := NewWebhookSink(context.Background(), server.URL, WebhookOptions{})
+	pod := webhookPod("pod-a")
+	pod.KubernetesResourceMeta.UID = "6f1c"
+	for _, keyFunc := range []string{"", config.KeyFuncNamespacedName} {
+		if err := sink.Write(pod, broker.Add, config.PipelineConfig{KeyFunc: keyFunc}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sink.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := make([]string, 0)
+	for _, payload := range recorder.payloads(t) {
+		for _, event := range payload.Events {
+			keys = append(keys, event.Key)
+		}
+	}
+	if expected := []string{"6f1c", "default/pod-a"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected the keys %v, got %v", expected, keys)
+	}
+}
+
+func TestWebhookSinkRetries(t *testing.T) {
+	testCases := []struct {
+		name             string
+		statuses         []int
+		expectedRequests int
+		expectErr        bool
+	}{
+		{name: "retried on 5xx", statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway}, expectedRequests: 3},
+		{name: "retries exhausted", statuses: []int{500, 500, 500, 500}, expectedRequests: 3, expectErr: true},
+		{name: "not retried on 4xx", statuses: []int{http.StatusUnauthorized}, expectedRequests: 1, expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := &webhookRecorder{statuses: tc.statuses}
+			server := httptest.NewServer(recorder)
+			defer server.Close()
+
+			errs := make(chan error, 1)
+			sink := NewWebhookSink(context.Background(), server.URL, WebhookOptions{
+				MaxRetries:   2,
+				RetryBackoff: time.Millisecond,
+				OnError:      func(err error) { errs <- err },
+			})
+			if err := sink.WriteControl(NewControlEvent(SnapshotCompleteEvent, "", 1)); err != nil {
+				t.Fatal(err)
+			}
+			if err := sink.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			var err error
+			select {
+			case err = <-errs:
+			default:
+			}
+			if tc.expectErr && err == nil {
+				t.Error("expected error")
+			}
+			if !tc.expectErr && err != nil {
+				t.Errorf("unexpected error %s", err.Error())
+			}
+			if len(recorder.requests) != tc.expectedRequests {
+				t.Errorf("expected %d requests, got %d", tc.expectedRequests, len(recorder.requests))
+			}
+		})
+	}
+}
+
+func TestWebhookSinkBatching(t *testing.T) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	flushed := make(chan error, 1)
+	sink := NewWebhookSink(context.Background(), server.URL, WebhookOptions{
+		BatchSize:     2,
+		FlushInterval: 10 * time.Millisecond,
+		OnError:       func(err error) { flushed <- err },
+	})
+	for _, name := range []string{"pod-a", "pod-b", "pod-c"} {
+		if err := sink.Write(webhookPod(name), broker.Update, config.PipelineConfig{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the full batch is posted right away, the partial one once the interval elapses
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		payloads := recorder.payloads(t)
+		if len(payloads) == 2 {
+			if len(payloads[0].Events) != 2 || len(payloads[1].Events) != 1 {
+				t.Errorf("expected batches of 2 and 1 events, got %d and %d", len(payloads[0].Events), len(payloads[1].Events))
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 2 batches, got %d", len(payloads))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	select {
+	case err := <-flushed:
+		t.Errorf("unexpected error %s", err.Error())
+	default:
+	}
+}
+
+func TestWebhookSinkHonorsCancellation(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sink := NewWebhookSink(ctx, server.URL, WebhookOptions{MaxRetries: 5, RetryBackoff: time.Hour})
+
+	done := make(chan error, 1)
+	go func() {
+		if err := sink.Write(webhookPod("pod-a"), broker.Add, config.PipelineConfig{}); err != nil {
+			done <- err
+			return
+		}
+		done <- sink.Flush()
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected the cancelled request to fail")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancelling the context did not abort the request")
+	}
+}
+
+func TestWebhookSinkTimeout(t *testing.T) {
+	release := make(chan struct{})
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	errs := make(chan error, 1)
+	sink := NewWebhookSink(context.Background(), server.URL, WebhookOptions{
+		Timeout:      10 * time.Millisecond,
+		MaxRetries:   1,
+		RetryBackoff: time.Millisecond,
+		OnError:      func(err error) { errs <- err },
+	})
+	if err := sink.Write(webhookPod("pod-a"), broker.Add, config.PipelineConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-errs:
+	default:
+		t.Error("expected the request to time out")
+	}
+	if requests.Load() != 2 {
+		t.Errorf("expected a timed out request to be retried, got %d requests", requests.Load())
+	}
+}
+
+func TestWebhookSinkDoesNotBlockOnSlowEndpoint(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sink := NewWebhookSink(ctx, server.URL, WebhookOptions{QueueSize: 1})
+
+	// a batch is being posted and one is queued at most, the next one is rejected right away
+	start := time.Now()
+	var err error
+	for i := 0; i < 3 && err == nil; i++ {
+		err = sink.Write(webhookPod("pod-a"), broker.Add, config.PipelineConfig{})
+	}
+	if !errors.Is(err, ErrWebhookQueueFull) {
+		t.Errorf("expected the full queue to reject the event, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the writes not to wait for the endpoint, took %s", elapsed)
+	}
+}
@@ -0,0 +1,183 @@
+package pipeline
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/meshery/meshkit/broker"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
+)
+
+// how long the replay waits for the next historical event before it stops short of the initial list,
+// f.e. when no object of the resource changed since the first replayed revision
+const backfillIdleTimeout = 2 * time.Second
+
+// backfillHandler receives the changes replayed ahead of the initial list
+type backfillHandler interface {
+	// replaying is called before the history is replayed, the returned function once it is replayed
+	replaying() func()
+	backfilled(event watch.Event)
+	backfillFailed(err error)
+}
+
+// backfillListWatch replays the changes of the revisions before the initial list from the watch cache
+// of the API server. The history is replayed in the background, the list is returned right away and
+// the handler holds the events of the list back until the replay is done. Relists are not backfilled.
+// If the API server no longer has the revisions (410 Gone), the initial list is served without history.
+type backfillListWatch struct {
+	cache.ListerWatcher
+	revisions int64
+	handler   backfillHandler
+	clock     clock.Clock
+	started   atomic.Bool
+}
+
+func newBackfillListWatch(lw cache.ListerWatcher, revisions int, handler backfillHandler, c clock.Clock) *backfillListWatch {
+	return &backfillListWatch{
+		ListerWatcher: lw,
+		revisions:     int64(revisions),
+		handler:       handler,
+		clock:         c,
+	}
+}
+
+func (lw *backfillListWatch) List(options metav1.ListOptions) (runtime.Object, error) {
+	list, err := lw.ListerWatcher.List(options)
+	if err != nil {
+		return list, err
+	}
+	if lw.started.CompareAndSwap(false, true) {
+		replayed := lw.handler.replaying()
+		go func() {
+			defer replayed()
+			if err := lw.replay(list); err != nil {
+				lw.handler.backfillFailed(err)
+			}
+		}()
+	}
+	return list, nil
+}
+
+// replay watches from the first replayed revision and hands the changes up to the list's revision to the handler
+func (lw *backfillListWatch) replay(list runtime.Object) error {
+	accessor, err := meta.ListAccessor(list)
+	if err != nil {
+		return err
+	}
+	listRV, err := strconv.ParseInt(accessor.GetResourceVersion(), 10, 64)
+	if err != nil {
+		return fmt.Errorf("resourceVersion %q of the list is not a revision", accessor.GetResourceVersion())
+	}
+	from := max(listRV-lw.revisions, 1)
+
+	w, err := lw.ListerWatcher.Watch(metav1.ListOptions{ResourceVersion: strconv.FormatInt(from, 10), AllowWatchBookmarks: true})
+	if err != nil {
+		return err
+	}
+	defer w.Stop()
+
+	for {
+		select {
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return nil
+			}
+			if event.Type == watch.Error {
+				return apierrors.FromObject(event.Object)
+			}
+			obj, ok := event.Object.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+			rv, _ := strconv.ParseInt(obj.GetResourceVersion(), 10, 64)
+			if rv > listRV {
+				// part of the list already
+				return nil
+			}
+			if event.Type != watch.Bookmark {
+				lw.handler.backfilled(event)
+			}
+			if rv == listRV {
+				return nil
+			}
+		case <-lw.clock.After(backfillIdleTimeout):
+			return nil
+		}
+	}
+}
+
+// backfillGate holds the events of the informers back while their history is replayed,
+// the zero value holds nothing back
+type backfillGate struct {
+	mu        sync.Mutex
+	replaying int
+	done      chan struct{}
+}
+
+func (g *backfillGate) start() func() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.replaying == 0 {
+		g.done = make(chan struct{})
+	}
+	g.replaying++
+	return g.finish
+}
+
+func (g *backfillGate) finish() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.replaying--
+	if g.replaying == 0 {
+		close(g.done)
+	}
+}
+
+// wait returns once no history is being replayed
+func (g *backfillGate) wait() {
+	g.mu.Lock()
+	done := g.done
+	g.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+}
+
+// replaying holds the events of the pipeline back until the history is replayed
+func (ri *RegisterInformer) replaying() func() {
+	return ri.backfills.start()
+}
+
+// backfilled emits the replayed change as it happened, the informer's cache is not involved
+func (ri *RegisterInformer) backfilled(event watch.Event) {
+	obj := event.Object.(*unstructured.Unstructured)
+	var evtype broker.EventType
+	switch event.Type {
+	case watch.Added:
+		evtype = broker.Add
+	case watch.Modified:
+		evtype = broker.Update
+	case watch.Deleted:
+		evtype = broker.Delete
+	default:
+		return
+	}
+	if err := ri.publishItem(obj, evtype, ri.config); err != nil {
+		ri.publishFailed(obj, evtype, err)
+	}
+}
+
+func (ri *RegisterInformer) backfillFailed(err error) {
+	// the initial list is emitted without history
+	ri.log.Warn(ErrBackfill(ri.config.Name, err))
+}
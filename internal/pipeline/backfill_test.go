@@ -0,0 +1,179 @@
+package pipeline
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/meshery/meshkit/broker"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"golang.org/x/exp/slices"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// historyListWatch serves a list and resumes the watches from revisions before the list's
+// with the recorded history, unless the history is expired
+type historyListWatch struct {
+	fakeListWatch
+	history []watch.Event
+	expired bool
+}
+
+func (lw *historyListWatch) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	if options.ResourceVersion == lw.list.GetResourceVersion() {
+		// the regular watch of the informer
+		return lw.fakeListWatch.Watch(options)
+	}
+	if lw.expired {
+		lw.fakeListWatch.mu.Lock()
+		lw.watchRVs = append(lw.watchRVs, options.ResourceVersion)
+		lw.fakeListWatch.mu.Unlock()
+		return nil, apierrors.NewResourceExpired("too old resource version")
+	}
+	// records the replay's watch, the regular watch of the informer follows it
+	_, _ = lw.fakeListWatch.Watch(options)
+	replayed := watch.NewFakeWithChanSize(len(lw.history), false)
+	for _, event := range lw.history {
+		replayed.Action(event.Type, event.Object)
+	}
+	return replayed, nil
+}
+
+func newTestRevision(name string, rv string) *unstructured.Unstructured {
+	obj := newTestObject("v1", "Pod", "default", name)
+	obj.SetResourceVersion(rv)
+	return obj
+}
+
+// runBackfilledInformer runs an informer of the pipeline over lw until the listed pod-a is emitted
+func runBackfilledInformer(t *testing.T, lw cache.ListerWatcher, writer *recordingWriter) {
+	t.Helper()
+	config := internalconfig.PipelineConfig{Name: "pods.v1.", Events: []string{"ADDED", "MODIFIED", "DELETED"}, BackfillRevisions: 3}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, config, internalconfig.GlobalSettings{}, writer, "")
+	informer := cache.NewSharedIndexInformer(newBackfillListWatch(lw, config.BackfillRevisions, ri, clock.RealClock{}), &unstructured.Unstructured{}, 0, cache.Indexers{})
+	_, _ = informer.AddEventHandler(ri.GetEventHandlers())
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go informer.Run(stopCh)
+	waitFor(t, informer.HasSynced)
+	waitFor(t, func() bool {
+		return len(writer.writtenObjects()) > 0 && writer.writtenObjects()[len(writer.writtenObjects())-1].KubernetesResourceMeta.Name == "pod-a"
+	})
+}
+
+func writtenEvents(writer *recordingWriter) []string {
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+	events := make([]string, 0, len(writer.events))
+	for i, evtype := range writer.events {
+		events = append(events, string(evtype)+" "+writer.objects[i].KubernetesResourceMeta.Name)
+	}
+	return events
+}
+
+func TestBackfillReplaysHistory(t *testing.T) {
+	list := &unstructured.UnstructuredList{
+		Object: map[string]interface{}{"apiVersion": "v1", "kind": "PodList", "metadata": map[string]interface{}{"resourceVersion": "10"}},
+		Items:  []unstructured.Unstructured{*newTestRevision("pod-a", "9")},
+	}
+	lw := &historyListWatch{
+		fakeListWatch: fakeListWatch{list: list},
+		history: []watch.Event{
+			{Type: watch.Added, Object: newTestRevision("pod-b", "8")},
+			{Type: watch.Modified, Object: newTestRevision("pod-a", "9")},
+			{Type: watch.Deleted, Object: newTestRevision("pod-b", "10")},
+			// newer than the list, not replayed
+			{Type: watch.Added, Object: newTestRevision("pod-c", "11")},
+		},
+	}
+	writer := &recordingWriter{}
+	runBackfilledInformer(t, lw, writer)
+
+	expected := []string{string(broker.Add) + " pod-b", string(broker.Update) + " pod-a", string(broker.Delete) + " pod-b", string(broker.Add) + " pod-a"}
+	if events := writtenEvents(writer); !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected the history ahead of the list %v, got %v", expected, events)
+	}
+	if lists, _, watchRVs := lw.state(); lists != 1 || !slices.Contains(watchRVs, "7") {
+		t.Errorf("expected the history to be replayed from revision 7 of a single list, got %d lists and watches from %v", lists, watchRVs)
+	}
+}
+
+func TestBackfillFallsBackWhenExpired(t *testing.T) {
+	list := &unstructured.UnstructuredList{
+		Object: map[string]interface{}{"apiVersion": "v1", "kind": "PodList", "metadata": map[string]interface{}{"resourceVersion": "10"}},
+		Items:  []unstructured.Unstructured{*newTestRevision("pod-a", "9")},
+	}
+	lw := &historyListWatch{fakeListWatch: fakeListWatch{list: list}, expired: true}
+	writer := &recordingWriter{}
+	runBackfilledInformer(t, lw, writer)
+
+	expected := []string{string(broker.Add) + " pod-a"}
+	if events := writtenEvents(writer); !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected the list without history %v, got %v", expected, events)
+	}
+}
+
+func TestBackfillDoesNotBlockTheList(t *testing.T) {
+	list := &unstructured.UnstructuredList{
+		Object: map[string]interface{}{"apiVersion": "v1", "kind": "PodList", "metadata": map[string]interface{}{"resourceVersion": "10"}},
+		Items:  []unstructured.Unstructured{*newTestRevision("pod-a", "9")},
+	}
+	// the history stops short of the list, the replay waits for more until it is idle
+	lw := &historyListWatch{
+		fakeListWatch: fakeListWatch{list: list},
+		history:       []watch.Event{{Type: watch.Added, Object: newTestRevision("pod-b", "8")}},
+	}
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	writer := &recordingWriter{}
+	config := internalconfig.PipelineConfig{Name: "pods.v1.", Events: []string{"ADDED", "MODIFIED", "DELETED"}, BackfillRevisions: 3}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, config, internalconfig.GlobalSettings{}, writer, "")
+	backfill := newBackfillListWatch(lw, config.BackfillRevisions, ri, fakeClock)
+
+	if _, err := backfill.List(metav1.ListOptions{}); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	waitFor(t, fakeClock.HasWaiters)
+	// a relist does not wait for the replay either
+	if _, err := backfill.List(metav1.ListOptions{}); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	listed := make(chan struct{})
+	go func() {
+		defer close(listed)
+		ri.GetEventHandlers().AddFunc(newTestRevision("pod-a", "9"))
+	}()
+	select {
+	case <-listed:
+		t.Fatal("expected the events of the list to wait for the replay")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	fakeClock.Step(backfillIdleTimeout)
+	select {
+	case <-listed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the events of the list once the replay is done")
+	}
+	expected := []string{string(broker.Add) + " pod-b", string(broker.Add) + " pod-a"}
+	if events := writtenEvents(writer); !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected the history ahead of the list %v, got %v", expected, events)
+	}
+}
+
+func TestBackfillNeedsDedicatedInformer(t *testing.T) {
+	informers := newTestInformers()
+	config := internalconfig.PipelineConfig{Name: "pods.v1.", BackfillRevisions: 3}
+	informer := informers.informerFor(config, schema.GroupVersionResource{Version: "v1", Resource: "pods"}, nil, nil)
+	if len(informers.dedicated) != 1 || informers.dedicated[0] != informer {
+		t.Errorf("expected a dedicated informer, got %v", informer)
+	}
+}
@@ -0,0 +1,161 @@
+package pipeline
+
+import (
+	"sync"
+	"time"
+
+	"github.com/meshery/meshsync/internal/output"
+	"k8s.io/utils/clock"
+)
+
+// bulkDeleteGuard throttles DELETE storms.
+// Deletes are counted in fixed windows starting with the first delete, up to threshold per window
+// are emitted right away. The excess is queued and released threshold per window or,
+// in summary mode, dropped and reported by a single summary once a window passes without excess.
+// Once stopped the queued deletes are released at once and every further delete is emitted right away.
+type bulkDeleteGuard struct {
+	clock     clock.WithDelayedExecution
+	threshold int
+	window    time.Duration
+	// summarize reports the number of deletes dropped during a bulk deletion, nil throttles instead
+	summarize func(dropped int)
+
+	mu      sync.Mutex
+	timer   clock.Timer
+	count   int
+	excess  bool
+	queue   []func()
+	dropped int
+	stopped bool
+	// held while emitting, so the deletes are emitted in order without holding mu
+	emitMu sync.Mutex
+}
+
+func newBulkDeleteGuard(c clock.WithDelayedExecution, threshold int, window time.Duration, summarize func(dropped int)) *bulkDeleteGuard {
+	return &bulkDeleteGuard{
+		clock:     c,
+		threshold: threshold,
+		window:    window,
+		summarize: summarize,
+	}
+}
+
+// submit emits the delete now, later or never depending on the current delete rate
+func (g *bulkDeleteGuard) submit(emit func()) {
+	g.mu.Lock()
+	if g.stopped {
+		g.release([]func(){emit})
+		return
+	}
+
+	g.arm()
+	g.count++
+	if g.count <= g.threshold && len(g.queue) == 0 {
+		g.release([]func(){emit})
+		return
+	}
+	defer g.mu.Unlock()
+
+	g.excess = true
+	if g.summarize != nil {
+		g.dropped++
+		return
+	}
+	g.queue = append(g.queue, emit)
+}
+
+// tick closes the current window
+func (g *bulkDeleteGuard) tick() {
+	g.mu.Lock()
+	if g.stopped {
+		g.mu.Unlock()
+		return
+	}
+	g.timer = nil
+
+	released := min(g.threshold, len(g.queue))
+	emits := g.queue[:released:released]
+	g.queue = g.queue[released:]
+	g.count = released
+
+	if g.dropped > 0 && !g.excess {
+		// the bulk deletion is over
+		emits = append(emits, g.summary(g.dropped))
+		g.dropped = 0
+	}
+	g.excess = false
+
+	if g.count > 0 || len(g.queue) > 0 || g.dropped > 0 {
+		g.arm()
+	}
+	g.release(emits)
+}
+
+// stop releases the queued deletes and the summary of the dropped ones, and stops the timer of the window
+func (g *bulkDeleteGuard) stop() {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	if g.stopped {
+		g.mu.Unlock()
+		return
+	}
+	g.stopped = true
+	if g.timer != nil {
+		g.timer.Stop()
+		g.timer = nil
+	}
+	emits := g.queue
+	g.queue = nil
+	if g.dropped > 0 {
+		emits = append(emits, g.summary(g.dropped))
+		g.dropped = 0
+	}
+	g.release(emits)
+}
+
+// summary returns the emit of the summary of the dropped deletes
+func (g *bulkDeleteGuard) summary(dropped int) func() {
+	return func() { g.summarize(dropped) }
+}
+
+// release unlocks mu and emits in order, must be called with the lock held
+func (g *bulkDeleteGuard) release(emits []func()) {
+	// taken before mu is unlocked, so later emits wait for these
+	g.emitMu.Lock()
+	g.mu.Unlock()
+	defer g.emitMu.Unlock()
+	for _, emit := range emits {
+		emit()
+	}
+}
+
+// arm schedules the end of the current window, must be called with the lock held
+func (g *bulkDeleteGuard) arm() {
+	if g.timer != nil {
+		return
+	}
+	g.timer = g.clock.AfterFunc(g.window, func() {
+		// timer callbacks must not block the clock
+		go g.tick()
+	})
+}
+
+// bulkDeleteGuardFor returns the guard of the pipeline, nil when bulk delete detection is disabled
+func (ri *RegisterInformer) bulkDeleteGuardFor(c clock.WithDelayedExecution) *bulkDeleteGuard {
+	if ri.config.BulkDeleteThreshold <= 0 || ri.config.BulkDeleteWindow <= 0 {
+		return nil
+	}
+	var summarize func(int)
+	if ri.config.BulkDeleteSummary {
+		summarize = func(dropped int) {
+			ri.suppressed(suppressedBulkDelete, dropped)
+			ri.log.Info("Bulk deletion completed for: ", ri.config.Name, " DELETE events left out: ", dropped)
+			if err := output.WriteControl(ri.outputWriter, output.NewControlEvent(output.BulkDeleteEvent, ri.config.Name, dropped)); err != nil {
+				ri.log.Error(ErrWriteOutput(ri.config.Name, err))
+			}
+		}
+	}
+	return newBulkDeleteGuard(c, ri.config.BulkDeleteThreshold, ri.config.BulkDeleteWindow, summarize)
+}
@@ -0,0 +1,95 @@
+package pipeline
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/internal/output"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestBulkDeleteThrottling(t *testing.T) {
+	for _, summary := range []bool{false, true} {
+		t.Run(fmt.Sprintf("summary %t", summary), func(t *testing.T) {
+			fakeClock := clocktesting.NewFakeClock(time.Now())
+			writer := &recordingWriter{}
+			ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+				Name:                "pods.v1.",
+				Events:              []string{"ADDED", "MODIFIED", "DELETED"},
+				BulkDeleteThreshold: 10,
+				BulkDeleteWindow:    time.Second,
+				BulkDeleteSummary:   summary,
+			}, internalconfig.GlobalSettings{}, writer, "")
+			ri.bulkDeletes = ri.bulkDeleteGuardFor(fakeClock)
+
+			for i := 0; i < 100; i++ {
+				ri.GetEventHandlers().DeleteFunc(newTestObject("v1", "Pod", "doomed", fmt.Sprintf("pod-%d", i)))
+			}
+			if count := len(writer.writtenObjects()); count != 10 {
+				t.Fatalf("expected the first 10 deletes to be emitted right away, got %d", count)
+			}
+
+			nextWindow := func() {
+				waitFor(t, fakeClock.HasWaiters)
+				fakeClock.Step(time.Second)
+			}
+
+			if summary {
+				// the window after the storm is quiet, so the bulk deletion is over
+				nextWindow()
+				nextWindow()
+				waitFor(t, func() bool { return len(writer.controlEvents()) == 1 })
+
+				event := writer.controlEvents()[0]
+				if event.Type != output.BulkDeleteEvent || event.Resource != "pods.v1." || event.Count != 90 {
+					t.Errorf("expected %s summary of 90 deletes for pods.v1., got %+v", output.BulkDeleteEvent, event)
+				}
+				if count := len(writer.writtenObjects()); count != 10 {
+					t.Errorf("expected no further deletes to be emitted, got %d", count)
+				}
+				return
+			}
+
+			for emitted := 20; emitted <= 100; emitted += 10 {
+				nextWindow()
+				waitFor(t, func() bool { return len(writer.writtenObjects()) == emitted })
+			}
+			if events := writer.controlEvents(); len(events) != 0 {
+				t.Errorf("expected no summary when throttling, got %+v", events)
+			}
+		})
+	}
+}
+
+func TestBulkDeleteStop(t *testing.T) {
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:                "pods.v1.",
+		Events:              []string{"DELETED"},
+		BulkDeleteThreshold: 10,
+		BulkDeleteWindow:    time.Second,
+	}, internalconfig.GlobalSettings{}, writer, "")
+	ri.bulkDeletes = ri.bulkDeleteGuardFor(fakeClock)
+
+	for i := 0; i < 30; i++ {
+		ri.GetEventHandlers().DeleteFunc(newTestObject("v1", "Pod", "doomed", fmt.Sprintf("pod-%d", i)))
+	}
+	stopChan := make(chan struct{})
+	close(stopChan)
+	ri.stopDeferred(stopChan)
+
+	// the rebuilt pipeline does not list the deleted objects, the queued deletes are emitted
+	if count := len(writer.writtenObjects()); count != 30 {
+		t.Errorf("expected the queued deletes to be emitted once stopped, got %d", count)
+	}
+	if fakeClock.HasWaiters() {
+		t.Error("expected the timer of the window to be stopped")
+	}
+	ri.GetEventHandlers().DeleteFunc(newTestObject("v1", "Pod", "doomed", "late"))
+	if count := len(writer.writtenObjects()); count != 31 || fakeClock.HasWaiters() {
+		t.Errorf("expected a delete after the stop to be emitted right away, got %d", count)
+	}
+}
@@ -0,0 +1,39 @@
+package pipeline
+
+import (
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+)
+
+// lastAppliedAnnotation is maintained by kubectl apply and holds a copy of the applied object
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// cacheTransformFor returns the transform trimming the objects before they enter the pipeline's informer cache,
+// nil unless the pipeline trims any part of them.
+// Unlike Transformers, it runs once per object received from the API server and changes what the cache holds,
+// hence what the handlers and DELETE tombstones see.
+func cacheTransformFor(config internalconfig.PipelineConfig) cache.TransformFunc {
+	if len(config.CacheTrim) == 0 {
+		return nil
+	}
+	trim := append([]string{}, config.CacheTrim...)
+	return func(obj interface{}) (interface{}, error) {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			// f.e. the list objects are not trimmed, only their items
+			return obj, nil
+		}
+		for _, part := range trim {
+			switch part {
+			case internalconfig.CacheTrimManagedFields:
+				u.SetManagedFields(nil)
+			case internalconfig.CacheTrimLastApplied:
+				dropLastApplied(u)
+			case internalconfig.CacheTrimStatus:
+				unstructured.RemoveNestedField(u.Object, "status")
+			}
+		}
+		return u, nil
+	}
+}
@@ -0,0 +1,87 @@
+package pipeline
+
+import (
+	"reflect"
+	"testing"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/myntra/pipeline"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newUntrimmedPod(name string) *unstructured.Unstructured {
+	obj := newTestObject("v1", "Pod", "default", name)
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationApply}})
+	obj.SetAnnotations(map[string]string{
+		lastAppliedAnnotation: "{}",
+		"team":                "mesh",
+	})
+	obj.Object["spec"] = map[string]interface{}{"nodeName": "node-a"}
+	obj.Object["status"] = map[string]interface{}{"phase": "Running"}
+	return obj
+}
+
+func TestCacheTrim(t *testing.T) {
+	testCases := []struct {
+		name    string
+		trim    []string
+		trimmed func(*unstructured.Unstructured) *unstructured.Unstructured
+	}{
+		{
+			name: "disabled",
+			trimmed: func(obj *unstructured.Unstructured) *unstructured.Unstructured {
+				return obj
+			},
+		},
+		{
+			name: "all",
+			trim: internalconfig.CacheTrimFields,
+			trimmed: func(obj *unstructured.Unstructured) *unstructured.Unstructured {
+				obj.SetManagedFields(nil)
+				obj.SetAnnotations(map[string]string{"team": "mesh"})
+				delete(obj.Object, "status")
+				return obj
+			},
+		},
+		{
+			name: "status",
+			trim: []string{internalconfig.CacheTrimStatus},
+			trimmed: func(obj *unstructured.Unstructured) *unstructured.Unstructured {
+				delete(obj.Object, "status")
+				return obj
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			informers := newTestInformers(newUntrimmedPod("pod-a"))
+			config := internalconfig.PipelineConfig{Name: "pods.v1.", Events: []string{"ADDED"}, CacheTrim: tc.trim}
+			stores := make(map[string]cache.Store)
+			step := newRegisterInformerStep(newTestLogger(t), informers, nil, nil, config, internalconfig.GlobalSettings{}, &recordingWriter{}, "")
+			if result := step.Exec(&pipeline.Request{Data: stores}); result.Error != nil {
+				t.Fatal(result.Error)
+			}
+
+			stopChan := make(chan struct{})
+			defer close(stopChan)
+			// the shared informer of the resource, f.e. the one of the rollups, is not trimmed
+			shared := informers.factory.ForResource(schema.GroupVersionResource{Version: "v1", Resource: "pods"}).Informer()
+			informers.factory.Start(stopChan)
+			informers.start(stopChan)
+			informers.factory.WaitForCacheSync(stopChan)
+			waitFor(t, func() bool { return len(stores[config.Name].List()) == 1 })
+
+			cached := stores[config.Name].List()
+			expected := tc.trimmed(newUntrimmedPod("pod-a"))
+			if !reflect.DeepEqual(cached[0], expected) {
+				t.Errorf("expected cached object %v, got %v", expected.Object, cached[0].(*unstructured.Unstructured).Object)
+			}
+			if sharedCached := shared.GetStore().List(); len(sharedCached) != 1 || !reflect.DeepEqual(sharedCached[0], newUntrimmedPod("pod-a")) {
+				t.Errorf("expected the shared informer to cache the untrimmed object, got %v", sharedCached)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package pipeline
+
+import (
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// the container lists of a Pod, the status ones carry restartCount, ready and friends
+var containerListPaths = [][]string{
+	{"spec", "containers"},
+	{"spec", "initContainers"},
+	{"spec", "ephemeralContainers"},
+	{"status", "containerStatuses"},
+	{"status", "initContainerStatuses"},
+	{"status", "ephemeralContainerStatuses"},
+}
+
+// containerFieldsChanged reports whether the update changed one of the fields of any container,
+// adding or removing a container counts as a change
+func containerFieldsChanged(oldObj, obj *unstructured.Unstructured, fields []string) bool {
+	for _, path := range containerListPaths {
+		if !reflect.DeepEqual(containerFields(oldObj, path, fields), containerFields(obj, path, fields)) {
+			return true
+		}
+	}
+	return false
+}
+
+// containerFields returns the values of the fields of the containers at path, keyed by container name
+func containerFields(obj *unstructured.Unstructured, path []string, fields []string) map[string]map[string]interface{} {
+	containers, _, _ := unstructured.NestedSlice(obj.Object, path...)
+	values := make(map[string]map[string]interface{}, len(containers))
+	for _, c := range containers {
+		container, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := container["name"].(string)
+		monitored := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			if value, ok := container[field]; ok {
+				monitored[field] = value
+			}
+		}
+		values[name] = monitored
+	}
+	return values
+}
@@ -0,0 +1,75 @@
+package pipeline
+
+import (
+	"testing"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newTestPod(name, image string, restartCount int64) *unstructured.Unstructured {
+	pod := newTestObject("v1", "Pod", "default", name)
+	pod.SetResourceVersion("1")
+	_ = unstructured.SetNestedSlice(pod.Object, []interface{}{
+		map[string]interface{}{"name": "app", "image": image},
+	}, "spec", "containers")
+	_ = unstructured.SetNestedSlice(pod.Object, []interface{}{
+		map[string]interface{}{"name": "app", "image": image, "ready": true, "restartCount": restartCount},
+	}, "status", "containerStatuses")
+	_ = unstructured.SetNestedField(pod.Object, "Running", "status", "phase")
+	return pod
+}
+
+func TestContainerFieldChanges(t *testing.T) {
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:            "pods.v1.",
+		Events:          []string{"ADDED", "MODIFIED", "DELETED"},
+		ContainerFields: internalconfig.DefaultContainerFields,
+	}, internalconfig.GlobalSettings{}, writer, "")
+	handlers := ri.GetEventHandlers()
+	suppressedBefore := suppressedCount("pods.v1.", suppressedContainerUnchanged)
+
+	pod := newTestPod("web", "nginx:1.25", 0)
+
+	// a status tick unrelated to the containers
+	tick := pod.DeepCopy()
+	tick.SetResourceVersion("2")
+	_ = unstructured.SetNestedField(tick.Object, "10.0.0.1", "status", "podIP")
+	handlers.UpdateFunc(pod, tick)
+
+	if count := len(writer.writtenObjects()); count != 0 {
+		t.Errorf("expected the unrelated update to be suppressed, got %d objects", count)
+	}
+	if count := suppressedCount("pods.v1.", suppressedContainerUnchanged) - suppressedBefore; count != 1 {
+		t.Errorf("expected 1 event suppressed as %s, got %v", suppressedContainerUnchanged, count)
+	}
+
+	imageChange := newTestPod("web", "nginx:1.26", 0)
+	imageChange.SetResourceVersion("3")
+	handlers.UpdateFunc(tick, imageChange)
+
+	restart := newTestPod("web", "nginx:1.26", 1)
+	restart.SetResourceVersion("4")
+	handlers.UpdateFunc(imageChange, restart)
+
+	if count := len(writer.writtenObjects()); count != 2 {
+		t.Errorf("expected the image change and the restart to be emitted, got %d objects", count)
+	}
+}
+
+func TestContainerFieldsChangedOnContainerSetChange(t *testing.T) {
+	pod := newTestPod("web", "nginx:1.25", 0)
+	withSidecar := pod.DeepCopy()
+	_ = unstructured.SetNestedSlice(withSidecar.Object, []interface{}{
+		map[string]interface{}{"name": "app", "image": "nginx:1.25"},
+		map[string]interface{}{"name": "proxy", "image": "envoy:1.30"},
+	}, "spec", "containers")
+
+	if !containerFieldsChanged(pod, withSidecar, []string{"image"}) {
+		t.Error("expected an added container to be a change")
+	}
+	if containerFieldsChanged(pod, pod.DeepCopy(), []string{"image"}) {
+		t.Error("expected no change between identical pods")
+	}
+}
@@ -0,0 +1,185 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"golang.org/x/exp/slices"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/openapi"
+)
+
+// openAPISchema is the part of an OpenAPI v3 schema needed to find defaulted fields
+type openAPISchema struct {
+	Ref        string                    `json:"$ref,omitempty"`
+	AllOf      []*openAPISchema          `json:"allOf,omitempty"`
+	Default    json.RawMessage           `json:"default,omitempty"`
+	Properties map[string]*openAPISchema `json:"properties,omitempty"`
+	Items      *openAPISchema            `json:"items,omitempty"`
+	GVKs       []schema.GroupVersionKind `json:"x-kubernetes-group-version-kind,omitempty"`
+}
+
+type openAPIDocument struct {
+	Components struct {
+		Schemas map[string]*openAPISchema `json:"schemas"`
+	} `json:"components"`
+
+	// the schemas of the kinds, indexed once the document is fetched
+	kinds map[schema.GroupVersionKind]*openAPISchema
+}
+
+const openAPISchemaRefPrefix = "#/components/schemas/"
+
+// fields identifying the object, never pruned
+var unprunedFields = []string{"apiVersion", "kind", "metadata"}
+
+// DefaultsPruner removes the fields of objects which equal the defaults of their OpenAPI schema,
+// so emitted objects carry what was configured rather than what the API server filled in.
+// Objects of kinds without a schema are emitted as they are.
+type DefaultsPruner struct {
+	client openapi.Client
+
+	mu sync.Mutex
+	// documents by group version, nil when the schema is not available
+	documents map[schema.GroupVersion]*openAPIDocument
+}
+
+func NewDefaultsPruner(client openapi.Client) *DefaultsPruner {
+	return &DefaultsPruner{
+		client:    client,
+		documents: make(map[schema.GroupVersion]*openAPIDocument),
+	}
+}
+
+func (p *DefaultsPruner) Transform(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	gvk := obj.GroupVersionKind()
+	doc := p.document(gvk.GroupVersion())
+	if doc == nil {
+		return obj, nil
+	}
+	root := doc.kinds[gvk]
+	if root == nil {
+		return obj, nil
+	}
+
+	for field, value := range obj.Object {
+		if slices.Contains(unprunedFields, field) {
+			continue
+		}
+		if doc.prune(value, root.Properties[field]) {
+			delete(obj.Object, field)
+		}
+	}
+	return obj, nil
+}
+
+// document returns the OpenAPI document of the group version, fetched on first use
+func (p *DefaultsPruner) document(gv schema.GroupVersion) *openAPIDocument {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if doc, ok := p.documents[gv]; ok {
+		return doc
+	}
+	// a schema failing to load is remembered as missing, the objects are emitted unpruned
+	doc, _ := p.fetch(gv)
+	p.documents[gv] = doc
+	return doc
+}
+
+func (p *DefaultsPruner) fetch(gv schema.GroupVersion) (*openAPIDocument, error) {
+	paths, err := p.client.Paths()
+	if err != nil {
+		return nil, err
+	}
+	path := "apis/" + gv.Group + "/" + gv.Version
+	if gv.Group == "" {
+		path = "api/" + gv.Version
+	}
+	groupVersion, ok := paths[path]
+	if !ok {
+		return nil, nil
+	}
+	raw, err := groupVersion.Schema(runtime.ContentTypeJSON)
+	if err != nil {
+		return nil, err
+	}
+	doc := &openAPIDocument{}
+	if err := json.Unmarshal(raw, doc); err != nil {
+		return nil, err
+	}
+	doc.kinds = make(map[schema.GroupVersionKind]*openAPISchema)
+	for _, s := range doc.Components.Schemas {
+		for _, gvk := range s.GVKs {
+			doc.kinds[gvk] = s
+		}
+	}
+	return doc, nil
+}
+
+// resolve follows the reference of the schema, OpenAPI v3 wraps references in allOf
+func (d *openAPIDocument) resolve(s *openAPISchema) *openAPISchema {
+	for s != nil {
+		switch {
+		case s.Ref != "":
+			s = d.Components.Schemas[strings.TrimPrefix(s.Ref, openAPISchemaRefPrefix)]
+		case len(s.AllOf) == 1 && s.Properties == nil:
+			s = s.AllOf[0]
+		default:
+			return s
+		}
+	}
+	return nil
+}
+
+// prune removes the defaulted fields nested in value
+// and reports whether what is left of value equals its own default
+func (d *openAPIDocument) prune(value interface{}, s *openAPISchema) bool {
+	if s == nil {
+		return false
+	}
+	structure := d.resolve(s)
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if structure != nil {
+			for field, nested := range v {
+				if d.prune(nested, structure.Properties[field]) {
+					delete(v, field)
+				}
+			}
+		}
+	case []interface{}:
+		if structure != nil && structure.Items != nil {
+			for _, item := range v {
+				// items are never removed, that would change the meaning of the list
+				d.prune(item, structure.Items)
+			}
+		}
+	}
+	return equalsDefault(value, s.Default)
+}
+
+// equalsDefault compares in JSON, the defaults decode to other numeric types than the objects
+func equalsDefault(value interface{}, defaultValue json.RawMessage) bool {
+	if len(defaultValue) == 0 {
+		return false
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(defaultValue, &normalized); err != nil {
+		return false
+	}
+	expected, err := json.Marshal(normalized)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(encoded, expected)
+}
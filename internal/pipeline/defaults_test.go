@@ -0,0 +1,87 @@
+package pipeline
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/openapi/openapitest"
+)
+
+func newDefaultedPod() *unstructured.Unstructured {
+	pod := newTestObject("v1", "Pod", "default", "web")
+	pod.SetLabels(map[string]string{"app": "web"})
+	_ = unstructured.SetNestedSlice(pod.Object, []interface{}{
+		map[string]interface{}{
+			"name":      "app",
+			"image":     "nginx:1.25",
+			"resources": map[string]interface{}{},
+			"ports": []interface{}{
+				map[string]interface{}{"containerPort": int64(8080), "protocol": "TCP"},
+				map[string]interface{}{"containerPort": int64(5353), "protocol": "UDP"},
+			},
+		},
+	}, "spec", "containers")
+	return pod
+}
+
+func TestDefaultsPrunerPrunesDefaultedFields(t *testing.T) {
+	pruner := NewDefaultsPruner(openapitest.NewEmbeddedFileClient())
+
+	pod, err := pruner.Transform(newDefaultedPod())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	containers, _, _ := unstructured.NestedSlice(pod.Object, "spec", "containers")
+	expected := []interface{}{
+		map[string]interface{}{
+			"name":  "app",
+			"image": "nginx:1.25",
+			"ports": []interface{}{
+				// TCP is the default protocol
+				map[string]interface{}{"containerPort": int64(8080)},
+				map[string]interface{}{"containerPort": int64(5353), "protocol": "UDP"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(containers, expected) {
+		t.Errorf("expected containers %v, got %v", expected, containers)
+	}
+	if pod.GetName() != "web" || !reflect.DeepEqual(pod.GetLabels(), map[string]string{"app": "web"}) {
+		t.Errorf("expected the metadata to be kept, got %v", pod.Object["metadata"])
+	}
+}
+
+func TestDefaultsPrunerWithoutSchema(t *testing.T) {
+	testCases := []struct {
+		name   string
+		pruner *DefaultsPruner
+		obj    *unstructured.Unstructured
+	}{
+		{
+			name:   "unknown kind",
+			pruner: NewDefaultsPruner(openapitest.NewEmbeddedFileClient()),
+			obj:    newTestObject("example.com/v1", "Widget", "default", "widget-a"),
+		},
+		{
+			name:   "schemas unavailable",
+			pruner: NewDefaultsPruner(&openapitest.FakeClient{ForcedErr: errors.New("openapi not served")}),
+			obj:    newDefaultedPod(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expected := tc.obj.DeepCopy()
+			obj, err := tc.pruner.Transform(tc.obj)
+			if err != nil {
+				t.Fatalf("expected objects without schema to pass, got %v", err)
+			}
+			if !reflect.DeepEqual(obj, expected) {
+				t.Errorf("expected the object unchanged, got %v", obj.Object)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package pipeline
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/meshery/meshsync/pkg/model"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+)
+
+// how long a deleted object is remembered to explain deletion of its dependents
+const deletionTrackerTTL = 5 * time.Minute
+
+// deletionTracker remembers recently deleted objects across all pipelines
+type deletionTracker struct {
+	clock clock.Clock
+
+	mu      sync.Mutex
+	deleted map[types.UID]time.Time
+}
+
+func newDeletionTracker(c clock.Clock) *deletionTracker {
+	return &deletionTracker{
+		clock:   c,
+		deleted: make(map[types.UID]time.Time),
+	}
+}
+
+func (t *deletionTracker) recordDeleted(uid types.UID) {
+	if uid == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.clock.Now()
+	for k, deletedAt := range t.deleted {
+		if now.Sub(deletedAt) > deletionTrackerTTL {
+			delete(t.deleted, k)
+		}
+	}
+	t.deleted[uid] = now
+}
+
+func (t *deletionTracker) wasDeleted(uid types.UID) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	deletedAt, ok := t.deleted[uid]
+	return ok && t.clock.Now().Sub(deletedAt) <= deletionTrackerTTL
+}
+
+// deletionHint infers from the available signals why the object was deleted
+func (t *deletionTracker) deletionHint(obj *unstructured.Unstructured) string {
+	for _, owner := range obj.GetOwnerReferences() {
+		if t.wasDeleted(owner.UID) {
+			return model.DeletionHintOwnerRemoved
+		}
+	}
+
+	if hasTTL(obj) {
+		return model.DeletionHintTTL
+	}
+
+	if obj.GetDeletionTimestamp() != nil && len(obj.GetOwnerReferences()) == 0 {
+		// graceful deletion requested for an object nobody owns
+		return model.DeletionHintManual
+	}
+
+	return model.DeletionHintUnknown
+}
+
+// hasTTL reports whether the object is subject to time based cleanup,
+// either by the TTL-after-finished controller or a janitor annotation
+func hasTTL(obj *unstructured.Unstructured) bool {
+	if _, found, _ := unstructured.NestedInt64(obj.Object, "spec", "ttlSecondsAfterFinished"); found {
+		return true
+	}
+	for key := range obj.GetAnnotations() {
+		if key == "ttl" || strings.HasSuffix(key, "/ttl") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestDeletionHint(t *testing.T) {
+	log := newTestLogger(t)
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	deletions := newDeletionTracker(fakeClock)
+	events := []string{"ADDED", "MODIFIED", "DELETED"}
+
+	writer := &recordingWriter{}
+	replicasets := newRegisterInformerStep(log, nil, deletions, nil, internalconfig.PipelineConfig{Name: "replicasets.v1.apps", Events: events}, internalconfig.GlobalSettings{}, writer, "")
+	pods := newRegisterInformerStep(log, nil, deletions, nil, internalconfig.PipelineConfig{Name: "pods.v1.", Events: events}, internalconfig.GlobalSettings{}, writer, "")
+
+	owner := newTestObject("apps/v1", "ReplicaSet", "default", "web")
+	ownedPod := func(name string) *model.KubernetesResource {
+		pod := newTestObject("v1", "Pod", "default", name)
+		pod.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: owner.GetName(), UID: owner.GetUID()}})
+		pods.GetEventHandlers().DeleteFunc(cache.DeletedFinalStateUnknown{Key: "default/" + name, Obj: pod})
+		return &writer.objects[len(writer.objects)-1]
+	}
+
+	// owner is still around
+	if hint := ownedPod("web-1").Envelope.DeletionHint; hint != model.DeletionHintUnknown {
+		t.Errorf("expected %s hint while owner exists, got %s", model.DeletionHintUnknown, hint)
+	}
+
+	replicasets.GetEventHandlers().DeleteFunc(owner)
+	if hint := ownedPod("web-2").Envelope.DeletionHint; hint != model.DeletionHintOwnerRemoved {
+		t.Errorf("expected %s hint after owner was deleted, got %s", model.DeletionHintOwnerRemoved, hint)
+	}
+
+	// the owner's deletion is forgotten after a while
+	fakeClock.Step(deletionTrackerTTL + time.Second)
+	if hint := ownedPod("web-3").Envelope.DeletionHint; hint != model.DeletionHintUnknown {
+		t.Errorf("expected %s hint once owner deletion expired, got %s", model.DeletionHintUnknown, hint)
+	}
+
+	job := newTestObject("batch/v1", "Job", "default", "migrate")
+	job.Object["spec"] = map[string]interface{}{"ttlSecondsAfterFinished": int64(60)}
+	if hint := deletions.deletionHint(job); hint != model.DeletionHintTTL {
+		t.Errorf("expected %s hint, got %s", model.DeletionHintTTL, hint)
+	}
+
+	standalone := newTestObject("v1", "Pod", "default", "debug")
+	now := metav1.NewTime(fakeClock.Now())
+	standalone.SetDeletionTimestamp(&now)
+	if hint := deletions.deletionHint(standalone); hint != model.DeletionHintManual {
+		t.Errorf("expected %s hint, got %s", model.DeletionHintManual, hint)
+	}
+}
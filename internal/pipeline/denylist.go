@@ -0,0 +1,100 @@
+package pipeline
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/meshery/meshkit/logger"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// FieldDenylist strips the fields which must never leave the cluster, see MeshsyncConfig.FieldDenylist.
+// It is the last line of defence applied to every emitted object after all other transformations,
+// whatever the per-resource DropPaths, Mask or WebAssembly module left in.
+type FieldDenylist struct {
+	log   logger.Handler
+	exprs []string
+	paths []internalconfig.FieldPath
+
+	mu sync.Mutex
+	// the kinds and fields which have been warned about, so a kind is not warned about for every object
+	warned map[string]bool
+}
+
+// NewFieldDenylist returns the denylist of the JSONPath expressions, nil if there are none
+func NewFieldDenylist(log logger.Handler, exprs []string) (*FieldDenylist, error) {
+	if len(exprs) == 0 {
+		return nil, nil
+	}
+	paths := make([]internalconfig.FieldPath, 0, len(exprs))
+	for _, expr := range exprs {
+		path, err := internalconfig.ParseFieldPath(expr)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return &FieldDenylist{log: log, exprs: exprs, paths: paths, warned: make(map[string]bool)}, nil
+}
+
+// Transform removes the denied fields and logs the expressions of the ones the object had, never their values.
+// The first object of a kind having these fields is logged as a warning, the following ones at debug level.
+func (d *FieldDenylist) Transform(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	stripped := make([]string, 0)
+	for i, path := range d.paths {
+		if !selects(obj.Object, path) {
+			continue
+		}
+		obj.Object = drop(obj.Object, path).(map[string]interface{})
+		stripped = append(stripped, d.exprs[i])
+	}
+	if len(stripped) > 0 {
+		d.logStripped(obj, strings.Join(stripped, ", "))
+	}
+	return obj, nil
+}
+
+func (d *FieldDenylist) logStripped(obj *unstructured.Unstructured, fields string) {
+	key := obj.GroupVersionKind().String() + "|" + fields
+	d.mu.Lock()
+	warned := d.warned[key]
+	d.warned[key] = true
+	d.mu.Unlock()
+
+	if warned {
+		d.log.Debugf("Stripped the denied fields %s of %s/%s of kind %s", fields, obj.GetName(), obj.GetNamespace(), obj.GetKind())
+		return
+	}
+	d.log.Warnf("Stripping the denied fields %s of the objects of kind %s, starting with %s/%s", fields, obj.GetKind(), obj.GetName(), obj.GetNamespace())
+}
+
+// selects reports whether path selects any value below node
+func selects(node interface{}, path internalconfig.FieldPath) bool {
+	if len(path) == 0 {
+		return false
+	}
+	segment, rest := path[0], path[1:]
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for key, value := range n {
+			if segment.MatchesKey(key) && (len(rest) == 0 || selects(value, rest)) {
+				return true
+			}
+			if segment.Descendant && selects(value, path) {
+				return true
+			}
+		}
+	case []interface{}:
+		for i, item := range n {
+			if segment.MatchesItem(i, item) && (len(rest) == 0 || selects(item, rest)) {
+				return true
+			}
+			if segment.Descendant && selects(item, path) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package pipeline
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/meshery/meshkit/logger"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestFieldDenylistOverridesResourceConfig(t *testing.T) {
+	denylist := []string{"$..caBundle", "$.spec.kubeconfig"}
+	testCases := []struct {
+		name   string
+		config internalconfig.PipelineConfig
+	}{
+		{name: "no transformation", config: internalconfig.PipelineConfig{}},
+		{name: "masked instead of dropped", config: internalconfig.PipelineConfig{Mask: []string{"$..caBundle", "$.spec.kubeconfig"}}},
+		{name: "other fields dropped", config: internalconfig.PipelineConfig{DropPaths: []string{"$.spec.server"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := tc.config
+			config.Name = "mutatingwebhookconfigurations.v1.admissionregistration.k8s.io"
+			config.Events = []string{"ADDED"}
+			settings := internalconfig.GlobalSettings{FieldDenylist: denylist}
+			ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, config, settings, &recordingWriter{}, "")
+
+			webhook := newTestWebhookConfig()
+			result, err := transform(webhook, ri.transformers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			webhooks, _, _ := unstructured.NestedSlice(result.Object, "webhooks")
+			if _, found, _ := unstructured.NestedFieldNoCopy(webhooks[0].(map[string]interface{}), "clientConfig", "caBundle"); found {
+				t.Error("expected the denied caBundle to be stripped")
+			}
+			if _, found, _ := unstructured.NestedFieldNoCopy(result.Object, "spec", "kubeconfig"); found {
+				t.Error("expected the denied kubeconfig to be stripped")
+			}
+			if _, found, _ := unstructured.NestedFieldNoCopy(webhook.Object, "spec", "kubeconfig"); !found {
+				t.Error("informer object must not be mutated by the denylist")
+			}
+		})
+	}
+}
+
+func TestFieldDenylistSelects(t *testing.T) {
+	webhook := newTestWebhookConfig()
+	testCases := []struct {
+		expr     string
+		expected bool
+	}{
+		{expr: "$..caBundle", expected: true},
+		{expr: "$.webhooks[?(@.name=='sidecar-*')].clientConfig.service.name", expected: true},
+		{expr: "$.webhooks[?(@.name=='other-*')].clientConfig", expected: false},
+		{expr: "$.spec.certificate", expected: false},
+	}
+	for _, tc := range testCases {
+		path, err := internalconfig.ParseFieldPath(tc.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if selected := selects(webhook.Object, path); selected != tc.expected {
+			t.Errorf("expected %s to select a field: %t, got %t", tc.expr, tc.expected, selected)
+		}
+	}
+}
+
+func TestFieldDenylistWarnsOncePerKind(t *testing.T) {
+	logged := &bytes.Buffer{}
+	log, err := logger.New("meshsync-test", logger.Options{Format: logger.SyslogLogFormat, Output: logged, LogLevel: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	denylist, err := NewFieldDenylist(log, []string{"$..caBundle"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		webhook := newTestWebhookConfig()
+		webhook.SetName(fmt.Sprintf("sidecar-injector-%d", i))
+		if _, err := denylist.Transform(webhook); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if warnings := strings.Count(logged.String(), "level=warning"); warnings != 1 {
+		t.Errorf("expected a single warning for the kind, got %d in %s", warnings, logged.String())
+	}
+	if debug := strings.Count(logged.String(), "level=debug"); debug != 2 {
+		t.Errorf("expected the following objects at debug level, got %d in %s", debug, logged.String())
+	}
+}
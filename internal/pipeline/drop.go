@@ -0,0 +1,72 @@
+package pipeline
+
+import (
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// dropTransformer removes the fields selected by the drop paths, keys and list items alike
+type dropTransformer struct {
+	paths []internalconfig.FieldPath
+}
+
+func newDropTransformer(exprs []string) (*dropTransformer, error) {
+	paths := make([]internalconfig.FieldPath, 0, len(exprs))
+	for _, expr := range exprs {
+		path, err := internalconfig.ParseFieldPath(expr)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return &dropTransformer{paths: paths}, nil
+}
+
+func (d *dropTransformer) Transform(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	for _, path := range d.paths {
+		obj.Object = drop(obj.Object, path).(map[string]interface{})
+	}
+	return obj, nil
+}
+
+// drop removes the values selected by path below node, lists are replaced when items are removed,
+// hence the caller stores the returned node
+func drop(node interface{}, path internalconfig.FieldPath) interface{} {
+	if len(path) == 0 {
+		return node
+	}
+	segment, rest := path[0], path[1:]
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for key, value := range n {
+			if segment.MatchesKey(key) {
+				if len(rest) == 0 {
+					delete(n, key)
+					continue
+				}
+				value = drop(value, rest)
+				n[key] = value
+			}
+			if segment.Descendant {
+				n[key] = drop(value, path)
+			}
+		}
+	case []interface{}:
+		kept := n[:0]
+		for i, item := range n {
+			if segment.MatchesItem(i, item) {
+				if len(rest) == 0 {
+					continue
+				}
+				item = drop(item, rest)
+			}
+			if segment.Descendant {
+				item = drop(item, path)
+			}
+			kept = append(kept, item)
+		}
+		return kept
+	}
+	return node
+}
@@ -0,0 +1,116 @@
+package pipeline
+
+import (
+	"reflect"
+	"testing"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newTestWebhookConfig() *unstructured.Unstructured {
+	webhook := newTestObject("admissionregistration.k8s.io/v1", "MutatingWebhookConfiguration", "", "injector")
+	_ = unstructured.SetNestedSlice(webhook.Object, []interface{}{
+		map[string]interface{}{
+			"name": "sidecar-injector.istio.io",
+			"clientConfig": map[string]interface{}{
+				"caBundle": "LS0tLS1CRUdJTi...",
+				"service":  map[string]interface{}{"name": "istiod", "namespace": "istio-system"},
+			},
+		},
+	}, "webhooks")
+	_ = unstructured.SetNestedField(webhook.Object, "LS0tLS1CRUdJTi...", "spec", "kubeconfig")
+	_ = unstructured.SetNestedField(webhook.Object, "istiod", "spec", "server")
+	return webhook
+}
+
+func TestDropTransform(t *testing.T) {
+	testCases := []struct {
+		name     string
+		paths    []string
+		dropped  [][]string
+		retained [][]string
+	}{
+		{
+			name:     "map entry",
+			paths:    []string{"$.spec.kubeconfig"},
+			dropped:  [][]string{{"spec", "kubeconfig"}},
+			retained: [][]string{{"spec", "server"}, {"webhooks"}},
+		},
+		{
+			name:     "descendant",
+			paths:    []string{"$..caBundle"},
+			dropped:  [][]string{{"clientConfig", "caBundle"}},
+			retained: [][]string{{"clientConfig", "service"}, {"spec", "kubeconfig"}},
+		},
+		{
+			name:     "list item field",
+			paths:    []string{"$.webhooks[?(@.name=='sidecar-*')].clientConfig.caBundle", "spec.kubeconfig"},
+			dropped:  [][]string{{"clientConfig", "caBundle"}, {"spec", "kubeconfig"}},
+			retained: [][]string{{"clientConfig", "service"}, {"name"}, {"spec", "server"}},
+		},
+		{
+			name:     "no match",
+			paths:    []string{"$.spec.certificate"},
+			retained: [][]string{{"spec", "kubeconfig"}, {"spec", "server"}, {"clientConfig", "caBundle"}},
+		},
+	}
+
+	// paths starting with clientConfig or name are looked up in the first webhook
+	lookup := func(obj *unstructured.Unstructured, path []string) bool {
+		if path[0] == "clientConfig" || path[0] == "name" {
+			webhooks, _, _ := unstructured.NestedSlice(obj.Object, "webhooks")
+			_, found, _ := unstructured.NestedFieldNoCopy(webhooks[0].(map[string]interface{}), path...)
+			return found
+		}
+		_, found, _ := unstructured.NestedFieldNoCopy(obj.Object, path...)
+		return found
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := internalconfig.PipelineConfig{
+				Name:      "mutatingwebhookconfigurations.v1.admissionregistration.k8s.io",
+				Events:    []string{"ADDED"},
+				DropPaths: tc.paths,
+			}
+			ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, config, internalconfig.GlobalSettings{}, &recordingWriter{}, "")
+
+			webhook := newTestWebhookConfig()
+			result, err := transform(webhook, ri.transformers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, path := range tc.dropped {
+				if lookup(result, path) {
+					t.Errorf("expected %v to be dropped", path)
+				}
+				if !lookup(webhook, path) {
+					t.Errorf("informer object must not be mutated by transformers, %v is missing", path)
+				}
+			}
+			for _, path := range tc.retained {
+				if !lookup(result, path) {
+					t.Errorf("expected %v to remain", path)
+				}
+			}
+		})
+	}
+}
+
+func TestDropListItems(t *testing.T) {
+	pod := newTestPodWithEnv(envVar("API_TOKEN", "s3cr3t"), envVar("LOG_LEVEL", "debug"), envVar("GITHUB_TOKEN", "ghp-secret"))
+
+	dropper, err := newDropTransformer([]string{"$..env[?(@.name=='*_TOKEN')]"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, _ := dropper.Transform(pod)
+
+	containers, _, _ := unstructured.NestedSlice(result.Object, "spec", "containers")
+	env := containers[0].(map[string]interface{})["env"]
+	expected := []interface{}{envVar("LOG_LEVEL", "debug")}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("expected env %v, got %v", expected, env)
+	}
+}
@@ -0,0 +1,215 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+	"github.com/myntra/pipeline"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPinnedEnvelopeVersion(t *testing.T) {
+	testCases := []struct {
+		version         string
+		expectedVersion string
+		expectedAdd     []string
+		expectedDelete  []string
+	}{
+		{version: model.SchemaVersionV1},
+		{version: model.SchemaVersionV2, expectedVersion: model.SchemaVersionV2, expectedAdd: []string{"schema_version"}, expectedDelete: []string{"deletion_hint", "schema_version"}},
+		{version: model.SchemaVersionV3, expectedVersion: model.SchemaVersionV3, expectedAdd: []string{"namespace_annotations", "namespace_labels", "schema_version"}, expectedDelete: []string{"deletion_hint", "namespace_annotations", "namespace_labels", "schema_version"}},
+		{version: model.SchemaVersionV4, expectedVersion: model.SchemaVersionV4, expectedAdd: []string{"namespace_annotations", "namespace_labels", "schema_version"}, expectedDelete: []string{"deletion_hint", "namespace_annotations", "namespace_labels", "schema_version"}},
+		{version: model.SchemaVersionV5, expectedVersion: model.SchemaVersionV5, expectedAdd: []string{"namespace_annotations", "namespace_labels", "schema_version"}, expectedDelete: []string{"deletion_hint", "namespace_annotations", "namespace_labels", "schema_version"}},
+		{version: "", expectedVersion: model.LatestSchemaVersion, expectedAdd: []string{"namespace_annotations", "namespace_labels", "schema_version"}, expectedDelete: []string{"deletion_hint", "namespace_annotations", "namespace_labels", "schema_version"}},
+	}
+	informers := newTestInformers(newTestNamespace("default", map[string]string{"env": "prod"}))
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+
+	for _, tc := range testCases {
+		t.Run("version "+tc.version, func(t *testing.T) {
+			writer := &recordingWriter{}
+			ri := newRegisterInformerStep(newTestLogger(t), informers, nil, nil, internalconfig.PipelineConfig{
+				Name:   "pods.v1.",
+				Events: []string{"ADDED", "MODIFIED", "DELETED"},
+			}, internalconfig.GlobalSettings{
+				EnvelopeVersion:   tc.version,
+				NamespaceMetadata: true,
+			}, writer, "")
+			syncNamespaces(t, ri, stopChan)
+
+			pod := newTestObject("v1", "Pod", "default", "web")
+			ri.GetEventHandlers().AddFunc(pod)
+			ri.GetEventHandlers().DeleteFunc(pod)
+
+			if len(writer.objects) != 2 {
+				t.Fatalf("expected 2 events, got %d", len(writer.objects))
+			}
+			if fields := envelopeFields(t, writer.objects[0]); !reflect.DeepEqual(fields, tc.expectedAdd) {
+				t.Errorf("expected ADDED envelope fields %v, got %v", tc.expectedAdd, fields)
+			}
+			if fields := envelopeFields(t, writer.objects[1]); !reflect.DeepEqual(fields, tc.expectedDelete) {
+				t.Errorf("expected DELETED envelope fields %v, got %v", tc.expectedDelete, fields)
+			}
+			if tc.expectedVersion != "" && writer.objects[0].Envelope.SchemaVersion != tc.expectedVersion {
+				t.Errorf("expected schema version %s, got %s", tc.expectedVersion, writer.objects[0].Envelope.SchemaVersion)
+			}
+		})
+	}
+}
+
+func TestNamespaceMetadataEnrichment(t *testing.T) {
+	informers := newTestInformers(newTestNamespace("default", map[string]string{"env": "prod", "tenant": "acme"}))
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+
+	for _, enabled := range []bool{true, false} {
+		writer := &recordingWriter{}
+		ri := newRegisterInformerStep(newTestLogger(t), informers, nil, nil, internalconfig.PipelineConfig{
+			Name:   "pods.v1.",
+			Events: []string{"ADDED"},
+		}, internalconfig.GlobalSettings{NamespaceMetadata: enabled}, writer, "")
+		syncNamespaces(t, ri, stopChan)
+
+		ri.GetEventHandlers().AddFunc(newTestObject("v1", "Pod", "default", "web"))
+		ri.GetEventHandlers().AddFunc(newTestObject("v1", "Node", "", "node-1"))
+
+		expected := map[string]string(nil)
+		if enabled {
+			expected = map[string]string{"env": "prod", "tenant": "acme"}
+		}
+		if labels := writer.objects[0].Envelope.NamespaceLabels; !reflect.DeepEqual(labels, expected) {
+			t.Errorf("enabled %t: expected namespace labels %v, got %v", enabled, expected, labels)
+		}
+		if annotations := writer.objects[0].Envelope.NamespaceAnnotations; enabled && annotations["owner"] != "team-a" {
+			t.Errorf("expected namespace annotations, got %v", annotations)
+		}
+		if labels := writer.objects[1].Envelope.NamespaceLabels; labels != nil {
+			t.Errorf("expected no namespace labels for cluster scoped object, got %v", labels)
+		}
+	}
+}
+
+func newTestNamespace(name string, labels map[string]string) *unstructured.Unstructured {
+	ns := newTestObject("v1", "Namespace", "", name)
+	ns.SetLabels(labels)
+	ns.SetAnnotations(map[string]string{"owner": "team-a"})
+	return ns
+}
+
+// syncNamespaces registers the step's informers and waits for the namespaces informer to sync
+func syncNamespaces(t *testing.T, ri *RegisterInformer, stopChan chan struct{}) {
+	t.Helper()
+	if result := ri.Exec(&pipeline.Request{}); result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	ri.informers.factory.Start(stopChan)
+	ri.informers.factory.WaitForCacheSync(stopChan)
+}
+
+func TestHealthEnrichment(t *testing.T) {
+	informers := newTestInformers()
+	pod := newTestObject("v1", "Pod", "default", "web")
+	if err := unstructured.SetNestedField(pod.Object, "Running", "status", "phase"); err != nil {
+		t.Fatal(err)
+	}
+	if err := unstructured.SetNestedSlice(pod.Object, []interface{}{
+		map[string]interface{}{"type": "Ready", "status": "True"},
+	}, "status", "conditions"); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		settings internalconfig.GlobalSettings
+		expected []string
+	}{
+		{
+			name:     "enabled",
+			settings: internalconfig.GlobalSettings{ComputeHealth: true},
+			expected: []string{model.HealthHealthy, ""},
+		},
+		{
+			name:     "disabled",
+			settings: internalconfig.GlobalSettings{},
+			expected: []string{"", ""},
+		},
+		{
+			name:     "envelope version without health",
+			settings: internalconfig.GlobalSettings{ComputeHealth: true, EnvelopeVersion: model.SchemaVersionV4},
+			expected: []string{"", ""},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			writer := &recordingWriter{}
+			config := internalconfig.PipelineConfig{Name: "pods.v1.", Events: []string{"ADDED", "DELETED"}}
+			ri := newRegisterInformerStep(newTestLogger(t), informers, nil, nil, config, tc.settings, writer, "")
+
+			ri.GetEventHandlers().AddFunc(pod)
+			ri.GetEventHandlers().DeleteFunc(pod)
+
+			if len(writer.objects) != 2 {
+				t.Fatalf("expected 2 events, got %d", len(writer.objects))
+			}
+			health := make([]string, 0, len(writer.objects))
+			for _, obj := range writer.objects {
+				health = append(health, obj.Envelope.Health)
+			}
+			if !reflect.DeepEqual(health, tc.expected) {
+				t.Errorf("expected the health %q of the ADDED and DELETED events, got %q", tc.expected, health)
+			}
+		})
+	}
+}
+
+func TestHealthEvaluatedBeforeTransformers(t *testing.T) {
+	pod := newTestObject("v1", "Pod", "default", "web")
+	if err := unstructured.SetNestedField(pod.Object, "Running", "status", "phase"); err != nil {
+		t.Fatal(err)
+	}
+	if err := unstructured.SetNestedSlice(pod.Object, []interface{}{
+		map[string]interface{}{"type": "Ready", "status": "True"},
+	}, "status", "conditions"); err != nil {
+		t.Fatal(err)
+	}
+
+	writer := &recordingWriter{}
+	config := internalconfig.PipelineConfig{Name: "pods.v1.", Events: []string{"ADDED"}, StripStatus: true}
+	ri := newRegisterInformerStep(newTestLogger(t), newTestInformers(), nil, nil, config, internalconfig.GlobalSettings{ComputeHealth: true}, writer, "")
+	ri.GetEventHandlers().AddFunc(pod)
+
+	if len(writer.objects) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(writer.objects))
+	}
+	// the status is stripped from the emitted object, the health is still the one of the cached object
+	if health := writer.objects[0].Envelope.Health; health != model.HealthHealthy {
+		t.Errorf("expected the health %q, got %q", model.HealthHealthy, health)
+	}
+}
+
+// envelopeFields returns the sorted envelope fields of the serialized object
+func envelopeFields(t *testing.T, obj model.KubernetesResource) []string {
+	t.Helper()
+	data, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serialized := struct {
+		Envelope map[string]interface{} `json:"envelope"`
+	}{}
+	if err := json.Unmarshal(data, &serialized); err != nil {
+		t.Fatal(err)
+	}
+
+	var fields []string
+	for field := range serialized.Envelope {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
@@ -1,15 +1,25 @@
 package pipeline
 
 import (
+	"strings"
+
 	"github.com/meshery/meshkit/errors"
 )
 
 const (
-	ErrListCode          = "1001"
-	ErrPublishCode       = "1002"
-	ErrDynamicClientCode = "1003"
-	ErrCacheSyncCode     = "1014"
-	ErrWriteOutputCode   = "1015"
+	ErrListCode              = "1001"
+	ErrPublishCode           = "1002"
+	ErrDynamicClientCode     = "1003"
+	ErrCacheSyncCode         = "1014"
+	ErrWriteOutputCode       = "1015"
+	ErrTransformCode         = "1016"
+	ErrAddHandlerCode        = "1018"
+	ErrSetTransformCode      = "1019"
+	ErrBackfillCode          = "1021"
+	ErrResyncObjectCode      = "1022"
+	ErrMissingNamespacesCode = "1023"
+	ErrStartupLeaseCode      = "1024"
+	ErrFullSyncCode          = "1025"
 )
 
 func ErrDynamicClient(name string, err error) error {
@@ -31,3 +41,35 @@ func ErrCacheSync(name string, err error) error {
 func ErrWriteOutput(name string, err error) error {
 	return errors.New(ErrWriteOutputCode, errors.Alert, []string{"Error while writing output for: " + name, err.Error()}, []string{}, []string{}, []string{})
 }
+
+func ErrTransform(name string, err error) error {
+	return errors.New(ErrTransformCode, errors.Alert, []string{"Error while transforming object for: " + name, err.Error()}, []string{}, []string{}, []string{})
+}
+
+func ErrAddHandler(name string, err error) error {
+	return errors.New(ErrAddHandlerCode, errors.Alert, []string{"Error while adding the event handler for: " + name, err.Error()}, []string{}, []string{}, []string{})
+}
+
+func ErrSetTransform(name string, err error) error {
+	return errors.New(ErrSetTransformCode, errors.Alert, []string{"Error while setting the cache transform for: " + name, err.Error()}, []string{}, []string{}, []string{})
+}
+
+func ErrBackfill(name string, err error) error {
+	return errors.New(ErrBackfillCode, errors.Alert, []string{"Error while replaying the history before the initial list of: " + name, err.Error()}, []string{}, []string{"The revisions are no longer in the watch cache of the API server."}, []string{"Lower the number of backfilled revisions."})
+}
+
+func ErrResyncObject(name string, err error) error {
+	return errors.New(ErrResyncObjectCode, errors.Alert, []string{"Error while re-emitting an object of: " + name, err.Error()}, []string{}, []string{"The object does not exist or its resource is not watched."}, []string{"Check the resource, namespace and name of the object."})
+}
+
+func ErrMissingNamespaces(name string, namespaces []string) error {
+	return errors.New(ErrMissingNamespacesCode, errors.Alert, []string{"Namespaces of: " + name + " do not exist: " + strings.Join(namespaces, ", ")}, []string{"Nothing is watched in these namespaces until they are created."}, []string{"The namespaces the pipeline is scoped to have not been created yet or have been deleted."}, []string{"Create the namespaces or remove them from the namespaces of the resource."})
+}
+
+func ErrStartupLease(name string, err error) error {
+	return errors.New(ErrStartupLeaseCode, errors.Alert, []string{"Error while reserving a start slot in the startup lease: " + name, err.Error()}, []string{"The informers start after the jitter only, not staggered with the other replicas."}, []string{"MeshSync is not allowed to get, create or update leases in its namespace."}, []string{"Grant MeshSync access to leases of the coordination.k8s.io group in its namespace."})
+}
+
+func ErrFullSync(err error) error {
+	return errors.New(ErrFullSyncCode, errors.Alert, []string{"Error while re-emitting the cached objects in a full sync", err.Error()}, []string{"The objects of the remaining resources have not been re-emitted."}, []string{"Another full sync is running or the full sync has been cancelled."}, []string{"Wait for the running full sync to complete and trigger it again."})
+}
@@ -2,11 +2,14 @@ package pipeline
 
 import (
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/meshery/meshkit/broker"
 	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/internal/output"
 	"github.com/meshery/meshsync/pkg/model"
 	"golang.org/x/exp/slices"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -16,36 +19,26 @@ import (
 func (ri *RegisterInformer) GetEventHandlers() cache.ResourceEventHandlerFuncs {
 	return cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
-			err := ri.publishItem(obj.(*unstructured.Unstructured), broker.Add, ri.config)
-			if err != nil {
-				ri.log.Error(err)
+			ri.backfills.wait()
+			ri.retained.observe(obj.(*unstructured.Unstructured))
+			ri.rollups.observe(obj.(*unstructured.Unstructured))
+			ri.relationships.observe(ri.config.Name, obj.(*unstructured.Unstructured))
+			if err := ri.publishItem(obj.(*unstructured.Unstructured), broker.Add, ri.config); err != nil {
+				ri.publishFailed(obj.(*unstructured.Unstructured), broker.Add, err)
 			}
-			ri.log.Info("Received ADD event for: ", obj.(*unstructured.Unstructured).GetName(), "/", obj.(*unstructured.Unstructured).GetNamespace(), " of kind: ", obj.(*unstructured.Unstructured).GroupVersionKind().Kind)
+			ri.objectLog.Info("Received ADD event for: ", obj.(*unstructured.Unstructured).GetName(), "/", obj.(*unstructured.Unstructured).GetNamespace(), " of kind: ", obj.(*unstructured.Unstructured).GroupVersionKind().Kind)
+			// f.e. listed while terminating
+			ri.deleteIfTerminating(obj.(*unstructured.Unstructured))
 		},
 		UpdateFunc: func(oldObj, obj interface{}) {
-			oldObjCasted := oldObj.(*unstructured.Unstructured)
-			objCasted := obj.(*unstructured.Unstructured)
-
-			oldRV, _ := strconv.ParseInt(oldObjCasted.GetResourceVersion(), 0, 64)
-			newRV, _ := strconv.ParseInt(objCasted.GetResourceVersion(), 0, 64)
-
-			if oldRV < newRV {
-				err := ri.publishItem(obj.(*unstructured.Unstructured), broker.Update, ri.config)
-
-				if err != nil {
-					ri.log.Error(err)
-				}
-				ri.log.Info("Received UPDATE event for: ", obj.(*unstructured.Unstructured).GetName(), "/", obj.(*unstructured.Unstructured).GetNamespace(), " of kind: ", obj.(*unstructured.Unstructured).GroupVersionKind().Kind)
-			} else {
-				ri.log.Debug(fmt.Sprintf(
-					"Skipping UPDATE event for: %s => [No changes detected]: %d %d",
-					objCasted.GetName(),
-					oldRV,
-					newRV,
-				))
-			}
+			ri.backfills.wait()
+			ri.retained.observe(obj.(*unstructured.Unstructured))
+			ri.rollups.observe(obj.(*unstructured.Unstructured))
+			ri.relationships.observe(ri.config.Name, obj.(*unstructured.Unstructured))
+			ri.handleUpdate(oldObj.(*unstructured.Unstructured), obj.(*unstructured.Unstructured))
 		},
 		DeleteFunc: func(obj interface{}) {
+			ri.backfills.wait()
 			// the obj can only be of two types, Unstructured or DeletedFinalStateUnknown.
 			// DeletedFinalStateUnknown means that the object that we receive may be `stale`
 			// because of the way informer behaves
@@ -53,61 +46,326 @@ func (ri *RegisterInformer) GetEventHandlers() cache.ResourceEventHandlerFuncs {
 			// refer 'https://pkg.go.dev/k8s.io/client-go/tools/cache#ResourceEventHandler.OnDelete'
 
 			var objCasted *unstructured.Unstructured
-			objCasted = obj.(*unstructured.Unstructured)
-
-			possiblyStaleObj, ok := obj.(cache.DeletedFinalStateUnknown)
-			if ok {
-				objCasted = possiblyStaleObj.Obj.(*unstructured.Unstructured)
+			switch o := obj.(type) {
+			case *unstructured.Unstructured:
+				objCasted = o
+			case cache.DeletedFinalStateUnknown:
+				objCasted, _ = o.Obj.(*unstructured.Unstructured)
+				if objCasted == nil {
+					// the tombstone lost the object, fall back to its last known state
+					if retained, ok := ri.retained.lookup(o.Key); ok {
+						ri.objectLog.Debug("Enriching DELETE event for: ", o.Key, " => [Last known state]")
+						objCasted = retained
+					}
+				}
 			}
-			err := ri.publishItem(objCasted, broker.Delete, ri.config)
-
-			if err != nil {
-				ri.log.Error(err)
+			if objCasted == nil {
+				ri.log.Warnf("Skipping DELETE event for unexpected object of type %T", obj)
+				return
+			}
+			ri.retained.forget(objCasted)
+			ri.rollups.remove(objCasted)
+			ri.relationships.remove(ri.config.Name, objCasted)
+			if ri.terminating.removed(objCasted) {
+				// emitted when its deletionTimestamp was set
+				if slices.Contains(ri.config.Events, string(broker.Delete)) {
+					ri.suppressed(suppressedTerminating, 1)
+				}
+				ri.objectLog.Debug("Skipping DELETE event for: ", objCasted.GetName(), " => [Deleted on deletionTimestamp]")
+				return
 			}
-			ri.log.Info("Received DELETE event for: ", obj.(*unstructured.Unstructured).GetName(), "/", obj.(*unstructured.Unstructured).GetNamespace(), " of kind: ", obj.(*unstructured.Unstructured).GroupVersionKind().Kind)
+			ri.handleDelete(objCasted)
 		},
 	}
 }
 
-func (ri *RegisterInformer) registerHandlers(s cache.SharedIndexInformer) {
-	s.AddEventHandler(ri.GetEventHandlers()) // nolint
+// handleDelete publishes the DELETE event of the object
+func (ri *RegisterInformer) handleDelete(obj *unstructured.Unstructured) {
+	ri.deletions.recordDeleted(obj.GetUID())
+	if ri.singletons != nil {
+		ri.singletons.forget(ri.identityOf(obj), obj.GetUID())
+	}
+
+	ri.publishDelete(obj)
+	ri.objectLog.Info("Received DELETE event for: ", obj.GetName(), "/", obj.GetNamespace(), " of kind: ", obj.GroupVersionKind().Kind)
+}
+
+// handleUpdate publishes the UPDATE event unless it carries no change worth emitting
+func (ri *RegisterInformer) handleUpdate(oldObj, obj *unstructured.Unstructured) {
+	terminating := ri.deleteIfTerminating(obj)
+	if !slices.Contains(ri.config.Events, string(broker.Update)) {
+		return
+	}
+	if terminating {
+		// the object was deleted as far as the consumers are concerned
+		ri.suppressed(suppressedTerminating, 1)
+		ri.objectLog.Debug("Skipping UPDATE event for: ", obj.GetName(), " => [Terminating]")
+		return
+	}
+
+	oldRV, _ := strconv.ParseInt(oldObj.GetResourceVersion(), 0, 64)
+	newRV, _ := strconv.ParseInt(obj.GetResourceVersion(), 0, 64)
+
+	switch {
+	case oldRV >= newRV:
+		ri.suppressed(suppressedDuplicate, 1)
+		ri.objectLog.Debug(fmt.Sprintf(
+			"Skipping UPDATE event for: %s => [No changes detected]: %d %d",
+			obj.GetName(),
+			oldRV,
+			newRV,
+		))
+	case ri.config.GenerationChangeOnly && !generationIncreased(oldObj, obj):
+		ri.suppressed(suppressedGenerationUnchanged, 1)
+		ri.objectLog.Debug("Skipping UPDATE event for: ", obj.GetName(), " => [Generation unchanged]")
+	case ri.config.StripStatus && statusOnlyChange(oldObj, obj):
+		// the emitted object would be identical to the previous one
+		ri.suppressed(suppressedStatusOnly, 1)
+		ri.objectLog.Debug("Skipping UPDATE event for: ", obj.GetName(), " => [Status only]")
+	case len(ri.config.ContainerFields) > 0 && !containerFieldsChanged(oldObj, obj, ri.config.ContainerFields):
+		ri.suppressed(suppressedContainerUnchanged, 1)
+		ri.objectLog.Debug("Skipping UPDATE event for: ", obj.GetName(), " => [No monitored container changes]")
+	case ri.monitorsMetadata() && !metadataKeysChanged(oldObj, obj, ri.config.MetadataLabels, ri.config.MetadataAnnotations):
+		ri.suppressed(suppressedMetadataUnchanged, 1)
+		ri.objectLog.Debug("Skipping UPDATE event for: ", obj.GetName(), " => [No monitored label or annotation changes]")
+	default:
+		ri.publishUpdate(obj)
+		ri.objectLog.Info("Received UPDATE event for: ", obj.GetName(), "/", obj.GetNamespace(), " of kind: ", obj.GroupVersionKind().Kind)
+	}
+}
+
+// publishUpdate publishes the UPDATE event, coalesced when the resource is a singleton
+func (ri *RegisterInformer) publishUpdate(obj *unstructured.Unstructured) {
+	publish := func() {
+		if err := ri.publishItem(obj, broker.Update, ri.config); err != nil {
+			ri.publishFailed(obj, broker.Update, err)
+		}
+	}
+	if ri.singletons == nil {
+		publish()
+		return
+	}
+	ri.singletons.submit(ri.identityOf(obj), obj.GetUID(), publish)
+}
+
+// publishDelete publishes the DELETE event, throttled when bulk delete detection is enabled
+func (ri *RegisterInformer) publishDelete(obj *unstructured.Unstructured) {
+	publish := func() {
+		if err := ri.publishItem(obj, broker.Delete, ri.config); err != nil {
+			ri.publishFailed(obj, broker.Delete, err)
+		}
+	}
+	if ri.bulkDeletes == nil {
+		publish()
+		return
+	}
+	ri.bulkDeletes.submit(publish)
+}
+
+func (ri *RegisterInformer) registerHandlers(s pipelineInformer) {
+	var handler cache.ResourceEventHandler = ri.GetEventHandlers()
+	if ri.phases != nil {
+		handler = phasedHandler{
+			handler:  handler,
+			phases:   ri.phases,
+			resource: ri.config.Name,
+		}
+	}
+	registration, err := s.AddEventHandlerWithResyncPeriod(handler, ri.config.ResyncPeriod)
+	if err != nil {
+		ri.log.Error(ErrAddHandler(ri.config.Name, err))
+		return
+	}
+	ri.informers.trackHandler(registration)
 }
 
 func (ri *RegisterInformer) publishItem(obj *unstructured.Unstructured, evtype broker.EventType, config internalconfig.PipelineConfig) error {
+	// the event is newer than the pending retry of the object
+	ri.retries.supersede(obj)
+	_, err := ri.publish(obj, evtype, config)
+	return err
+}
+
+// publish writes the event of the object unless it is filtered or suppressed, and reports whether it was written
+func (ri *RegisterInformer) publish(obj *unstructured.Unstructured, evtype broker.EventType, config internalconfig.PipelineConfig) (bool, error) {
 
 	// if the event is not supported skip
 	if !slices.Contains(ri.config.Events, string(evtype)) {
-		return nil
+		return false, nil
+	}
+
+	if ri.staleness.isStale() {
+		// the cache no longer reflects the cluster, the events are replayed on reconnect
+		ri.suppressed(suppressedStale, 1)
+		return false, nil
+	}
+
+	if config.SummaryOnly {
+		// the objects are counted by the periodic summaries instead
+		ri.suppressed(suppressedSummarized, 1)
+		return false, nil
+	}
+
+	if config.RollupOnly {
+		// the Pods are counted by the rollups of their owners instead
+		ri.suppressed(suppressedRolledUp, 1)
+		return false, nil
 	}
-	k8sResource := model.ParseList(*obj, evtype, ri.clusterID)
+
+	if reason, ok := ri.admits(obj, config); !ok {
+		ri.suppressed(reason, 1)
+		return false, nil
+	}
+
+	if evtype == broker.Add && ri.config.NewObjectsOnly && ri.predatesStart(obj) {
+		ri.suppressed(suppressedPreExisting, 1)
+		return false, nil
+	}
+
+	original := obj
+	obj, err := transform(obj, ri.transformers)
+	if err != nil {
+		if output.IsRetryable(err) {
+			return false, output.Retryable(ErrTransform(config.Name, err))
+		}
+		return false, ErrTransform(config.Name, err)
+	}
+	k8sResource := ri.resourceFor(original, obj, evtype)
 
 	mustSkip := false
 
 	if internalconfig.OutputNamespace != "" &&
 		obj.GetNamespace() != internalconfig.OutputNamespace {
 		mustSkip = true
-	}
-
-	if internalconfig.OutputOnlySpecifiedResources &&
+		ri.suppressed(suppressedNamespaceExcluded, 1)
+	} else if internalconfig.OutputOnlySpecifiedResources &&
 		!internalconfig.OutputResourcesSet[strings.ToLower(k8sResource.Kind)] {
 		mustSkip = true
+		ri.suppressed(suppressedKindExcluded, 1)
 	}
 
 	if mustSkip {
 		// skip this resource
-		ri.log.Info("Skipping resource: ", obj.GetName(), "/", obj.GetNamespace(), " of kind: ", k8sResource.Kind)
-		return nil
+		ri.objectLog.Info("Skipping resource: ", obj.GetName(), "/", obj.GetNamespace(), " of kind: ", k8sResource.Kind)
+		return false, nil
 
 	}
 
+	config.PublishTo = buildSubject(config, obj.GetNamespace(), ri.namespaceLabels)
+	// the sinks remap the event type where they serialize it, see PipelineConfig.EmittedEventType
+	config.EventTypeMapping = ri.settings.EventTypeMapping
+
 	if err := ri.outputWriter.Write(
 		k8sResource,
 		evtype,
 		config,
 	); err != nil {
 		ri.log.Error(ErrWriteOutput(config.Name, err))
-		return err
+		return false, err
+	}
+	if err := ri.manifest.record(k8sResource, evtype, config); err != nil {
+		ri.log.Error(ErrWriteOutput(config.Name, err))
+	}
+
+	return true, nil
+}
+
+// admits reports whether the pipeline reports the object at all, or the reason it is suppressed for otherwise.
+// The events and the listing of the cache share it, so neither reports an object the other leaves out.
+func (ri *RegisterInformer) admits(obj *unstructured.Unstructured, config internalconfig.PipelineConfig) (string, bool) {
+	switch {
+	case !inNamespaceScope(config, obj.GetNamespace()):
+		return suppressedNamespaceExcluded, false
+	case !ri.sampler.sample(obj):
+		return suppressedSampled, false
+	case !matchesAnnotations(obj, config.AnnotationFilter):
+		return suppressedAnnotationFiltered, false
 	}
+	return "", true
+}
+
+// resourceFor converts the transformed object into the emitted representation, the envelope is collected
+// from the original object, so the transformers dropping f.e. the status do not change the computed health
+func (ri *RegisterInformer) resourceFor(original, obj *unstructured.Unstructured, evtype broker.EventType) model.KubernetesResource {
+	k8sResource := model.ParseList(*obj, evtype, ri.clusterID)
+	k8sResource.Envelope = ri.envelopeFor(original, evtype).Versioned(ri.settings.EnvelopeVersion)
+	if ri.settings.CanonicalJSON {
+		k8sResource.Canonicalize()
+	}
+	return k8sResource
+}
+
+// envelopeFor collects the information attached to the event of the object
+func (ri *RegisterInformer) envelopeFor(obj *unstructured.Unstructured, evtype broker.EventType) *model.Envelope {
+	envelope := &model.Envelope{}
+	if evtype == broker.Delete {
+		envelope.DeletionHint = ri.deletions.deletionHint(obj)
+	}
+	if ri.settings.NamespaceMetadata && obj.GetNamespace() != "" {
+		if ns, ok := ri.namespace(obj.GetNamespace()); ok {
+			envelope.NamespaceLabels = ns.GetLabels()
+			envelope.NamespaceAnnotations = ns.GetAnnotations()
+		}
+	}
+	if ri.settings.ComputeHealth && evtype != broker.Delete {
+		envelope.Health, _ = model.EvaluateHealth(obj)
+	}
+	return envelope
+}
+
+// matchesAnnotations reports whether the object carries every annotation of the filter with its value,
+// an empty filter matches all objects
+func matchesAnnotations(obj *unstructured.Unstructured, filter map[string]string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	annotations := obj.GetAnnotations()
+	for key, value := range filter {
+		if actual, ok := annotations[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// statusOnlyChange reports whether the update changed nothing but the status,
+// the bookkeeping fields the API server updates along with it are ignored
+func statusOnlyChange(oldObj, obj *unstructured.Unstructured) bool {
+	withoutStatus := func(o *unstructured.Unstructured) map[string]interface{} {
+		c := o.DeepCopy()
+		unstructured.RemoveNestedField(c.Object, "status")
+		unstructured.RemoveNestedField(c.Object, "metadata", "resourceVersion")
+		unstructured.RemoveNestedField(c.Object, "metadata", "managedFields")
+		return c.Object
+	}
+	return reflect.DeepEqual(withoutStatus(oldObj), withoutStatus(obj))
+}
+
+// generationIncreased reports whether the update increased metadata.generation, which the API server
+// increments on spec changes only. Objects without generation count as changed, there is nothing to compare.
+func generationIncreased(oldObj, obj *unstructured.Unstructured) bool {
+	if oldObj.GetGeneration() == 0 && obj.GetGeneration() == 0 {
+		return true
+	}
+	return obj.GetGeneration() > oldObj.GetGeneration()
+}
+
+// predatesStart reports whether the object existed before MeshSync started,
+// rather than the pipeline, which is rebuilt on every resync or reload.
+// Creation timestamps have a resolution of one second,
+// objects created within the second MeshSync started count as new.
+func (ri *RegisterInformer) predatesStart(obj *unstructured.Unstructured) bool {
+	return obj.GetCreationTimestamp().Time.Before(ri.startedAt.Truncate(time.Second))
+}
+
+// inNamespaces reports whether an object of the namespace is in the pipeline's namespace scope,
+// cluster scoped objects always are
+func inNamespaces(namespaces []string, namespace string) bool {
+	return len(namespaces) == 0 || namespace == "" || slices.Contains(namespaces, namespace)
+}
 
-	return nil
+// inNamespaceScope reports whether an object of the namespace is in the namespace scope of the pipeline
+// and not in one of its excluded namespaces, cluster scoped objects always are
+func inNamespaceScope(config internalconfig.PipelineConfig, namespace string) bool {
+	return inNamespaces(config.Namespaces, namespace) && (namespace == "" || !slices.Contains(config.ExcludeNamespaces, namespace))
 }
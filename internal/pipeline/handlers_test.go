@@ -0,0 +1,229 @@
+package pipeline
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/meshery/meshkit/broker"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNewObjectsOnly(t *testing.T) {
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:           "pods.v1.",
+		Events:         []string{"ADDED", "MODIFIED", "DELETED"},
+		NewObjectsOnly: true,
+	}, internalconfig.GlobalSettings{}, writer, "")
+	handlers := ri.GetEventHandlers()
+
+	preExisting := newTestObject("v1", "Pod", "default", "old")
+	preExisting.SetCreationTimestamp(metav1.NewTime(ri.startedAt.Add(-time.Hour)))
+	handlers.AddFunc(preExisting)
+
+	modified := preExisting.DeepCopy()
+	modified.SetResourceVersion("2")
+	handlers.UpdateFunc(preExisting, modified)
+	handlers.DeleteFunc(modified)
+
+	created := newTestObject("v1", "Pod", "default", "new")
+	created.SetCreationTimestamp(metav1.NewTime(ri.startedAt.Add(time.Minute)))
+	handlers.AddFunc(created)
+
+	expected := []broker.EventType{broker.Update, broker.Delete, broker.Add}
+	if !reflect.DeepEqual(writer.events, expected) {
+		t.Errorf("expected events %v, got %v", expected, writer.events)
+	}
+	if name := writer.objects[2].KubernetesResourceMeta.Name; name != "new" {
+		t.Errorf("expected ADDED event for the new object, got %s", name)
+	}
+}
+
+func TestNewObjectsOnlyAfterRebuild(t *testing.T) {
+	// MeshSync started an hour ago, the pipeline is rebuilt since, f.e. on a hot reload
+	defer func(startedAt time.Time) { instanceStartedAt = startedAt }(instanceStartedAt)
+	instanceStartedAt = time.Now().Add(-time.Hour)
+
+	preExisting := newTestObject("v1", "Pod", "default", "old")
+	preExisting.SetCreationTimestamp(metav1.NewTime(instanceStartedAt.Add(-time.Hour)))
+	created := newTestObject("v1", "Pod", "default", "new")
+	created.SetCreationTimestamp(metav1.NewTime(instanceStartedAt.Add(30 * time.Minute)))
+
+	for build := 0; build < 2; build++ {
+		writer := &recordingWriter{}
+		ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+			Name:           "pods.v1.",
+			Events:         []string{"ADDED", "MODIFIED", "DELETED"},
+			NewObjectsOnly: true,
+		}, internalconfig.GlobalSettings{}, writer, "")
+		handlers := ri.GetEventHandlers()
+		handlers.AddFunc(preExisting)
+		handlers.AddFunc(created)
+
+		if len(writer.objects) != 1 || writer.objects[0].KubernetesResourceMeta.Name != "new" {
+			t.Errorf("build %d: expected the ADDED event of the object created since MeshSync started only, got %d events", build, len(writer.objects))
+		}
+	}
+}
+
+func TestEventTypeRemapping(t *testing.T) {
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:   "pods.v1.",
+		Events: []string{"ADDED", "MODIFIED", "DELETED"},
+	}, internalconfig.GlobalSettings{
+		EventTypeMapping: map[string]string{"ADDED": "CREATE", "MODIFIED": "UPDATE", "DELETED": "DELETE"},
+	}, writer, "")
+	handlers := ri.GetEventHandlers()
+
+	pod := newTestObject("v1", "Pod", "default", "web")
+	handlers.AddFunc(pod)
+	modified := pod.DeepCopy()
+	modified.SetResourceVersion("2")
+	handlers.UpdateFunc(pod, modified)
+	handlers.DeleteFunc(modified)
+
+	// the writers see the original event types, the sinks remap them where they serialize them
+	expected := []broker.EventType{broker.Add, broker.Update, broker.Delete}
+	if !reflect.DeepEqual(writer.events, expected) {
+		t.Errorf("expected events %v, got %v", expected, writer.events)
+	}
+	emitted := make([]broker.EventType, 0, len(writer.events))
+	for i, evtype := range writer.events {
+		emitted = append(emitted, writer.configs[i].EmittedEventType(evtype))
+	}
+	if expected := []broker.EventType{"CREATE", "UPDATE", "DELETE"}; !reflect.DeepEqual(emitted, expected) {
+		t.Errorf("expected emitted events %v, got %v", expected, emitted)
+	}
+	if hint := writer.objects[2].Envelope.DeletionHint; hint == "" {
+		t.Error("expected deletion hint on the remapped DELETE event")
+	}
+}
+
+func TestNamespaceScope(t *testing.T) {
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:       "pods.v1.",
+		Events:     []string{"ADDED", "MODIFIED", "DELETED"},
+		Namespaces: []string{"default", "prod"},
+	}, internalconfig.GlobalSettings{}, writer, "")
+	handlers := ri.GetEventHandlers()
+
+	for _, namespace := range []string{"default", "kube-system", "prod", ""} {
+		handlers.AddFunc(newTestObject("v1", "Pod", namespace, "web"))
+	}
+
+	namespaces := make([]string, 0, len(writer.objects))
+	for _, obj := range writer.objects {
+		namespaces = append(namespaces, obj.KubernetesResourceMeta.Namespace)
+	}
+	expected := []string{"default", "prod", ""}
+	if !reflect.DeepEqual(namespaces, expected) {
+		t.Errorf("expected objects of namespaces %v, got %v", expected, namespaces)
+	}
+}
+
+func TestExcludeNamespaces(t *testing.T) {
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:              "pods.v1.",
+		Events:            []string{"ADDED", "MODIFIED", "DELETED"},
+		ExcludeNamespaces: []string{"kube-system", "kube-public"},
+	}, internalconfig.GlobalSettings{}, writer, "")
+	handlers := ri.GetEventHandlers()
+	suppressedBefore := suppressedCount("pods.v1.", suppressedNamespaceExcluded)
+
+	for _, namespace := range []string{"default", "kube-system", "kube-public", "prod", ""} {
+		handlers.AddFunc(newTestObject("v1", "Pod", namespace, "web"))
+	}
+
+	namespaces := make([]string, 0, len(writer.objects))
+	for _, obj := range writer.objects {
+		namespaces = append(namespaces, obj.KubernetesResourceMeta.Namespace)
+	}
+	// cluster scoped objects are never excluded
+	expected := []string{"default", "prod", ""}
+	if !reflect.DeepEqual(namespaces, expected) {
+		t.Errorf("expected objects of namespaces %v, got %v", expected, namespaces)
+	}
+	if suppressed := suppressedCount("pods.v1.", suppressedNamespaceExcluded) - suppressedBefore; suppressed != 2 {
+		t.Errorf("expected the objects of the 2 excluded namespaces to be suppressed, got %v", suppressed)
+	}
+}
+
+func TestAnnotationFilter(t *testing.T) {
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:             "pods.v1.",
+		Events:           []string{"ADDED", "MODIFIED", "DELETED"},
+		AnnotationFilter: map[string]string{"meshery.io/managed": "true"},
+	}, internalconfig.GlobalSettings{}, writer, "")
+	handlers := ri.GetEventHandlers()
+	suppressedBefore := suppressedCount("pods.v1.", suppressedAnnotationFiltered)
+
+	matching := newTestObject("v1", "Pod", "default", "matching")
+	matching.SetAnnotations(map[string]string{"meshery.io/managed": "true", "owner": "team-a"})
+	otherValue := newTestObject("v1", "Pod", "default", "other-value")
+	otherValue.SetAnnotations(map[string]string{"meshery.io/managed": "false"})
+	unannotated := newTestObject("v1", "Pod", "default", "unannotated")
+	for _, obj := range []*unstructured.Unstructured{matching, otherValue, unannotated} {
+		handlers.AddFunc(obj)
+	}
+
+	names := make([]string, 0, len(writer.objects))
+	for _, obj := range writer.objects {
+		names = append(names, obj.KubernetesResourceMeta.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"matching"}) {
+		t.Errorf("expected only the matching object to be emitted, got %v", names)
+	}
+	if suppressed := suppressedCount("pods.v1.", suppressedAnnotationFiltered) - suppressedBefore; suppressed != 2 {
+		t.Errorf("expected the 2 objects not matching the filter to be suppressed, got %v", suppressed)
+	}
+}
+
+func TestGenerationChangeOnly(t *testing.T) {
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:                 "deployments.v1.apps",
+		Events:               []string{"ADDED", "MODIFIED", "DELETED"},
+		GenerationChangeOnly: true,
+	}, internalconfig.GlobalSettings{}, writer, "")
+	handlers := ri.GetEventHandlers()
+	suppressedBefore := suppressedCount("deployments.v1.apps", suppressedGenerationUnchanged)
+
+	deployment := newTestObject("apps/v1", "Deployment", "default", "web")
+	deployment.SetResourceVersion("1")
+	deployment.SetGeneration(1)
+
+	statusUpdate := deployment.DeepCopy()
+	statusUpdate.SetResourceVersion("2")
+	_ = unstructured.SetNestedField(statusUpdate.Object, int64(1), "status", "readyReplicas")
+	handlers.UpdateFunc(deployment, statusUpdate)
+
+	labelUpdate := statusUpdate.DeepCopy()
+	labelUpdate.SetResourceVersion("3")
+	labelUpdate.SetLabels(map[string]string{"team": "web"})
+	handlers.UpdateFunc(statusUpdate, labelUpdate)
+
+	if count := len(writer.writtenObjects()); count != 0 {
+		t.Errorf("expected the updates leaving the generation unchanged to be suppressed, got %d objects", count)
+	}
+	if count := suppressedCount("deployments.v1.apps", suppressedGenerationUnchanged) - suppressedBefore; count != 2 {
+		t.Errorf("expected 2 events suppressed as %s, got %v", suppressedGenerationUnchanged, count)
+	}
+
+	specUpdate := labelUpdate.DeepCopy()
+	specUpdate.SetResourceVersion("4")
+	specUpdate.SetGeneration(2)
+	_ = unstructured.SetNestedField(specUpdate.Object, int64(3), "spec", "replicas")
+	handlers.UpdateFunc(labelUpdate, specUpdate)
+
+	expected := []broker.EventType{broker.Update}
+	if !reflect.DeepEqual(writer.events, expected) {
+		t.Errorf("expected the generation bump to be emitted as %v, got %v", expected, writer.events)
+	}
+}
@@ -0,0 +1,67 @@
+package pipeline
+
+import (
+	"fmt"
+	"sort"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// identityOf returns the identity the updates of the object are coalesced by,
+// the value selected by the pipeline's IdentityPath or, if it selects none, the UID
+func (ri *RegisterInformer) identityOf(obj *unstructured.Unstructured) string {
+	if len(ri.identity) > 0 {
+		if value, ok := lookup(obj.Object, ri.identity); ok {
+			return "identity:" + value
+		}
+	}
+	return string(obj.GetUID())
+}
+
+// lookup returns the first scalar value selected by path below node, map keys are visited in order
+func lookup(node interface{}, path internalconfig.FieldPath) (string, bool) {
+	if len(path) == 0 {
+		switch value := node.(type) {
+		case string, bool, int64, float64:
+			return fmt.Sprint(value), true
+		}
+		return "", false
+	}
+	segment, rest := path[0], path[1:]
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(n))
+		for key := range n {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if segment.MatchesKey(key) {
+				if value, ok := lookup(n[key], rest); ok {
+					return value, true
+				}
+			}
+			if segment.Descendant {
+				if value, ok := lookup(n[key], path); ok {
+					return value, true
+				}
+			}
+		}
+	case []interface{}:
+		for i, item := range n {
+			if segment.MatchesItem(i, item) {
+				if value, ok := lookup(item, rest); ok {
+					return value, true
+				}
+			}
+			if segment.Descendant {
+				if value, ok := lookup(item, path); ok {
+					return value, true
+				}
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,297 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
+)
+
+// informerSet keeps track of the informers registered by the pipeline steps.
+// Most pipelines use the informer of the shared factory,
+// pipelines which need to customize list/watch get a dedicated informer.
+type informerSet struct {
+	factory dynamicinformer.DynamicSharedInformerFactory
+	client  dynamic.Interface
+	clock   clock.Clock
+	// cancelled once the pipeline stops, aborts the list and watch requests of the dedicated informers
+	ctx context.Context
+	// the namespace strategy and clients the pipelines are watched with
+	settings internalconfig.GlobalSettings
+
+	mu        sync.Mutex
+	informers map[string]pipelineInformer
+	dedicated []cache.SharedIndexInformer
+	// every informer in order of registration, shared and dedicated
+	all []cache.SharedIndexInformer
+	// the event handlers of the pipelines
+	handlers []cache.ResourceEventHandlerRegistration
+}
+
+func newInformerSet(ctx context.Context, factory dynamicinformer.DynamicSharedInformerFactory, client dynamic.Interface) *informerSet {
+	return &informerSet{
+		factory:   factory,
+		client:    client,
+		clock:     clock.RealClock{},
+		ctx:       ctx,
+		informers: make(map[string]pipelineInformer),
+	}
+}
+
+// needsDedicatedInformer reports whether the pipeline customizes list/watch,
+// or trims the cached objects, which the other consumers of the shared informer must see untrimmed
+func needsDedicatedInformer(config internalconfig.PipelineConfig) bool {
+	return config.MaxWatchAge > 0 || config.StaleAfter > 0 || config.BackfillRevisions > 0 || config.LabelSelector != "" || config.FieldSelector != "" || config.PollInterval > 0 ||
+		len(config.CacheTrim) > 0
+}
+
+// tweakListOptionsFor returns the tweak of the list and watch options of the pipeline's informer
+func tweakListOptionsFor(config internalconfig.PipelineConfig) dynamicinformer.TweakListOptionsFunc {
+	return func(options *metav1.ListOptions) {
+		if config.LabelSelector != "" {
+			options.LabelSelector = config.LabelSelector
+		}
+		if config.FieldSelector != "" {
+			options.FieldSelector = config.FieldSelector
+		}
+	}
+}
+
+// informerFor returns the informer for the pipeline, creating it on first use.
+// The observer, if any, learns about the connection state of a dedicated informer,
+// the backfill handler receives the changes replayed ahead of its initial list.
+// Pipelines watching their namespaces one by one, see PipelineConfig.WatchedNamespaces,
+// get a dedicated informer per namespace.
+func (s *informerSet) informerFor(config internalconfig.PipelineConfig, gvr schema.GroupVersionResource, observer connectionObserver, backfill backfillHandler) pipelineInformer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if informer, ok := s.informers[config.Name]; ok {
+		return informer
+	}
+
+	var informer pipelineInformer
+	if namespaces := config.WatchedNamespaces(s.settings); len(namespaces) > 0 && s.client != nil {
+		namespaced := &namespacedInformer{}
+		for _, namespace := range namespaces {
+			namespaced.informers = append(namespaced.informers, s.dedicatedInformer(config, gvr, namespace, observer, backfill))
+		}
+		informer = namespaced
+	} else if needsDedicatedInformer(config) && s.client != nil {
+		informer = s.dedicatedInformer(config, gvr, metav1.NamespaceAll, observer, backfill)
+	} else {
+		shared := s.factory.ForResource(gvr).Informer()
+		s.track(shared)
+		informer = shared
+	}
+
+	s.informers[config.Name] = informer
+	return informer
+}
+
+// dedicatedInformer creates an informer of the pipeline's own, must be called with the lock held
+func (s *informerSet) dedicatedInformer(
+	config internalconfig.PipelineConfig,
+	gvr schema.GroupVersionResource,
+	namespace string,
+	observer connectionObserver,
+	backfill backfillHandler,
+) cache.SharedIndexInformer {
+	informer := cache.NewSharedIndexInformer(
+		s.listWatchFor(config, gvr, namespace, observer, backfill),
+		&unstructured.Unstructured{},
+		internalconfig.ResyncCheckPeriod,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+	)
+	s.dedicated = append(s.dedicated, informer)
+	s.track(informer)
+	return informer
+}
+
+// registerNamespaces registers the informer of the namespaces, used to look up namespaces of objects
+func (s *informerSet) registerNamespaces() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.track(s.factory.ForResource(namespacesGVR).Informer())
+}
+
+// registerOwners registers the informers of the intermediate owners of Pods, used to resolve their top-level owner
+func (s *informerSet) registerOwners() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, gvr := range ownerResources {
+		s.track(s.factory.ForResource(gvr).Informer())
+	}
+}
+
+// track adds the informer to the ones to start, must be called with the lock held
+func (s *informerSet) track(informer cache.SharedIndexInformer) {
+	for _, tracked := range s.all {
+		if tracked == informer {
+			// shared by several pipelines
+			return
+		}
+	}
+	s.all = append(s.all, informer)
+}
+
+// trackHandler adds the event handler of a pipeline to the ones waited for by waitForHandlers
+func (s *informerSet) trackHandler(registration cache.ResourceEventHandlerRegistration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers = append(s.handlers, registration)
+}
+
+// waitForHandlers waits until the event handlers of all pipelines have handled the events of their initial lists,
+// which may still be on their way once the informers have synced.
+// It returns false if stopCh was closed before.
+func (s *informerSet) waitForHandlers(stopCh <-chan struct{}) bool {
+	s.mu.Lock()
+	synced := make([]cache.InformerSynced, 0, len(s.handlers))
+	for _, registration := range s.handlers {
+		synced = append(synced, registration.HasSynced)
+	}
+	s.mu.Unlock()
+	return cache.WaitForCacheSync(stopCh, synced...)
+}
+
+// get returns the informer registered for the pipeline
+func (s *informerSet) get(name string) (pipelineInformer, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	informer, ok := s.informers[name]
+	return informer, ok
+}
+
+// start runs the dedicated informers, the shared ones are started by the factory
+func (s *informerSet) start(stopCh <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, informer := range s.dedicated {
+		go informer.Run(stopCh)
+	}
+}
+
+// startInWaves runs all informers, at most limit of them initializing at once.
+// Each wave is started once the initial sync of the previous one has completed,
+// which bounds the number of concurrent LIST requests to the API server.
+func (s *informerSet) startInWaves(stopCh <-chan struct{}, limit int) {
+	s.mu.Lock()
+	informers := append([]cache.SharedIndexInformer{}, s.all...)
+	s.mu.Unlock()
+	startInWaves(stopCh, informers, limit)
+}
+
+func startInWaves(stopCh <-chan struct{}, informers []cache.SharedIndexInformer, limit int) {
+	for len(informers) > 0 {
+		wave := informers[:min(limit, len(informers))]
+		informers = informers[len(wave):]
+
+		synced := make([]cache.InformerSynced, 0, len(wave))
+		for _, informer := range wave {
+			go informer.Run(stopCh)
+			synced = append(synced, informer.HasSynced)
+		}
+		if !cache.WaitForCacheSync(stopCh, synced...) {
+			// stopped before the wave synced
+			return
+		}
+	}
+}
+
+func (s *informerSet) listWatchFor(
+	config internalconfig.PipelineConfig,
+	gvr schema.GroupVersionResource,
+	namespace string,
+	observer connectionObserver,
+	backfill backfillHandler,
+) cache.ListerWatcher {
+	client := s.client.Resource(gvr).Namespace(namespace)
+	tweakListOptions := tweakListOptionsFor(config)
+	var lw cache.ListerWatcher = &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			tweakListOptions(&options)
+			return client.List(s.ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			tweakListOptions(&options)
+			return client.Watch(s.ctx, options)
+		},
+	}
+
+	if config.PollInterval > 0 {
+		lw = newPollingListWatch(lw, config.PollInterval, s.clock)
+	}
+	if config.MaxWatchAge > 0 {
+		lw = newAgeLimitedListWatch(lw, config.MaxWatchAge, s.clock)
+	}
+	if config.BackfillRevisions > 0 && backfill != nil {
+		lw = newBackfillListWatch(lw, config.BackfillRevisions, backfill, s.clock)
+	}
+	if config.StaleAfter > 0 && observer != nil {
+		lw = &observedListWatch{ListerWatcher: lw, observer: observer}
+	}
+	return lw
+}
+
+// ageLimitedListWatch stops every watch once it reaches the max age.
+// The reflector then re-establishes the watch from the last observed resourceVersion,
+// so no re-list happens and no duplicate events are delivered to the handlers.
+// This protects against long lived watches which stall silently.
+type ageLimitedListWatch struct {
+	cache.ListerWatcher
+	maxAge time.Duration
+	clock  clock.Clock
+}
+
+func newAgeLimitedListWatch(lw cache.ListerWatcher, maxAge time.Duration, c clock.Clock) *ageLimitedListWatch {
+	return &ageLimitedListWatch{
+		ListerWatcher: lw,
+		maxAge:        maxAge,
+		clock:         c,
+	}
+}
+
+func (lw *ageLimitedListWatch) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	w, err := lw.ListerWatcher.Watch(options)
+	if err != nil {
+		return nil, err
+	}
+
+	limited := &ageLimitedWatch{
+		Interface: w,
+		done:      make(chan struct{}),
+	}
+	expired := lw.clock.After(lw.maxAge)
+	go func() {
+		select {
+		case <-expired:
+			limited.Stop()
+		case <-limited.done:
+		}
+	}()
+	return limited, nil
+}
+
+type ageLimitedWatch struct {
+	watch.Interface
+	once sync.Once
+	done chan struct{}
+}
+
+func (w *ageLimitedWatch) Stop() {
+	w.once.Do(func() {
+		close(w.done)
+		w.Interface.Stop()
+	})
+}
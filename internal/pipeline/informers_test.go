@@ -0,0 +1,353 @@
+package pipeline
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// fakeListWatch serves a fixed list and records every watch it opens
+type fakeListWatch struct {
+	list *unstructured.UnstructuredList
+
+	mu       sync.Mutex
+	lists    int
+	watches  []*watch.FakeWatcher
+	watchRVs []string
+}
+
+func (lw *fakeListWatch) List(options metav1.ListOptions) (runtime.Object, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	lw.lists++
+	return lw.list.DeepCopy(), nil
+}
+
+func (lw *fakeListWatch) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	w := watch.NewFake()
+	lw.watches = append(lw.watches, w)
+	lw.watchRVs = append(lw.watchRVs, options.ResourceVersion)
+	return w, nil
+}
+
+func (lw *fakeListWatch) state() (int, []*watch.FakeWatcher, []string) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	return lw.lists, append([]*watch.FakeWatcher{}, lw.watches...), append([]string{}, lw.watchRVs...)
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAgeLimitedWatchIsReestablished(t *testing.T) {
+	pod := newTestObject("v1", "Pod", "default", "pod-a")
+	lw := &fakeListWatch{
+		list: &unstructured.UnstructuredList{
+			Object: map[string]interface{}{"apiVersion": "v1", "kind": "PodList", "metadata": map[string]interface{}{"resourceVersion": "1"}},
+			Items:  []unstructured.Unstructured{*pod},
+		},
+	}
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	maxAge := 10 * time.Minute
+
+	informer := cache.NewSharedIndexInformer(newAgeLimitedListWatch(lw, maxAge, fakeClock), &unstructured.Unstructured{}, 0, cache.Indexers{})
+	var mu sync.Mutex
+	adds, updates := 0, 0
+	_, _ = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+			adds++
+		},
+		UpdateFunc: func(oldObj, obj interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+			updates++
+		},
+	})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go informer.Run(stopCh)
+
+	waitFor(t, func() bool {
+		_, watches, _ := lw.state()
+		return informer.HasSynced() && len(watches) == 1 && fakeClock.HasWaiters()
+	})
+
+	_, watches, _ := lw.state()
+	updated := pod.DeepCopy()
+	updated.SetResourceVersion("2")
+	watches[0].Modify(updated)
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return updates == 1
+	})
+
+	// watch is not re-established before it reaches the max age
+	fakeClock.Step(maxAge - time.Second)
+	if _, watches, _ := lw.state(); len(watches) != 1 {
+		t.Fatalf("watch re-established before max age, watches: %d", len(watches))
+	}
+
+	fakeClock.Step(time.Second)
+	waitFor(t, func() bool {
+		_, watches, _ := lw.state()
+		return len(watches) == 2
+	})
+
+	lists, _, watchRVs := lw.state()
+	if lists != 1 {
+		t.Errorf("expected watch to resume without re-list, lists: %d", lists)
+	}
+	if watchRVs[1] != "2" {
+		t.Errorf("expected watch to resume from resourceVersion 2, got %q", watchRVs[1])
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if adds != 1 || updates != 1 {
+		t.Errorf("expected no duplicate events, got adds: %d, updates: %d", adds, updates)
+	}
+}
+
+// concurrencyRecorder tracks how many lists are in flight at once
+type concurrencyRecorder struct {
+	mu        sync.Mutex
+	active    int
+	maxActive int
+}
+
+func (r *concurrencyRecorder) listWatch() cache.ListerWatcher {
+	return &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			r.mu.Lock()
+			r.active++
+			r.maxActive = max(r.maxActive, r.active)
+			r.mu.Unlock()
+
+			// give other informers the chance to list meanwhile
+			time.Sleep(20 * time.Millisecond)
+
+			r.mu.Lock()
+			r.active--
+			r.mu.Unlock()
+			return &unstructured.UnstructuredList{Object: map[string]interface{}{"metadata": map[string]interface{}{"resourceVersion": "1"}}}, nil
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return watch.NewFake(), nil
+		},
+	}
+}
+
+func TestStartInWavesCapsConcurrentInitialization(t *testing.T) {
+	for _, limit := range []int{1, 2, 3} {
+		recorder := &concurrencyRecorder{}
+		informers := make([]cache.SharedIndexInformer, 0, 7)
+		for i := 0; i < 7; i++ {
+			informers = append(informers, cache.NewSharedIndexInformer(recorder.listWatch(), &unstructured.Unstructured{}, 0, cache.Indexers{}))
+		}
+
+		stopCh := make(chan struct{})
+		startInWaves(stopCh, informers, limit)
+		close(stopCh)
+
+		for i, informer := range informers {
+			if !informer.HasSynced() {
+				t.Errorf("limit %d: expected informer %d to have synced", limit, i)
+			}
+		}
+		if recorder.maxActive > limit {
+			t.Errorf("expected at most %d informers initializing at once, got %d", limit, recorder.maxActive)
+		}
+	}
+}
+
+func TestNamespaceStrategy(t *testing.T) {
+	testCases := []struct {
+		name              string
+		strategy          string
+		threshold         int
+		expectedDedicated int
+	}{
+		{name: "all", strategy: internalconfig.NamespaceStrategyAll, expectedDedicated: 0},
+		{name: "per namespace", strategy: internalconfig.NamespaceStrategyPerNamespace, expectedDedicated: 2},
+		{name: "auto below threshold", strategy: internalconfig.NamespaceStrategyAuto, threshold: 2, expectedDedicated: 2},
+		{name: "auto above threshold", strategy: internalconfig.NamespaceStrategyAuto, threshold: 1, expectedDedicated: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			informers := newTestInformers(
+				newTestObject("v1", "Pod", "default", "pod-a"),
+				newTestObject("v1", "Pod", "prod", "pod-b"),
+				newTestObject("v1", "Pod", "kube-system", "pod-c"),
+			)
+			informers.settings = internalconfig.GlobalSettings{
+				NamespaceStrategy:          tc.strategy,
+				NamespaceStrategyThreshold: tc.threshold,
+			}
+			config := internalconfig.PipelineConfig{Name: "pods.v1.", Namespaces: []string{"default", "prod"}}
+			informer := informers.informerFor(config, schema.GroupVersionResource{Version: "v1", Resource: "pods"}, nil, nil)
+
+			if len(informers.dedicated) != tc.expectedDedicated {
+				t.Errorf("expected %d dedicated informers, got %d", tc.expectedDedicated, len(informers.dedicated))
+			}
+			if len(informers.all) != max(tc.expectedDedicated, 1) {
+				t.Errorf("expected %d informers to start, got %d", max(tc.expectedDedicated, 1), len(informers.all))
+			}
+
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+			informers.startInWaves(stopCh, len(informers.all))
+			if !cache.WaitForCacheSync(stopCh, informer.HasSynced) {
+				t.Fatal("informer did not sync")
+			}
+
+			// an informer per namespace lists only the pipeline's namespaces
+			expectedObjects := 3
+			if tc.expectedDedicated > 0 {
+				expectedObjects = 2
+			}
+			keys := informer.GetStore().ListKeys()
+			if len(keys) != expectedObjects {
+				t.Errorf("expected %d objects in the store, got %v", expectedObjects, keys)
+			}
+			if _, exists, _ := informer.GetStore().GetByKey("prod/pod-b"); !exists {
+				t.Error("expected prod/pod-b in the store")
+			}
+		})
+	}
+}
+
+func TestLabelSelectorFiltersListAndWatch(t *testing.T) {
+	managed := newTestObject("v1", "Pod", "default", "pod-a")
+	managed.SetLabels(map[string]string{"app.kubernetes.io/managed-by": "meshery"})
+	informers := newTestInformers(managed, newTestObject("v1", "Pod", "default", "pod-b"))
+	config := internalconfig.PipelineConfig{Name: "pods.v1.", LabelSelector: "app.kubernetes.io/managed-by=meshery"}
+	informer := informers.informerFor(config, schema.GroupVersionResource{Version: "v1", Resource: "pods"}, nil, nil)
+	if len(informers.dedicated) != 1 {
+		t.Fatalf("expected a dedicated informer for the label selector, got %d", len(informers.dedicated))
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	informers.startInWaves(stopCh, len(informers.all))
+	if !cache.WaitForCacheSync(stopCh, informer.HasSynced) {
+		t.Fatal("informer did not sync")
+	}
+	if keys := informer.GetStore().ListKeys(); !reflect.DeepEqual(keys, []string{"default/pod-a"}) {
+		t.Errorf("expected only the selected Pod in the store, got %v", keys)
+	}
+}
+
+func TestSelectorsTweakListOptions(t *testing.T) {
+	config := internalconfig.PipelineConfig{
+		Name:          "pods.v1.",
+		LabelSelector: "app=web",
+		FieldSelector: "status.phase=Running",
+	}
+	if !needsDedicatedInformer(config) {
+		t.Error("expected a dedicated informer for the selectors")
+	}
+	options := metav1.ListOptions{}
+	tweakListOptionsFor(config)(&options)
+	if options.LabelSelector != "app=web" || options.FieldSelector != "status.phase=Running" {
+		t.Errorf("expected the list and watch to select by label and field, got %+v", options)
+	}
+}
+
+func TestNamespacedClients(t *testing.T) {
+	informers := newTestInformers(
+		newTestObject("v1", "Pod", "default", "pod-a"),
+		newTestObject("v1", "Pod", "prod", "pod-b"),
+		newTestObject("v1", "Pod", "kube-system", "pod-c"),
+	)
+	informers.settings = internalconfig.GlobalSettings{
+		NamespaceStrategy: internalconfig.NamespaceStrategyAll,
+		NamespacedClients: true,
+	}
+	config := internalconfig.PipelineConfig{Name: "pods.v1.", Namespaces: []string{"default", "prod"}}
+	informer := informers.informerFor(config, schema.GroupVersionResource{Version: "v1", Resource: "pods"}, nil, nil)
+	if len(informers.dedicated) != 2 {
+		t.Fatalf("expected an informer per namespace whatever the strategy, got %d", len(informers.dedicated))
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	informers.startInWaves(stopCh, len(informers.all))
+	if !cache.WaitForCacheSync(stopCh, informer.HasSynced) {
+		t.Fatal("informer did not sync")
+	}
+	if keys := informer.GetStore().ListKeys(); len(keys) != 2 {
+		t.Errorf("expected only the objects of the pipeline's namespaces in the store, got %v", keys)
+	}
+
+	// no list or watch requires cluster-wide permissions
+	namespaces := make(map[string]bool)
+	for _, action := range informers.client.(*fake.FakeDynamicClient).Actions() {
+		if action.GetVerb() != "list" && action.GetVerb() != "watch" {
+			continue
+		}
+		if action.GetNamespace() == metav1.NamespaceAll {
+			t.Errorf("expected only namespaced clients, got a cluster-wide %s of %s", action.GetVerb(), action.GetResource().Resource)
+		}
+		namespaces[action.GetNamespace()] = true
+	}
+	if !reflect.DeepEqual(namespaces, map[string]bool{"default": true, "prod": true}) {
+		t.Errorf("expected the clients of default and prod, got %v", namespaces)
+	}
+}
+
+func TestResyncPeriodReplaysCachedObjects(t *testing.T) {
+	informers := newTestInformers(newTestObject("v1", "Pod", "default", "pod-a"))
+	config := internalconfig.PipelineConfig{Name: "resync.v1.", Events: []string{"ADDED", "MODIFIED"}, ResyncPeriod: internalconfig.MinResyncPeriod}
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	informer := informers.informerFor(config, gvr, nil, nil)
+	if informer != informers.factory.ForResource(gvr).Informer() {
+		t.Fatal("expected the informer of the shared factory")
+	}
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), informers, nil, nil, config, internalconfig.GlobalSettings{}, writer, "")
+	ri.registerHandlers(informer)
+	// a pipeline sharing the informer without ResyncPeriod of its own
+	other := internalconfig.PipelineConfig{Name: "noresync.v1.", Events: []string{"ADDED", "MODIFIED"}}
+	newRegisterInformerStep(newTestLogger(t), informers, nil, nil, other, internalconfig.GlobalSettings{}, &recordingWriter{}, "").
+		registerHandlers(informers.informerFor(other, gvr, nil, nil))
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	duplicates := suppressedCount(config.Name, suppressedDuplicate)
+	otherDuplicates := suppressedCount(other.Name, suppressedDuplicate)
+	informers.startInWaves(stopCh, len(informers.all))
+	// the replayed object is no newer than the cached one, it is not emitted again
+	waitFor(t, func() bool { return suppressedCount(config.Name, suppressedDuplicate) > duplicates })
+	if events := len(writer.writtenObjects()); events != 1 {
+		t.Errorf("expected only the ADDED event of the object, got %d events", events)
+	}
+	if replayed := suppressedCount(other.Name, suppressedDuplicate) - otherDuplicates; replayed != 0 {
+		t.Errorf("expected no resync of the pipeline without ResyncPeriod, got %v replayed objects", replayed)
+	}
+}
@@ -0,0 +1,37 @@
+package pipeline
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/meshery/meshkit/logger"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/internal/output"
+	"k8s.io/utils/clock"
+)
+
+// instanceID identifies this MeshSync process in its liveness events,
+// it stays the same across the pipelines the process runs
+var instanceID = uuid.New().String()
+
+// EmitLiveness emits the liveness event to ow every interval until stopChan is closed,
+// regardless of whether the watched resources change or the pipelines restart
+func EmitLiveness(log logger.Handler, ow output.Writer, interval time.Duration, stopChan <-chan struct{}) {
+	emitLiveness(log, ow, clock.RealClock{}, instanceStartedAt, interval, stopChan)
+}
+
+func emitLiveness(log logger.Handler, ow output.Writer, clock clock.WithTicker, startedAt time.Time, interval time.Duration, stopChan <-chan struct{}) {
+	ticker := clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopChan:
+			return
+		case <-ticker.C():
+			event := output.NewLivenessEvent(instanceID, clock.Since(startedAt))
+			if err := output.WriteControl(ow, event); err != nil {
+				log.Error(ErrWriteOutput(internalconfig.PipelineNameKey, err))
+			}
+		}
+	}
+}
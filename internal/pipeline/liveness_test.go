@@ -0,0 +1,53 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/meshery/meshsync/internal/output"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func livenessEvents(writer *recordingWriter) []output.ControlEvent {
+	events := make([]output.ControlEvent, 0)
+	for _, event := range writer.controlEvents() {
+		if event.Type == output.LivenessEvent {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func TestLivenessPerInterval(t *testing.T) {
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	writer := &recordingWriter{}
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	go emitLiveness(newTestLogger(t), writer, fakeClock, fakeClock.Now(), 30*time.Second, stopChan)
+
+	waitFor(t, fakeClock.HasWaiters)
+	if count := len(livenessEvents(writer)); count != 0 {
+		t.Fatalf("expected no liveness event before the interval is over, got %d", count)
+	}
+
+	for interval := 1; interval <= 3; interval++ {
+		fakeClock.Step(30 * time.Second)
+		waitFor(t, func() bool { return len(livenessEvents(writer)) == interval })
+	}
+
+	for i, event := range livenessEvents(writer) {
+		if event.InstanceID != instanceID {
+			t.Errorf("expected the instance ID %s, got %s", instanceID, event.InstanceID)
+		}
+		if expected := float64(30 * (i + 1)); event.UptimeSeconds != expected {
+			t.Errorf("expected an uptime of %vs, got %vs", expected, event.UptimeSeconds)
+		}
+	}
+	// nothing is watched, the liveness events are emitted regardless
+	if count := len(writer.controlEvents()); count != 3 {
+		t.Errorf("expected only the liveness events, got %d control events", count)
+	}
+	if count := len(writer.writtenObjects()); count != 0 {
+		t.Errorf("expected no resource events, got %d", count)
+	}
+}
@@ -0,0 +1,82 @@
+package pipeline
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/meshery/meshkit/broker"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/internal/output"
+	"github.com/meshery/meshsync/pkg/model"
+)
+
+// snapshotManifest records the key and checksum of the objects emitted until the initial snapshot is complete.
+// Objects updated meanwhile are listed with the checksum of their latest emission, deleted ones are left out.
+// Objects are told apart by their UID, several of them may share a key, f.e. the value of a label.
+type snapshotManifest struct {
+	mu      sync.Mutex
+	sealed  bool
+	entries map[manifestObject]output.ManifestEntry
+}
+
+type manifestObject struct {
+	resource string
+	uid      string
+}
+
+func newSnapshotManifest() *snapshotManifest {
+	return &snapshotManifest{entries: make(map[manifestObject]output.ManifestEntry)}
+}
+
+// record adds the emitted object to the manifest unless it has been sealed
+func (m *snapshotManifest) record(obj model.KubernetesResource, evtype broker.EventType, config internalconfig.PipelineConfig) error {
+	if m == nil {
+		return nil
+	}
+	uid := output.UIDKeyFunc(obj)
+	if uid == "" {
+		return nil
+	}
+	var checksum string
+	if evtype != broker.Delete {
+		var err error
+		if checksum, err = output.Checksum(obj); err != nil {
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sealed {
+		return nil
+	}
+	object := manifestObject{resource: config.Name, uid: uid}
+	if evtype == broker.Delete {
+		delete(m.entries, object)
+		return nil
+	}
+	m.entries[object] = output.ManifestEntry{Resource: config.Name, Key: output.KeyFuncFor(config)(obj), Checksum: checksum}
+	return nil
+}
+
+// seal completes the manifest and returns its entries ordered by resource and key
+func (m *snapshotManifest) seal() []output.ManifestEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sealed = true
+
+	entries := make([]output.ManifestEntry, 0, len(m.entries))
+	for _, entry := range m.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Resource != entries[j].Resource {
+			return entries[i].Resource < entries[j].Resource
+		}
+		if entries[i].Key != entries[j].Key {
+			return entries[i].Key < entries[j].Key
+		}
+		return entries[i].Checksum < entries[j].Checksum
+	})
+	return entries
+}
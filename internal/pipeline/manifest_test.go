@@ -0,0 +1,154 @@
+package pipeline
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/meshery/meshkit/broker"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/internal/output"
+	"github.com/myntra/pipeline"
+	"k8s.io/client-go/tools/cache"
+)
+
+func manifestEvent(writer *recordingWriter) (output.ControlEvent, bool) {
+	for _, event := range writer.controlEvents() {
+		if event.Type == output.SnapshotManifestEvent {
+			return event, true
+		}
+	}
+	return output.ControlEvent{}, false
+}
+
+func TestSnapshotManifest(t *testing.T) {
+	informers := newTestInformers(
+		newTestObject("v1", "Pod", "default", "pod-a"),
+		newTestObject("v1", "Pod", "prod", "pod-b"),
+		newTestObject("v1", "Service", "default", "svc-a"),
+	)
+	writer := &recordingWriter{}
+	manifest := newSnapshotManifest()
+	stores := make(map[string]cache.Store)
+	for _, config := range []internalconfig.PipelineConfig{
+		{Name: "pods.v1.", Events: []string{"ADDED", "MODIFIED", "DELETED"}},
+		{Name: "services.v1.", Events: []string{"ADDED", "MODIFIED", "DELETED"}, KeyFunc: internalconfig.KeyFuncNamespacedName},
+	} {
+		step := newRegisterInformerStep(newTestLogger(t), informers, nil, nil, config, internalconfig.GlobalSettings{}, writer, "")
+		step.manifest = manifest
+		if result := step.Exec(&pipeline.Request{Data: stores}); result.Error != nil {
+			t.Fatal(result.Error)
+		}
+	}
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	start := newStartInformersStep(stopChan, newTestLogger(t), informers, nil, writer, true)
+	start.manifest = manifest
+	start.Exec(&pipeline.Request{Data: stores})
+
+	waitFor(t, func() bool {
+		_, ok := manifestEvent(writer)
+		return ok
+	})
+	event, _ := manifestEvent(writer)
+
+	// every object emitted during the snapshot is listed with the checksum of what was emitted
+	expected := make([]output.ManifestEntry, 0)
+	for _, obj := range writer.writtenObjects() {
+		checksum, err := output.Checksum(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry := output.ManifestEntry{Resource: "pods.v1.", Key: obj.KubernetesResourceMeta.UID, Checksum: checksum}
+		if obj.Kind == "Service" {
+			entry = output.ManifestEntry{Resource: "services.v1.", Key: "default/svc-a", Checksum: checksum}
+		}
+		expected = append(expected, entry)
+	}
+	if len(expected) != 3 {
+		t.Fatalf("expected 3 emitted objects, got %d", len(expected))
+	}
+	sortManifest(expected)
+	if !reflect.DeepEqual(event.Manifest, expected) {
+		t.Errorf("expected manifest %v, got %v", expected, event.Manifest)
+	}
+	if event.Count != 3 {
+		t.Errorf("expected manifest count 3, got %d", event.Count)
+	}
+
+	events := writer.controlEvents()
+	if last := events[len(events)-1]; last.Type != output.SnapshotManifestEvent || events[len(events)-2].Type != output.SnapshotCompleteEvent {
+		t.Errorf("expected the manifest to follow the snapshot complete marker, got %v", events)
+	}
+}
+
+func TestSnapshotManifestRecord(t *testing.T) {
+	manifest := newSnapshotManifest()
+	pods := internalconfig.PipelineConfig{Name: "pods.v1.", KeyFunc: internalconfig.KeyFuncNamespacedName}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, pods, internalconfig.GlobalSettings{}, &recordingWriter{}, "")
+	objA, objB := newTestObject("v1", "Pod", "default", "pod-a"), newTestObject("v1", "Pod", "default", "pod-b")
+	podA := ri.resourceFor(objA, objA, broker.Add)
+	podB := ri.resourceFor(objB, objB, broker.Add)
+	updated := newTestObject("v1", "Pod", "default", "pod-a")
+	updated.SetResourceVersion("2")
+	updatedA := ri.resourceFor(updated, updated, broker.Update)
+
+	// pod-a is updated and pod-b deleted before the snapshot is complete
+	for _, err := range []error{
+		manifest.record(podA, broker.Add, pods),
+		manifest.record(podB, broker.Add, pods),
+		manifest.record(updatedA, broker.Update, pods),
+		manifest.record(podB, broker.Delete, pods),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checksum, _ := output.Checksum(updatedA)
+	expected := []output.ManifestEntry{{Resource: "pods.v1.", Key: "default/pod-a", Checksum: checksum}}
+	if entries := manifest.seal(); !reflect.DeepEqual(entries, expected) {
+		t.Errorf("expected the latest emission of the remaining objects, got %v", entries)
+	}
+
+	_ = manifest.record(podB, broker.Add, pods)
+	if entries := manifest.seal(); !reflect.DeepEqual(entries, expected) {
+		t.Errorf("expected a sealed manifest to be left as it is, got %v", entries)
+	}
+}
+
+func TestSnapshotManifestSharedKey(t *testing.T) {
+	manifest := newSnapshotManifest()
+	pods := internalconfig.PipelineConfig{Name: "pods.v1.", KeyFunc: internalconfig.KeyFuncLabelPrefix + "app"}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, pods, internalconfig.GlobalSettings{}, &recordingWriter{}, "")
+	expected := make([]output.ManifestEntry, 0)
+	for _, name := range []string{"web-a", "web-b"} {
+		obj := newTestObject("v1", "Pod", "default", name)
+		obj.SetLabels(map[string]string{"app": "web"})
+		pod := ri.resourceFor(obj, obj, broker.Add)
+		if err := manifest.record(pod, broker.Add, pods); err != nil {
+			t.Fatal(err)
+		}
+		checksum, _ := output.Checksum(pod)
+		expected = append(expected, output.ManifestEntry{Resource: "pods.v1.", Key: "web", Checksum: checksum})
+	}
+
+	// the objects sharing the key are listed each
+	sortManifest(expected)
+	if entries := manifest.seal(); !reflect.DeepEqual(entries, expected) {
+		t.Errorf("expected %v, got %v", expected, entries)
+	}
+}
+
+func sortManifest(entries []output.ManifestEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Resource != entries[j].Resource {
+			return entries[i].Resource < entries[j].Resource
+		}
+		if entries[i].Key != entries[j].Key {
+			return entries[i].Key < entries[j].Key
+		}
+		return entries[i].Checksum < entries[j].Checksum
+	})
+}
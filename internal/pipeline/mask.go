@@ -0,0 +1,67 @@
+package pipeline
+
+import (
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// maskTransformer replaces the values of the fields selected by the mask paths with a placeholder
+type maskTransformer struct {
+	paths []internalconfig.MaskPath
+}
+
+func newMaskTransformer(exprs []string) (*maskTransformer, error) {
+	paths := make([]internalconfig.MaskPath, 0, len(exprs))
+	for _, expr := range exprs {
+		path, err := internalconfig.ParseMaskPath(expr)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return &maskTransformer{paths: paths}, nil
+}
+
+func (m *maskTransformer) Transform(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	for _, path := range m.paths {
+		mask(obj.Object, path)
+	}
+	return obj, nil
+}
+
+// mask replaces the values selected by path below node
+func mask(node interface{}, path internalconfig.MaskPath) {
+	if len(path) == 0 {
+		return
+	}
+	segment, rest := path[0], path[1:]
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for key, value := range n {
+			if segment.MatchesKey(key) {
+				if len(rest) == 0 {
+					n[key] = internalconfig.MaskedValue
+					continue
+				}
+				mask(value, rest)
+			}
+			if segment.Descendant {
+				mask(value, path)
+			}
+		}
+	case []interface{}:
+		for i, item := range n {
+			if segment.MatchesItem(i, item) {
+				if len(rest) == 0 {
+					n[i] = internalconfig.MaskedValue
+					continue
+				}
+				mask(item, rest)
+			}
+			if segment.Descendant {
+				mask(item, path)
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package pipeline
+
+import (
+	"reflect"
+	"testing"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newTestPodWithEnv(env ...map[string]interface{}) *unstructured.Unstructured {
+	vars := make([]interface{}, 0, len(env))
+	for _, e := range env {
+		vars = append(vars, e)
+	}
+	pod := newTestObject("v1", "Pod", "default", "web")
+	_ = unstructured.SetNestedSlice(pod.Object, []interface{}{
+		map[string]interface{}{"name": "app", "image": "web:1.0", "env": vars},
+	}, "spec", "containers")
+	return pod
+}
+
+func envVar(name, value string) map[string]interface{} {
+	return map[string]interface{}{"name": name, "value": value}
+}
+
+func TestMaskTransform(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mask     []string
+		expected []interface{}
+	}{
+		{
+			name: "filter with wildcard",
+			mask: []string{"$.spec.containers[*].env[?(@.name=='*_TOKEN')].value"},
+			expected: []interface{}{
+				envVar("API_TOKEN", internalconfig.MaskedValue),
+				envVar("LOG_LEVEL", "debug"),
+				envVar("GITHUB_TOKEN", internalconfig.MaskedValue),
+			},
+		},
+		{
+			name: "descendant",
+			mask: []string{"$..env[?(@.name=='API_*')].value"},
+			expected: []interface{}{
+				envVar("API_TOKEN", internalconfig.MaskedValue),
+				envVar("LOG_LEVEL", "debug"),
+				envVar("GITHUB_TOKEN", "ghp-secret"),
+			},
+		},
+		{
+			name: "index",
+			mask: []string{"$.spec.containers[0].env[1]['value']"},
+			expected: []interface{}{
+				envVar("API_TOKEN", "s3cr3t"),
+				envVar("LOG_LEVEL", internalconfig.MaskedValue),
+				envVar("GITHUB_TOKEN", "ghp-secret"),
+			},
+		},
+		{
+			name: "no match",
+			mask: []string{"$.spec.containers[*].env[?(@.name=='*_PASSWORD')].value"},
+			expected: []interface{}{
+				envVar("API_TOKEN", "s3cr3t"),
+				envVar("LOG_LEVEL", "debug"),
+				envVar("GITHUB_TOKEN", "ghp-secret"),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			writer := &recordingWriter{}
+			config := internalconfig.PipelineConfig{
+				Name:   "pods.v1.",
+				Events: []string{"ADDED"},
+				Mask:   tc.mask,
+			}
+			ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, config, internalconfig.GlobalSettings{}, writer, "")
+
+			pod := newTestPodWithEnv(envVar("API_TOKEN", "s3cr3t"), envVar("LOG_LEVEL", "debug"), envVar("GITHUB_TOKEN", "ghp-secret"))
+			masked, err := transform(pod, ri.transformers)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			containers, _, _ := unstructured.NestedSlice(masked.Object, "spec", "containers")
+			env := containers[0].(map[string]interface{})["env"]
+			if !reflect.DeepEqual(env, tc.expected) {
+				t.Errorf("expected env %v, got %v", tc.expected, env)
+			}
+			if image := containers[0].(map[string]interface{})["image"]; image != "web:1.0" {
+				t.Errorf("expected the image to remain, got %v", image)
+			}
+
+			original, _, _ := unstructured.NestedSlice(pod.Object, "spec", "containers")
+			if value := original[0].(map[string]interface{})["env"].([]interface{})[0].(map[string]interface{})["value"]; value != "s3cr3t" {
+				t.Errorf("informer object must not be mutated by transformers, got %v", value)
+			}
+		})
+	}
+}
+
+func TestMaskSecretData(t *testing.T) {
+	secret := newTestObject("v1", "Secret", "default", "credentials")
+	_ = unstructured.SetNestedStringMap(secret.Object, map[string]string{
+		"DB_TOKEN": "dG9rZW4=",
+		"DB_HOST":  "ZGI=",
+	}, "data")
+
+	masker, err := newMaskTransformer([]string{"$.data.*_TOKEN"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	masked, _ := masker.Transform(secret)
+
+	data, _, _ := unstructured.NestedStringMap(masked.Object, "data")
+	expected := map[string]string{"DB_TOKEN": internalconfig.MaskedValue, "DB_HOST": "ZGI="}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("expected data %v, got %v", expected, data)
+	}
+}
@@ -0,0 +1,28 @@
+package pipeline
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// monitorsMetadata reports whether the pipeline emits updates only when monitored labels or annotations change
+func (ri *RegisterInformer) monitorsMetadata() bool {
+	return len(ri.config.MetadataLabels) > 0 || len(ri.config.MetadataAnnotations) > 0
+}
+
+// metadataKeysChanged reports whether the update changed the value of one of the labels or annotations,
+// adding or removing a key counts as a change
+func metadataKeysChanged(oldObj, obj *unstructured.Unstructured, labels, annotations []string) bool {
+	return keysChanged(oldObj.GetLabels(), obj.GetLabels(), labels) ||
+		keysChanged(oldObj.GetAnnotations(), obj.GetAnnotations(), annotations)
+}
+
+func keysChanged(old, current map[string]string, keys []string) bool {
+	for _, key := range keys {
+		oldValue, oldOk := old[key]
+		value, ok := current[key]
+		if oldOk != ok || oldValue != value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package pipeline
+
+import (
+	"testing"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestMetadataChanges(t *testing.T) {
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:                "deployments.v1.apps",
+		Events:              []string{"ADDED", "MODIFIED", "DELETED"},
+		MetadataLabels:      []string{"team"},
+		MetadataAnnotations: []string{"owner"},
+	}, internalconfig.GlobalSettings{}, writer, "")
+	handlers := ri.GetEventHandlers()
+	suppressedBefore := suppressedCount("deployments.v1.apps", suppressedMetadataUnchanged)
+
+	deployment := newTestObject("apps/v1", "Deployment", "default", "web")
+	deployment.SetLabels(map[string]string{"team": "mesh", "app": "web"})
+	_ = unstructured.SetNestedField(deployment.Object, int64(1), "spec", "replicas")
+
+	// a spec change and an unrelated label change
+	scaled := deployment.DeepCopy()
+	scaled.SetResourceVersion("2")
+	scaled.SetLabels(map[string]string{"team": "mesh", "app": "web-v2"})
+	_ = unstructured.SetNestedField(scaled.Object, int64(3), "spec", "replicas")
+	handlers.UpdateFunc(deployment, scaled)
+
+	if count := len(writer.writtenObjects()); count != 0 {
+		t.Errorf("expected the unrelated update to be suppressed, got %d objects", count)
+	}
+	if count := suppressedCount("deployments.v1.apps", suppressedMetadataUnchanged) - suppressedBefore; count != 1 {
+		t.Errorf("expected 1 event suppressed as %s, got %v", suppressedMetadataUnchanged, count)
+	}
+
+	relabeled := scaled.DeepCopy()
+	relabeled.SetResourceVersion("3")
+	relabeled.SetLabels(map[string]string{"team": "platform", "app": "web-v2"})
+	handlers.UpdateFunc(scaled, relabeled)
+
+	annotated := relabeled.DeepCopy()
+	annotated.SetResourceVersion("4")
+	annotated.SetAnnotations(map[string]string{"owner": "alice"})
+	handlers.UpdateFunc(relabeled, annotated)
+
+	unlabeled := annotated.DeepCopy()
+	unlabeled.SetResourceVersion("5")
+	unlabeled.SetLabels(map[string]string{"app": "web-v2"})
+	handlers.UpdateFunc(annotated, unlabeled)
+
+	if count := len(writer.writtenObjects()); count != 3 {
+		t.Errorf("expected the changed, added and removed keys to be emitted, got %d objects", count)
+	}
+}
+
+func TestMetadataKeysChanged(t *testing.T) {
+	obj := newTestObject("v1", "Service", "default", "web")
+	obj.SetLabels(map[string]string{"team": ""})
+	unlabeled := obj.DeepCopy()
+	unlabeled.SetLabels(nil)
+
+	if !metadataKeysChanged(obj, unlabeled, []string{"team"}, nil) {
+		t.Error("expected removing an empty label to be a change")
+	}
+	if metadataKeysChanged(obj, obj.DeepCopy(), []string{"team"}, []string{"owner"}) {
+		t.Error("expected no change between identical objects")
+	}
+}
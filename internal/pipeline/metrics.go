@@ -0,0 +1,56 @@
+package pipeline
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// reasons an event is suppressed, the values of the reason label
+const (
+	// an update changing nothing but the status of a pipeline which strips the status
+	suppressedStatusOnly = "status_only"
+	// an update carrying no newer resourceVersion, f.e. a resync
+	suppressedDuplicate = "duplicate"
+	// the object is not part of the sample
+	suppressedSampled = "sampled"
+	// the object lacks the annotations of the annotation filter
+	suppressedAnnotationFiltered = "annotation_filtered"
+	// the object is outside of the output namespace
+	suppressedNamespaceExcluded = "namespace_excluded"
+	// the kind is not one of the output resources
+	suppressedKindExcluded = "kind_excluded"
+	// the ADDED event of an object which existed before MeshSync started
+	suppressedPreExisting = "pre_existing"
+	// the pipeline's cache is stale
+	suppressedStale = "stale"
+	// the DELETE event was replaced by a bulk delete summary
+	suppressedBulkDelete = "bulk_delete"
+	// the MODIFIED event of a singleton was replaced by a later one
+	suppressedCoalesced = "coalesced"
+	// the update of a Pod left the monitored container fields unchanged
+	suppressedContainerUnchanged = "container_unchanged"
+	// the update left the monitored labels and annotations unchanged
+	suppressedMetadataUnchanged = "metadata_unchanged"
+	// the update left metadata.generation unchanged, the spec did not change
+	suppressedGenerationUnchanged = "generation_unchanged"
+	// the event of an object whose DELETE event was emitted once its deletionTimestamp was set
+	suppressedTerminating = "terminating"
+	// the pipeline emits summaries instead of the individual events
+	suppressedSummarized = "summarized"
+	// the pipeline emits rollups per owner instead of the individual events
+	suppressedRolledUp = "rolled_up"
+	// the retry of a failed event whose object had a later event
+	suppressedSuperseded = "superseded"
+)
+
+// eventsSuppressed counts the events which are deliberately not emitted,
+// events of types the pipeline does not subscribe to are not counted
+var eventsSuppressed = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "meshsync_events_suppressed_total",
+	Help: "Number of events not emitted, by resource and reason.",
+}, []string{"resource", "reason"})
+
+// suppressed records that count events of the pipeline were suppressed for the reason
+func (ri *RegisterInformer) suppressed(reason string, count int) {
+	eventsSuppressed.WithLabelValues(ri.config.Name, reason).Add(float64(count))
+}
@@ -0,0 +1,74 @@
+package pipeline
+
+import (
+	"testing"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func suppressedCount(resource, reason string) float64 {
+	return testutil.ToFloat64(eventsSuppressed.WithLabelValues(resource, reason))
+}
+
+func TestStatusOnlySuppression(t *testing.T) {
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:        "statusonly.v1.example.com",
+		Events:      []string{"ADDED", "MODIFIED", "DELETED"},
+		StripStatus: true,
+	}, internalconfig.GlobalSettings{}, writer, "")
+	suppressedBefore := suppressedCount("statusonly.v1.example.com", suppressedStatusOnly)
+
+	oldObj := newTestObject("example.com/v1", "Widget", "default", "widget-a")
+	oldObj.SetResourceVersion("1")
+	_ = unstructured.SetNestedField(oldObj.Object, "Pending", "status", "phase")
+
+	statusUpdate := oldObj.DeepCopy()
+	statusUpdate.SetResourceVersion("2")
+	_ = unstructured.SetNestedField(statusUpdate.Object, "Running", "status", "phase")
+	ri.GetEventHandlers().UpdateFunc(oldObj, statusUpdate)
+
+	if count := suppressedCount("statusonly.v1.example.com", suppressedStatusOnly) - suppressedBefore; count != 1 {
+		t.Errorf("expected 1 event suppressed as %s, got %v", suppressedStatusOnly, count)
+	}
+	if count := len(writer.writtenObjects()); count != 0 {
+		t.Errorf("expected no emitted objects, got %d", count)
+	}
+
+	specUpdate := statusUpdate.DeepCopy()
+	specUpdate.SetResourceVersion("3")
+	_ = unstructured.SetNestedField(specUpdate.Object, int64(2), "spec", "replicas")
+	ri.GetEventHandlers().UpdateFunc(statusUpdate, specUpdate)
+
+	if count := suppressedCount("statusonly.v1.example.com", suppressedStatusOnly) - suppressedBefore; count != 1 {
+		t.Errorf("expected spec updates not to be suppressed, got %v suppressed", count)
+	}
+	if count := len(writer.writtenObjects()); count != 1 {
+		t.Errorf("expected the spec update to be emitted, got %d objects", count)
+	}
+}
+
+func TestSamplingSuppression(t *testing.T) {
+	rate := 0.0
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:     "sampled.v1.example.com",
+		Events:   []string{"ADDED", "MODIFIED", "DELETED"},
+		Sampling: &internalconfig.SamplingConfig{Rate: &rate, DefaultRate: &rate},
+	}, internalconfig.GlobalSettings{}, writer, "")
+	sampledBefore := suppressedCount("sampled.v1.example.com", suppressedSampled)
+	statusOnlyBefore := suppressedCount("sampled.v1.example.com", suppressedStatusOnly)
+
+	for _, name := range []string{"widget-a", "widget-b", "widget-c"} {
+		ri.GetEventHandlers().AddFunc(newTestObject("example.com/v1", "Widget", "default", name))
+	}
+
+	if count := suppressedCount("sampled.v1.example.com", suppressedSampled) - sampledBefore; count != 3 {
+		t.Errorf("expected 3 events suppressed as %s, got %v", suppressedSampled, count)
+	}
+	if count := suppressedCount("sampled.v1.example.com", suppressedStatusOnly) - statusOnlyBefore; count != 0 {
+		t.Errorf("expected no events suppressed as %s, got %v", suppressedStatusOnly, count)
+	}
+}
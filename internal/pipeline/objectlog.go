@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"sync"
+	"time"
+
+	"github.com/meshery/meshkit/logger"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"k8s.io/utils/clock"
+)
+
+// objectLogger samples the lines logged per processed object of a pipeline, see internalconfig.ObjectLogSampling.
+// The number of lines left out is logged once their window has passed.
+type objectLogger struct {
+	log      logger.Handler
+	resource string
+	sampling internalconfig.ObjectLogSampling
+	clock    clock.PassiveClock
+
+	mu          sync.Mutex
+	seen        int
+	windowStart time.Time
+	logged      int
+	leftOut     int
+}
+
+func newObjectLogger(log logger.Handler, resource string, sampling internalconfig.ObjectLogSampling, clock clock.PassiveClock) *objectLogger {
+	if sampling.Window <= 0 {
+		sampling.Window = internalconfig.DefaultObjectLogWindow
+	}
+	return &objectLogger{
+		log:         log,
+		resource:    resource,
+		sampling:    sampling,
+		clock:       clock,
+		windowStart: clock.Now(),
+	}
+}
+
+func (l *objectLogger) Info(description ...interface{}) {
+	if l.allow() {
+		l.log.Info(description...)
+	}
+}
+
+func (l *objectLogger) Debug(description ...interface{}) {
+	if l.allow() {
+		l.log.Debug(description...)
+	}
+}
+
+// allow reports whether the next line is logged
+func (l *objectLogger) allow() bool {
+	if !l.sampling.Enabled() {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now := l.clock.Now(); now.Sub(l.windowStart) >= l.sampling.Window {
+		if l.leftOut > 0 {
+			l.log.Info("Left out ", l.leftOut, " object log lines of: ", l.resource, " within ", l.sampling.Window)
+		}
+		l.windowStart = now
+		l.logged = 0
+		l.leftOut = 0
+	}
+
+	l.seen++
+	if l.sampling.Every > 1 && (l.seen-1)%l.sampling.Every != 0 {
+		l.leftOut++
+		return false
+	}
+	if l.sampling.Limit > 0 && l.logged >= l.sampling.Limit {
+		l.leftOut++
+		return false
+	}
+	l.logged++
+	return true
+}
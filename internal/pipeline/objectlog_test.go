@@ -0,0 +1,91 @@
+package pipeline
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/meshery/meshkit/logger"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	testclock "k8s.io/utils/clock/testing"
+)
+
+// logBuffer collects the lines written by a logger
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// count returns the number of lines containing s
+func (b *logBuffer) count(s string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.Count(b.buf.String(), s)
+}
+
+func newBufferedObjectLogger(t *testing.T, sampling internalconfig.ObjectLogSampling, clock *testclock.FakeClock) (*objectLogger, *logBuffer) {
+	t.Helper()
+	buf := &logBuffer{}
+	log, err := logger.New("meshsync-test", logger.Options{Format: logger.SyslogLogFormat, Output: buf, LogLevel: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newObjectLogger(log, "pods.v1.", sampling, clock), buf
+}
+
+func TestObjectLogLimit(t *testing.T) {
+	clock := testclock.NewFakeClock(time.Now())
+	objectLog, buf := newBufferedObjectLogger(t, internalconfig.ObjectLogSampling{Limit: 3, Window: time.Second}, clock)
+
+	for window := 1; window <= 3; window++ {
+		for i := 0; i < 10; i++ {
+			objectLog.Info("Received ADD event")
+			clock.Step(50 * time.Millisecond)
+		}
+		if logged := buf.count("Received ADD event"); logged != 3*window {
+			t.Errorf("expected at most 3 lines per window, got %d after %d windows", logged, window)
+		}
+		clock.Step(time.Second)
+	}
+
+	// the lines left out of a window are reported once it has passed
+	objectLog.Debug("Skipping UPDATE event")
+	if reported := buf.count("Left out 7 object log lines of: pods.v1."); reported != 3 {
+		t.Errorf("expected the left out lines of every window to be reported, got %d reports", reported)
+	}
+	if logged := buf.count("Skipping UPDATE event"); logged != 1 {
+		t.Errorf("expected debug lines to be sampled alike, got %d", logged)
+	}
+}
+
+func TestObjectLogEvery(t *testing.T) {
+	clock := testclock.NewFakeClock(time.Now())
+	objectLog, buf := newBufferedObjectLogger(t, internalconfig.ObjectLogSampling{Every: 4}, clock)
+
+	for i := 0; i < 10; i++ {
+		objectLog.Info("Received ADD event")
+	}
+	if logged := buf.count("Received ADD event"); logged != 3 {
+		t.Errorf("expected one in 4 lines to be logged, got %d of 10", logged)
+	}
+}
+
+func TestObjectLogDisabled(t *testing.T) {
+	clock := testclock.NewFakeClock(time.Now())
+	objectLog, buf := newBufferedObjectLogger(t, internalconfig.ObjectLogSampling{}, clock)
+
+	for i := 0; i < 10; i++ {
+		objectLog.Info("Received ADD event")
+	}
+	if logged := buf.count("Received ADD event"); logged != 10 {
+		t.Errorf("expected all lines to be logged without sampling, got %d of 10", logged)
+	}
+}
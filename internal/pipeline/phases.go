@@ -0,0 +1,110 @@
+package pipeline
+
+import (
+	"sync"
+
+	"k8s.io/client-go/tools/cache"
+)
+
+// PhaseCallbacks are invoked when the pipelines move from the initial sync to the live watch.
+// Each callback is invoked at most once per run of the pipelines.
+type PhaseCallbacks struct {
+	// OnInitialSyncComplete is invoked once every pipeline has synced and the events
+	// of the initial lists have been emitted, with the number of objects per pipeline
+	OnInitialSyncComplete func(objects map[string]int)
+	// OnFirstLiveEvent is invoked before the first event that is not part of an initial list
+	// is emitted, with the pipeline the event belongs to
+	OnFirstLiveEvent func(resource string)
+	// GateLiveEvents holds back live events until OnInitialSyncComplete has returned,
+	// otherwise pipelines which synced early emit live events while others are still syncing
+	GateLiveEvents bool
+}
+
+func (c PhaseCallbacks) enabled() bool {
+	return c.OnInitialSyncComplete != nil || c.OnFirstLiveEvent != nil || c.GateLiveEvents
+}
+
+// phaseTracker separates the initial sync from the live watch of all pipelines
+type phaseTracker struct {
+	callbacks PhaseCallbacks
+	stopChan  <-chan struct{}
+
+	initialSyncOnce sync.Once
+	initialSynced   chan struct{}
+	firstLiveOnce   sync.Once
+}
+
+// newPhaseTracker returns nil when no callback is configured
+func newPhaseTracker(callbacks PhaseCallbacks, stopChan <-chan struct{}) *phaseTracker {
+	if !callbacks.enabled() {
+		return nil
+	}
+	return &phaseTracker{
+		callbacks:     callbacks,
+		stopChan:      stopChan,
+		initialSynced: make(chan struct{}),
+	}
+}
+
+// initialSyncComplete invokes OnInitialSyncComplete once the events of the initial lists have been handled,
+// live events held back are released once it has returned
+func (t *phaseTracker) initialSyncComplete(objects map[string]int) {
+	if t == nil {
+		return
+	}
+	t.initialSyncOnce.Do(func() {
+		if t.callbacks.OnInitialSyncComplete != nil {
+			t.callbacks.OnInitialSyncComplete(objects)
+		}
+		close(t.initialSynced)
+	})
+}
+
+// live is called before a live event of the pipeline is handled,
+// it reports false if the pipelines were stopped while the event was held back
+func (t *phaseTracker) live(resource string) bool {
+	if t == nil {
+		return true
+	}
+	if t.callbacks.GateLiveEvents {
+		select {
+		case <-t.initialSynced:
+		case <-t.stopChan:
+			return false
+		}
+	}
+	t.firstLiveOnce.Do(func() {
+		if t.callbacks.OnFirstLiveEvent != nil {
+			t.callbacks.OnFirstLiveEvent(resource)
+		}
+	})
+	return true
+}
+
+// phasedHandler tells the live events of a pipeline from the events of its initial list
+type phasedHandler struct {
+	handler  cache.ResourceEventHandler
+	phases   *phaseTracker
+	resource string
+}
+
+func (h phasedHandler) OnAdd(obj interface{}, isInInitialList bool) {
+	if !isInInitialList && !h.phases.live(h.resource) {
+		return
+	}
+	h.handler.OnAdd(obj, isInInitialList)
+}
+
+func (h phasedHandler) OnUpdate(oldObj, newObj interface{}) {
+	if !h.phases.live(h.resource) {
+		return
+	}
+	h.handler.OnUpdate(oldObj, newObj)
+}
+
+func (h phasedHandler) OnDelete(obj interface{}) {
+	if !h.phases.live(h.resource) {
+		return
+	}
+	h.handler.OnDelete(obj)
+}
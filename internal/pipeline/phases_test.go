@@ -0,0 +1,156 @@
+package pipeline
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/meshery/meshkit/broker"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/internal/output"
+	"github.com/meshery/meshsync/pkg/model"
+	"github.com/myntra/pipeline"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+// phaseLog records emitted events and invoked callbacks in the order they happen
+type phaseLog struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (l *phaseLog) record(entry string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+}
+
+func (l *phaseLog) list() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string{}, l.entries...)
+}
+
+func (l *phaseLog) index(entry string) int {
+	for i, e := range l.list() {
+		if e == entry {
+			return i
+		}
+	}
+	return -1
+}
+
+func (l *phaseLog) Write(obj model.KubernetesResource, evtype broker.EventType, _ internalconfig.PipelineConfig) error {
+	l.record(string(evtype) + " " + obj.KubernetesResourceMeta.Name)
+	return nil
+}
+
+func (l *phaseLog) WriteControl(output.ControlEvent) error {
+	return nil
+}
+
+// startPhasedPipelines runs the pods and services pipelines with the given phase callbacks
+func startPhasedPipelines(t *testing.T, informers *informerSet, log *phaseLog, callbacks PhaseCallbacks, stopChan chan struct{}) {
+	t.Helper()
+	phases := newPhaseTracker(callbacks, stopChan)
+	stores := make(map[string]cache.Store)
+	for _, name := range []string{"pods.v1.", "services.v1."} {
+		config := internalconfig.PipelineConfig{Name: name, Events: []string{"ADDED", "MODIFIED", "DELETED"}}
+		step := newRegisterInformerStep(newTestLogger(t), informers, nil, nil, config, internalconfig.GlobalSettings{}, log, "")
+		step.phases = phases
+		if result := step.Exec(&pipeline.Request{Data: stores}); result.Error != nil {
+			t.Fatal(result.Error)
+		}
+	}
+	start := newStartInformersStep(stopChan, newTestLogger(t), informers, nil, log, false)
+	start.phases = phases
+	start.Exec(&pipeline.Request{Data: stores})
+}
+
+func createTestPod(t *testing.T, informers *informerSet, name string) {
+	t.Helper()
+	pods := informers.client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "pods"}).Namespace("default")
+	if _, err := pods.Create(context.Background(), newTestObject("v1", "Pod", "default", name), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPhaseCallbacksOrder(t *testing.T) {
+	informers := newTestInformers(
+		newTestObject("v1", "Pod", "default", "pod-a"),
+		newTestObject("v1", "Pod", "default", "pod-b"),
+		newTestObject("v1", "Service", "default", "svc-a"),
+	)
+	log := &phaseLog{}
+	var synced map[string]int
+	callbacks := PhaseCallbacks{
+		OnInitialSyncComplete: func(objects map[string]int) {
+			synced = objects
+			log.record("initial sync complete")
+		},
+		OnFirstLiveEvent: func(resource string) {
+			log.record("first live event " + resource)
+		},
+	}
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	startPhasedPipelines(t, informers, log, callbacks, stopChan)
+	waitFor(t, func() bool { return log.index("initial sync complete") >= 0 })
+
+	createTestPod(t, informers, "pod-c")
+	createTestPod(t, informers, "pod-d")
+	waitFor(t, func() bool { return log.index("ADDED pod-d") >= 0 })
+
+	entries := log.list()
+	initial := entries[:3]
+	for _, name := range []string{"pod-a", "pod-b", "svc-a"} {
+		if i := log.index("ADDED " + name); i < 0 || i >= len(initial) {
+			t.Errorf("expected the initial list to be emitted before the initial sync completes, got %v", entries)
+		}
+	}
+	expected := []string{"initial sync complete", "first live event pods.v1.", "ADDED pod-c", "ADDED pod-d"}
+	if len(entries) != len(initial)+len(expected) || !reflect.DeepEqual(entries[3:], expected) {
+		t.Errorf("expected %v after the initial list, got %v", expected, entries)
+	}
+	if !reflect.DeepEqual(synced, map[string]int{"pods.v1.": 2, "services.v1.": 1}) {
+		t.Errorf("expected the synced objects per pipeline, got %v", synced)
+	}
+}
+
+func TestPhaseCallbacksGateLiveEvents(t *testing.T) {
+	informers := newTestInformers(newTestObject("v1", "Pod", "default", "pod-a"))
+	log := &phaseLog{}
+	callbacks := PhaseCallbacks{
+		OnInitialSyncComplete: func(map[string]int) {
+			// the event of the created pod must be held back until the callback returns
+			createTestPod(t, informers, "pod-b")
+			time.Sleep(100 * time.Millisecond)
+			log.record("initial sync complete")
+		},
+		OnFirstLiveEvent: func(resource string) {
+			log.record("first live event " + resource)
+		},
+		GateLiveEvents: true,
+	}
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	startPhasedPipelines(t, informers, log, callbacks, stopChan)
+	waitFor(t, func() bool { return log.index("ADDED pod-b") >= 0 })
+
+	expected := []string{"ADDED pod-a", "initial sync complete", "first live event pods.v1.", "ADDED pod-b"}
+	if entries := log.list(); !reflect.DeepEqual(entries, expected) {
+		t.Errorf("expected %v, got %v", expected, entries)
+	}
+}
+
+func TestPhaseTrackerDisabled(t *testing.T) {
+	if phases := newPhaseTracker(PhaseCallbacks{}, nil); phases != nil {
+		t.Errorf("expected no tracker without callbacks, got %v", phases)
+	}
+}
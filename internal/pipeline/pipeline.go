@@ -5,55 +5,104 @@ import (
 	internalconfig "github.com/meshery/meshsync/internal/config"
 	"github.com/meshery/meshsync/internal/output"
 	"github.com/myntra/pipeline"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/openapi"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
 )
 
-var (
-	Name                 = internalconfig.PipelineNameKey
-	GlobalDiscoveryStage = &pipeline.Stage{
-		Name:       internalconfig.GlobalResourceKey,
-		Concurrent: false,
-		Steps:      []pipeline.Step{},
-	}
+var Name = internalconfig.PipelineNameKey
 
-	LocalDiscoveryStage = &pipeline.Stage{
-		Name:       internalconfig.LocalResourceKey,
-		Concurrent: false,
-		Steps:      []pipeline.Step{},
-	}
+// names of the stages of the pipeline
+const (
+	GlobalDiscoveryStageName = internalconfig.GlobalResourceKey
+	LocalDiscoveryStageName  = internalconfig.LocalResourceKey
+	StartInformersStageName  = "StartInformers"
+)
 
-	StartInformersStage = &pipeline.Stage{
-		Name:       "StartInformers",
+// newStage returns an empty stage, every pipeline built gets stages of its own,
+// so a rebuilt pipeline does not run the steps of the previous ones
+func newStage(name string) *pipeline.Stage {
+	return &pipeline.Stage{
+		Name:       name,
 		Concurrent: false,
 		Steps:      []pipeline.Step{},
 	}
-)
+}
 
 func New(
 	log logger.Handler,
 	informer dynamicinformer.DynamicSharedInformerFactory,
+	dynamicClient dynamic.Interface,
 	ow output.Writer,
 	plConfigs map[string]internalconfig.PipelineConfigs,
+	settings internalconfig.GlobalSettings,
 	stopChan chan struct{},
 	clusterID string,
+	statuses *StatusTracker,
+	resyncer *ObjectResyncer,
+	schemas openapi.Client,
+	callbacks PhaseCallbacks,
 ) *pipeline.Pipeline {
+	informers := newInformerSet(wait.ContextForChannel(stopChan), informer, dynamicClient)
+	informers.settings = settings
+	deletions := newDeletionTracker(clock.RealClock{})
+	phases := newPhaseTracker(callbacks, stopChan)
+	retries := make([]*retryQueue, 0)
+	var manifest *snapshotManifest
+	if settings.SnapshotManifest {
+		manifest = newSnapshotManifest()
+	}
+	var pruner *DefaultsPruner
+	if schemas != nil {
+		pruner = NewDefaultsPruner(schemas)
+	}
+	relationships := relationshipTrackerFor(log, ow, informers, plConfigs, settings)
+	namespaces := namespaceCheckerFor(log, informers, statuses, plConfigs, settings)
+	resyncer.reset()
+	statuses.reset()
+	newStep := func(config internalconfig.PipelineConfig) *RegisterInformer {
+		step := newRegisterInformerStep(log, informers, deletions, statuses, config, settings, ow, clusterID)
+		step.phases = phases
+		step.retries = newRetryQueue(log, config.Name, workqueue.DefaultTypedControllerRateLimiter[*failedEvent]())
+		retries = append(retries, step.retries)
+		step.manifest = manifest
+		step.relationships = relationships
+		if config.PruneDefaults {
+			step.transformers = transformersFor(log, config, settings, pruner)
+		}
+		resyncer.register(step)
+		return step
+	}
+
 	// Global discovery
-	gdstage := GlobalDiscoveryStage
+	gdstage := newStage(GlobalDiscoveryStageName)
 	configs := plConfigs[gdstage.Name]
 	for _, config := range configs {
-		gdstage.AddStep(newRegisterInformerStep(log, informer, config, ow, clusterID)) // Register the informers for different resources
+		gdstage.AddStep(newStep(config)) // Register the informers for different resources
 	}
 
 	// Local discovery
-	ldstage := LocalDiscoveryStage
+	ldstage := newStage(LocalDiscoveryStageName)
 	configs = plConfigs[ldstage.Name]
 	for _, config := range configs {
-		ldstage.AddStep(newRegisterInformerStep(log, informer, config, ow, clusterID)) // Register the informers for different resources
+		ldstage.AddStep(newStep(config)) // Register the informers for different resources
 	}
 
 	// Start informers
-	strtInfmrs := StartInformersStage
-	strtInfmrs.AddStep(newStartInformersStep(stopChan, log, informer)) // Start the registered informers
+	strtInfmrs := newStage(StartInformersStageName)
+	startStep := newStartInformersStep(stopChan, log, informers, statuses, ow, settings.SnapshotCompleteMarker)
+	startStep.maxConcurrentInitializing = settings.MaxConcurrentInitializing
+	startStep.summaries = summarizedPipelines(plConfigs)
+	startStep.phases = phases
+	startStep.retries = retries
+	startStep.manifest = manifest
+	startStep.namespaces = namespaces
+	startStep.startupJitter = settings.StartupJitter
+	startStep.coordinator = startupCoordinatorFor(dynamicClient, internalconfig.CRDConfigFromEnv().Namespace, settings.StartupStagger)
+	strtInfmrs.AddStep(startStep) // Start the registered informers
 
 	// Create Pipeline
 	clusterPipeline := pipeline.New(Name, 1000)
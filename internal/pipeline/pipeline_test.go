@@ -0,0 +1,109 @@
+package pipeline
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+// runPipeline builds and runs the pipeline of the configs until stopChan is closed,
+// and returns the names of the stores it reports
+func runPipeline(t *testing.T, informers *informerSet, writer *recordingWriter, configs map[string]internalconfig.PipelineConfigs, statuses *StatusTracker, stopChan chan struct{}) []string {
+	t.Helper()
+	pl := New(newTestLogger(t), informers.factory, informers.client, writer, configs, internalconfig.GlobalSettings{}, stopChan, "", statuses, NewObjectResyncer(), nil, PhaseCallbacks{})
+	// nothing reads the output of the pipeline
+	pl.SetDrainTimeout(10 * time.Millisecond)
+	result := pl.Run()
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	names := make([]string, 0)
+	for name := range result.Data.(map[string]cache.Store) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRebuiltPipelineRunsOnlyItsOwnSteps(t *testing.T) {
+	informers := newTestInformers()
+	pods := internalconfig.PipelineConfig{Name: "pods.v1.", Events: []string{"ADDED"}}
+	services := internalconfig.PipelineConfig{Name: "services.v1.", Events: []string{"ADDED"}}
+	namespaces := internalconfig.PipelineConfig{Name: "namespaces.v1.", Events: []string{"ADDED"}}
+
+	stopChan := make(chan struct{})
+	names := runPipeline(t, informers, &recordingWriter{}, map[string]internalconfig.PipelineConfigs{
+		internalconfig.GlobalResourceKey: {namespaces},
+		internalconfig.LocalResourceKey:  {pods, services},
+	}, NewStatusTracker(), stopChan)
+	if expected := []string{"namespaces.v1.", "pods.v1.", "services.v1."}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected the stores %v, got %v", expected, names)
+	}
+	close(stopChan)
+
+	// reloaded without services.v1.
+	stopChan = make(chan struct{})
+	defer close(stopChan)
+	names = runPipeline(t, newTestInformers(), &recordingWriter{}, map[string]internalconfig.PipelineConfigs{
+		internalconfig.GlobalResourceKey: {namespaces},
+		internalconfig.LocalResourceKey:  {pods},
+	}, NewStatusTracker(), stopChan)
+	if expected := []string{"namespaces.v1.", "pods.v1."}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected the rebuilt pipeline to report the stores %v, got %v", expected, names)
+	}
+}
+
+func TestReloadResetsPipelineStatuses(t *testing.T) {
+	statuses := NewStatusTracker()
+	namespaces := internalconfig.PipelineConfig{Name: "namespaces.v1.", Events: []string{"ADDED"}}
+	pods := internalconfig.PipelineConfig{Name: "pods.v1.", Events: []string{"ADDED"}}
+	services := internalconfig.PipelineConfig{Name: "services.v1.", Events: []string{"ADDED"}}
+
+	stopChan := make(chan struct{})
+	runPipeline(t, newTestInformers(), &recordingWriter{}, map[string]internalconfig.PipelineConfigs{
+		internalconfig.GlobalResourceKey: {namespaces},
+		internalconfig.LocalResourceKey:  {pods, services},
+	}, statuses, stopChan)
+	waitFor(t, func() bool {
+		status, _ := statuses.Get("pods.v1.")
+		return status.Synced
+	})
+	close(stopChan)
+
+	// reloaded without services.v1., the pods are listed once released
+	informers := newTestInformers()
+	release := make(chan struct{})
+	informers.client.(*fake.FakeDynamicClient).PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		<-release
+		return false, nil, nil
+	})
+	stopChan = make(chan struct{})
+	defer close(stopChan)
+	runPipeline(t, informers, &recordingWriter{}, map[string]internalconfig.PipelineConfigs{
+		internalconfig.GlobalResourceKey: {namespaces},
+		internalconfig.LocalResourceKey:  {pods},
+	}, statuses, stopChan)
+
+	names := make([]string, 0)
+	for _, status := range statuses.List() {
+		names = append(names, status.Name)
+	}
+	if expected := []string{"namespaces.v1.", "pods.v1."}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected the statuses of %v, got %v", expected, names)
+	}
+	if status, _ := statuses.Get("pods.v1."); status.Synced {
+		t.Error("expected the reloaded pipeline not to be synced before its informer")
+	}
+	close(release)
+	waitFor(t, func() bool {
+		status, _ := statuses.Get("pods.v1.")
+		return status.Synced
+	})
+}
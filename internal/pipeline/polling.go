@@ -0,0 +1,194 @@
+package pipeline
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
+)
+
+// pollingListWatch never establishes a watch, instead it lists every interval and
+// serves the differences to the previous list as watch events, so the informer and its handlers
+// emit ADDED, MODIFIED and DELETED events as if the resource was watched.
+// Changes between two polls are coalesced, an object created and deleted in between is never seen.
+type pollingListWatch struct {
+	cache.ListerWatcher
+	interval time.Duration
+	clock    clock.Clock
+
+	mu sync.Mutex
+	// the objects of the previous list by key
+	last map[string]runtime.Object
+	// the objects of the pages of a paginated list so far
+	pages map[string]runtime.Object
+}
+
+func newPollingListWatch(lw cache.ListerWatcher, interval time.Duration, c clock.Clock) *pollingListWatch {
+	return &pollingListWatch{
+		ListerWatcher: lw,
+		interval:      interval,
+		clock:         c,
+		last:          make(map[string]runtime.Object),
+	}
+}
+
+// List lists the objects and records them as the baseline of the next poll,
+// the pages of a paginated list are collected until the last one
+func (lw *pollingListWatch) List(options metav1.ListOptions) (runtime.Object, error) {
+	list, err := lw.ListerWatcher.List(options)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := objectsByKey(list)
+	if err != nil {
+		return nil, err
+	}
+	listMeta, err := meta.ListAccessor(list)
+	if err != nil {
+		return nil, err
+	}
+
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	if options.Continue == "" {
+		lw.pages = make(map[string]runtime.Object, len(objects))
+	}
+	for key, obj := range objects {
+		lw.pages[key] = obj
+	}
+	if listMeta.GetContinue() == "" {
+		lw.last = lw.pages
+		lw.pages = nil
+	}
+	return list, nil
+}
+
+// Watch returns a watch fed by the polls
+func (lw *pollingListWatch) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	w := &pollingWatch{
+		result: make(chan watch.Event),
+		done:   make(chan struct{}),
+	}
+	go lw.poll(w)
+	return w, nil
+}
+
+// poll lists every interval until the watch is stopped, a failed list ends the watch with an error event
+func (lw *pollingListWatch) poll(w *pollingWatch) {
+	defer close(w.result)
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-lw.clock.After(lw.interval):
+		}
+
+		events, err := lw.diff()
+		if err != nil {
+			status := apierrors.NewInternalError(err).ErrStatus
+			select {
+			case w.result <- watch.Event{Type: watch.Error, Object: &status}:
+			case <-w.done:
+			}
+			return
+		}
+		for _, event := range events {
+			select {
+			case w.result <- event:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+// diff lists the objects and returns the events turning the previous list into the current one,
+// ordered by key
+func (lw *pollingListWatch) diff() ([]watch.Event, error) {
+	list, err := lw.ListerWatcher.List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	current, err := objectsByKey(list)
+	if err != nil {
+		return nil, err
+	}
+
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	keys := make([]string, 0, len(current)+len(lw.last))
+	for key := range current {
+		keys = append(keys, key)
+	}
+	for key := range lw.last {
+		if _, ok := current[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	events := make([]watch.Event, 0)
+	for _, key := range keys {
+		obj, exists := current[key]
+		previous, existed := lw.last[key]
+		switch {
+		case !existed:
+			events = append(events, watch.Event{Type: watch.Added, Object: obj})
+		case !exists:
+			events = append(events, watch.Event{Type: watch.Deleted, Object: previous})
+		case resourceVersionOf(obj) != resourceVersionOf(previous):
+			events = append(events, watch.Event{Type: watch.Modified, Object: obj})
+		}
+	}
+	lw.last = current
+	return events, nil
+}
+
+// objectsByKey returns the items of the list by their namespace/name key
+func objectsByKey(list runtime.Object) (map[string]runtime.Object, error) {
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	objects := make(map[string]runtime.Object, len(items))
+	for _, item := range items {
+		key, err := cache.MetaNamespaceKeyFunc(item)
+		if err != nil {
+			return nil, err
+		}
+		objects[key] = item
+	}
+	return objects, nil
+}
+
+func resourceVersionOf(obj runtime.Object) string {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return ""
+	}
+	return accessor.GetResourceVersion()
+}
+
+// pollingWatch delivers the events of the polls until it is stopped
+type pollingWatch struct {
+	result chan watch.Event
+	once   sync.Once
+	done   chan struct{}
+}
+
+func (w *pollingWatch) Stop() {
+	w.once.Do(func() {
+		close(w.done)
+	})
+}
+
+func (w *pollingWatch) ResultChan() <-chan watch.Event {
+	return w.result
+}
@@ -0,0 +1,107 @@
+package pipeline
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func podList(resourceVersion string, pods ...*unstructured.Unstructured) *unstructured.UnstructuredList {
+	list := &unstructured.UnstructuredList{
+		Object: map[string]interface{}{"apiVersion": "v1", "kind": "PodList", "metadata": map[string]interface{}{"resourceVersion": resourceVersion}},
+	}
+	for _, pod := range pods {
+		list.Items = append(list.Items, *pod)
+	}
+	return list
+}
+
+func podWithVersion(name, resourceVersion string) *unstructured.Unstructured {
+	pod := newTestObject("v1", "Pod", "default", name)
+	pod.SetResourceVersion(resourceVersion)
+	return pod
+}
+
+func TestPollingEmitsTheDifferences(t *testing.T) {
+	lw := &fakeListWatch{list: podList("1", podWithVersion("pod-a", "1"), podWithVersion("pod-b", "1"))}
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	interval := 5 * time.Minute
+
+	informer := cache.NewSharedIndexInformer(newPollingListWatch(lw, interval, fakeClock), &unstructured.Unstructured{}, 0, cache.Indexers{})
+	var mu sync.Mutex
+	events := make([]string, 0)
+	record := func(event string, obj interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event+" "+obj.(*unstructured.Unstructured).GetName())
+	}
+	_, _ = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { record("ADDED", obj) },
+		UpdateFunc: func(oldObj, obj interface{}) { record("MODIFIED", obj) },
+		DeleteFunc: func(obj interface{}) { record("DELETED", obj) },
+	})
+	recorded := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, events...)
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go informer.Run(stopCh)
+	waitFor(t, func() bool { return informer.HasSynced() && fakeClock.HasWaiters() })
+
+	polls := []struct {
+		list     *unstructured.UnstructuredList
+		expected []string
+	}{
+		{
+			// pod-a changed, pod-c was created
+			list:     podList("3", podWithVersion("pod-a", "2"), podWithVersion("pod-b", "1"), podWithVersion("pod-c", "3")),
+			expected: []string{"MODIFIED pod-a", "ADDED pod-c"},
+		},
+		{
+			// nothing changed
+			list: podList("3", podWithVersion("pod-a", "2"), podWithVersion("pod-b", "1"), podWithVersion("pod-c", "3")),
+		},
+		{
+			// pod-b was deleted, pod-c changed
+			list:     podList("5", podWithVersion("pod-a", "2"), podWithVersion("pod-c", "5")),
+			expected: []string{"DELETED pod-b", "MODIFIED pod-c"},
+		},
+	}
+
+	expected := []string{"ADDED pod-a", "ADDED pod-b"}
+	waitFor(t, func() bool { return len(recorded()) == len(expected) })
+	for i, poll := range polls {
+		lw.mu.Lock()
+		lw.list = poll.list
+		lw.mu.Unlock()
+
+		// nothing is listed before the interval is over
+		fakeClock.Step(interval - time.Second)
+		if lists, _, _ := lw.state(); lists != i+1 {
+			t.Fatalf("poll %d: expected %d lists before the interval is over, got %d", i, i+1, lists)
+		}
+		fakeClock.Step(time.Second)
+		waitFor(t, func() bool {
+			lists, _, _ := lw.state()
+			return lists == i+2 && fakeClock.HasWaiters()
+		})
+
+		expected = append(expected, poll.expected...)
+		waitFor(t, func() bool { return len(recorded()) >= len(expected) })
+		if events := recorded(); !reflect.DeepEqual(events, expected) {
+			t.Errorf("poll %d: expected the events %v, got %v", i, expected, events)
+		}
+	}
+
+	if _, watches, _ := lw.state(); len(watches) != 0 {
+		t.Errorf("expected no watch to be established, got %d", len(watches))
+	}
+}
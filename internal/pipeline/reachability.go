@@ -0,0 +1,156 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+
+	"github.com/meshery/meshkit/logger"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"golang.org/x/exp/slices"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// namespaceChecker reports the namespaces pipelines are scoped to which do not exist,
+// their watches silently produce nothing until the namespaces are created.
+// The pipelines are re-evaluated from the namespaces informer whenever a namespace is added or deleted.
+type namespaceChecker struct {
+	log       logger.Handler
+	informers *informerSet
+	statuses  *StatusTracker
+	// the pipelines scoped to namespaces
+	configs []internalconfig.PipelineConfig
+	strict  bool
+
+	mu           sync.Mutex
+	registration cache.ResourceEventHandlerRegistration
+	// the initial list of the namespaces has been handled, before it every namespace would be reported
+	synced bool
+	// the missing namespaces by pipeline
+	missing map[string][]string
+}
+
+// namespaceCheckerFor returns nil unless a pipeline is scoped to namespaces,
+// and with namespaced clients, which lack the permissions to watch the cluster scoped namespaces
+func namespaceCheckerFor(
+	log logger.Handler,
+	informers *informerSet,
+	statuses *StatusTracker,
+	plConfigs map[string]internalconfig.PipelineConfigs,
+	settings internalconfig.GlobalSettings,
+) *namespaceChecker {
+	if settings.NamespacedClients {
+		return nil
+	}
+	checker := &namespaceChecker{
+		log:       log,
+		informers: informers,
+		statuses:  statuses,
+		strict:    settings.StrictNamespaces,
+		missing:   make(map[string][]string),
+	}
+	for _, configs := range plConfigs {
+		for _, config := range configs {
+			if len(config.Namespaces) > 0 {
+				checker.configs = append(checker.configs, config)
+			}
+		}
+	}
+	if len(checker.configs) == 0 {
+		return nil
+	}
+
+	informers.registerNamespaces()
+	registration, err := informers.factory.ForResource(namespacesGVR).Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { checker.evaluate() },
+		DeleteFunc: func(interface{}) { checker.evaluate() },
+	})
+	if err != nil {
+		log.Error(ErrAddHandler(namespacesGVR.Resource, err))
+		return nil
+	}
+	checker.registration = registration
+	return checker
+}
+
+// verify fails if a namespace of a pipeline does not exist in the strict mode,
+// it lists the namespaces as the informers have not been started yet
+func (c *namespaceChecker) verify(ctx context.Context) error {
+	if c == nil || !c.strict || c.informers.client == nil {
+		return nil
+	}
+	list, err := c.informers.client.Resource(namespacesGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return ErrList(namespacesGVR.Resource, err)
+	}
+	existing := make(map[string]bool, len(list.Items))
+	for _, namespace := range list.Items {
+		existing[namespace.GetName()] = true
+	}
+	for _, config := range c.configs {
+		missing := missingNamespaces(config.Namespaces, func(namespace string) bool { return existing[namespace] })
+		if len(missing) > 0 {
+			return ErrMissingNamespaces(config.Name, missing)
+		}
+	}
+	return nil
+}
+
+// run reports the missing namespaces once the initial list of the namespaces has been handled
+func (c *namespaceChecker) run(stopCh <-chan struct{}) {
+	if c == nil {
+		return
+	}
+	if !cache.WaitForCacheSync(stopCh, c.registration.HasSynced) {
+		return
+	}
+	c.mu.Lock()
+	c.synced = true
+	c.mu.Unlock()
+	c.evaluate()
+}
+
+// evaluate updates the missing namespaces of every pipeline from the namespaces informer,
+// warning about the namespaces which went missing
+func (c *namespaceChecker) evaluate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.synced {
+		return
+	}
+	store := c.informers.factory.ForResource(namespacesGVR).Informer().GetStore()
+	for _, config := range c.configs {
+		missing := missingNamespaces(config.Namespaces, func(namespace string) bool {
+			_, exists, _ := store.GetByKey(namespace)
+			return exists
+		})
+		previous, reported := c.missing[config.Name]
+		if reported && slices.Equal(previous, missing) {
+			continue
+		}
+		c.missing[config.Name] = missing
+
+		c.statuses.update(config.Name, func(status *PipelineStatus) {
+			status.MissingNamespaces = missing
+		})
+		for _, namespace := range previous {
+			if !slices.Contains(missing, namespace) {
+				c.log.Infof("Namespace %s of %s has been created, its objects are watched", namespace, config.Name)
+			}
+		}
+		if len(missing) > 0 {
+			c.log.Warn(ErrMissingNamespaces(config.Name, missing))
+		}
+	}
+}
+
+// missingNamespaces returns the namespaces which do not exist, in their order
+func missingNamespaces(namespaces []string, exists func(namespace string) bool) []string {
+	var missing []string
+	for _, namespace := range namespaces {
+		if !exists(namespace) {
+			missing = append(missing, namespace)
+		}
+	}
+	return missing
+}
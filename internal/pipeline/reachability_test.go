@@ -0,0 +1,112 @@
+package pipeline
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/meshery/meshkit/errors"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/myntra/pipeline"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestMissingNamespacesAreReported(t *testing.T) {
+	log := newTestLogger(t)
+	informers := newTestInformers(
+		newTestObject("v1", "Namespace", "", "default"),
+		newTestObject("v1", "Pod", "default", "pod-a"),
+	)
+	settings := internalconfig.GlobalSettings{NamespaceStrategy: internalconfig.NamespaceStrategyPerNamespace}
+	informers.settings = settings
+	config := internalconfig.PipelineConfig{
+		Name:       "pods.v1.",
+		Events:     []string{"ADDED"},
+		Namespaces: []string{"default", "prod"},
+	}
+	plConfigs := map[string]internalconfig.PipelineConfigs{internalconfig.LocalResourceKey: {config}}
+	statuses := NewStatusTracker()
+	writer := &recordingWriter{}
+	stores := map[string]cache.Store{}
+	if result := newRegisterInformerStep(log, informers, nil, statuses, config, settings, writer, "").Exec(&pipeline.Request{Data: stores}); result.Error != nil {
+		t.Fatal(result.Error)
+	}
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	startStep := newStartInformersStep(stopChan, log, informers, statuses, writer, false)
+	startStep.namespaces = namespaceCheckerFor(log, informers, statuses, plConfigs, settings)
+	if result := startStep.Exec(&pipeline.Request{Data: stores}); result.Error != nil {
+		t.Fatal(result.Error)
+	}
+
+	missing := func() []string {
+		status, _ := statuses.Get(config.Name)
+		return status.MissingNamespaces
+	}
+	waitFor(t, func() bool { return len(missing()) > 0 })
+	if expected := []string{"prod"}; !reflect.DeepEqual(missing(), expected) {
+		t.Errorf("expected the missing namespaces %v, got %v", expected, missing())
+	}
+
+	ctx := context.Background()
+	if _, err := informers.client.Resource(namespacesGVR).Create(ctx, newTestObject("v1", "Namespace", "", "prod"), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return len(missing()) == 0 })
+
+	// the watch of the created namespace is active
+	if _, err := informers.client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "pods"}).Namespace("prod").Create(ctx, newTestObject("v1", "Pod", "prod", "pod-b"), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		for _, obj := range writer.writtenObjects() {
+			if obj.KubernetesResourceMeta.Namespace == "prod" && obj.KubernetesResourceMeta.Name == "pod-b" {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func TestMissingNamespacesFailInStrictMode(t *testing.T) {
+	log := newTestLogger(t)
+	informers := newTestInformers(newTestObject("v1", "Namespace", "", "default"))
+	plConfigs := map[string]internalconfig.PipelineConfigs{
+		internalconfig.LocalResourceKey: {
+			{Name: "pods.v1.", Events: []string{"ADDED"}, Namespaces: []string{"default", "prod"}},
+			{Name: "services.v1.", Events: []string{"ADDED"}},
+		},
+	}
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	startStep := newStartInformersStep(stopChan, log, informers, nil, &recordingWriter{}, false)
+	startStep.namespaces = namespaceCheckerFor(log, informers, nil, plConfigs, internalconfig.GlobalSettings{StrictNamespaces: true})
+	result := startStep.Exec(&pipeline.Request{Data: map[string]cache.Store{}})
+	if result.Error == nil {
+		t.Fatal("expected the missing namespace to fail the start in the strict mode")
+	}
+	if code := errors.GetCode(result.Error); code != ErrMissingNamespacesCode {
+		t.Errorf("expected error code %s, got %s", ErrMissingNamespacesCode, code)
+	}
+}
+
+func TestNamespaceCheckerDisabled(t *testing.T) {
+	plConfigs := map[string]internalconfig.PipelineConfigs{
+		internalconfig.LocalResourceKey: {{Name: "pods.v1.", Events: []string{"ADDED"}}},
+	}
+	settings := internalconfig.GlobalSettings{StrictNamespaces: true}
+	if checker := namespaceCheckerFor(newTestLogger(t), newTestInformers(), nil, plConfigs, settings); checker != nil {
+		t.Error("expected no namespace checker without pipelines scoped to namespaces")
+	}
+
+	// the namespaces cannot be watched with namespaced clients
+	plConfigs[internalconfig.LocalResourceKey][0].Namespaces = []string{"default"}
+	settings.NamespacedClients = true
+	if checker := namespaceCheckerFor(newTestLogger(t), newTestInformers(), nil, plConfigs, settings); checker != nil {
+		t.Error("expected no namespace checker with namespaced clients")
+	}
+}
@@ -0,0 +1,75 @@
+package pipeline
+
+import (
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// the kinds redacted and the fields holding their values, keyed by their names
+var (
+	secretGroupKind    = schema.GroupKind{Kind: "Secret"}
+	configMapGroupKind = schema.GroupKind{Kind: "ConfigMap"}
+	secretDataFields   = []string{"data", "stringData"}
+	configMapFields    = []string{"data", "binaryData"}
+)
+
+// Redactor replaces the values of the data of Secrets with a placeholder before they leave the cluster,
+// and the ones of ConfigMaps if enabled. The keys are kept, so are the metadata and the type,
+// consumers still see the shape of the objects. The last-applied-configuration annotation of kubectl apply
+// holds a copy of the values, it is dropped from the redacted objects.
+// Objects of other kinds are left untouched.
+type Redactor struct {
+	configMaps bool
+}
+
+func NewRedactor(configMaps bool) *Redactor {
+	return &Redactor{configMaps: configMaps}
+}
+
+func (r *Redactor) Transform(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	switch obj.GroupVersionKind().GroupKind() {
+	case secretGroupKind:
+		redactValues(obj, secretDataFields)
+		dropLastApplied(obj)
+	case configMapGroupKind:
+		if r.configMaps {
+			redactValues(obj, configMapFields)
+			dropLastApplied(obj)
+		}
+	}
+	return obj, nil
+}
+
+// redacted reports whether the objects of the pipeline are redacted: the ones of Secrets always,
+// the ones of ConfigMaps if enabled
+func redacted(config internalconfig.PipelineConfig, settings internalconfig.GlobalSettings) bool {
+	gvr, _ := schema.ParseResourceArg(config.Name)
+	if gvr == nil || gvr.Group != "" {
+		return false
+	}
+	return gvr.Resource == "secrets" || (gvr.Resource == "configmaps" && settings.RedactConfigMaps)
+}
+
+// redactValues replaces the value of every key of the maps of the fields
+func redactValues(obj *unstructured.Unstructured, fields []string) {
+	for _, field := range fields {
+		values, ok := obj.Object[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key := range values {
+			values[key] = internalconfig.RedactedValue
+		}
+	}
+}
+
+// dropLastApplied removes the annotation kubectl apply keeps the applied object in
+func dropLastApplied(obj *unstructured.Unstructured) {
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations[lastAppliedAnnotation]; !ok {
+		return
+	}
+	delete(annotations, lastAppliedAnnotation)
+	obj.SetAnnotations(annotations)
+}
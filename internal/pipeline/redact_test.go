@@ -0,0 +1,168 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/pkg/model"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// roundTrip marshals the emitted object and unmarshals it, like a consumer of the broker does
+func roundTrip(t *testing.T, resource model.KubernetesResource) model.KubernetesResource {
+	t.Helper()
+	data, err := json.Marshal(resource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result model.KubernetesResource
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+// emittedValues returns the map of the emitted field, f.e. Data, by key
+func emittedValues(t *testing.T, field string) map[string]string {
+	t.Helper()
+	values := make(map[string]string)
+	if field == "" {
+		return values
+	}
+	if err := json.Unmarshal([]byte(field), &values); err != nil {
+		t.Fatal(err)
+	}
+	return values
+}
+
+func TestRedactSecrets(t *testing.T) {
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:   "secrets.v1.",
+		Events: []string{"ADDED", "MODIFIED", "DELETED"},
+	}, internalconfig.GlobalSettings{}, writer, "")
+
+	secret := newTestObject("v1", "Secret", "default", "credentials")
+	secret.SetLabels(map[string]string{"app": "web"})
+	secret.Object["type"] = "kubernetes.io/basic-auth"
+	_ = unstructured.SetNestedStringMap(secret.Object, map[string]string{"username": "YWRtaW4=", "password": "c2VjcmV0"}, "data")
+	_ = unstructured.SetNestedStringMap(secret.Object, map[string]string{"token": "plain"}, "stringData")
+	ri.GetEventHandlers().AddFunc(secret)
+
+	objects := writer.writtenObjects()
+	if len(objects) != 1 {
+		t.Fatalf("expected a single object to be emitted, got %d", len(objects))
+	}
+	emitted := roundTrip(t, objects[0])
+
+	expectedData := map[string]string{"username": internalconfig.RedactedValue, "password": internalconfig.RedactedValue}
+	if data := emittedValues(t, emitted.Data); !reflect.DeepEqual(data, expectedData) {
+		t.Errorf("expected the data %v, got %v", expectedData, data)
+	}
+	expectedStringData := map[string]string{"token": internalconfig.RedactedValue}
+	if stringData := emittedValues(t, emitted.StringData); !reflect.DeepEqual(stringData, expectedStringData) {
+		t.Errorf("expected the stringData %v, got %v", expectedStringData, stringData)
+	}
+	if emitted.Type != "kubernetes.io/basic-auth" {
+		t.Errorf("expected the type to be kept, got %q", emitted.Type)
+	}
+	meta := emitted.KubernetesResourceMeta
+	if meta.Name != "credentials" || meta.Namespace != "default" || len(meta.Labels) != 1 || meta.Labels[0].Key != "app" || meta.Labels[0].Value != "web" {
+		t.Errorf("expected the metadata to be kept, got %+v", meta)
+	}
+
+	// the cached object keeps its values
+	if data, _, _ := unstructured.NestedStringMap(secret.Object, "data"); data["password"] != "c2VjcmV0" {
+		t.Errorf("expected the cached secret not to be redacted, got %v", data)
+	}
+}
+
+func TestRedactAppliedSecret(t *testing.T) {
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:   "secrets.v1.",
+		Events: []string{"ADDED", "MODIFIED", "DELETED"},
+	}, internalconfig.GlobalSettings{}, writer, "")
+
+	// as created by kubectl apply
+	secret := newTestObject("v1", "Secret", "default", "credentials")
+	secret.SetAnnotations(map[string]string{
+		lastAppliedAnnotation: `{"apiVersion":"v1","data":{"password":"c2VjcmV0"},"kind":"Secret","metadata":{"name":"credentials","namespace":"default"}}`,
+		"owner":               "team-a",
+	})
+	_ = unstructured.SetNestedStringMap(secret.Object, map[string]string{"password": "c2VjcmV0"}, "data")
+	ri.GetEventHandlers().AddFunc(secret)
+
+	objects := writer.writtenObjects()
+	if len(objects) != 1 {
+		t.Fatalf("expected a single object to be emitted, got %d", len(objects))
+	}
+	emitted := roundTrip(t, objects[0])
+	data, err := json.Marshal(emitted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "c2VjcmV0") {
+		t.Errorf("expected no value of the secret to be emitted, got %s", data)
+	}
+	annotations := emitted.KubernetesResourceMeta.Annotations
+	if len(annotations) != 1 || annotations[0].Key != "owner" || annotations[0].Value != "team-a" {
+		t.Errorf("expected only the last-applied-configuration annotation to be dropped, got %+v", annotations)
+	}
+
+	// the cached object keeps its annotations
+	if _, ok := secret.GetAnnotations()[lastAppliedAnnotation]; !ok {
+		t.Error("expected the cached secret to keep the last-applied-configuration annotation")
+	}
+}
+
+func TestRedactConfigMaps(t *testing.T) {
+	configMap := newTestObject("v1", "ConfigMap", "default", "settings")
+	_ = unstructured.SetNestedStringMap(configMap.Object, map[string]string{"DB_PASSWORD": "secret"}, "data")
+	_ = unstructured.SetNestedStringMap(configMap.Object, map[string]string{"cert": "Y2VydA=="}, "binaryData")
+
+	testCases := []struct {
+		name       string
+		redact     bool
+		data       map[string]string
+		binaryData map[string]string
+	}{
+		{
+			name:       "not redacted by default",
+			data:       map[string]string{"DB_PASSWORD": "secret"},
+			binaryData: map[string]string{"cert": "Y2VydA=="},
+		},
+		{
+			name:       "redacted if enabled",
+			redact:     true,
+			data:       map[string]string{"DB_PASSWORD": internalconfig.RedactedValue},
+			binaryData: map[string]string{"cert": internalconfig.RedactedValue},
+		},
+	}
+	for _, tc := range testCases {
+		writer := &recordingWriter{}
+		ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+			Name:   "configmaps.v1.",
+			Events: []string{"ADDED", "MODIFIED", "DELETED"},
+		}, internalconfig.GlobalSettings{RedactConfigMaps: tc.redact}, writer, "")
+		ri.GetEventHandlers().AddFunc(configMap)
+
+		objects := writer.writtenObjects()
+		if len(objects) != 1 {
+			t.Fatalf("%s: expected a single object to be emitted, got %d", tc.name, len(objects))
+		}
+		emitted := roundTrip(t, objects[0])
+		if data := emittedValues(t, emitted.Data); !reflect.DeepEqual(data, tc.data) {
+			t.Errorf("%s: expected the data %v, got %v", tc.name, tc.data, data)
+		}
+		if binaryData := emittedValues(t, emitted.BinaryData); !reflect.DeepEqual(binaryData, tc.binaryData) {
+			t.Errorf("%s: expected the binaryData %v, got %v", tc.name, tc.binaryData, binaryData)
+		}
+		if emitted.KubernetesResourceMeta.Name != "settings" {
+			t.Errorf("%s: expected the metadata to be kept, got %+v", tc.name, emitted.KubernetesResourceMeta)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package pipeline
+
+import (
+	"sync"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshkit/logger"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/internal/output"
+	"golang.org/x/exp/slices"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// relationshipMatchers report whether the source and the target object, of the same namespace,
+// are related by the relationship type
+var relationshipMatchers = map[string]func(source, target *unstructured.Unstructured) bool{
+	internalconfig.RelationshipServicePod:     serviceSelectsPod,
+	internalconfig.RelationshipIngressService: ingressRoutesToService,
+}
+
+// relationshipEdge is a relationship of the given type between two objects
+type relationshipEdge struct {
+	relationship string
+	source       output.ObjectRef
+	target       output.ObjectRef
+}
+
+type edgeSet map[relationshipEdge]struct{}
+
+// relationshipTracker maintains the relationships between the objects of the watched resources
+// and emits an event whenever one is found or no longer holds.
+// The relationships of an object are recomputed from the caches of the pipelines at the other end
+// whenever the object changes, whichever of two related objects is cached last finds the other.
+type relationshipTracker struct {
+	log           logger.Handler
+	writer        output.Writer
+	informers     *informerSet
+	relationships []string
+	// the pipelines by name, for their namespace scope
+	configs map[string]internalconfig.PipelineConfig
+
+	// the events of an edge are emitted with the lock held, so they keep their order
+	mu   sync.Mutex
+	from map[output.ObjectRef]edgeSet
+	to   map[output.ObjectRef]edgeSet
+}
+
+// relationshipTrackerFor returns nil unless relationships are configured
+func relationshipTrackerFor(
+	log logger.Handler,
+	writer output.Writer,
+	informers *informerSet,
+	plConfigs map[string]internalconfig.PipelineConfigs,
+	settings internalconfig.GlobalSettings,
+) *relationshipTracker {
+	if len(settings.Relationships) == 0 {
+		return nil
+	}
+	configs := make(map[string]internalconfig.PipelineConfig)
+	for _, pipelines := range plConfigs {
+		for _, config := range pipelines {
+			configs[config.Name] = config
+		}
+	}
+	return &relationshipTracker{
+		log:           log,
+		writer:        writer,
+		informers:     informers,
+		relationships: settings.Relationships,
+		configs:       configs,
+		from:          make(map[output.ObjectRef]edgeSet),
+		to:            make(map[output.ObjectRef]edgeSet),
+	}
+}
+
+// observe recomputes the relationships of the added or updated object of the resource
+func (r *relationshipTracker) observe(resource string, obj *unstructured.Unstructured) {
+	if r == nil {
+		return
+	}
+	if !r.inScope(resource, obj) {
+		r.remove(resource, obj)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ref := objectRefOf(obj)
+	for _, relationship := range r.relationships {
+		ends := internalconfig.RelationshipResources[relationship]
+		relates := relationshipMatchers[relationship]
+		if ends.Source == resource {
+			desired := make(edgeSet)
+			for _, target := range r.candidates(ends.Target, obj.GetNamespace()) {
+				if relates(obj, target) {
+					desired[relationshipEdge{relationship: relationship, source: ref, target: objectRefOf(target)}] = struct{}{}
+				}
+			}
+			r.reconcile(relationship, r.from[ref], desired)
+		}
+		if ends.Target == resource {
+			desired := make(edgeSet)
+			for _, source := range r.candidates(ends.Source, obj.GetNamespace()) {
+				if relates(source, obj) {
+					desired[relationshipEdge{relationship: relationship, source: objectRefOf(source), target: ref}] = struct{}{}
+				}
+			}
+			r.reconcile(relationship, r.to[ref], desired)
+		}
+	}
+}
+
+// remove drops the relationships of the deleted object of the resource
+func (r *relationshipTracker) remove(resource string, obj *unstructured.Unstructured) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ref := objectRefOf(obj)
+	for _, relationship := range r.relationships {
+		ends := internalconfig.RelationshipResources[relationship]
+		if ends.Source == resource {
+			r.reconcile(relationship, r.from[ref], edgeSet{})
+		}
+		if ends.Target == resource {
+			r.reconcile(relationship, r.to[ref], edgeSet{})
+		}
+	}
+}
+
+// reconcile turns the current edges of the relationship type into the desired ones,
+// emitting the edges found and gone, must be called with the lock held
+func (r *relationshipTracker) reconcile(relationship string, current edgeSet, desired edgeSet) {
+	gone := make([]relationshipEdge, 0)
+	for edge := range current {
+		if _, ok := desired[edge]; !ok && edge.relationship == relationship {
+			gone = append(gone, edge)
+		}
+	}
+	for _, edge := range gone {
+		r.unlink(edge)
+		r.emit(output.RelationshipRemovedEvent, edge)
+	}
+	for edge := range desired {
+		if _, ok := current[edge]; ok {
+			continue
+		}
+		r.link(edge)
+		r.emit(output.RelationshipAddedEvent, edge)
+	}
+}
+
+// link adds the edge to the indexes of both of its ends, must be called with the lock held
+func (r *relationshipTracker) link(edge relationshipEdge) {
+	if r.from[edge.source] == nil {
+		r.from[edge.source] = make(edgeSet)
+	}
+	r.from[edge.source][edge] = struct{}{}
+	if r.to[edge.target] == nil {
+		r.to[edge.target] = make(edgeSet)
+	}
+	r.to[edge.target][edge] = struct{}{}
+}
+
+// unlink removes the edge from the indexes of both of its ends, must be called with the lock held
+func (r *relationshipTracker) unlink(edge relationshipEdge) {
+	delete(r.from[edge.source], edge)
+	if len(r.from[edge.source]) == 0 {
+		delete(r.from, edge.source)
+	}
+	delete(r.to[edge.target], edge)
+	if len(r.to[edge.target]) == 0 {
+		delete(r.to, edge.target)
+	}
+}
+
+func (r *relationshipTracker) emit(evtype broker.EventType, edge relationshipEdge) {
+	resource := internalconfig.RelationshipResources[edge.relationship].Source
+	event := output.NewRelationshipEvent(evtype, resource, output.Relationship{
+		Type:   edge.relationship,
+		Source: edge.source,
+		Target: edge.target,
+	})
+	if err := output.WriteControl(r.writer, event); err != nil {
+		r.log.Error(ErrWriteOutput(resource, err))
+	}
+}
+
+// candidates returns the cached objects of the resource in the namespace which are in the scope of its pipeline
+func (r *relationshipTracker) candidates(resource, namespace string) []*unstructured.Unstructured {
+	informer, ok := r.informers.get(resource)
+	if !ok {
+		return nil
+	}
+	var items []interface{}
+	if indexer, ok := informer.GetStore().(cache.Indexer); ok {
+		items, _ = indexer.ByIndex(cache.NamespaceIndex, namespace)
+	} else {
+		items = informer.GetStore().List()
+	}
+	objects := make([]*unstructured.Unstructured, 0, len(items))
+	for _, item := range items {
+		obj, ok := item.(*unstructured.Unstructured)
+		if ok && obj.GetNamespace() == namespace && r.inScope(resource, obj) {
+			objects = append(objects, obj)
+		}
+	}
+	return objects
+}
+
+// inScope reports whether the object is in the namespace scope of the pipeline of its resource
+func (r *relationshipTracker) inScope(resource string, obj *unstructured.Unstructured) bool {
+	return inNamespaceScope(r.configs[resource], obj.GetNamespace())
+}
+
+func objectRefOf(obj *unstructured.Unstructured) output.ObjectRef {
+	return output.ObjectRef{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+}
+
+// serviceSelectsPod reports whether the selector of the Service matches the labels of the Pod,
+// Services without selector select no Pods
+func serviceSelectsPod(service, pod *unstructured.Unstructured) bool {
+	selector, _, _ := unstructured.NestedStringMap(service.Object, "spec", "selector")
+	if len(selector) == 0 {
+		return false
+	}
+	return labels.SelectorFromSet(selector).Matches(labels.Set(pod.GetLabels()))
+}
+
+// ingressRoutesToService reports whether the default backend or a path of the Ingress routes to the Service
+func ingressRoutesToService(ingress, service *unstructured.Unstructured) bool {
+	return slices.Contains(ingressBackendServices(ingress), service.GetName())
+}
+
+// ingressBackendServices returns the names of the Services the backends of the Ingress route to
+func ingressBackendServices(ingress *unstructured.Unstructured) []string {
+	names := make([]string, 0)
+	if name, ok, _ := unstructured.NestedString(ingress.Object, "spec", "defaultBackend", "service", "name"); ok {
+		names = append(names, name)
+	}
+	rules, _, _ := unstructured.NestedSlice(ingress.Object, "spec", "rules")
+	for _, rule := range rules {
+		rule, ok := rule.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		paths, _, _ := unstructured.NestedSlice(rule, "http", "paths")
+		for _, path := range paths {
+			path, ok := path.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if name, ok, _ := unstructured.NestedString(path, "backend", "service", "name"); ok {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,144 @@
+package pipeline
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/meshery/meshkit/broker"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/internal/output"
+	"github.com/myntra/pipeline"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+// relationshipEvents returns the relationship events emitted in order as "<event> <source> -> <target>"
+func relationshipEvents(writer *recordingWriter) []string {
+	events := make([]string, 0)
+	for _, event := range writer.controlEvents() {
+		if event.Relationship != nil {
+			events = append(events, string(event.Type)+" "+event.Relationship.Source.Name+" -> "+event.Relationship.Target.Name)
+		}
+	}
+	return events
+}
+
+func newLabeledPod(name string, labels map[string]string) *unstructured.Unstructured {
+	pod := newTestObject("v1", "Pod", "default", name)
+	pod.SetLabels(labels)
+	return pod
+}
+
+func TestServiceSelectsPods(t *testing.T) {
+	service := newTestObject("v1", "Service", "default", "web")
+	_ = unstructured.SetNestedStringMap(service.Object, map[string]string{"app": "web"}, "spec", "selector")
+	informers := newTestInformers(
+		service,
+		newLabeledPod("web-a", map[string]string{"app": "web"}),
+		newLabeledPod("db-a", map[string]string{"app": "db"}),
+	)
+	plConfigs := map[string]internalconfig.PipelineConfigs{
+		internalconfig.LocalResourceKey: {
+			{Name: "services.v1.", Events: []string{"ADDED"}},
+			{Name: "pods.v1.", Events: []string{"ADDED"}},
+		},
+	}
+	settings := internalconfig.GlobalSettings{Relationships: []string{internalconfig.RelationshipServicePod}}
+	writer := &recordingWriter{}
+	log := newTestLogger(t)
+	relationships := relationshipTrackerFor(log, writer, informers, plConfigs, settings)
+	for _, config := range plConfigs[internalconfig.LocalResourceKey] {
+		step := newRegisterInformerStep(log, informers, nil, nil, config, settings, writer, "")
+		step.relationships = relationships
+		if result := step.Exec(&pipeline.Request{Data: map[string]cache.Store{}}); result.Error != nil {
+			t.Fatal(result.Error)
+		}
+	}
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	informers.factory.Start(stopChan)
+	waitFor(t, func() bool { return len(relationshipEvents(writer)) == 1 })
+
+	event := writer.controlEvents()[0]
+	expected := &output.Relationship{
+		Type:   internalconfig.RelationshipServicePod,
+		Source: output.ObjectRef{APIVersion: "v1", Kind: "Service", Namespace: "default", Name: "web"},
+		Target: output.ObjectRef{APIVersion: "v1", Kind: "Pod", Namespace: "default", Name: "web-a"},
+	}
+	if event.Type != output.RelationshipAddedEvent || !reflect.DeepEqual(event.Relationship, expected) {
+		t.Errorf("expected the relationship %+v to be added, got %s %+v", expected, event.Type, event.Relationship)
+	}
+
+	pods := informers.client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "pods"}).Namespace("default")
+	// the Pod no longer matches the selector
+	relabeled := newLabeledPod("web-a", map[string]string{"app": "web-canary"})
+	relabeled.SetResourceVersion("2")
+	if _, err := pods.Update(context.Background(), relabeled, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return len(relationshipEvents(writer)) == 2 })
+
+	// the other Pod starts to match the selector
+	relabeled = newLabeledPod("db-a", map[string]string{"app": "web"})
+	relabeled.SetResourceVersion("2")
+	if _, err := pods.Update(context.Background(), relabeled, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return len(relationshipEvents(writer)) == 3 })
+
+	if err := pods.Delete(context.Background(), "db-a", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return len(relationshipEvents(writer)) == 4 })
+
+	expectedEvents := []string{
+		string(output.RelationshipAddedEvent) + " web -> web-a",
+		string(output.RelationshipRemovedEvent) + " web -> web-a",
+		string(output.RelationshipAddedEvent) + " web -> db-a",
+		string(output.RelationshipRemovedEvent) + " web -> db-a",
+	}
+	if events := relationshipEvents(writer); !reflect.DeepEqual(events, expectedEvents) {
+		t.Errorf("expected the relationship events %v, got %v", expectedEvents, events)
+	}
+}
+
+func TestRelationshipMatchers(t *testing.T) {
+	service := newTestObject("v1", "Service", "default", "web")
+	ingress := newTestObject("networking.k8s.io/v1", "Ingress", "default", "web")
+	_ = unstructured.SetNestedSlice(ingress.Object, []interface{}{
+		map[string]interface{}{"http": map[string]interface{}{"paths": []interface{}{
+			map[string]interface{}{"path": "/", "backend": map[string]interface{}{"service": map[string]interface{}{"name": "web"}}},
+		}}},
+	}, "spec", "rules")
+	defaultBackend := newTestObject("networking.k8s.io/v1", "Ingress", "default", "fallback")
+	_ = unstructured.SetNestedField(defaultBackend.Object, "web", "spec", "defaultBackend", "service", "name")
+
+	testCases := []struct {
+		name     string
+		matches  bool
+		expected bool
+	}{
+		{name: "Service without selector", matches: serviceSelectsPod(service, newLabeledPod("web-a", map[string]string{"app": "web"})), expected: false},
+		{name: "Ingress path", matches: ingressRoutesToService(ingress, service), expected: true},
+		{name: "Ingress default backend", matches: ingressRoutesToService(defaultBackend, service), expected: true},
+		{name: "Ingress routing elsewhere", matches: ingressRoutesToService(ingress, newTestObject("v1", "Service", "default", "api")), expected: false},
+	}
+	for _, tc := range testCases {
+		if tc.matches != tc.expected {
+			t.Errorf("%s: expected %t, got %t", tc.name, tc.expected, tc.matches)
+		}
+	}
+}
+
+func TestRelationshipsDisabled(t *testing.T) {
+	plConfigs := map[string]internalconfig.PipelineConfigs{
+		internalconfig.LocalResourceKey: {{Name: "pods.v1.", Events: []string{string(broker.Add)}}},
+	}
+	if tracker := relationshipTrackerFor(newTestLogger(t), &recordingWriter{}, newTestInformers(), plConfigs, internalconfig.GlobalSettings{}); tracker != nil {
+		t.Error("expected no relationship tracker without relationships")
+	}
+}
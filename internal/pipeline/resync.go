@@ -0,0 +1,269 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshsync/pkg/model"
+	"golang.org/x/exp/slices"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ObjectResyncer re-emits single objects on demand through the pipeline of their resource,
+// f.e. when a consumer reports an object out of sync, without resyncing the whole pipeline.
+// A nil resyncer knows no pipeline.
+type ObjectResyncer struct {
+	mu        sync.RWMutex
+	pipelines map[string]*RegisterInformer
+	// held while a full sync runs, so full syncs do not overlap
+	fullSync sync.Mutex
+}
+
+func NewObjectResyncer() *ObjectResyncer {
+	return &ObjectResyncer{
+		pipelines: make(map[string]*RegisterInformer),
+	}
+}
+
+// reset forgets the pipelines, they are registered again when the pipelines restart
+func (r *ObjectResyncer) reset() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pipelines = make(map[string]*RegisterInformer)
+}
+
+func (r *ObjectResyncer) register(ri *RegisterInformer) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pipelines[ri.config.Name] = ri
+}
+
+// ResyncObject re-emits the object of the resource, f.e. pods.v1., as MODIFIED event,
+// or as ADDED event if the pipeline does not emit MODIFIED events.
+// The object is read from the cache of the pipeline, or from the API server if it is not cached.
+func (r *ObjectResyncer) ResyncObject(resource, namespace, name string) error {
+	var ri *RegisterInformer
+	if r != nil {
+		r.mu.RLock()
+		ri = r.pipelines[resource]
+		r.mu.RUnlock()
+	}
+	if ri == nil {
+		return ErrResyncObject(resource, fmt.Errorf("no pipeline watches %s", resource))
+	}
+	return ri.resyncObject(namespace, name)
+}
+
+// TriggerFullSync re-emits every cached object of every pipeline as ADDED event, f.e. after an outage
+// of the broker let the consumers drift, and returns the number of objects re-emitted by resource.
+// The objects go through the filters of their pipeline like the watched ones, the pipelines which do not
+// emit ADDED events re-emit nothing. It fails if another full sync is running, and stops when ctx is done.
+func (r *ObjectResyncer) TriggerFullSync(ctx context.Context) (map[string]int, error) {
+	counts := make(map[string]int)
+	if r == nil {
+		return counts, nil
+	}
+	if !r.fullSync.TryLock() {
+		return nil, ErrFullSync(fmt.Errorf("another full sync is running"))
+	}
+	defer r.fullSync.Unlock()
+
+	r.mu.RLock()
+	pipelines := make([]*RegisterInformer, 0, len(r.pipelines))
+	for _, ri := range r.pipelines {
+		pipelines = append(pipelines, ri)
+	}
+	r.mu.RUnlock()
+	slices.SortFunc(pipelines, func(a, b *RegisterInformer) int {
+		return strings.Compare(a.config.Name, b.config.Name)
+	})
+
+	for _, ri := range pipelines {
+		count, err := ri.fullSync(ctx)
+		counts[ri.config.Name] = count
+		if err != nil {
+			return counts, ErrFullSync(err)
+		}
+	}
+	return counts, nil
+}
+
+// CachedObjects returns the cached objects of every pipeline as their ADDED events carry them,
+// f.e. to answer a request for the contents of the informer stores.
+// They are transformed like the emitted ones, the objects failing their transformers are left out.
+func (r *ObjectResyncer) CachedObjects() []model.KubernetesResource {
+	objects := make([]model.KubernetesResource, 0)
+	if r == nil {
+		return objects
+	}
+	r.mu.RLock()
+	pipelines := make([]*RegisterInformer, 0, len(r.pipelines))
+	for _, ri := range r.pipelines {
+		pipelines = append(pipelines, ri)
+	}
+	r.mu.RUnlock()
+
+	for _, ri := range pipelines {
+		objects = append(objects, ri.cachedObjects()...)
+	}
+	return objects
+}
+
+// cachedObjects returns the cached objects the pipeline reports, transformed by its transformers
+func (ri *RegisterInformer) cachedObjects() []model.KubernetesResource {
+	informer, ok := ri.informers.get(ri.config.Name)
+	if !ok {
+		return nil
+	}
+	objects := make([]model.KubernetesResource, 0)
+	for _, item := range informer.GetStore().List() {
+		obj, ok := item.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		if _, ok := ri.admits(obj, ri.config); !ok {
+			continue
+		}
+		transformed, err := transform(obj, ri.transformers)
+		if err != nil {
+			ri.log.Error(ErrTransform(ri.config.Name, err))
+			continue
+		}
+		objects = append(objects, ri.resourceFor(obj, transformed, broker.Add))
+	}
+	return objects
+}
+
+// fullSync re-emits the cached objects of the pipeline, a failed write is retried like the one of a watched object
+func (ri *RegisterInformer) fullSync(ctx context.Context) (int, error) {
+	if !slices.Contains(ri.config.Events, string(broker.Add)) {
+		return 0, nil
+	}
+	informer, ok := ri.informers.get(ri.config.Name)
+	if !ok {
+		return 0, nil
+	}
+
+	count := 0
+	for _, item := range informer.GetStore().List() {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+		obj, ok := item.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		ri.retries.supersede(obj)
+		published, err := ri.publish(obj, broker.Add, ri.config)
+		if err != nil {
+			ri.publishFailed(obj, broker.Add, err)
+			continue
+		}
+		if published {
+			count++
+		}
+	}
+	ri.log.Infof("Re-emitted %d objects of %s in a full sync", count, ri.config.Name)
+	return count, nil
+}
+
+func (ri *RegisterInformer) resyncObject(namespace, name string) error {
+	evtype := broker.Update
+	if !slices.Contains(ri.config.Events, string(evtype)) {
+		evtype = broker.Add
+	}
+	if !slices.Contains(ri.config.Events, string(evtype)) {
+		return ErrResyncObject(ri.config.Name, fmt.Errorf("the pipeline emits neither %s nor %s events", broker.Update, broker.Add))
+	}
+
+	obj, err := ri.lookupObject(namespace, name)
+	if err != nil {
+		return ErrResyncObject(ri.config.Name, err)
+	}
+	ri.objectLog.Info("Re-emitting ", evtype, " event for: ", name, "/", namespace, " on demand")
+	if err := ri.publishItem(obj, evtype, ri.config); err != nil {
+		return ErrResyncObject(ri.config.Name, err)
+	}
+	return nil
+}
+
+// lookupObject returns the object from the cache of the pipeline, falling back to the API server.
+// The object fetched from the API server is listed with the selectors of the pipeline's informer,
+// and either object must pass the filters of the pipeline, so nothing it does not watch is re-emitted.
+func (ri *RegisterInformer) lookupObject(namespace, name string) (*unstructured.Unstructured, error) {
+	key := name
+	if namespace != "" {
+		key = namespace + "/" + name
+	}
+	obj, err := ri.cachedObject(key)
+	if err != nil {
+		return nil, err
+	}
+	if obj == nil {
+		obj, err = ri.liveObject(namespace, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if obj == nil {
+		return nil, fmt.Errorf("object %s not found", key)
+	}
+	if reason, ok := ri.admits(obj, ri.config); !ok {
+		return nil, fmt.Errorf("object %s is filtered out by the pipeline [%s]", key, reason)
+	}
+	return obj, nil
+}
+
+// cachedObject returns the object from the cache of the pipeline, nil if it is not cached
+func (ri *RegisterInformer) cachedObject(key string) (*unstructured.Unstructured, error) {
+	informer, ok := ri.informers.get(ri.config.Name)
+	if !ok {
+		return nil, nil
+	}
+	item, exists, err := informer.GetStore().GetByKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if obj, ok := item.(*unstructured.Unstructured); exists && ok {
+		return obj, nil
+	}
+	return nil, nil
+}
+
+// liveObject lists the object from the API server with the selectors of the pipeline, nil if none matches
+func (ri *RegisterInformer) liveObject(namespace, name string) (*unstructured.Unstructured, error) {
+	gvr, _ := schema.ParseResourceArg(ri.config.Name)
+	if gvr == nil {
+		return nil, fmt.Errorf("error parsing resource arg, gvr not found")
+	}
+	options := metav1.ListOptions{}
+	tweakListOptionsFor(ri.config)(&options)
+	nameSelector := fields.OneTermEqualSelector("metadata.name", name).String()
+	if options.FieldSelector != "" {
+		options.FieldSelector += "," + nameSelector
+	} else {
+		options.FieldSelector = nameSelector
+	}
+	list, err := ri.informers.client.Resource(*gvr).Namespace(namespace).List(ri.informers.ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list.Items {
+		if list.Items[i].GetName() == name {
+			return &list.Items[i], nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,268 @@
+package pipeline
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshkit/errors"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/myntra/pipeline"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// registerResyncedPipeline registers the pipeline of pods with the resyncer
+func registerResyncedPipeline(t *testing.T, informers *informerSet, writer *recordingWriter, events []string) *ObjectResyncer {
+	t.Helper()
+	resyncer := NewObjectResyncer()
+	step := newRegisterInformerStep(newTestLogger(t), informers, nil, nil, internalconfig.PipelineConfig{Name: "pods.v1.", Events: events}, internalconfig.GlobalSettings{}, writer, "")
+	resyncer.register(step)
+	if result := step.Exec(&pipeline.Request{}); result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	return resyncer
+}
+
+func TestResyncObject(t *testing.T) {
+	informers := newTestInformers(
+		newTestObject("v1", "Pod", "default", "pod-a"),
+		newTestObject("v1", "Pod", "default", "pod-b"),
+	)
+	writer := &recordingWriter{}
+	resyncer := registerResyncedPipeline(t, informers, writer, []string{"MODIFIED"})
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	informers.factory.Start(stopChan)
+	informers.factory.WaitForCacheSync(stopChan)
+
+	if err := resyncer.ResyncObject("pods.v1.", "default", "pod-a"); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	objects := writer.writtenObjects()
+	if len(objects) != 1 || objects[0].KubernetesResourceMeta.Name != "pod-a" {
+		t.Fatalf("expected a single re-emit of pod-a, got %v", objects)
+	}
+	if writer.events[0] != broker.Update {
+		t.Errorf("expected a %s event, got %s", broker.Update, writer.events[0])
+	}
+
+	testCases := []struct {
+		name      string
+		resource  string
+		namespace string
+		object    string
+	}{
+		{name: "object not found", resource: "pods.v1.", namespace: "default", object: "pod-c"},
+		{name: "object in another namespace", resource: "pods.v1.", namespace: "prod", object: "pod-a"},
+		{name: "resource not watched", resource: "services.v1.", namespace: "default", object: "pod-a"},
+	}
+	for _, tc := range testCases {
+		err := resyncer.ResyncObject(tc.resource, tc.namespace, tc.object)
+		if err == nil {
+			t.Errorf("%s: expected error", tc.name)
+			continue
+		}
+		if errors.GetCode(err) != ErrResyncObjectCode {
+			t.Errorf("%s: expected error code %s, got %s", tc.name, ErrResyncObjectCode, errors.GetCode(err))
+		}
+	}
+	if objects := writer.writtenObjects(); len(objects) != 1 {
+		t.Errorf("expected nothing more to be emitted, got %d objects", len(objects))
+	}
+}
+
+func TestResyncObjectNotCached(t *testing.T) {
+	informers := newTestInformers(newTestObject("v1", "Pod", "default", "pod-a"))
+	writer := &recordingWriter{}
+	// the informers are not started, the object is fetched from the API server
+	resyncer := registerResyncedPipeline(t, informers, writer, []string{"ADDED", "DELETED"})
+
+	if err := resyncer.ResyncObject("pods.v1.", "default", "pod-a"); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	objects := writer.writtenObjects()
+	if len(objects) != 1 || objects[0].KubernetesResourceMeta.Name != "pod-a" {
+		t.Fatalf("expected a single re-emit of pod-a, got %v", objects)
+	}
+	if writer.events[0] != broker.Add {
+		t.Errorf("expected an %s event for a pipeline not emitting %s events, got %s", broker.Add, broker.Update, writer.events[0])
+	}
+}
+
+func TestResyncObjectNotCachedIsFiltered(t *testing.T) {
+	labelled := func(namespace, name string) *unstructured.Unstructured {
+		obj := newTestObject("v1", "Pod", namespace, name)
+		obj.SetLabels(map[string]string{"app": "web"})
+		return obj
+	}
+	informers := newTestInformers(
+		newTestObject("v1", "Pod", "default", "unlabelled"),
+		labelled("kube-system", "excluded"),
+		labelled("default", "web"),
+	)
+	writer := &recordingWriter{}
+	resyncer := NewObjectResyncer()
+	step := newRegisterInformerStep(newTestLogger(t), informers, nil, nil, internalconfig.PipelineConfig{
+		Name:              "pods.v1.",
+		Events:            []string{"ADDED"},
+		LabelSelector:     "app=web",
+		ExcludeNamespaces: []string{"kube-system"},
+	}, internalconfig.GlobalSettings{}, writer, "")
+	resyncer.register(step)
+	if result := step.Exec(&pipeline.Request{}); result.Error != nil {
+		t.Fatal(result.Error)
+	}
+
+	// the informers are not started, the objects are fetched from the API server
+	for _, name := range []string{"unlabelled", "excluded"} {
+		namespace := "default"
+		if name == "excluded" {
+			namespace = "kube-system"
+		}
+		if err := resyncer.ResyncObject("pods.v1.", namespace, name); err == nil {
+			t.Errorf("expected the object %s the pipeline does not watch not to be re-emitted", name)
+		}
+	}
+	if err := resyncer.ResyncObject("pods.v1.", "default", "web"); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	objects := writer.writtenObjects()
+	if len(objects) != 1 || objects[0].KubernetesResourceMeta.Name != "web" {
+		t.Errorf("expected a single re-emit of web, got %v", objects)
+	}
+}
+
+func TestTriggerFullSync(t *testing.T) {
+	informers := newTestInformers(
+		newTestObject("v1", "Pod", "default", "pod-a"),
+		newTestObject("v1", "Pod", "default", "pod-b"),
+		newTestObject("v1", "Pod", "kube-system", "pod-c"),
+		newTestObject("v1", "Service", "default", "svc-a"),
+	)
+	writer := &recordingWriter{}
+	resyncer := NewObjectResyncer()
+	for _, config := range []internalconfig.PipelineConfig{
+		{Name: "pods.v1.", Events: []string{"ADDED", "MODIFIED", "DELETED"}, ExcludeNamespaces: []string{"kube-system"}},
+		// re-emits nothing, the pipeline does not emit ADDED events
+		{Name: "services.v1.", Events: []string{"MODIFIED"}},
+	} {
+		step := newRegisterInformerStep(newTestLogger(t), informers, nil, nil, config, internalconfig.GlobalSettings{}, writer, "")
+		resyncer.register(step)
+		if result := step.Exec(&pipeline.Request{}); result.Error != nil {
+			t.Fatal(result.Error)
+		}
+	}
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	informers.factory.Start(stopChan)
+	informers.factory.WaitForCacheSync(stopChan)
+	// the initial list
+	waitFor(t, func() bool { return len(writer.writtenObjects()) == 2 })
+
+	// safe to repeat, every full sync re-emits the current objects
+	for i := 1; i <= 2; i++ {
+		counts, err := resyncer.TriggerFullSync(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+		if expected := map[string]int{"pods.v1.": 2, "services.v1.": 0}; !reflect.DeepEqual(counts, expected) {
+			t.Errorf("expected the re-emitted objects %v, got %v", expected, counts)
+		}
+		objects := writer.writtenObjects()
+		if len(objects) != 2+2*i {
+			t.Fatalf("expected %d objects to be written, got %d", 2+2*i, len(objects))
+		}
+		names := []string{objects[len(objects)-2].KubernetesResourceMeta.Name, objects[len(objects)-1].KubernetesResourceMeta.Name}
+		sort.Strings(names)
+		if !reflect.DeepEqual(names, []string{"pod-a", "pod-b"}) {
+			t.Errorf("expected pod-a and pod-b to be re-emitted, got %v", names)
+		}
+		for _, evtype := range writer.events[len(writer.events)-2:] {
+			if evtype != broker.Add {
+				t.Errorf("expected %s events, got %s", broker.Add, evtype)
+			}
+		}
+	}
+
+	// full syncs do not overlap
+	resyncer.fullSync.Lock()
+	_, err := resyncer.TriggerFullSync(context.Background())
+	resyncer.fullSync.Unlock()
+	if errors.GetCode(err) != ErrFullSyncCode {
+		t.Errorf("expected error code %s while another full sync runs, got %v", ErrFullSyncCode, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := resyncer.TriggerFullSync(ctx); errors.GetCode(err) != ErrFullSyncCode {
+		t.Errorf("expected error code %s for a cancelled full sync, got %v", ErrFullSyncCode, err)
+	}
+}
+
+func TestCachedObjectsAreTransformed(t *testing.T) {
+	pod := newTestPodWithEnv(envVar("API_TOKEN", "secret"))
+	_ = unstructured.SetNestedField(pod.Object, "Running", "status", "phase")
+	informers := newTestInformers(pod)
+	writer := &recordingWriter{}
+	resyncer := NewObjectResyncer()
+	step := newRegisterInformerStep(newTestLogger(t), informers, nil, nil, internalconfig.PipelineConfig{
+		Name:        "pods.v1.",
+		Events:      []string{"ADDED"},
+		Mask:        []string{"$.spec.containers[*].env[*].value"},
+		StripStatus: true,
+	}, internalconfig.GlobalSettings{}, writer, "")
+	resyncer.register(step)
+	if result := step.Exec(&pipeline.Request{}); result.Error != nil {
+		t.Fatal(result.Error)
+	}
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	informers.factory.Start(stopChan)
+	informers.factory.WaitForCacheSync(stopChan)
+
+	objects := resyncer.CachedObjects()
+	if len(objects) != 1 {
+		t.Fatalf("expected a single cached object, got %d", len(objects))
+	}
+	if objects[0].Spec == nil || strings.Contains(objects[0].Spec.Attribute, "secret") || !strings.Contains(objects[0].Spec.Attribute, internalconfig.MaskedValue) {
+		t.Errorf("expected the value to be masked, got %+v", objects[0].Spec)
+	}
+	if objects[0].Status != nil && objects[0].Status.Attribute != "" {
+		t.Errorf("expected the status to be stripped, got %s", objects[0].Status.Attribute)
+	}
+}
+
+func TestCachedObjectsAreFiltered(t *testing.T) {
+	annotated := newTestObject("v1", "Pod", "default", "pod-a")
+	annotated.SetAnnotations(map[string]string{"meshery.io/managed": "true"})
+	excluded := newTestObject("v1", "Pod", "kube-system", "pod-b")
+	excluded.SetAnnotations(map[string]string{"meshery.io/managed": "true"})
+	informers := newTestInformers(annotated, excluded, newTestObject("v1", "Pod", "default", "pod-c"))
+	resyncer := NewObjectResyncer()
+	step := newRegisterInformerStep(newTestLogger(t), informers, nil, nil, internalconfig.PipelineConfig{
+		Name:              "pods.v1.",
+		Events:            []string{"ADDED"},
+		ExcludeNamespaces: []string{"kube-system"},
+		AnnotationFilter:  map[string]string{"meshery.io/managed": "true"},
+	}, internalconfig.GlobalSettings{}, &recordingWriter{}, "")
+	resyncer.register(step)
+	if result := step.Exec(&pipeline.Request{}); result.Error != nil {
+		t.Fatal(result.Error)
+	}
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	informers.factory.Start(stopChan)
+	informers.factory.WaitForCacheSync(stopChan)
+
+	objects := resyncer.CachedObjects()
+	if len(objects) != 1 || objects[0].KubernetesResourceMeta.Name != "pod-a" {
+		t.Errorf("expected only pod-a to be listed, got %v", objects)
+	}
+}
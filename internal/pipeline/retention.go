@@ -0,0 +1,81 @@
+package pipeline
+
+import (
+	"sync"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/lru"
+)
+
+// retainedObjects keeps the last known state of the most recently seen objects of a pipeline by UID,
+// so the DELETE event of an object the informer lost, f.e. a tombstone without object, still carries it
+type retainedObjects struct {
+	// guards uids, which the eviction of objects updates
+	mu      sync.Mutex
+	objects *lru.Cache
+	// the UIDs of the retained objects by their namespace/name key, tombstones carry the key only
+	uids map[string]types.UID
+}
+
+// retainedObjectsFor returns nil unless the pipeline retains objects
+func retainedObjectsFor(config internalconfig.PipelineConfig) *retainedObjects {
+	if config.RetainedObjects <= 0 {
+		return nil
+	}
+	r := &retainedObjects{uids: make(map[string]types.UID)}
+	// evictions happen within the calls holding the lock
+	r.objects = lru.NewWithEvictionFunc(config.RetainedObjects, func(uid lru.Key, value interface{}) {
+		key := retentionKey(value.(*unstructured.Unstructured))
+		if r.uids[key] == uid {
+			delete(r.uids, key)
+		}
+	})
+	return r
+}
+
+// observe retains the state of the added or updated object
+func (r *retainedObjects) observe(obj *unstructured.Unstructured) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.objects.Add(obj.GetUID(), obj)
+	r.uids[retentionKey(obj)] = obj.GetUID()
+}
+
+// forget drops the deleted object
+func (r *retainedObjects) forget(obj *unstructured.Unstructured) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.objects.Remove(obj.GetUID())
+}
+
+// lookup returns the last known state of the object of the namespace/name key
+func (r *retainedObjects) lookup(key string) (*unstructured.Unstructured, bool) {
+	if r == nil {
+		return nil, false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	uid, ok := r.uids[key]
+	if !ok {
+		return nil, false
+	}
+	obj, ok := r.objects.Get(uid)
+	if !ok {
+		return nil, false
+	}
+	return obj.(*unstructured.Unstructured), true
+}
+
+func retentionKey(obj *unstructured.Unstructured) string {
+	key, _ := cache.MetaNamespaceKeyFunc(obj)
+	return key
+}
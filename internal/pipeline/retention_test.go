@@ -0,0 +1,60 @@
+package pipeline
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/meshery/meshkit/broker"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestDeleteEnrichedFromRetainedObjects(t *testing.T) {
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:            "pods.v1.",
+		Events:          []string{"ADDED", "MODIFIED", "DELETED"},
+		RetainedObjects: 2,
+	}, internalconfig.GlobalSettings{}, writer, "")
+	handlers := ri.GetEventHandlers()
+
+	handlers.AddFunc(newTestObject("v1", "Pod", "default", "web-a"))
+	webB := newTestObject("v1", "Pod", "default", "web-b")
+	handlers.AddFunc(webB)
+	updated := webB.DeepCopy()
+	updated.SetResourceVersion("2")
+	updated.SetLabels(map[string]string{"app": "web"})
+	handlers.UpdateFunc(webB, updated)
+	// evicts web-a, the least recently seen object
+	handlers.AddFunc(newTestObject("v1", "Pod", "default", "web-c"))
+
+	// tombstones without object, f.e. the informer missed the deletion while its watch was down
+	for _, name := range []string{"web-b", "web-a", "web-b"} {
+		handlers.DeleteFunc(cache.DeletedFinalStateUnknown{Key: "default/" + name})
+	}
+
+	expected := []broker.EventType{broker.Add, broker.Add, broker.Update, broker.Add, broker.Delete}
+	if !reflect.DeepEqual(writer.events, expected) {
+		t.Fatalf("expected events %v, got %v", expected, writer.events)
+	}
+	deleted := writer.objects[len(writer.objects)-1].KubernetesResourceMeta
+	if deleted.Name != "web-b" || deleted.ResourceVersion != "2" {
+		t.Errorf("expected the DELETE event to carry the last known state of web-b, got %s at %s", deleted.Name, deleted.ResourceVersion)
+	}
+}
+
+func TestRetainedObjectsDisabled(t *testing.T) {
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:   "pods.v1.",
+		Events: []string{"ADDED", "DELETED"},
+	}, internalconfig.GlobalSettings{}, writer, "")
+	handlers := ri.GetEventHandlers()
+
+	handlers.AddFunc(newTestObject("v1", "Pod", "default", "web-a"))
+	handlers.DeleteFunc(cache.DeletedFinalStateUnknown{Key: "default/web-a"})
+
+	if expected := []broker.EventType{broker.Add}; !reflect.DeepEqual(writer.events, expected) {
+		t.Errorf("expected events %v, got %v", expected, writer.events)
+	}
+}
@@ -0,0 +1,183 @@
+package pipeline
+
+import (
+	"sync"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshkit/logger"
+	"github.com/meshery/meshsync/internal/output"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// how often an event failing with retryable errors is processed again before it is dead-lettered
+const maxPublishRetries = 5
+
+// reasons an event is dead-lettered, the values of the reason label
+const (
+	// the event failed with an error which is not retryable
+	deadLetterNotRetryable = "not_retryable"
+	// the event kept failing with retryable errors
+	deadLetterRetriesExhausted = "retries_exhausted"
+)
+
+// eventsDeadLettered counts the events dropped as they failed to be emitted
+var eventsDeadLettered = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "meshsync_events_dead_lettered_total",
+	Help: "Number of events dropped after failing to be emitted, by resource and reason.",
+}, []string{"resource", "reason"})
+
+// retryQueueDepth is the number of failed events waiting to be retried, by resource
+var retryQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "meshsync_retry_queue_depth",
+	Help: "Number of failed events waiting to be retried, by resource.",
+}, []string{"resource"})
+
+// failedEvent is an event which failed to be emitted
+type failedEvent struct {
+	step   *RegisterInformer
+	obj    *unstructured.Unstructured
+	evtype broker.EventType
+}
+
+// retryQueue processes the events of a pipeline failing with retryable errors again, with a per event backoff,
+// see output.Retryable. A later event of the same object supersedes the pending retry, so a retried event
+// is never emitted after a newer one, f.e. an ADDED event after the DELETE event of the object.
+// Every pipeline has a queue of its own, a sink failing persistently for one resource does not delay
+// the retries of the others.
+type retryQueue struct {
+	log        logger.Handler
+	resource   string
+	queue      workqueue.TypedRateLimitingInterface[*failedEvent]
+	maxRetries int
+
+	mu sync.Mutex
+	// the event to retry by object, see retryKey, the queued events missing here are superseded
+	pending map[string]*failedEvent
+}
+
+// retryKey identifies the object of an event, the namespace/name consumers key the objects by
+func retryKey(obj *unstructured.Unstructured) string {
+	key, _ := cache.MetaNamespaceKeyFunc(obj)
+	return key
+}
+
+func newRetryQueue(log logger.Handler, resource string, limiter workqueue.TypedRateLimiter[*failedEvent]) *retryQueue {
+	return &retryQueue{
+		log:      log,
+		resource: resource,
+		queue: workqueue.NewTypedRateLimitingQueueWithConfig(limiter, workqueue.TypedRateLimitingQueueConfig[*failedEvent]{
+			Name: "meshsync_retries_" + resource,
+		}),
+		maxRetries: maxPublishRetries,
+		pending:    make(map[string]*failedEvent),
+	}
+}
+
+// run processes the failed events until stopCh is closed
+func (q *retryQueue) run(stopCh <-chan struct{}) {
+	go func() {
+		<-stopCh
+		q.queue.ShutDown()
+	}()
+	defer retryQueueDepth.DeleteLabelValues(q.resource)
+	for q.processNext() {
+	}
+}
+
+// add queues the event for its next attempt once its backoff has passed, superseding the pending retry of the object
+func (q *retryQueue) add(event *failedEvent) {
+	q.mu.Lock()
+	q.pending[retryKey(event.obj)] = event
+	q.mu.Unlock()
+	q.requeue(event)
+}
+
+// requeue queues the event again once its backoff has passed
+func (q *retryQueue) requeue(event *failedEvent) {
+	retryQueueDepth.WithLabelValues(q.resource).Inc()
+	q.queue.AddRateLimited(event)
+}
+
+// supersede drops the pending retry of the object, a later event of it is being emitted
+func (q *retryQueue) supersede(obj *unstructured.Unstructured) {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.pending, retryKey(obj))
+}
+
+// current reports whether the event is the pending retry of its object
+func (q *retryQueue) current(event *failedEvent) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.pending[retryKey(event.obj)] == event
+}
+
+// done forgets the event unless it has been superseded in the meantime
+func (q *retryQueue) done(event *failedEvent) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	key := retryKey(event.obj)
+	if q.pending[key] == event {
+		delete(q.pending, key)
+	}
+}
+
+func (q *retryQueue) processNext() bool {
+	event, shutdown := q.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer q.queue.Done(event)
+	retryQueueDepth.WithLabelValues(q.resource).Dec()
+
+	if !q.current(event) {
+		q.queue.Forget(event)
+		event.step.suppressed(suppressedSuperseded, 1)
+		event.step.objectLog.Debug("Skipping the retry of the ", event.evtype, " event for: ", retryKey(event.obj), " => [Superseded]")
+		return true
+	}
+
+	// publish, not publishItem, the retry must not supersede itself
+	_, err := event.step.publish(event.obj, event.evtype, event.step.config)
+	switch {
+	case err == nil:
+		q.queue.Forget(event)
+		q.done(event)
+	case !output.IsRetryable(err):
+		q.queue.Forget(event)
+		q.done(event)
+		event.step.deadLetter(event.obj, event.evtype, deadLetterNotRetryable, err)
+	case q.queue.NumRequeues(event) >= q.maxRetries:
+		q.queue.Forget(event)
+		q.done(event)
+		event.step.deadLetter(event.obj, event.evtype, deadLetterRetriesExhausted, err)
+	case q.current(event):
+		q.requeue(event)
+	default:
+		// superseded while it was retried
+		q.queue.Forget(event)
+	}
+	return true
+}
+
+// publishFailed retries the event if err is retryable and dead-letters it otherwise
+func (ri *RegisterInformer) publishFailed(obj *unstructured.Unstructured, evtype broker.EventType, err error) {
+	if ri.retries == nil || !output.IsRetryable(err) {
+		ri.deadLetter(obj, evtype, deadLetterNotRetryable, err)
+		return
+	}
+	ri.retries.add(&failedEvent{step: ri, obj: obj, evtype: evtype})
+}
+
+func (ri *RegisterInformer) deadLetter(obj *unstructured.Unstructured, evtype broker.EventType, reason string, err error) {
+	eventsDeadLettered.WithLabelValues(ri.config.Name, reason).Inc()
+	ri.log.Error(err)
+	ri.log.Warnf("Dropping %s event for: %s/%s of kind: %s [%s]", evtype, obj.GetNamespace(), obj.GetName(), obj.GetKind(), reason)
+}
@@ -0,0 +1,256 @@
+package pipeline
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/meshery/meshkit/broker"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/internal/output"
+	"github.com/meshery/meshsync/pkg/model"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// failingWriter fails the first failures writes with err
+type failingWriter struct {
+	recordingWriter
+	err      error
+	failures int
+
+	mu       sync.Mutex
+	attempts int
+}
+
+func (w *failingWriter) Write(obj model.KubernetesResource, evtype broker.EventType, config internalconfig.PipelineConfig) error {
+	w.mu.Lock()
+	w.attempts++
+	fail := w.failures < 0 || w.attempts <= w.failures
+	w.mu.Unlock()
+	if fail {
+		return w.err
+	}
+	return w.recordingWriter.Write(obj, evtype, config)
+}
+
+func (w *failingWriter) writeAttempts() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.attempts
+}
+
+func deadLetteredCount(resource, reason string) float64 {
+	return testutil.ToFloat64(eventsDeadLettered.WithLabelValues(resource, reason))
+}
+
+func TestRetryQueue(t *testing.T) {
+	transient := output.Retryable(errors.New("broker unavailable"))
+	permanent := errors.New("object too large")
+
+	testCases := []struct {
+		name             string
+		err              error
+		failures         int
+		expectedAttempts int
+		expectEmitted    bool
+		deadLetterReason string
+	}{
+		{
+			name:             "retryable error recovers",
+			err:              transient,
+			failures:         2,
+			expectedAttempts: 3,
+			expectEmitted:    true,
+		},
+		{
+			name:             "retryable error keeps failing",
+			err:              transient,
+			failures:         -1,
+			expectedAttempts: 1 + maxPublishRetries,
+			deadLetterReason: deadLetterRetriesExhausted,
+		},
+		{
+			name:             "non-retryable error",
+			err:              permanent,
+			failures:         -1,
+			expectedAttempts: 1,
+			deadLetterReason: deadLetterNotRetryable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resource := "retried.v1.example.com"
+			before := map[string]float64{
+				deadLetterNotRetryable:     deadLetteredCount(resource, deadLetterNotRetryable),
+				deadLetterRetriesExhausted: deadLetteredCount(resource, deadLetterRetriesExhausted),
+			}
+
+			writer := &failingWriter{err: tc.err, failures: tc.failures}
+			ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+				Name:   resource,
+				Events: []string{"ADDED", "MODIFIED", "DELETED"},
+			}, internalconfig.GlobalSettings{}, writer, "")
+			ri.retries = newRetryQueue(ri.log, resource, workqueue.NewTypedItemExponentialFailureRateLimiter[*failedEvent](time.Millisecond, 10*time.Millisecond))
+			stopChan := make(chan struct{})
+			defer close(stopChan)
+			go ri.retries.run(stopChan)
+
+			ri.GetEventHandlers().AddFunc(newTestObject("example.com/v1", "Widget", "default", "widget-a"))
+
+			waitFor(t, func() bool {
+				return writer.writeAttempts() >= tc.expectedAttempts && ri.retries.queue.Len() == 0
+			})
+			// give an unexpected retry the chance to show up
+			time.Sleep(50 * time.Millisecond)
+
+			if attempts := writer.writeAttempts(); attempts != tc.expectedAttempts {
+				t.Errorf("expected %d write attempts, got %d", tc.expectedAttempts, attempts)
+			}
+			if emitted := len(writer.writtenObjects()) == 1; emitted != tc.expectEmitted {
+				t.Errorf("expected emitted %t, got %d objects", tc.expectEmitted, len(writer.writtenObjects()))
+			}
+			for reason, count := range before {
+				expected := 0.0
+				if reason == tc.deadLetterReason {
+					expected = 1
+				}
+				if delta := deadLetteredCount(resource, reason) - count; delta != expected {
+					t.Errorf("expected %v events dead-lettered as %s, got %v", expected, reason, delta)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryableTransformError(t *testing.T) {
+	resource := "transformed.v1.example.com"
+	before := deadLetteredCount(resource, deadLetterNotRetryable)
+
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:   resource,
+		Events: []string{"ADDED"},
+	}, internalconfig.GlobalSettings{}, writer, "")
+	ri.retries = newRetryQueue(ri.log, resource, workqueue.NewTypedItemExponentialFailureRateLimiter[*failedEvent](time.Millisecond, 10*time.Millisecond))
+
+	var calls int
+	ri.transformers = []Transformer{TransformerFunc(func(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+		calls++
+		if calls == 1 {
+			return nil, output.Retryable(errors.New("module busy"))
+		}
+		return obj, nil
+	})}
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	go ri.retries.run(stopChan)
+
+	ri.GetEventHandlers().AddFunc(newTestObject("example.com/v1", "Widget", "default", "widget-a"))
+	waitFor(t, func() bool { return len(writer.writtenObjects()) == 1 })
+
+	if delta := deadLetteredCount(resource, deadLetterNotRetryable) - before; delta != 0 {
+		t.Errorf("expected no dead-lettered events, got %v", delta)
+	}
+}
+
+// stuckWriter fails the events with err and blocks their retries until released
+type stuckWriter struct {
+	recordingWriter
+	err     error
+	release chan struct{}
+
+	mu     sync.Mutex
+	failed map[string]bool
+}
+
+func (w *stuckWriter) Write(obj model.KubernetesResource, evtype broker.EventType, config internalconfig.PipelineConfig) error {
+	w.mu.Lock()
+	retry := w.failed[obj.KubernetesResourceMeta.Name]
+	w.failed[obj.KubernetesResourceMeta.Name] = true
+	w.mu.Unlock()
+	if !retry {
+		return w.err
+	}
+	<-w.release
+	return w.recordingWriter.Write(obj, evtype, config)
+}
+
+func retryDepth(resource string) float64 {
+	return testutil.ToFloat64(retryQueueDepth.WithLabelValues(resource))
+}
+
+func TestRetryQueuesAreIsolated(t *testing.T) {
+	transient := output.Retryable(errors.New("sink unavailable"))
+	limiter := func() workqueue.TypedRateLimiter[*failedEvent] {
+		return workqueue.NewTypedItemExponentialFailureRateLimiter[*failedEvent](time.Millisecond, 10*time.Millisecond)
+	}
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+
+	stuck := &stuckWriter{err: transient, release: make(chan struct{}), failed: make(map[string]bool)}
+	defer close(stuck.release)
+	stuckStep := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:   "stuck.v1.example.com",
+		Events: []string{"ADDED"},
+	}, internalconfig.GlobalSettings{}, stuck, "")
+	stuckStep.retries = newRetryQueue(stuckStep.log, "stuck.v1.example.com", limiter())
+	go stuckStep.retries.run(stopChan)
+
+	healthy := &failingWriter{err: transient, failures: 1}
+	healthyStep := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:   "healthy.v1.example.com",
+		Events: []string{"ADDED"},
+	}, internalconfig.GlobalSettings{}, healthy, "")
+	healthyStep.retries = newRetryQueue(healthyStep.log, "healthy.v1.example.com", limiter())
+	go healthyStep.retries.run(stopChan)
+
+	// the retry of the first object blocks the queue of the stuck pipeline, the second one waits behind it
+	stuckStep.GetEventHandlers().AddFunc(newTestObject("example.com/v1", "Widget", "default", "stuck-a"))
+	stuckStep.GetEventHandlers().AddFunc(newTestObject("example.com/v1", "Widget", "default", "stuck-b"))
+	waitFor(t, func() bool { return retryDepth("stuck.v1.example.com") == 1 })
+
+	healthyStep.GetEventHandlers().AddFunc(newTestObject("example.com/v1", "Widget", "default", "healthy-a"))
+	waitFor(t, func() bool { return len(healthy.writtenObjects()) == 1 })
+
+	if depth := retryDepth("healthy.v1.example.com"); depth != 0 {
+		t.Errorf("expected no retries left for the healthy pipeline, got %v", depth)
+	}
+	if depth := retryDepth("stuck.v1.example.com"); depth != 1 {
+		t.Errorf("expected the stuck pipeline to keep its retry waiting, got %v", depth)
+	}
+	if count := len(stuck.writtenObjects()); count != 0 {
+		t.Errorf("expected the stuck pipeline to emit nothing, got %d objects", count)
+	}
+}
+
+func TestRetryQueueDropsSupersededEvents(t *testing.T) {
+	resource := "superseded.v1.example.com"
+	before := suppressedCount(resource, suppressedSuperseded)
+
+	// the ADDED event fails, the DELETE event of the object is emitted before its retry
+	writer := &failingWriter{err: output.Retryable(errors.New("broker unavailable")), failures: 1}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:   resource,
+		Events: []string{"ADDED", "MODIFIED", "DELETED"},
+	}, internalconfig.GlobalSettings{}, writer, "")
+	ri.retries = newRetryQueue(ri.log, resource, workqueue.NewTypedItemExponentialFailureRateLimiter[*failedEvent](time.Millisecond, 10*time.Millisecond))
+
+	obj := newTestObject("example.com/v1", "Widget", "default", "widget-a")
+	ri.GetEventHandlers().AddFunc(obj)
+	ri.GetEventHandlers().DeleteFunc(obj)
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	go ri.retries.run(stopChan)
+	waitFor(t, func() bool { return suppressedCount(resource, suppressedSuperseded)-before == 1 })
+
+	writer.recordingWriter.mu.Lock()
+	defer writer.recordingWriter.mu.Unlock()
+	if len(writer.events) != 1 || writer.events[0] != broker.Delete {
+		t.Errorf("expected only the DELETE event to be emitted, got %v", writer.events)
+	}
+}
@@ -0,0 +1,257 @@
+package pipeline
+
+import (
+	"sync"
+
+	"github.com/meshery/meshkit/logger"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/internal/output"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ownerResources are the intermediate owners of Pods whose own owner is looked up in their cache,
+// f.e. the ReplicaSet between a Pod and its Deployment
+var ownerResources = map[schema.GroupKind]schema.GroupVersionResource{
+	{Group: "apps", Kind: "ReplicaSet"}: {Group: "apps", Version: "v1", Resource: "replicasets"},
+	{Group: "batch", Kind: "Job"}:       {Group: "batch", Version: "v1", Resource: "jobs"},
+}
+
+// maxOwnerDepth bounds the ownership chains followed, guarding against cycles
+const maxOwnerDepth = 5
+
+// podRollup is the state of a Pod counted in a rollup
+type podRollup struct {
+	owner output.OwnerRef
+	ready bool
+}
+
+// rollupTracker maintains the ready and total Pods per top-level owner
+// and emits the rollup of an owner whenever it changes.
+// Owners are resolved from the caches of the intermediate owners, a Pod listed before its ReplicaSet
+// is cached counts towards the ReplicaSet until the ReplicaSet is cached, see reattribute.
+type rollupTracker struct {
+	log       logger.Handler
+	writer    output.Writer
+	informers *informerSet
+	config    internalconfig.PipelineConfig
+
+	mu     sync.Mutex
+	pods   map[types.UID]podRollup
+	owners map[output.OwnerRef]*ownerRollup
+}
+
+type ownerRollup struct {
+	ready, total int
+}
+
+// rollupTrackerFor returns nil unless the pipeline emits rollups
+func rollupTrackerFor(log logger.Handler, writer output.Writer, informers *informerSet, config internalconfig.PipelineConfig) *rollupTracker {
+	if !config.Rollup {
+		return nil
+	}
+	return &rollupTracker{
+		log:       log,
+		writer:    writer,
+		informers: informers,
+		config:    config,
+		pods:      make(map[types.UID]podRollup),
+		owners:    make(map[output.OwnerRef]*ownerRollup),
+	}
+}
+
+// observe counts the added or updated Pod towards the rollup of its owner, Pods without owner are not counted
+func (r *rollupTracker) observe(pod *unstructured.Unstructured) {
+	if r == nil || !inNamespaceScope(r.config, pod.GetNamespace()) {
+		return
+	}
+	owner, ok := r.ownerOf(pod)
+	if !ok {
+		r.remove(pod)
+		return
+	}
+	current := podRollup{owner: owner, ready: podReady(pod)}
+
+	r.mu.Lock()
+	previous, tracked := r.pods[pod.GetUID()]
+	if tracked && previous == current {
+		r.mu.Unlock()
+		return
+	}
+	r.pods[pod.GetUID()] = current
+	if tracked {
+		r.count(previous, -1)
+	}
+	r.count(current, 1)
+	changed := []output.ControlEvent{r.rollupOf(current.owner)}
+	if tracked && previous.owner != current.owner {
+		changed = append(changed, r.rollupOf(previous.owner))
+	}
+	r.mu.Unlock()
+
+	r.emit(changed...)
+}
+
+// remove drops the deleted Pod from the rollup of its owner
+func (r *rollupTracker) remove(pod *unstructured.Unstructured) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	previous, tracked := r.pods[pod.GetUID()]
+	if !tracked {
+		r.mu.Unlock()
+		return
+	}
+	delete(r.pods, pod.GetUID())
+	r.count(previous, -1)
+	event := r.rollupOf(previous.owner)
+	r.mu.Unlock()
+
+	r.emit(event)
+}
+
+// count adds delta Pods in the given state to the rollup of their owner, must be called with the lock held
+func (r *rollupTracker) count(pod podRollup, delta int) {
+	rollup, ok := r.owners[pod.owner]
+	if !ok {
+		rollup = &ownerRollup{}
+		r.owners[pod.owner] = rollup
+	}
+	rollup.total += delta
+	if pod.ready {
+		rollup.ready += delta
+	}
+}
+
+// rollupOf returns the current rollup of the owner, must be called with the lock held
+func (r *rollupTracker) rollupOf(owner output.OwnerRef) output.ControlEvent {
+	rollup := r.owners[owner]
+	if rollup.total == 0 {
+		// the last Pod is gone, the final rollup is emitted with no Pods
+		delete(r.owners, owner)
+	}
+	return output.NewResourceRollupEvent(r.config.Name, owner, rollup.ready, rollup.total)
+}
+
+func (r *rollupTracker) emit(events ...output.ControlEvent) {
+	for _, event := range events {
+		if err := output.WriteControl(r.writer, event); err != nil {
+			r.log.Error(ErrWriteOutput(r.config.Name, err))
+		}
+	}
+}
+
+// watchOwners re-attributes the Pods of the intermediate owners once these are cached
+func (r *rollupTracker) watchOwners() {
+	if r == nil || r.informers == nil {
+		return
+	}
+	for _, gvr := range ownerResources {
+		_, _ = r.informers.factory.ForResource(gvr).Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				if owner, ok := obj.(*unstructured.Unstructured); ok {
+					r.reattribute(owner)
+				}
+			},
+			UpdateFunc: func(_, obj interface{}) {
+				if owner, ok := obj.(*unstructured.Unstructured); ok {
+					r.reattribute(owner)
+				}
+			},
+		})
+	}
+}
+
+// reattribute moves the Pods counted towards the intermediate owner, observed before it was cached,
+// to the top-level owner of the intermediate owner
+func (r *rollupTracker) reattribute(owner *unstructured.Unstructured) {
+	if metav1.GetControllerOfNoCopy(owner) == nil {
+		return
+	}
+	intermediate := output.OwnerRef{APIVersion: owner.GetAPIVersion(), Kind: owner.GetKind(), Namespace: owner.GetNamespace(), Name: owner.GetName()}
+	top := r.resolve(metav1.NewControllerRef(owner, owner.GroupVersionKind()), owner.GetNamespace())
+	if top == intermediate {
+		return
+	}
+
+	r.mu.Lock()
+	moved := false
+	for uid, pod := range r.pods {
+		if pod.owner != intermediate {
+			continue
+		}
+		r.count(pod, -1)
+		pod.owner = top
+		r.pods[uid] = pod
+		r.count(pod, 1)
+		moved = true
+	}
+	if !moved {
+		r.mu.Unlock()
+		return
+	}
+	changed := []output.ControlEvent{r.rollupOf(top), r.rollupOf(intermediate)}
+	r.mu.Unlock()
+
+	r.emit(changed...)
+}
+
+// ownerOf follows the controller references of the Pod to its top-level owner
+func (r *rollupTracker) ownerOf(pod *unstructured.Unstructured) (output.OwnerRef, bool) {
+	ref := metav1.GetControllerOfNoCopy(pod)
+	if ref == nil {
+		return output.OwnerRef{}, false
+	}
+	return r.resolve(ref, pod.GetNamespace()), true
+}
+
+// resolve follows the controller references from ref to the top-level owner
+func (r *rollupTracker) resolve(ref *metav1.OwnerReference, namespace string) output.OwnerRef {
+	for depth := 0; depth < maxOwnerDepth; depth++ {
+		parent, ok := r.controllerOf(ref, namespace)
+		if !ok {
+			break
+		}
+		ref = parent
+	}
+	return output.OwnerRef{APIVersion: ref.APIVersion, Kind: ref.Kind, Namespace: namespace, Name: ref.Name}
+}
+
+// controllerOf returns the controller of the intermediate owner referenced by ref from its cache
+func (r *rollupTracker) controllerOf(ref *metav1.OwnerReference, namespace string) (*metav1.OwnerReference, bool) {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil || r.informers == nil {
+		return nil, false
+	}
+	gvr, ok := ownerResources[gv.WithKind(ref.Kind).GroupKind()]
+	if !ok {
+		return nil, false
+	}
+	obj, err := r.informers.factory.ForResource(gvr).Lister().ByNamespace(namespace).Get(ref.Name)
+	if err != nil {
+		return nil, false
+	}
+	owner, ok := obj.(*unstructured.Unstructured)
+	if !ok || owner.GetUID() != ref.UID {
+		// a different object of the same name
+		return nil, false
+	}
+	parent := metav1.GetControllerOfNoCopy(owner)
+	return parent, parent != nil
+}
+
+// podReady reports whether the Ready condition of the Pod is true
+func podReady(pod *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(pod.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == "Ready" {
+			return condition["status"] == "True"
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package pipeline
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/internal/output"
+	"github.com/myntra/pipeline"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newOwnedTestObject(apiVersion, kind, name string, owner *unstructured.Unstructured) *unstructured.Unstructured {
+	obj := newTestObject(apiVersion, kind, "default", name)
+	obj.SetOwnerReferences([]metav1.OwnerReference{*metav1.NewControllerRef(owner, owner.GroupVersionKind())})
+	return obj
+}
+
+func setPodReady(pod *unstructured.Unstructured, ready bool) {
+	status := "False"
+	if ready {
+		status = "True"
+	}
+	_ = unstructured.SetNestedSlice(pod.Object, []interface{}{
+		map[string]interface{}{"type": "Ready", "status": status},
+	}, "status", "conditions")
+}
+
+// rollups returns the ready and total Pods of every emitted rollup in order
+func rollups(writer *recordingWriter) [][2]int {
+	counts := make([][2]int, 0)
+	for _, event := range writer.controlEvents() {
+		if event.Type == output.ResourceRollupEvent {
+			counts = append(counts, [2]int{event.Ready, event.Count})
+		}
+	}
+	return counts
+}
+
+func TestRollupFollowsOwnershipChain(t *testing.T) {
+	deployment := newTestObject("apps/v1", "Deployment", "default", "web")
+	replicaset := newOwnedTestObject("apps/v1", "ReplicaSet", "web-5d4f", deployment)
+	podA := newOwnedTestObject("v1", "Pod", "web-5d4f-a", replicaset)
+	podB := newOwnedTestObject("v1", "Pod", "web-5d4f-b", replicaset)
+	setPodReady(podA, true)
+	setPodReady(podB, false)
+	informers := newTestInformers(replicaset, podA, podB)
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	// the owners are cached before the Pods are listed
+	informers.registerOwners()
+	informers.factory.Start(stopChan)
+	informers.factory.WaitForCacheSync(stopChan)
+
+	writer := &recordingWriter{}
+	config := internalconfig.PipelineConfig{Name: "pods.v1.", Events: []string{"ADDED", "MODIFIED", "DELETED"}, Rollup: true, RollupOnly: true}
+	step := newRegisterInformerStep(newTestLogger(t), informers, nil, nil, config, internalconfig.GlobalSettings{}, writer, "")
+	if result := step.Exec(&pipeline.Request{Data: map[string]cache.Store{}}); result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	informers.factory.Start(stopChan)
+	waitFor(t, func() bool { return len(rollups(writer)) == 2 })
+
+	owner := writer.controlEvents()[0].Owner
+	expectedOwner := &output.OwnerRef{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "web"}
+	if !reflect.DeepEqual(owner, expectedOwner) {
+		t.Errorf("expected the rollup of %v, got %v", expectedOwner, owner)
+	}
+
+	// the second Pod becomes ready
+	pods := informers.client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "pods"}).Namespace("default")
+	ready := podB.DeepCopy()
+	ready.SetResourceVersion("2")
+	setPodReady(ready, true)
+	if _, err := pods.Update(context.Background(), ready, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return len(rollups(writer)) == 3 })
+
+	// the first Pod is deleted
+	if err := pods.Delete(context.Background(), podA.GetName(), metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return len(rollups(writer)) == 4 })
+
+	if got, expected := rollups(writer), [][2]int{{1, 1}, {1, 2}, {2, 2}, {1, 1}}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected ready and total Pods %v, got %v", expected, got)
+	}
+	if objects := writer.writtenObjects(); len(objects) != 0 {
+		t.Errorf("expected the Pods to be rolled up only, got %d objects", len(objects))
+	}
+}
+
+func TestRollupReattributesOnceOwnerIsCached(t *testing.T) {
+	deployment := newTestObject("apps/v1", "Deployment", "default", "web")
+	replicaset := newOwnedTestObject("apps/v1", "ReplicaSet", "web-5d4f", deployment)
+	pod := newOwnedTestObject("v1", "Pod", "web-5d4f-a", replicaset)
+	setPodReady(pod, true)
+	informers := newTestInformers()
+
+	writer := &recordingWriter{}
+	config := internalconfig.PipelineConfig{Name: "pods.v1.", Events: []string{"ADDED", "MODIFIED", "DELETED"}, Rollup: true, RollupOnly: true}
+	step := newRegisterInformerStep(newTestLogger(t), informers, nil, nil, config, internalconfig.GlobalSettings{}, writer, "")
+	if result := step.Exec(&pipeline.Request{Data: map[string]cache.Store{}}); result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	informers.factory.Start(stopChan)
+	informers.factory.WaitForCacheSync(stopChan)
+
+	// the Pod is observed before its ReplicaSet is cached
+	step.GetEventHandlers().AddFunc(pod)
+	if events := writer.controlEvents(); len(events) != 1 || events[0].Owner.Kind != "ReplicaSet" {
+		t.Fatalf("expected a rollup of the ReplicaSet, got %v", events)
+	}
+
+	replicasets := informers.client.Resource(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "replicasets"}).Namespace("default")
+	if _, err := replicasets.Create(context.Background(), replicaset, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return len(rollups(writer)) == 3 })
+
+	events := writer.controlEvents()
+	expectedOwner := &output.OwnerRef{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "web"}
+	if !reflect.DeepEqual(events[1].Owner, expectedOwner) || events[1].Ready != 1 || events[1].Count != 1 {
+		t.Errorf("expected the Pod to be counted towards %v, got %+v", expectedOwner, events[1])
+	}
+	if events[2].Owner.Kind != "ReplicaSet" || events[2].Count != 0 {
+		t.Errorf("expected the final rollup of the ReplicaSet with no Pods, got %+v", events[2])
+	}
+}
+
+func TestRollupWithoutOwnerCache(t *testing.T) {
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:   "pods.v1.",
+		Events: []string{"ADDED", "MODIFIED", "DELETED"},
+		Rollup: true,
+	}, internalconfig.GlobalSettings{}, writer, "")
+	handlers := ri.GetEventHandlers()
+
+	replicaset := newTestObject("apps/v1", "ReplicaSet", "default", "web-5d4f")
+	pod := newOwnedTestObject("v1", "Pod", "web-5d4f-a", replicaset)
+	handlers.AddFunc(pod)
+	handlers.AddFunc(newTestObject("v1", "Pod", "default", "standalone"))
+
+	events := writer.controlEvents()
+	if len(events) != 1 || events[0].Owner.Kind != "ReplicaSet" {
+		t.Errorf("expected a rollup of the ReplicaSet only, got %v", events)
+	}
+	if objects := writer.writtenObjects(); len(objects) != 2 {
+		t.Errorf("expected the events of the Pods next to the rollups, got %d objects", len(objects))
+	}
+}
@@ -0,0 +1,70 @@
+package pipeline
+
+import (
+	"hash/fnv"
+	"math"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// sampler decides per object whether its events are emitted.
+// The decision is derived from the object's UID, so all events of a sampled
+// object are emitted and none of an object which is not.
+type sampler struct {
+	selector     labels.Selector
+	matchRate    float64
+	nonMatchRate float64
+}
+
+// newSampler returns the sampler of the pipeline, nil when all objects are emitted
+func newSampler(config *internalconfig.SamplingConfig) (*sampler, error) {
+	if config == nil {
+		return nil, nil
+	}
+	selector, err := labels.Parse(config.Selector)
+	if err != nil {
+		return nil, err
+	}
+	return &sampler{
+		selector:     selector,
+		matchRate:    config.MatchRate(),
+		nonMatchRate: config.NonMatchRate(),
+	}, nil
+}
+
+func (s *sampler) sample(obj *unstructured.Unstructured) bool {
+	if s == nil {
+		return true
+	}
+	rate := s.nonMatchRate
+	if s.selector.Matches(labels.Set(obj.GetLabels())) {
+		rate = s.matchRate
+	}
+	switch {
+	case rate >= 1:
+		return true
+	case rate <= 0:
+		return false
+	}
+	return samplePoint(string(obj.GetUID())) < rate
+}
+
+// samplePoint maps the key uniformly onto [0, 1)
+func samplePoint(key string) float64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return float64(mix64(h.Sum64())) / (math.MaxUint64 + 1.0)
+}
+
+// mix64 spreads the hash over all bits (murmur3 finalizer),
+// FNV alone barely changes the high bits for keys differing in the last characters
+func mix64(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
@@ -0,0 +1,53 @@
+package pipeline
+
+import (
+	"fmt"
+	"testing"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+)
+
+func TestLabelBasedSampling(t *testing.T) {
+	prodRate, devRate := 1.0, 0.1
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:   "pods.v1.",
+		Events: []string{"ADDED", "MODIFIED", "DELETED"},
+		Sampling: &internalconfig.SamplingConfig{
+			Selector:    "env=prod",
+			Rate:        &prodRate,
+			DefaultRate: &devRate,
+		},
+	}, internalconfig.GlobalSettings{}, writer, "")
+
+	const total = 2000
+	emitted := func(env string) int {
+		writer.objects = nil
+		for i := 0; i < total; i++ {
+			pod := newTestObject("v1", "Pod", env, fmt.Sprintf("web-%d", i))
+			pod.SetLabels(map[string]string{"env": env})
+			ri.GetEventHandlers().AddFunc(pod)
+		}
+		return len(writer.objects)
+	}
+
+	if count := emitted("prod"); count != total {
+		t.Errorf("expected all %d prod objects to be emitted, got %d", total, count)
+	}
+
+	count := emitted("dev")
+	expected := int(devRate * total)
+	if count < expected*3/4 || count > expected*5/4 {
+		t.Errorf("expected about %d of %d dev objects to be emitted, got %d", expected, total, count)
+	}
+
+	// the decision is stable per object
+	for i := 0; i < 50; i++ {
+		pod := newTestObject("v1", "Pod", "dev", fmt.Sprintf("web-%d", i))
+		pod.SetLabels(map[string]string{"env": "dev"})
+		first := ri.sampler.sample(pod)
+		if ri.sampler.sample(pod) != first {
+			t.Fatalf("sampling decision for %s changed", pod.GetName())
+		}
+	}
+}
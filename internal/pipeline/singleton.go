@@ -0,0 +1,141 @@
+package pipeline
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+)
+
+// singletonCoalescer collapses the MODIFIED events of singleton resources,
+// which operators reconcile constantly, into at most one per interval and identity.
+// The first update is emitted right away, later ones within the interval replace each other
+// and the latest is emitted once the interval is over.
+// The identity is the UID of the object unless the pipeline configures an IdentityPath,
+// the updates of all objects sharing an identity are collapsed then.
+// Once stopped the pending updates are dropped, the rebuilt pipeline lists the objects again.
+type singletonCoalescer struct {
+	clock    clock.WithDelayedExecution
+	interval time.Duration
+	// coalesced is told about every update replaced by a later one
+	coalesced func()
+
+	mu sync.Mutex
+	// the timer of the open interval by identity
+	open    map[string]clock.Timer
+	pending map[string]pendingUpdate
+	stopped bool
+	// held while emitting, so the updates are emitted in order without holding mu
+	emitMu sync.Mutex
+}
+
+// pendingUpdate is the latest update of an identity, waiting for the interval to be over
+type pendingUpdate struct {
+	uid  types.UID
+	emit func()
+}
+
+func newSingletonCoalescer(c clock.WithDelayedExecution, interval time.Duration, coalesced func()) *singletonCoalescer {
+	return &singletonCoalescer{
+		clock:     c,
+		interval:  interval,
+		coalesced: coalesced,
+		open:      make(map[string]clock.Timer),
+		pending:   make(map[string]pendingUpdate),
+	}
+}
+
+// submit emits the update of the object with uid now or once the current interval of its identity is over
+func (c *singletonCoalescer) submit(identity string, uid types.UID, emit func()) {
+	c.mu.Lock()
+	if c.stopped {
+		c.release(emit)
+		return
+	}
+	if _, open := c.open[identity]; !open {
+		c.arm(identity)
+		c.release(emit)
+		return
+	}
+	defer c.mu.Unlock()
+	if _, ok := c.pending[identity]; ok {
+		c.coalesced()
+	}
+	c.pending[identity] = pendingUpdate{uid: uid, emit: emit}
+}
+
+// forget drops the pending update of a deleted object,
+// a pending update of another object sharing its identity is kept
+func (c *singletonCoalescer) forget(identity string, uid types.UID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if pending, ok := c.pending[identity]; ok && pending.uid == uid {
+		c.coalesced()
+		delete(c.pending, identity)
+	}
+}
+
+// tick ends the interval of the identity
+func (c *singletonCoalescer) tick(identity string) {
+	c.mu.Lock()
+	if c.stopped {
+		c.mu.Unlock()
+		return
+	}
+
+	pending, ok := c.pending[identity]
+	if !ok {
+		delete(c.open, identity)
+		c.mu.Unlock()
+		return
+	}
+	delete(c.pending, identity)
+	c.arm(identity)
+	c.release(pending.emit)
+}
+
+// stop drops the pending updates and stops the timers of the open intervals
+func (c *singletonCoalescer) stop() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopped = true
+	for identity, timer := range c.open {
+		timer.Stop()
+		delete(c.open, identity)
+	}
+	for identity := range c.pending {
+		c.coalesced()
+		delete(c.pending, identity)
+	}
+}
+
+// release unlocks mu and emits the update, must be called with the lock held
+func (c *singletonCoalescer) release(emit func()) {
+	// taken before mu is unlocked, so later updates wait for this one
+	c.emitMu.Lock()
+	c.mu.Unlock()
+	defer c.emitMu.Unlock()
+	emit()
+}
+
+// arm opens the next interval of the identity, must be called with the lock held
+func (c *singletonCoalescer) arm(identity string) {
+	c.open[identity] = c.clock.AfterFunc(c.interval, func() {
+		// timer callbacks must not block the clock
+		go c.tick(identity)
+	})
+}
+
+// singletonCoalescerFor returns the coalescer of the pipeline, nil unless the resource is a singleton
+func (ri *RegisterInformer) singletonCoalescerFor(c clock.WithDelayedExecution) *singletonCoalescer {
+	if ri.config.SingletonInterval <= 0 {
+		return nil
+	}
+	return newSingletonCoalescer(c, ri.config.SingletonInterval, func() {
+		ri.suppressed(suppressedCoalesced, 1)
+	})
+}
@@ -0,0 +1,188 @@
+package pipeline
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestSingletonUpdatesCollapse(t *testing.T) {
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:              "clusterconfigs.v1.example.com",
+		Events:            []string{"ADDED", "MODIFIED", "DELETED"},
+		SingletonInterval: time.Minute,
+	}, internalconfig.GlobalSettings{}, writer, "")
+	ri.singletons = ri.singletonCoalescerFor(fakeClock)
+	coalescedBefore := suppressedCount("clusterconfigs.v1.example.com", suppressedCoalesced)
+
+	rv := 1
+	current := newTestObject("example.com/v1", "ClusterConfig", "", "cluster")
+	reconcile := func(times int) {
+		for i := 0; i < times; i++ {
+			rv++
+			updated := current.DeepCopy()
+			updated.SetResourceVersion(fmt.Sprint(rv))
+			ri.GetEventHandlers().UpdateFunc(current, updated)
+			current = updated
+		}
+	}
+	emittedVersions := func() []string {
+		versions := make([]string, 0)
+		for _, obj := range writer.writtenObjects() {
+			versions = append(versions, obj.KubernetesResourceMeta.ResourceVersion)
+		}
+		return versions
+	}
+
+	reconcile(50)
+	if versions := emittedVersions(); len(versions) != 1 || versions[0] != "2" {
+		t.Fatalf("expected only the first update to be emitted right away, got %v", versions)
+	}
+
+	// the latest update of the interval is emitted once it is over
+	fakeClock.Step(time.Minute)
+	waitFor(t, func() bool { return len(writer.writtenObjects()) == 2 })
+	if versions := emittedVersions(); versions[1] != "51" {
+		t.Errorf("expected the latest update to be emitted, got %v", versions)
+	}
+
+	reconcile(50)
+	waitFor(t, fakeClock.HasWaiters)
+	fakeClock.Step(time.Minute)
+	waitFor(t, func() bool { return len(writer.writtenObjects()) == 3 })
+	if versions := emittedVersions(); versions[2] != "101" {
+		t.Errorf("expected the latest update to be emitted, got %v", versions)
+	}
+
+	// a quiet interval closes the window, the next update is emitted right away
+	waitFor(t, fakeClock.HasWaiters)
+	fakeClock.Step(time.Minute)
+	waitFor(t, func() bool {
+		ri.singletons.mu.Lock()
+		defer ri.singletons.mu.Unlock()
+		_, open := ri.singletons.open[ri.identityOf(current)]
+		return !open
+	})
+	reconcile(1)
+	if count := len(writer.writtenObjects()); count != 4 {
+		t.Errorf("expected the update after a quiet interval to be emitted right away, got %d emissions", count)
+	}
+
+	// the first burst emits its first and last update, the second one only its last
+	if count := suppressedCount("clusterconfigs.v1.example.com", suppressedCoalesced) - coalescedBefore; count != 48+49 {
+		t.Errorf("expected %d coalesced updates, got %v", 48+49, count)
+	}
+}
+
+func TestSingletonStop(t *testing.T) {
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:              "clusterconfigs.v1.example.com",
+		Events:            []string{"MODIFIED"},
+		SingletonInterval: time.Minute,
+	}, internalconfig.GlobalSettings{}, writer, "")
+	ri.singletons = ri.singletonCoalescerFor(fakeClock)
+
+	current := newTestObject("example.com/v1", "ClusterConfig", "", "cluster")
+	for rv := 2; rv <= 3; rv++ {
+		updated := current.DeepCopy()
+		updated.SetResourceVersion(fmt.Sprint(rv))
+		ri.GetEventHandlers().UpdateFunc(current, updated)
+		current = updated
+	}
+	stopChan := make(chan struct{})
+	close(stopChan)
+	ri.stopDeferred(stopChan)
+
+	// the rebuilt pipeline lists the object again, the pending update is dropped
+	if fakeClock.HasWaiters() {
+		t.Error("expected the timer of the interval to be stopped")
+	}
+	fakeClock.Step(time.Minute)
+	time.Sleep(50 * time.Millisecond)
+	if count := len(writer.writtenObjects()); count != 1 {
+		t.Errorf("expected only the first update to be emitted, got %d emissions", count)
+	}
+}
+
+func TestSingletonCoalescesByIdentity(t *testing.T) {
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:              "gateways.v1.example.com",
+		Events:            []string{"ADDED", "MODIFIED", "DELETED"},
+		SingletonInterval: time.Minute,
+		IdentityPath:      "$.spec.hostname",
+	}, internalconfig.GlobalSettings{}, writer, "")
+	ri.singletons = ri.singletonCoalescerFor(fakeClock)
+	coalescedBefore := suppressedCount("gateways.v1.example.com", suppressedCoalesced)
+
+	newGateway := func(name, hostname string) *unstructured.Unstructured {
+		gateway := newTestObject("example.com/v1", "Gateway", "default", name)
+		if hostname != "" {
+			_ = unstructured.SetNestedField(gateway.Object, hostname, "spec", "hostname")
+		}
+		return gateway
+	}
+	update := func(obj *unstructured.Unstructured) {
+		updated := obj.DeepCopy()
+		updated.SetResourceVersion("2")
+		ri.GetEventHandlers().UpdateFunc(obj, updated)
+	}
+	emittedNames := func() []string {
+		names := make([]string, 0)
+		for _, obj := range writer.writtenObjects() {
+			names = append(names, obj.KubernetesResourceMeta.Name)
+		}
+		return names
+	}
+
+	// the gateways share the hostname, their updates are collapsed
+	update(newGateway("gateway-a", "shop.example.com"))
+	update(newGateway("gateway-b", "shop.example.com"))
+	update(newGateway("gateway-c", "shop.example.com"))
+	// without the hostname the gateway is identified by its UID
+	update(newGateway("gateway-d", ""))
+
+	if names := emittedNames(); !reflect.DeepEqual(names, []string{"gateway-a", "gateway-d"}) {
+		t.Fatalf("expected the first update of each identity to be emitted right away, got %v", names)
+	}
+
+	fakeClock.Step(time.Minute)
+	waitFor(t, func() bool { return len(writer.writtenObjects()) == 3 })
+	if names := emittedNames(); names[2] != "gateway-c" {
+		t.Errorf("expected the latest update of the identity to be emitted, got %v", names)
+	}
+	if count := suppressedCount("gateways.v1.example.com", suppressedCoalesced) - coalescedBefore; count != 1 {
+		t.Errorf("expected 1 coalesced update, got %v", count)
+	}
+}
+
+func TestIdentityOf(t *testing.T) {
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:         "gateways.v1.example.com",
+		IdentityPath: "$.spec.listeners[?(@.protocol=='HTTPS')].hostname",
+	}, internalconfig.GlobalSettings{}, &recordingWriter{}, "")
+
+	gateway := newTestObject("example.com/v1", "Gateway", "default", "gateway-a")
+	_ = unstructured.SetNestedSlice(gateway.Object, []interface{}{
+		map[string]interface{}{"protocol": "HTTP", "hostname": "plain.example.com"},
+		map[string]interface{}{"protocol": "HTTPS", "hostname": "shop.example.com"},
+	}, "spec", "listeners")
+	if identity := ri.identityOf(gateway); identity != "identity:shop.example.com" {
+		t.Errorf("expected the identity of the HTTPS listener, got %s", identity)
+	}
+
+	plain := newTestObject("example.com/v1", "Gateway", "default", "gateway-b")
+	if identity := ri.identityOf(plain); identity != string(plain.GetUID()) {
+		t.Errorf("expected the UID without identity, got %s", identity)
+	}
+}
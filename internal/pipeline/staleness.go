@@ -0,0 +1,144 @@
+package pipeline
+
+import (
+	"sync"
+	"time"
+
+	"github.com/meshery/meshsync/internal/output"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
+)
+
+// connectionObserver is told about the outcome of every list and watch request of an informer
+type connectionObserver interface {
+	connected()
+	disconnected()
+}
+
+// observedListWatch reports to the observer whether the API server could be reached
+type observedListWatch struct {
+	cache.ListerWatcher
+	observer connectionObserver
+}
+
+func (lw *observedListWatch) List(options metav1.ListOptions) (runtime.Object, error) {
+	obj, err := lw.ListerWatcher.List(options)
+	lw.report(err)
+	return obj, err
+}
+
+func (lw *observedListWatch) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	w, err := lw.ListerWatcher.Watch(options)
+	lw.report(err)
+	return w, err
+}
+
+func (lw *observedListWatch) report(err error) {
+	if err != nil {
+		lw.observer.disconnected()
+		return
+	}
+	lw.observer.connected()
+}
+
+// stalenessTracker marks a pipeline stale once its watch has been disconnected
+// for longer than the configured duration, and fresh again on reconnect
+type stalenessTracker struct {
+	clock    clock.WithDelayedExecution
+	after    time.Duration
+	onChange func(stale bool, since time.Time)
+
+	mu             sync.Mutex
+	disconnectedAt time.Time
+	isDisconnected bool
+	stale          bool
+	timer          clock.Timer
+}
+
+func newStalenessTracker(c clock.WithDelayedExecution, after time.Duration, onChange func(stale bool, since time.Time)) *stalenessTracker {
+	return &stalenessTracker{
+		clock:    c,
+		after:    after,
+		onChange: onChange,
+	}
+}
+
+func (s *stalenessTracker) disconnected() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.isDisconnected {
+		return
+	}
+	s.isDisconnected = true
+	s.disconnectedAt = s.clock.Now()
+	s.timer = s.clock.AfterFunc(s.after, func() {
+		// timer callbacks must not block the clock
+		go s.markStale()
+	})
+}
+
+func (s *stalenessTracker) connected() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.isDisconnected {
+		return
+	}
+	s.isDisconnected = false
+	s.timer.Stop()
+	if s.stale {
+		s.stale = false
+		s.onChange(false, s.disconnectedAt)
+	}
+}
+
+func (s *stalenessTracker) markStale() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.isDisconnected || s.stale {
+		return
+	}
+	s.stale = true
+	s.onChange(true, s.disconnectedAt)
+}
+
+func (s *stalenessTracker) isStale() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stale
+}
+
+// stalenessTrackerFor returns the tracker of the pipeline, nil when staleness tracking is disabled
+func (ri *RegisterInformer) stalenessTrackerFor(c clock.WithDelayedExecution) *stalenessTracker {
+	if ri.config.StaleAfter <= 0 {
+		return nil
+	}
+	return newStalenessTracker(c, ri.config.StaleAfter, func(stale bool, since time.Time) {
+		ri.statuses.update(ri.config.Name, func(status *PipelineStatus) {
+			status.Stale = stale
+			status.StaleSince = nil
+			if stale {
+				status.StaleSince = &since
+			}
+		})
+
+		evtype := output.PipelineRecoveredEvent
+		if stale {
+			evtype = output.PipelineStaleEvent
+			ri.log.Warnf("Watch of %s disconnected since %s, cache is stale", ri.config.Name, since)
+		} else {
+			ri.log.Info("Watch of ", ri.config.Name, " reconnected, cache is fresh")
+		}
+		if !ri.config.StaleNotify {
+			return
+		}
+		if err := output.WriteControl(ri.outputWriter, output.NewControlEvent(evtype, ri.config.Name, 0)); err != nil {
+			ri.log.Error(ErrWriteOutput(ri.config.Name, err))
+		}
+	})
+}
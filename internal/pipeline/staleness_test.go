@@ -0,0 +1,133 @@
+package pipeline
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/meshery/meshkit/broker"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/internal/output"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestStalePipelineAfterLongDisconnect(t *testing.T) {
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	writer := &recordingWriter{}
+	statuses := NewStatusTracker()
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, statuses, internalconfig.PipelineConfig{
+		Name:        "pods.v1.",
+		Events:      []string{"ADDED", "MODIFIED", "DELETED"},
+		StaleAfter:  5 * time.Minute,
+		StaleNotify: true,
+	}, internalconfig.GlobalSettings{}, writer, "")
+	ri.staleness = ri.stalenessTrackerFor(fakeClock)
+
+	// the API server is unreachable while down is set
+	var down atomic.Bool
+	unreachable := errors.New("connection refused")
+	lw := &observedListWatch{
+		ListerWatcher: &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				if down.Load() {
+					return nil, unreachable
+				}
+				return &unstructured.UnstructuredList{}, nil
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				if down.Load() {
+					return nil, unreachable
+				}
+				return watch.NewFake(), nil
+			},
+		},
+		observer: ri.staleness,
+	}
+	isStale := func() bool {
+		status, _ := statuses.Get("pods.v1.")
+		return status.Stale
+	}
+
+	if _, err := lw.List(metav1.ListOptions{}); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	down.Store(true)
+	disconnectedAt := fakeClock.Now()
+	_, _ = lw.Watch(metav1.ListOptions{})
+	fakeClock.Step(4 * time.Minute)
+	// the reflector keeps retrying
+	_, _ = lw.List(metav1.ListOptions{})
+	if isStale() {
+		t.Fatal("expected the pipeline not to be stale before the configured duration")
+	}
+
+	fakeClock.Step(time.Minute)
+	waitFor(t, isStale)
+	status, _ := statuses.Get("pods.v1.")
+	if status.StaleSince == nil || !status.StaleSince.Equal(disconnectedAt) {
+		t.Errorf("expected the pipeline to be stale since %s, got %v", disconnectedAt, status.StaleSince)
+	}
+	if events := writer.controlEvents(); len(events) != 1 || events[0].Type != output.PipelineStaleEvent || events[0].Resource != "pods.v1." {
+		t.Errorf("expected a single %s event for pods.v1., got %+v", output.PipelineStaleEvent, events)
+	}
+
+	if err := ri.publishItem(newTestObject("v1", "Pod", "default", "pod-a"), broker.Add, ri.config); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if count := len(writer.writtenObjects()); count != 0 {
+		t.Errorf("expected no emission while stale, got %d objects", count)
+	}
+
+	down.Store(false)
+	if _, err := lw.List(metav1.ListOptions{}); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if isStale() {
+		t.Error("expected the stale flag to clear on reconnect")
+	}
+	if status, _ := statuses.Get("pods.v1."); status.StaleSince != nil {
+		t.Errorf("expected no stale since on reconnect, got %s", status.StaleSince)
+	}
+	if events := writer.controlEvents(); len(events) != 2 || events[1].Type != output.PipelineRecoveredEvent {
+		t.Errorf("expected a %s event on reconnect, got %+v", output.PipelineRecoveredEvent, events)
+	}
+
+	if err := ri.publishItem(newTestObject("v1", "Pod", "default", "pod-a"), broker.Add, ri.config); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if count := len(writer.writtenObjects()); count != 1 {
+		t.Errorf("expected emission to resume on reconnect, got %d objects", count)
+	}
+}
+
+func TestShortDisconnectDoesNotMarkStale(t *testing.T) {
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	writer := &recordingWriter{}
+	statuses := NewStatusTracker()
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, statuses, internalconfig.PipelineConfig{
+		Name:       "pods.v1.",
+		StaleAfter: 5 * time.Minute,
+	}, internalconfig.GlobalSettings{}, writer, "")
+	ri.staleness = ri.stalenessTrackerFor(fakeClock)
+
+	ri.staleness.disconnected()
+	fakeClock.Step(time.Minute)
+	ri.staleness.connected()
+	if fakeClock.HasWaiters() {
+		t.Error("expected the staleness timer to be stopped on reconnect")
+	}
+	fakeClock.Step(10 * time.Minute)
+	if ri.staleness.isStale() {
+		t.Error("expected the pipeline not to be stale after a short disconnect")
+	}
+	if events := writer.controlEvents(); len(events) != 0 {
+		t.Errorf("expected no control events, got %+v", events)
+	}
+}
@@ -0,0 +1,154 @@
+package pipeline
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/clock"
+)
+
+const (
+	// StartupLeaseName is the Lease the replicas of a namespace reserve their start slots in
+	StartupLeaseName = "meshsync-startup"
+	// nextStartAnnotation records on the startup lease when the next replica may start its informers
+	nextStartAnnotation = "meshery.io/meshsync-next-start"
+)
+
+var (
+	leasesGVR = schema.GroupVersionResource{Group: "coordination.k8s.io", Version: "v1", Resource: "leases"}
+	// defaultInt63n is the random source of the startup jitter
+	defaultInt63n = rand.Int63n
+	// processStartup delays the first start of the informers only, see startupGate
+	processStartup = &startupGate{}
+)
+
+// startupGate takes the startup delay once, the pipelines rebuilt on every resync or reload
+// start their informers right away once the delay of the process is over
+type startupGate struct {
+	once sync.Once
+	at   time.Time
+}
+
+// jitterDelay returns a random delay within [0, jitter], int63n returns a random number within [0, n)
+func jitterDelay(jitter time.Duration, int63n func(n int64) int64) time.Duration {
+	if jitter <= 0 {
+		return 0
+	}
+	return time.Duration(int63n(int64(jitter) + 1))
+}
+
+// startupCoordinator hands out start slots stagger apart to the replicas sharing the startup lease.
+// The lease records the next free slot in an annotation, a replica takes the later of now and that slot
+// and moves it on by stagger. Concurrent reservations conflict on the resourceVersion and are retried,
+// so no two replicas get the same slot. A slot in the past, f.e. long after the last restart, is now.
+type startupCoordinator struct {
+	leases  dynamic.ResourceInterface
+	stagger time.Duration
+	clock   clock.PassiveClock
+}
+
+// startupCoordinatorFor returns nil unless the replicas are staggered
+func startupCoordinatorFor(dynamicClient dynamic.Interface, namespace string, stagger time.Duration) *startupCoordinator {
+	if stagger <= 0 || dynamicClient == nil {
+		return nil
+	}
+	return &startupCoordinator{
+		leases:  dynamicClient.Resource(leasesGVR).Namespace(namespace),
+		stagger: stagger,
+		clock:   clock.RealClock{},
+	}
+}
+
+// reserve takes the next start slot and returns how long until it
+func (c *startupCoordinator) reserve(ctx context.Context) (time.Duration, error) {
+	var delay time.Duration
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		lease, err := c.leases.Get(ctx, StartupLeaseName, metav1.GetOptions{})
+		create := apierrors.IsNotFound(err)
+		if create {
+			lease = &unstructured.Unstructured{}
+			lease.SetAPIVersion(leasesGVR.GroupVersion().String())
+			lease.SetKind("Lease")
+			lease.SetName(StartupLeaseName)
+		} else if err != nil {
+			return err
+		}
+
+		now := c.clock.Now()
+		slot := now
+		if next, err := time.Parse(time.RFC3339Nano, lease.GetAnnotations()[nextStartAnnotation]); err == nil && next.After(now) {
+			slot = next
+		}
+		annotations := lease.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[nextStartAnnotation] = slot.Add(c.stagger).UTC().Format(time.RFC3339Nano)
+		lease.SetAnnotations(annotations)
+
+		if create {
+			_, err = c.leases.Create(ctx, lease, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				// another replica created it first, reserve in its lease
+				return apierrors.NewConflict(leasesGVR.GroupResource(), StartupLeaseName, err)
+			}
+		} else {
+			_, err = c.leases.Update(ctx, lease, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return err
+		}
+		delay = slot.Sub(now)
+		return nil
+	})
+	return delay, err
+}
+
+// startupDelay returns how long the informers wait before they start: the random jitter
+// plus, if the replicas are staggered, the time until the start slot of this replica
+func (si *StartInformers) startupDelay() time.Duration {
+	delay := jitterDelay(si.startupJitter, si.int63n)
+	if si.coordinator == nil {
+		return delay
+	}
+	slot, err := si.coordinator.reserve(wait.ContextForChannel(si.stopChan))
+	if err != nil {
+		si.log.Warn(ErrStartupLease(StartupLeaseName, err))
+		return delay
+	}
+	return delay + slot
+}
+
+// startupTime returns when the informers may start, the jitter and the start slot are taken once per process
+func (si *StartInformers) startupTime() time.Time {
+	si.startup.once.Do(func() {
+		delay := si.startupDelay()
+		si.log.Infof("Delaying the start of the informers by %s", delay)
+		si.startup.at = si.clock.Now().Add(delay)
+	})
+	return si.startup.at
+}
+
+// startAfterDelay starts the informers once the startup delay is over, unless stopped before
+func (si *StartInformers) startAfterDelay() {
+	delay := si.startupTime().Sub(si.clock.Now())
+	if delay <= 0 {
+		si.startInformers()
+		return
+	}
+	select {
+	case <-si.stopChan:
+		return
+	case <-si.clock.After(delay):
+	}
+	si.startInformers()
+}
@@ -0,0 +1,135 @@
+package pipeline
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/myntra/pipeline"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestJitterDelayWithinBounds(t *testing.T) {
+	jitter := 10 * time.Second
+	if delay := jitterDelay(jitter, func(n int64) int64 { return 0 }); delay != 0 {
+		t.Errorf("expected the lower bound 0, got %s", delay)
+	}
+	if delay := jitterDelay(jitter, func(n int64) int64 { return n - 1 }); delay != jitter {
+		t.Errorf("expected the upper bound %s, got %s", jitter, delay)
+	}
+	if delay := jitterDelay(0, func(n int64) int64 { t.Fatal("expected no random delay without jitter"); return 0 }); delay != 0 {
+		t.Errorf("expected no delay without jitter, got %s", delay)
+	}
+
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		if delay := jitterDelay(jitter, random.Int63n); delay < 0 || delay > jitter {
+			t.Fatalf("expected a delay within [0, %s], got %s", jitter, delay)
+		}
+	}
+}
+
+func TestStartupDelayedByJitter(t *testing.T) {
+	informers := newTestInformers(newTestObject("v1", "Pod", "default", "web"))
+	writer := &recordingWriter{}
+	step := newRegisterInformerStep(newTestLogger(t), informers, nil, nil, internalconfig.PipelineConfig{
+		Name:   "pods.v1.",
+		Events: []string{"ADDED"},
+	}, internalconfig.GlobalSettings{}, writer, "")
+	stores := step.Exec(&pipeline.Request{}).Data
+
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	startup := &startupGate{}
+	start := newStartInformersStep(stopChan, newTestLogger(t), informers, nil, writer, false)
+	start.clock = fakeClock
+	start.startup = startup
+	start.startupJitter = 10 * time.Second
+	start.int63n = func(n int64) int64 {
+		if n != int64(start.startupJitter)+1 {
+			t.Errorf("expected a random delay within the jitter of %s, got the bound %s", start.startupJitter, time.Duration(n))
+		}
+		return int64(4 * time.Second)
+	}
+	start.Exec(&pipeline.Request{Data: stores})
+
+	waitFor(t, fakeClock.HasWaiters)
+	fakeClock.Step(3 * time.Second)
+	time.Sleep(100 * time.Millisecond)
+	if count := len(writer.writtenObjects()); count != 0 {
+		t.Fatalf("expected no event before the startup delay is over, got %d", count)
+	}
+
+	fakeClock.Step(time.Second)
+	waitFor(t, func() bool { return len(writer.writtenObjects()) == 1 })
+
+	// the pipelines rebuilt afterwards start right away, without another delay
+	rebuilt := newTestInformers(newTestObject("v1", "Pod", "default", "web"))
+	step = newRegisterInformerStep(newTestLogger(t), rebuilt, nil, nil, internalconfig.PipelineConfig{
+		Name:   "pods.v1.",
+		Events: []string{"ADDED"},
+	}, internalconfig.GlobalSettings{}, writer, "")
+	stores = step.Exec(&pipeline.Request{}).Data
+	restart := newStartInformersStep(stopChan, newTestLogger(t), rebuilt, nil, writer, false)
+	restart.clock = fakeClock
+	restart.startup = startup
+	restart.startupJitter = 10 * time.Second
+	restart.int63n = func(n int64) int64 {
+		t.Error("expected the startup delay to be taken once")
+		return 0
+	}
+	restart.Exec(&pipeline.Request{Data: stores})
+	waitFor(t, func() bool { return len(writer.writtenObjects()) == 2 })
+}
+
+func TestStartupCoordinatorStaggersReplicas(t *testing.T) {
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		leasesGVR: "LeaseList",
+	})
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	stagger := 5 * time.Second
+	replica := func() *startupCoordinator {
+		coordinator := startupCoordinatorFor(client, "meshery", stagger)
+		coordinator.clock = fakeClock
+		return coordinator
+	}
+
+	for i, expected := range []time.Duration{0, stagger, 2 * stagger} {
+		delay, err := replica().reserve(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+		if delay != expected {
+			t.Errorf("expected replica %d to start after %s, got %s", i, expected, delay)
+		}
+	}
+
+	// slots in the past are not waited for
+	fakeClock.Step(time.Minute)
+	delay, err := replica().reserve(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if delay != 0 {
+		t.Errorf("expected a replica started long after the others to start right away, got %s", delay)
+	}
+
+	lease, err := client.Resource(leasesGVR).Namespace("meshery").Get(context.Background(), StartupLeaseName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := fakeClock.Now().Add(stagger).UTC().Format(time.RFC3339Nano); lease.GetAnnotations()[nextStartAnnotation] != expected {
+		t.Errorf("expected the next slot %s, got %s", expected, lease.GetAnnotations()[nextStartAnnotation])
+	}
+
+	if startupCoordinatorFor(client, "meshery", 0) != nil {
+		t.Error("expected no coordination without stagger")
+	}
+}
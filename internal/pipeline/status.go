@@ -0,0 +1,89 @@
+package pipeline
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// PipelineStatus describes the state of a pipeline
+type PipelineStatus struct {
+	Name string `json:"name" yaml:"name"`
+	// the initial sync of the pipeline's informer has completed
+	Synced bool `json:"synced" yaml:"synced"`
+	// number of objects at the initial sync
+	Objects int `json:"objects" yaml:"objects"`
+	// the watch has been disconnected for longer than configured, the cache may be outdated
+	Stale      bool       `json:"stale" yaml:"stale"`
+	StaleSince *time.Time `json:"stale_since,omitempty" yaml:"stale_since,omitempty"`
+	// the namespaces the pipeline is scoped to which do not exist, nothing is watched in them until they are created
+	MissingNamespaces []string `json:"missing_namespaces,omitempty" yaml:"missing_namespaces,omitempty"`
+}
+
+// StatusTracker keeps the status of every pipeline, a nil tracker discards all updates
+type StatusTracker struct {
+	mu       sync.RWMutex
+	statuses map[string]*PipelineStatus
+}
+
+func NewStatusTracker() *StatusTracker {
+	return &StatusTracker{
+		statuses: make(map[string]*PipelineStatus),
+	}
+}
+
+// Get returns the status of the pipeline
+func (t *StatusTracker) Get(name string) (PipelineStatus, bool) {
+	if t == nil {
+		return PipelineStatus{}, false
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	status, ok := t.statuses[name]
+	if !ok {
+		return PipelineStatus{}, false
+	}
+	return *status, true
+}
+
+// List returns the status of all pipelines ordered by name
+func (t *StatusTracker) List() []PipelineStatus {
+	if t == nil {
+		return nil
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	statuses := make([]PipelineStatus, 0, len(t.statuses))
+	for _, status := range t.statuses {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// reset forgets the statuses, the pipelines are registered again when they restart
+func (t *StatusTracker) reset() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.statuses = make(map[string]*PipelineStatus)
+}
+
+// update applies the change to the status of the pipeline, adding the pipeline if unknown
+func (t *StatusTracker) update(name string, change func(status *PipelineStatus)) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	status, ok := t.statuses[name]
+	if !ok {
+		status = &PipelineStatus{Name: name}
+		t.statuses[name] = status
+	}
+	change(status)
+}
@@ -2,39 +2,102 @@ package pipeline
 
 import (
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/meshery/meshkit/logger"
 	internalconfig "github.com/meshery/meshsync/internal/config"
 	"github.com/meshery/meshsync/internal/output"
 	"github.com/myntra/pipeline"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
 )
 
+// instanceStartedAt is when the process started, the pipelines are rebuilt on every resync or reload
+// while the objects created since are still new, see RegisterInformer.predatesStart
+var instanceStartedAt = time.Now()
+
 type RegisterInformer struct {
 	pipeline.StepContext
 	log          logger.Handler
-	informer     dynamicinformer.DynamicSharedInformerFactory
+	informers    *informerSet
 	config       internalconfig.PipelineConfig
+	settings     internalconfig.GlobalSettings
 	outputWriter output.Writer
 	clusterID    string
+	transformers []Transformer
+	deletions    *deletionTracker
+	sampler      *sampler
+	// objects created before the process started are considered pre-existing
+	startedAt   time.Time
+	bulkDeletes *bulkDeleteGuard
+	statuses    *StatusTracker
+	staleness   *stalenessTracker
+	singletons  *singletonCoalescer
+	phases      *phaseTracker
+	retries     *retryQueue
+	manifest    *snapshotManifest
+	// the objects deleted on their deletionTimestamp, nil unless DeleteOnDeletionTimestamp
+	terminating *terminatingObjects
+	// the last known state of recently seen objects, nil unless RetainedObjects
+	retained *retainedObjects
+	rollups  *rollupTracker
+	// shared by all pipelines, nil unless relationships are configured
+	relationships *relationshipTracker
+	objectLog     *objectLogger
+	// the path of the identity singleton updates are coalesced by, empty coalesces by UID
+	identity internalconfig.FieldPath
+	// holds the events back while the history before the initial list is replayed
+	backfills backfillGate
 }
 
 func newRegisterInformerStep(
 	log logger.Handler,
-	informer dynamicinformer.DynamicSharedInformerFactory,
+	informers *informerSet,
+	deletions *deletionTracker,
+	statuses *StatusTracker,
 	config internalconfig.PipelineConfig,
+	settings internalconfig.GlobalSettings,
 	ow output.Writer,
 	clusterID string,
 ) *RegisterInformer {
-	return &RegisterInformer{
+	if deletions == nil {
+		deletions = newDeletionTracker(clock.RealClock{})
+	}
+	sampler, err := newSampler(config.Sampling)
+	if err != nil {
+		// the selector is validated when the config is loaded
+		log.Error(internalconfig.MeshkitError(internalconfig.ErrInitConfig(err)))
+	}
+	identity, err := internalconfig.ParseFieldPath(config.IdentityPath)
+	if err != nil && config.IdentityPath != "" {
+		// the path is validated when the config is loaded
+		log.Error(internalconfig.MeshkitError(internalconfig.ErrInitConfig(err)))
+	}
+	ri := &RegisterInformer{
 		log:          log,
-		informer:     informer,
+		informers:    informers,
 		config:       config,
+		settings:     settings,
 		outputWriter: ow,
 		clusterID:    clusterID,
+		transformers: transformersFor(log, config, settings, nil),
+		deletions:    deletions,
+		sampler:      sampler,
+		startedAt:    instanceStartedAt,
+		statuses:     statuses,
+		identity:     identity,
+		terminating:  terminatingObjectsFor(config),
+		retained:     retainedObjectsFor(config),
+		rollups:      rollupTrackerFor(log, ow, informers, config),
+		objectLog:    newObjectLogger(log, config.Name, settings.ObjectLogSampling, clock.RealClock{}),
 	}
+	ri.bulkDeletes = ri.bulkDeleteGuardFor(clock.RealClock{})
+	ri.staleness = ri.stalenessTrackerFor(clock.RealClock{})
+	ri.singletons = ri.singletonCoalescerFor(clock.RealClock{})
+	return ri
 }
 
 // TODO: Find a way to respond when an informer has stopped for some reason unknown
@@ -48,22 +111,49 @@ func (ri *RegisterInformer) Exec(request *pipeline.Request) *pipeline.Result {
 		}
 	}
 
-	iclient := ri.informer.ForResource(*gvr)
+	informer := ri.informers.informerFor(ri.config, *gvr, ri.staleness, ri)
+	ri.statuses.update(ri.config.Name, func(*PipelineStatus) {})
+
+	if transform := cacheTransformFor(ri.config); transform != nil {
+		if err := informer.SetTransform(transform); err != nil {
+			// the informer has been started already, its objects are cached untrimmed
+			ri.log.Error(ErrSetTransform(ri.config.Name, err))
+		}
+	}
+	ri.registerHandlers(informer)
+	if ri.bulkDeletes != nil || ri.singletons != nil {
+		go ri.stopDeferred(ri.informers.ctx.Done())
+	}
 
-	ri.registerHandlers(iclient.Informer())
+	if ri.config.Rollup {
+		ri.informers.registerOwners()
+		ri.rollups.watchOwners()
+	}
+	if needsNamespaces(ri.config, ri.settings) {
+		// tenant partitioning and namespace enrichment resolve namespaces from the namespaces informer
+		ri.informers.registerNamespaces()
+	}
 
 	// add the instance of store to the Result
 	data := make(map[string]cache.Store)
 	if request.Data != nil {
 		data = request.Data.(map[string]cache.Store)
 	}
-	data[ri.config.Name] = iclient.Informer().GetStore()
+	data[ri.config.Name] = informer.GetStore()
 	return &pipeline.Result{
 		Error: nil,
 		Data:  data,
 	}
 }
 
+// stopDeferred stops the timers of the throttled deletes and coalesced updates once the pipeline stops,
+// the throttled deletes are emitted then as the rebuilt pipeline does not list the deleted objects
+func (ri *RegisterInformer) stopDeferred(stopCh <-chan struct{}) {
+	<-stopCh
+	ri.bulkDeletes.stop()
+	ri.singletons.stop()
+}
+
 // Cancel - step interface
 func (ri *RegisterInformer) Cancel() error {
 	ri.Status("cancel step")
@@ -74,28 +164,155 @@ func (ri *RegisterInformer) Cancel() error {
 
 type StartInformers struct {
 	pipeline.StepContext
-	stopChan chan struct{}
-	informer dynamicinformer.DynamicSharedInformerFactory
-	log      logger.Handler
+	stopChan       chan struct{}
+	informers      *informerSet
+	outputWriter   output.Writer
+	log            logger.Handler
+	statuses       *StatusTracker
+	snapshotMarker bool
+	// informers initializing at once, zero starts all of them at once
+	maxConcurrentInitializing int
+	// pipelines emitting summaries of their objects
+	summaries []internalconfig.PipelineConfig
+	clock     clock.WithTicker
+	phases    *phaseTracker
+	// the retry queue of every pipeline
+	retries  []*retryQueue
+	manifest *snapshotManifest
+	// nil unless a pipeline is scoped to namespaces
+	namespaces *namespaceChecker
+	// the upper bound of the random delay before the informers start, zero starts right away
+	startupJitter time.Duration
+	int63n        func(n int64) int64
+	// nil unless the replicas are staggered
+	coordinator *startupCoordinator
+	startup     *startupGate
 }
 
-func newStartInformersStep(stopChan chan struct{}, log logger.Handler, informer dynamicinformer.DynamicSharedInformerFactory) *StartInformers {
+func newStartInformersStep(stopChan chan struct{}, log logger.Handler, informers *informerSet, statuses *StatusTracker, ow output.Writer, snapshotMarker bool) *StartInformers {
 	return &StartInformers{
-		log:      log,
-		informer: informer,
-		stopChan: stopChan,
+		log:            log,
+		informers:      informers,
+		statuses:       statuses,
+		outputWriter:   ow,
+		stopChan:       stopChan,
+		snapshotMarker: snapshotMarker,
+		clock:          clock.RealClock{},
+		int63n:         defaultInt63n,
+		startup:        processStartup,
 	}
 }
 
 func (si *StartInformers) Exec(request *pipeline.Request) *pipeline.Result {
-	si.informer.WaitForCacheSync(si.stopChan)
-	si.informer.Start(si.stopChan)
+	if err := si.namespaces.verify(wait.ContextForChannel(si.stopChan)); err != nil {
+		return &pipeline.Result{
+			Error: err,
+			Data:  request.Data,
+		}
+	}
+	if si.startupJitter > 0 || si.coordinator != nil {
+		go si.startAfterDelay()
+	} else {
+		si.startInformers()
+	}
+	if stores, ok := request.Data.(map[string]cache.Store); ok {
+		go si.notifySynced(stores)
+	}
+	for _, config := range si.summaries {
+		go si.summarize(config)
+	}
+	for _, retries := range si.retries {
+		go retries.run(si.stopChan)
+	}
+	go si.namespaces.run(si.stopChan)
 	return &pipeline.Result{
 		Error: nil,
 		Data:  request.Data,
 	}
 }
 
+// startInformers starts the registered informers, in waves if their initialization is capped
+func (si *StartInformers) startInformers() {
+	if si.maxConcurrentInitializing > 0 {
+		go si.informers.startInWaves(si.stopChan, si.maxConcurrentInitializing)
+		return
+	}
+	si.informers.factory.WaitForCacheSync(si.stopChan)
+	si.informers.factory.Start(si.stopChan)
+	si.informers.start(si.stopChan)
+}
+
+// notifySynced notifies about the initial sync of every pipeline and, once all of them have synced,
+// emits the snapshot complete marker and manifest if enabled and completes the initial sync phase
+func (si *StartInformers) notifySynced(stores map[string]cache.Store) {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		counts = make(map[string]int, len(stores))
+	)
+	for name := range stores {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			if count, ok := si.notifyPipelineSynced(name); ok {
+				mu.Lock()
+				counts[name] = count
+				mu.Unlock()
+			}
+		}(name)
+	}
+	wg.Wait()
+
+	if len(counts) != len(stores) {
+		// stopped before all pipelines synced
+		return
+	}
+	if si.snapshotMarker {
+		si.log.Info("Initial sync completed for all pipelines")
+		if err := output.WriteControl(si.outputWriter, output.NewSnapshotCompleteEvent(counts)); err != nil {
+			si.log.Error(ErrWriteOutput(internalconfig.PipelineNameKey, err))
+		}
+	}
+
+	if si.manifest == nil && si.phases == nil {
+		return
+	}
+	if !si.informers.waitForHandlers(si.stopChan) {
+		return
+	}
+	if si.manifest != nil {
+		if err := output.WriteControl(si.outputWriter, output.NewSnapshotManifestEvent(si.manifest.seal())); err != nil {
+			si.log.Error(ErrWriteOutput(internalconfig.PipelineNameKey, err))
+		}
+	}
+	si.phases.initialSyncComplete(counts)
+}
+
+// notifyPipelineSynced waits for the initial list of the pipeline's informer
+// and emits a control event carrying the number of objects it has synced.
+// It returns the number of objects and whether the initial sync completed.
+func (si *StartInformers) notifyPipelineSynced(name string) (int, bool) {
+	informer, ok := si.informers.get(name)
+	if !ok {
+		return 0, false
+	}
+	if !cache.WaitForCacheSync(si.stopChan, informer.HasSynced) {
+		// stopped before the initial sync completed
+		return 0, false
+	}
+
+	count := len(informer.GetStore().ListKeys())
+	si.statuses.update(name, func(status *PipelineStatus) {
+		status.Synced = true
+		status.Objects = count
+	})
+	si.log.Info("Initial sync completed for: ", name, " objects: ", count)
+	if err := output.WriteControl(si.outputWriter, output.NewControlEvent(output.PipelineSyncedEvent, name, count)); err != nil {
+		si.log.Error(ErrWriteOutput(name, err))
+	}
+	return count, true
+}
+
 // Cancel - step interface
 func (si *StartInformers) Cancel() error {
 	si.Status("cancel step")
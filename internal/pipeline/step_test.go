@@ -0,0 +1,191 @@
+package pipeline
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshkit/logger"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/internal/output"
+	"github.com/meshery/meshsync/pkg/model"
+	"github.com/myntra/pipeline"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+// recordingWriter collects everything written to it
+type recordingWriter struct {
+	mu       sync.Mutex
+	objects  []model.KubernetesResource
+	events   []broker.EventType
+	configs  []internalconfig.PipelineConfig
+	controls []output.ControlEvent
+}
+
+func (w *recordingWriter) Write(obj model.KubernetesResource, evtype broker.EventType, config internalconfig.PipelineConfig) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.objects = append(w.objects, obj)
+	w.events = append(w.events, evtype)
+	w.configs = append(w.configs, config)
+	return nil
+}
+
+func (w *recordingWriter) WriteControl(event output.ControlEvent) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.controls = append(w.controls, event)
+	return nil
+}
+
+func (w *recordingWriter) controlEvents() []output.ControlEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]output.ControlEvent{}, w.controls...)
+}
+
+func (w *recordingWriter) writtenObjects() []model.KubernetesResource {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]model.KubernetesResource{}, w.objects...)
+}
+
+func newTestLogger(t *testing.T) logger.Handler {
+	t.Helper()
+	log, err := logger.New("meshsync-test", logger.Options{Format: logger.SyslogLogFormat})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return log
+}
+
+func newTestObject(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetUID(types.UID(namespace + "/" + name))
+	obj.SetResourceVersion("1")
+	return obj
+}
+
+func newTestInformers(objects ...runtime.Object) *informerSet {
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			{Version: "v1", Resource: "pods"}:                       "PodList",
+			{Version: "v1", Resource: "services"}:                   "ServiceList",
+			{Version: "v1", Resource: "namespaces"}:                 "NamespaceList",
+			{Group: "apps", Version: "v1", Resource: "replicasets"}: "ReplicaSetList",
+			{Group: "batch", Version: "v1", Resource: "jobs"}:       "JobList",
+		},
+		objects...,
+	)
+	return newInformerSet(context.Background(), dynamicinformer.NewDynamicSharedInformerFactory(client, internalconfig.ResyncCheckPeriod), client)
+}
+
+func TestStartInformersEmitsPipelineSynced(t *testing.T) {
+	log := newTestLogger(t)
+	informers := newTestInformers(
+		newTestObject("v1", "Pod", "default", "pod-a"),
+		newTestObject("v1", "Pod", "default", "pod-b"),
+		newTestObject("v1", "Service", "default", "svc-a"),
+	)
+	writer := &recordingWriter{}
+	stores := registerTestPipelines(t, informers, writer, "pods.v1.", "services.v1.")
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	newStartInformersStep(stopChan, log, informers, nil, writer, false).Exec(&pipeline.Request{Data: stores})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(writer.controlEvents()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	counts := make(map[string]int)
+	for _, event := range writer.controlEvents() {
+		if event.Type != output.PipelineSyncedEvent {
+			t.Errorf("unexpected control event type %s", event.Type)
+		}
+		if _, ok := counts[event.Resource]; ok {
+			t.Errorf("more than one sync event for %s", event.Resource)
+		}
+		counts[event.Resource] = event.Count
+	}
+
+	expected := map[string]int{"pods.v1.": 2, "services.v1.": 1}
+	for name, count := range expected {
+		if counts[name] != count {
+			t.Errorf("expected sync event for %s with count %d, got %d", name, count, counts[name])
+		}
+	}
+}
+
+func TestStartInformersEmitsSnapshotComplete(t *testing.T) {
+	log := newTestLogger(t)
+	informers := newTestInformers(
+		newTestObject("v1", "Pod", "default", "pod-a"),
+		newTestObject("v1", "Pod", "default", "pod-b"),
+		newTestObject("v1", "Service", "default", "svc-a"),
+	)
+	writer := &recordingWriter{}
+	stores := registerTestPipelines(t, informers, writer, "pods.v1.", "services.v1.")
+
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	newStartInformersStep(stopChan, log, informers, nil, writer, true).Exec(&pipeline.Request{Data: stores})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(writer.controlEvents()) < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	// give a duplicate marker the chance to show up
+	time.Sleep(50 * time.Millisecond)
+
+	events := writer.controlEvents()
+	if len(events) != 3 {
+		t.Fatalf("expected 2 pipeline synced events and 1 snapshot marker, got %d events", len(events))
+	}
+	for _, event := range events[:2] {
+		if event.Type != output.PipelineSyncedEvent {
+			t.Errorf("expected %s before the marker, got %s", output.PipelineSyncedEvent, event.Type)
+		}
+	}
+
+	marker := events[2]
+	if marker.Type != output.SnapshotCompleteEvent {
+		t.Fatalf("expected %s last, got %s", output.SnapshotCompleteEvent, marker.Type)
+	}
+	expected := map[string]int{"pods.v1.": 2, "services.v1.": 1}
+	if !reflect.DeepEqual(marker.Resources, expected) {
+		t.Errorf("expected marker resources %v, got %v", expected, marker.Resources)
+	}
+	if marker.Count != 3 {
+		t.Errorf("expected marker total count 3, got %d", marker.Count)
+	}
+}
+
+// registerTestPipelines registers an informer for each resource like the discovery stages do
+func registerTestPipelines(t *testing.T, informers *informerSet, writer output.Writer, names ...string) map[string]cache.Store {
+	t.Helper()
+	stores := make(map[string]cache.Store)
+	for _, name := range names {
+		step := newRegisterInformerStep(newTestLogger(t), informers, nil, nil, internalconfig.PipelineConfig{Name: name}, internalconfig.GlobalSettings{}, writer, "")
+		result := step.Exec(&pipeline.Request{Data: stores})
+		if result.Error != nil {
+			t.Fatal(result.Error)
+		}
+	}
+	return stores
+}
@@ -0,0 +1,70 @@
+package pipeline
+
+import (
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/internal/output"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var namespacesGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+// namespaceLabelsFunc returns the labels of the given namespace
+// and whether the namespace is known
+type namespaceLabelsFunc func(namespace string) (map[string]string, bool)
+
+// buildSubject returns the subject an object is published to.
+// When tenant partitioning is configured, the value of the tenant label
+// of the object's namespace is appended to the pipeline subject,
+// objects without a tenant (including cluster scoped ones) go to the fallback subject.
+func buildSubject(config internalconfig.PipelineConfig, namespace string, nsLabels namespaceLabelsFunc) string {
+	if config.TenantLabel == "" {
+		return config.PublishTo
+	}
+
+	fallback := config.TenantFallbackSubject
+	if fallback == "" {
+		fallback = config.PublishTo
+	}
+
+	if namespace == "" || nsLabels == nil {
+		return fallback
+	}
+	labels, ok := nsLabels(namespace)
+	if !ok {
+		return fallback
+	}
+	tenant := labels[config.TenantLabel]
+	if tenant == "" {
+		return fallback
+	}
+
+	return config.PublishTo + "." + output.SubjectToken(tenant)
+}
+
+// namespaceLabels looks up namespace labels from the shared namespaces informer
+func (ri *RegisterInformer) namespaceLabels(namespace string) (map[string]string, bool) {
+	ns, ok := ri.namespace(namespace)
+	if !ok {
+		return nil, false
+	}
+	return ns.GetLabels(), true
+}
+
+// namespace looks up the namespace from the shared namespaces informer
+func (ri *RegisterInformer) namespace(name string) (*unstructured.Unstructured, bool) {
+	if ri.informers == nil {
+		return nil, false
+	}
+	obj, err := ri.informers.factory.ForResource(namespacesGVR).Lister().Get(name)
+	if err != nil {
+		return nil, false
+	}
+	ns, ok := obj.(*unstructured.Unstructured)
+	return ns, ok
+}
+
+// needsNamespaces reports whether the pipeline looks up the namespaces of its objects
+func needsNamespaces(config internalconfig.PipelineConfig, settings internalconfig.GlobalSettings) bool {
+	return config.TenantLabel != "" || settings.NamespaceMetadata
+}
@@ -0,0 +1,87 @@
+package pipeline
+
+import (
+	"testing"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+)
+
+func TestBuildSubject(t *testing.T) {
+	namespaces := map[string]map[string]string{
+		"team-a":    {"tenant": "acme"},
+		"team-b":    {"tenant": "acme.corp"},
+		"unlabeled": {"env": "dev"},
+	}
+	nsLabels := func(namespace string) (map[string]string, bool) {
+		labels, ok := namespaces[namespace]
+		return labels, ok
+	}
+
+	partitioned := internalconfig.PipelineConfig{
+		PublishTo:             "meshery.meshsync.core",
+		TenantLabel:           "tenant",
+		TenantFallbackSubject: "meshery.meshsync.shared",
+	}
+
+	testCases := []struct {
+		name      string
+		config    internalconfig.PipelineConfig
+		namespace string
+		expected  string
+	}{
+		{
+			name:      "partitioning disabled",
+			config:    internalconfig.PipelineConfig{PublishTo: "meshery.meshsync.core"},
+			namespace: "team-a",
+			expected:  "meshery.meshsync.core",
+		},
+		{
+			name:      "labeled namespace routes to tenant subject",
+			config:    partitioned,
+			namespace: "team-a",
+			expected:  "meshery.meshsync.core.acme",
+		},
+		{
+			name:      "tenant value is sanitized",
+			config:    partitioned,
+			namespace: "team-b",
+			expected:  "meshery.meshsync.core.acme_corp",
+		},
+		{
+			name:      "unlabeled namespace routes to fallback",
+			config:    partitioned,
+			namespace: "unlabeled",
+			expected:  "meshery.meshsync.shared",
+		},
+		{
+			name:      "unknown namespace routes to fallback",
+			config:    partitioned,
+			namespace: "missing",
+			expected:  "meshery.meshsync.shared",
+		},
+		{
+			name:      "cluster scoped object routes to fallback",
+			config:    partitioned,
+			namespace: "",
+			expected:  "meshery.meshsync.shared",
+		},
+		{
+			name: "fallback defaults to pipeline subject",
+			config: internalconfig.PipelineConfig{
+				PublishTo:   "meshery.meshsync.core",
+				TenantLabel: "tenant",
+			},
+			namespace: "unlabeled",
+			expected:  "meshery.meshsync.core",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			subject := buildSubject(tc.config, tc.namespace, nsLabels)
+			if subject != tc.expected {
+				t.Errorf("expected subject %s, got %s", tc.expected, subject)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package pipeline
+
+import (
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/internal/output"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// summarizedPipelines returns the configs of the pipelines emitting summaries
+func summarizedPipelines(plConfigs map[string]internalconfig.PipelineConfigs) []internalconfig.PipelineConfig {
+	summarized := make([]internalconfig.PipelineConfig, 0)
+	for _, configs := range plConfigs {
+		for _, config := range configs {
+			if config.SummaryInterval > 0 {
+				summarized = append(summarized, config)
+			}
+		}
+	}
+	return summarized
+}
+
+// summarize emits a summary of the pipeline's objects every interval until stopped,
+// intervals ending before the initial sync of the pipeline are skipped
+func (si *StartInformers) summarize(config internalconfig.PipelineConfig) {
+	informer, ok := si.informers.get(config.Name)
+	if !ok {
+		return
+	}
+
+	ticker := si.clock.NewTicker(config.SummaryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-si.stopChan:
+			return
+		case <-ticker.C():
+			if !informer.HasSynced() {
+				continue
+			}
+			event := newResourceSummary(config, informer.GetStore().List())
+			if err := output.WriteControl(si.outputWriter, event); err != nil {
+				si.log.Error(ErrWriteOutput(config.Name, err))
+			}
+		}
+	}
+}
+
+// newResourceSummary counts the objects of the pipeline's namespaces in total, by namespace and by status.phase
+func newResourceSummary(config internalconfig.PipelineConfig, objects []interface{}) output.ControlEvent {
+	event := output.NewControlEvent(output.ResourceSummaryEvent, config.Name, 0)
+	event.Namespaces = make(map[string]int)
+	event.Phases = make(map[string]int)
+	for _, o := range objects {
+		obj, ok := o.(*unstructured.Unstructured)
+		if !ok || !inNamespaceScope(config, obj.GetNamespace()) {
+			continue
+		}
+		event.Count++
+		if namespace := obj.GetNamespace(); namespace != "" {
+			event.Namespaces[namespace]++
+		}
+		if phase, found, _ := unstructured.NestedString(obj.Object, "status", "phase"); found && phase != "" {
+			event.Phases[phase]++
+		}
+	}
+	return event
+}
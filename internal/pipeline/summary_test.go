@@ -0,0 +1,82 @@
+package pipeline
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/internal/output"
+	"github.com/myntra/pipeline"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func newTestPodInPhase(namespace, name, phase string) *unstructured.Unstructured {
+	pod := newTestObject("v1", "Pod", namespace, name)
+	_ = unstructured.SetNestedField(pod.Object, phase, "status", "phase")
+	return pod
+}
+
+func summaryEvents(writer *recordingWriter) []output.ControlEvent {
+	summaries := make([]output.ControlEvent, 0)
+	for _, event := range writer.controlEvents() {
+		if event.Type == output.ResourceSummaryEvent {
+			summaries = append(summaries, event)
+		}
+	}
+	return summaries
+}
+
+func TestResourceSummaryPerInterval(t *testing.T) {
+	informers := newTestInformers(
+		newTestPodInPhase("default", "web-a", "Running"),
+		newTestPodInPhase("default", "web-b", "Pending"),
+		newTestPodInPhase("prod", "api", "Running"),
+	)
+	writer := &recordingWriter{}
+	config := internalconfig.PipelineConfig{
+		Name:            "pods.v1.",
+		Events:          []string{"ADDED", "MODIFIED", "DELETED"},
+		SummaryInterval: time.Minute,
+		SummaryOnly:     true,
+	}
+	step := newRegisterInformerStep(newTestLogger(t), informers, nil, nil, config, internalconfig.GlobalSettings{}, writer, "")
+	stores := step.Exec(&pipeline.Request{}).Data
+
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+	start := newStartInformersStep(stopChan, newTestLogger(t), informers, nil, writer, false)
+	start.clock = fakeClock
+	start.summaries = summarizedPipelines(map[string]internalconfig.PipelineConfigs{
+		internalconfig.LocalResourceKey: {config, {Name: "services.v1."}},
+	})
+	start.Exec(&pipeline.Request{Data: stores})
+
+	informer, _ := informers.get("pods.v1.")
+	waitFor(t, func() bool { return informer.HasSynced() && fakeClock.HasWaiters() })
+	if count := len(summaryEvents(writer)); count != 0 {
+		t.Fatalf("expected no summary before the interval is over, got %d", count)
+	}
+
+	for interval := 1; interval <= 2; interval++ {
+		fakeClock.Step(time.Minute)
+		waitFor(t, func() bool { return len(summaryEvents(writer)) == interval })
+	}
+
+	summary := summaryEvents(writer)[1]
+	if summary.Resource != "pods.v1." || summary.Count != 3 {
+		t.Errorf("expected a summary of 3 pods, got %s with %d", summary.Resource, summary.Count)
+	}
+	if expected := map[string]int{"default": 2, "prod": 1}; !reflect.DeepEqual(summary.Namespaces, expected) {
+		t.Errorf("expected counts by namespace %v, got %v", expected, summary.Namespaces)
+	}
+	if expected := map[string]int{"Running": 2, "Pending": 1}; !reflect.DeepEqual(summary.Phases, expected) {
+		t.Errorf("expected counts by phase %v, got %v", expected, summary.Phases)
+	}
+	// the summaries replace the individual events
+	if count := len(writer.writtenObjects()); count != 0 {
+		t.Errorf("expected no individual events, got %d", count)
+	}
+}
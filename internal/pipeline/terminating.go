@@ -0,0 +1,60 @@
+package pipeline
+
+import (
+	"sync"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// terminatingObjects tracks the objects whose DELETE event was emitted once their deletionTimestamp was set,
+// until they are finally removed
+type terminatingObjects struct {
+	mu   sync.Mutex
+	uids map[types.UID]struct{}
+}
+
+// terminatingObjectsFor returns nil unless the pipeline emits DELETE events on the deletionTimestamp
+func terminatingObjectsFor(config internalconfig.PipelineConfig) *terminatingObjects {
+	if config.DeleteSemantics != internalconfig.DeleteOnDeletionTimestamp {
+		return nil
+	}
+	return &terminatingObjects{uids: make(map[types.UID]struct{})}
+}
+
+// observe reports whether obj is terminating and, the first time, marks it as deleted
+func (t *terminatingObjects) observe(obj *unstructured.Unstructured) (terminating, first bool) {
+	if t == nil || obj.GetDeletionTimestamp() == nil {
+		return false, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.uids[obj.GetUID()]; ok {
+		return true, false
+	}
+	t.uids[obj.GetUID()] = struct{}{}
+	return true, true
+}
+
+// removed forgets the finally removed object and reports whether its DELETE event was emitted already
+func (t *terminatingObjects) removed(obj *unstructured.Unstructured) bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.uids[obj.GetUID()]
+	delete(t.uids, obj.GetUID())
+	return ok
+}
+
+// deleteIfTerminating emits the DELETE event of an object whose deletionTimestamp was just set,
+// it reports whether obj is terminating so its updates are not emitted
+func (ri *RegisterInformer) deleteIfTerminating(obj *unstructured.Unstructured) bool {
+	terminating, first := ri.terminating.observe(obj)
+	if first {
+		ri.handleDelete(obj)
+	}
+	return terminating
+}
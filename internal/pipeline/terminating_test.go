@@ -0,0 +1,90 @@
+package pipeline
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/meshery/meshkit/broker"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeleteSemantics(t *testing.T) {
+	testCases := []struct {
+		semantics string
+		// the events emitted over the lifecycle of a finalizer-held object
+		expected []broker.EventType
+	}{
+		{
+			semantics: internalconfig.DeleteOnFinalRemoval,
+			expected:  []broker.EventType{broker.Add, broker.Update, broker.Update, broker.Delete},
+		},
+		{
+			semantics: internalconfig.DeleteOnDeletionTimestamp,
+			expected:  []broker.EventType{broker.Add, broker.Delete},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.semantics, func(t *testing.T) {
+			writer := &recordingWriter{}
+			ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+				Name:            "pods.v1.",
+				Events:          []string{"ADDED", "MODIFIED", "DELETED"},
+				DeleteSemantics: tc.semantics,
+			}, internalconfig.GlobalSettings{}, writer, "")
+			handlers := ri.GetEventHandlers()
+
+			pod := newTestObject("v1", "Pod", "default", "web")
+			pod.SetFinalizers([]string{"example.com/cleanup"})
+			handlers.AddFunc(pod)
+
+			// the deletion is requested, the finalizer holds the object
+			deleting := pod.DeepCopy()
+			deleting.SetResourceVersion("2")
+			now := metav1.NewTime(time.Now())
+			deleting.SetDeletionTimestamp(&now)
+			handlers.UpdateFunc(pod, deleting)
+
+			// the finalizer completes
+			finalized := deleting.DeepCopy()
+			finalized.SetResourceVersion("3")
+			finalized.SetFinalizers(nil)
+			handlers.UpdateFunc(deleting, finalized)
+
+			handlers.DeleteFunc(finalized)
+
+			writer.mu.Lock()
+			events := append([]broker.EventType{}, writer.events...)
+			writer.mu.Unlock()
+			if !reflect.DeepEqual(events, tc.expected) {
+				t.Errorf("expected events %v, got %v", tc.expected, events)
+			}
+			if ri.terminating != nil && len(ri.terminating.uids) != 0 {
+				t.Errorf("expected the removed object to be forgotten, got %v", ri.terminating.uids)
+			}
+		})
+	}
+}
+
+func TestDeleteOnDeletionTimestampListed(t *testing.T) {
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:            "pods.v1.",
+		Events:          []string{"ADDED", "MODIFIED", "DELETED"},
+		DeleteSemantics: internalconfig.DeleteOnDeletionTimestamp,
+	}, internalconfig.GlobalSettings{}, writer, "")
+
+	// already terminating when listed
+	pod := newTestObject("v1", "Pod", "default", "web")
+	pod.SetFinalizers([]string{"example.com/cleanup"})
+	now := metav1.NewTime(time.Now())
+	pod.SetDeletionTimestamp(&now)
+	ri.GetEventHandlers().AddFunc(pod)
+	ri.GetEventHandlers().DeleteFunc(pod)
+
+	expected := []broker.EventType{broker.Add, broker.Delete}
+	if !reflect.DeepEqual(writer.events, expected) {
+		t.Errorf("expected events %v, got %v", expected, writer.events)
+	}
+}
@@ -0,0 +1,119 @@
+package pipeline
+
+import (
+	"errors"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+)
+
+// pipelineInformer is what a pipeline needs of its informer,
+// satisfied by a single informer as well as by an informer per namespace
+type pipelineInformer interface {
+	AddEventHandlerWithResyncPeriod(handler cache.ResourceEventHandler, resyncPeriod time.Duration) (cache.ResourceEventHandlerRegistration, error)
+	HasSynced() bool
+	GetStore() cache.Store
+	SetTransform(handler cache.TransformFunc) error
+}
+
+// namespacedInformer watches each of the pipeline's namespaces with an informer of its own
+type namespacedInformer struct {
+	informers []cache.SharedIndexInformer
+}
+
+func (n *namespacedInformer) AddEventHandlerWithResyncPeriod(handler cache.ResourceEventHandler, resyncPeriod time.Duration) (cache.ResourceEventHandlerRegistration, error) {
+	registrations := make(namespacedRegistration, 0, len(n.informers))
+	for _, informer := range n.informers {
+		registration, err := informer.AddEventHandlerWithResyncPeriod(handler, resyncPeriod)
+		if err != nil {
+			return nil, err
+		}
+		registrations = append(registrations, registration)
+	}
+	return registrations, nil
+}
+
+func (n *namespacedInformer) HasSynced() bool {
+	for _, informer := range n.informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *namespacedInformer) SetTransform(handler cache.TransformFunc) error {
+	for _, informer := range n.informers {
+		if err := informer.SetTransform(handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *namespacedInformer) GetStore() cache.Store {
+	stores := make(unionStore, 0, len(n.informers))
+	for _, informer := range n.informers {
+		stores = append(stores, informer.GetStore())
+	}
+	return stores
+}
+
+type namespacedRegistration []cache.ResourceEventHandlerRegistration
+
+func (r namespacedRegistration) HasSynced() bool {
+	for _, registration := range r {
+		if !registration.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// unionStore is a read-only view of the stores of disjoint namespaces,
+// like the store of any informer it is written to by the informers only
+type unionStore []cache.Store
+
+var errReadOnlyStore = errors.New("the store of a namespaced informer is read-only")
+
+func (u unionStore) Add(interface{}) error    { return errReadOnlyStore }
+func (u unionStore) Update(interface{}) error { return errReadOnlyStore }
+func (u unionStore) Delete(interface{}) error { return errReadOnlyStore }
+
+func (u unionStore) Replace([]interface{}, string) error { return errReadOnlyStore }
+
+func (u unionStore) Resync() error { return nil }
+
+func (u unionStore) List() []interface{} {
+	items := make([]interface{}, 0)
+	for _, store := range u {
+		items = append(items, store.List()...)
+	}
+	return items
+}
+
+func (u unionStore) ListKeys() []string {
+	keys := make([]string, 0)
+	for _, store := range u {
+		keys = append(keys, store.ListKeys()...)
+	}
+	return keys
+}
+
+func (u unionStore) Get(obj interface{}) (interface{}, bool, error) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return nil, false, err
+	}
+	return u.GetByKey(key)
+}
+
+func (u unionStore) GetByKey(key string) (interface{}, bool, error) {
+	for _, store := range u {
+		item, exists, err := store.GetByKey(key)
+		if err != nil || exists {
+			return item, exists, err
+		}
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,103 @@
+package pipeline
+
+import (
+	"github.com/meshery/meshkit/logger"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Transformer modifies an object before it is emitted.
+// Transformers receive a private copy of the informer's object, hence are free to mutate it.
+type Transformer interface {
+	Transform(obj *unstructured.Unstructured) (*unstructured.Unstructured, error)
+}
+
+// TransformerFunc is an adapter to use ordinary functions as Transformer
+type TransformerFunc func(obj *unstructured.Unstructured) (*unstructured.Unstructured, error)
+
+func (f TransformerFunc) Transform(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return f(obj)
+}
+
+// stripStatus removes the status subtree
+var stripStatus = TransformerFunc(func(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	unstructured.RemoveNestedField(obj.Object, "status")
+	return obj, nil
+})
+
+// transformersFor returns the transformations configured for the pipeline, in order of application.
+// Defaults are pruned with the given pruner, without one they are kept.
+// The values of Secrets are always redacted.
+func transformersFor(log logger.Handler, config internalconfig.PipelineConfig, settings internalconfig.GlobalSettings, pruner *DefaultsPruner) []Transformer {
+	transformers := make([]Transformer, 0)
+	if config.StripStatus {
+		transformers = append(transformers, stripStatus)
+	}
+	if config.PruneDefaults && pruner != nil {
+		transformers = append(transformers, pruner)
+	}
+	if len(config.DropPaths) > 0 {
+		t, err := newDropTransformer(config.DropPaths)
+		if err != nil {
+			transformers = append(transformers, failingTransformer(err))
+		} else {
+			transformers = append(transformers, t)
+		}
+	}
+	if len(config.Mask) > 0 {
+		// masked before the module, which must not see the masked values either
+		t, err := newMaskTransformer(config.Mask)
+		if err != nil {
+			transformers = append(transformers, failingTransformer(err))
+		} else {
+			transformers = append(transformers, t)
+		}
+	}
+	if redacted(config, settings) {
+		// redacted before the module too
+		transformers = append(transformers, NewRedactor(settings.RedactConfigMaps))
+	}
+	if config.WasmModule != "" {
+		t, err := wasmTransformerFor(config)
+		if err != nil {
+			// fail the events rather than emitting untransformed objects
+			transformers = append(transformers, failingTransformer(err))
+		} else {
+			transformers = append(transformers, t)
+		}
+	}
+	if len(settings.FieldDenylist) > 0 {
+		// last, nothing configured per resource can bring the denied fields back
+		t, err := NewFieldDenylist(log, settings.FieldDenylist)
+		if err != nil {
+			transformers = append(transformers, failingTransformer(err))
+		} else {
+			transformers = append(transformers, t)
+		}
+	}
+	return transformers
+}
+
+// transform applies the transformers to a copy of obj, leaving the informer's cache intact
+func transform(obj *unstructured.Unstructured, transformers []Transformer) (*unstructured.Unstructured, error) {
+	if len(transformers) == 0 {
+		return obj, nil
+	}
+
+	result := obj.DeepCopy()
+	for _, t := range transformers {
+		var err error
+		result, err = t.Transform(result)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// failingTransformer fails every object with err, f.e. when the transformer could not be loaded
+func failingTransformer(err error) Transformer {
+	return TransformerFunc(func(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+		return nil, err
+	})
+}
@@ -0,0 +1,48 @@
+package pipeline
+
+import (
+	"testing"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestStripStatusTransform(t *testing.T) {
+	log := newTestLogger(t)
+
+	testCases := []struct {
+		name         string
+		stripStatus  bool
+		expectStatus bool
+	}{
+		{name: "status retained", stripStatus: false, expectStatus: true},
+		{name: "status stripped", stripStatus: true, expectStatus: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			writer := &recordingWriter{}
+			config := internalconfig.PipelineConfig{
+				Name:        "pods.v1.",
+				Events:      []string{"ADDED", "MODIFIED", "DELETED"},
+				StripStatus: tc.stripStatus,
+			}
+			ri := newRegisterInformerStep(log, nil, nil, nil, config, internalconfig.GlobalSettings{}, writer, "")
+
+			obj := newTestObject("v1", "Pod", "default", "pod-a")
+			_ = unstructured.SetNestedField(obj.Object, "Running", "status", "phase")
+			ri.GetEventHandlers().AddFunc(obj)
+
+			if len(writer.objects) != 1 {
+				t.Fatalf("expected 1 emitted object, got %d", len(writer.objects))
+			}
+			hasStatus := writer.objects[0].Status != nil && writer.objects[0].Status.Attribute != ""
+			if hasStatus != tc.expectStatus {
+				t.Errorf("expected status present %t, got %t", tc.expectStatus, hasStatus)
+			}
+			if _, found, _ := unstructured.NestedString(obj.Object, "status", "phase"); !found {
+				t.Error("informer object must not be mutated by transformers")
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package pipeline
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	internalconfig "github.com/meshery/meshsync/internal/config"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/sys"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// WasmTransformer runs the transform function of a WebAssembly module over the JSON of the object.
+// The module exports its memory and the functions
+//
+//	alloc(size i32) i32                  reserves size bytes for the input
+//	transform(ptr i32, len i32) i64      returns the transformed JSON, its pointer in the upper
+//	                                     and its length in the lower 32 bits
+//
+// Every call runs in a fresh instance of the module, bounded by the timeout and the memory limit,
+// the module has no access to the host.
+type WasmTransformer struct {
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+	timeout  time.Duration
+}
+
+func NewWasmTransformer(wasm []byte, timeout time.Duration, maxMemoryMiB int) (*WasmTransformer, error) {
+	ctx := context.Background()
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithCloseOnContextDone(true).
+		// a page is 64KiB
+		WithMemoryLimitPages(uint32(maxMemoryMiB)*16),
+	)
+
+	compiled, err := runtime.CompileModule(ctx, wasm)
+	if err != nil {
+		_ = runtime.Close(ctx)
+		return nil, err
+	}
+	if err := validateWasmExports(compiled); err != nil {
+		_ = runtime.Close(ctx)
+		return nil, err
+	}
+
+	return &WasmTransformer{
+		runtime:  runtime,
+		compiled: compiled,
+		timeout:  timeout,
+	}, nil
+}
+
+func validateWasmExports(compiled wazero.CompiledModule) error {
+	if _, ok := compiled.ExportedMemories()["memory"]; !ok {
+		return errors.New("wasm module does not export its memory")
+	}
+	for _, name := range []string{"alloc", "transform"} {
+		if _, ok := compiled.ExportedFunctions()[name]; !ok {
+			return fmt.Errorf("wasm module does not export %s", name)
+		}
+	}
+	return nil
+}
+
+func (t *WasmTransformer) Transform(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	in, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), t.timeout)
+	defer cancel()
+	out, err := t.call(ctx, in)
+	if err != nil {
+		var exitErr *sys.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == sys.ExitCodeDeadlineExceeded {
+			return nil, fmt.Errorf("wasm transform timed out after %s", t.timeout)
+		}
+		return nil, err
+	}
+
+	result := &unstructured.Unstructured{}
+	if err := result.UnmarshalJSON(out); err != nil {
+		return nil, fmt.Errorf("wasm transform returned an invalid object: %w", err)
+	}
+	return result, nil
+}
+
+// call runs transform over in within a fresh instance of the module
+func (t *WasmTransformer) call(ctx context.Context, in []byte) ([]byte, error) {
+	mod, err := t.runtime.InstantiateModule(ctx, t.compiled, wazero.NewModuleConfig().WithName(""))
+	if err != nil {
+		return nil, err
+	}
+	defer mod.Close(context.Background())
+
+	allocated, err := mod.ExportedFunction("alloc").Call(ctx, uint64(len(in)))
+	if err != nil {
+		return nil, err
+	}
+	ptr := uint32(allocated[0])
+	if !mod.Memory().Write(ptr, in) {
+		return nil, errors.New("wasm alloc returned memory out of range")
+	}
+
+	transformed, err := mod.ExportedFunction("transform").Call(ctx, uint64(ptr), uint64(len(in)))
+	if err != nil {
+		return nil, err
+	}
+	out, ok := mod.Memory().Read(uint32(transformed[0]>>32), uint32(transformed[0]))
+	if !ok {
+		return nil, errors.New("wasm transform returned memory out of range")
+	}
+	// the memory is released with the instance
+	return append([]byte{}, out...), nil
+}
+
+// Close releases the compiled module
+func (t *WasmTransformer) Close() error {
+	return t.runtime.Close(context.Background())
+}
+
+// wasmTransformers shares the compiled modules between the pipelines, which are recreated on every resync,
+// by the settings of their transformer
+var wasmTransformers = struct {
+	sync.Mutex
+	loaded map[string]loadedWasm
+}{loaded: make(map[string]loadedWasm)}
+
+// loadedWasm is a compiled module and the digest of the file it was compiled from
+type loadedWasm struct {
+	digest      [sha256.Size]byte
+	transformer *WasmTransformer
+}
+
+// wasmTransformerFor loads the module the pipeline is configured with.
+// The file is read again every time, a module replaced on disk is compiled anew
+// and the one compiled from its previous contents is released.
+func wasmTransformerFor(config internalconfig.PipelineConfig) (*WasmTransformer, error) {
+	key := fmt.Sprintf("%s|%s|%d", config.WasmModule, config.WasmTimeout, config.WasmMaxMemoryMiB)
+
+	wasm, err := os.ReadFile(config.WasmModule)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(wasm)
+
+	wasmTransformers.Lock()
+	defer wasmTransformers.Unlock()
+	previous, ok := wasmTransformers.loaded[key]
+	if ok && previous.digest == digest {
+		return previous.transformer, nil
+	}
+
+	t, err := NewWasmTransformer(wasm, config.WasmTimeout, config.WasmMaxMemoryMiB)
+	if err != nil {
+		return nil, fmt.Errorf("invalid wasm module %s: %w", config.WasmModule, err)
+	}
+	if ok {
+		// the pipelines using it have been stopped by the resync
+		_ = previous.transformer.Close()
+	}
+	wasmTransformers.loaded[key] = loadedWasm{digest: digest, transformer: t}
+	return t, nil
+}
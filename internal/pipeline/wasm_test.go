@@ -0,0 +1,168 @@
+package pipeline
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meshery/meshkit/broker"
+	"github.com/meshery/meshkit/errors"
+	internalconfig "github.com/meshery/meshsync/internal/config"
+)
+
+// wasmModule assembles a module exporting its memory, a bump allocator as alloc
+// and the given body as transform(ptr i32, len i32) i64
+func wasmModule(transformBody ...byte) []byte {
+	module := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	section := func(id byte, content ...byte) {
+		module = append(module, id, byte(len(content)))
+		module = append(module, content...)
+	}
+
+	section(0x01, 0x02,
+		0x60, 0x01, 0x7f, 0x01, 0x7f, // (i32) -> i32
+		0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7e, // (i32, i32) -> i64
+	)
+	section(0x03, 0x02, 0x00, 0x01)
+	section(0x05, 0x01, 0x00, 0x01)
+	// the heap starts at 1024
+	section(0x06, 0x01, 0x7f, 0x01, 0x41, 0x80, 0x08, 0x0b)
+
+	exports := []byte{0x03}
+	for _, export := range []struct {
+		name  string
+		kind  byte
+		index byte
+	}{{"memory", 0x02, 0}, {"alloc", 0x00, 0}, {"transform", 0x00, 1}} {
+		exports = append(exports, byte(len(export.name)))
+		exports = append(exports, export.name...)
+		exports = append(exports, export.kind, export.index)
+	}
+	section(0x07, exports...)
+
+	// alloc returns the heap pointer and moves it by size
+	alloc := []byte{0x00, 0x23, 0x00, 0x23, 0x00, 0x20, 0x00, 0x6a, 0x24, 0x00, 0x0b}
+	transform := append([]byte{0x00}, transformBody...)
+	code := []byte{0x02, byte(len(alloc))}
+	code = append(code, alloc...)
+	code = append(code, byte(len(transform)))
+	code = append(code, transform...)
+	section(0x0a, code...)
+	return module
+}
+
+// returns its input: ptr << 32 | len
+var identityTransform = []byte{0x20, 0x00, 0xad, 0x42, 0x20, 0x86, 0x20, 0x01, 0xad, 0x84, 0x0b}
+
+// loops forever
+var slowTransform = []byte{0x03, 0x40, 0x0c, 0x00, 0x0b, 0x42, 0x00, 0x0b}
+
+func writeWasmModule(t *testing.T, module []byte) string {
+	path := filepath.Join(t.TempDir(), "transform.wasm")
+	if err := os.WriteFile(path, module, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestWasmIdentityTransform(t *testing.T) {
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:             "pods.v1.",
+		Events:           []string{"ADDED", "MODIFIED", "DELETED"},
+		WasmModule:       writeWasmModule(t, wasmModule(identityTransform...)),
+		WasmTimeout:      time.Second,
+		WasmMaxMemoryMiB: internalconfig.DefaultWasmMaxMemoryMiB,
+	}, internalconfig.GlobalSettings{}, writer, "")
+
+	pod := newTestObject("v1", "Pod", "default", "pod-a")
+	pod.SetLabels(map[string]string{"app": "web"})
+	if err := ri.publishItem(pod, broker.Add, ri.config); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	unchanged := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:   "pods.v1.",
+		Events: []string{"ADDED", "MODIFIED", "DELETED"},
+	}, internalconfig.GlobalSettings{}, writer, "")
+	if err := unchanged.publishItem(pod, broker.Add, unchanged.config); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	objects := writer.writtenObjects()
+	if len(objects) != 2 {
+		t.Fatalf("expected 2 emitted objects, got %d", len(objects))
+	}
+	if !reflect.DeepEqual(objects[0], objects[1]) {
+		t.Errorf("expected the object to pass through unchanged, got %+v, expected %+v", objects[0], objects[1])
+	}
+}
+
+func TestWasmModuleReloadedWhenReplaced(t *testing.T) {
+	path := writeWasmModule(t, wasmModule(identityTransform...))
+	config := internalconfig.PipelineConfig{
+		Name:             "pods.v1.",
+		WasmModule:       path,
+		WasmTimeout:      100 * time.Millisecond,
+		WasmMaxMemoryMiB: internalconfig.DefaultWasmMaxMemoryMiB,
+	}
+	loaded, err := wasmTransformerFor(config)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if again, err := wasmTransformerFor(config); err != nil || again != loaded {
+		t.Errorf("expected the unchanged module to be shared, got %v", err)
+	}
+
+	// the module is replaced in place, f.e. by an update of its ConfigMap
+	if err := os.WriteFile(path, wasmModule(slowTransform...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := wasmTransformerFor(config)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if reloaded == loaded {
+		t.Fatal("expected the replaced module to be compiled anew")
+	}
+	if _, err := reloaded.Transform(newTestObject("v1", "Pod", "default", "pod-a")); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected the replaced module to run, got %v", err)
+	}
+}
+
+func TestWasmTransformTimeout(t *testing.T) {
+	writer := &recordingWriter{}
+	ri := newRegisterInformerStep(newTestLogger(t), nil, nil, nil, internalconfig.PipelineConfig{
+		Name:             "pods.v1.",
+		Events:           []string{"ADDED", "MODIFIED", "DELETED"},
+		WasmModule:       writeWasmModule(t, wasmModule(slowTransform...)),
+		WasmTimeout:      50 * time.Millisecond,
+		WasmMaxMemoryMiB: internalconfig.DefaultWasmMaxMemoryMiB,
+	}, internalconfig.GlobalSettings{}, writer, "")
+
+	done := make(chan error)
+	go func() {
+		done <- ri.publishItem(newTestObject("v1", "Pod", "default", "pod-a"), broker.Add, ri.config)
+	}()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(errors.GetSDescription(err), "timed out") {
+			t.Errorf("expected the slow module to time out, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the slow module was not interrupted")
+	}
+	if count := len(writer.writtenObjects()); count != 0 {
+		t.Errorf("expected no emitted objects, got %d", count)
+	}
+}
+
+func TestInvalidWasmModule(t *testing.T) {
+	_, err := NewWasmTransformer([]byte("not wasm"), time.Second, internalconfig.DefaultWasmMaxMemoryMiB)
+	if err == nil {
+		t.Error("expected error for an invalid module")
+	}
+}
@@ -0,0 +1,67 @@
+package rpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// Client calls the RPCs of ServiceName
+type Client struct {
+	conn grpc.ClientConnInterface
+}
+
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{conn: conn}
+}
+
+func (c *Client) GetConfig(ctx context.Context, opts ...grpc.CallOption) (*structpb.Struct, error) {
+	out := new(structpb.Struct)
+	if err := c.conn.Invoke(ctx, GetConfigMethod, &emptypb.Empty{}, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) ListPipelines(ctx context.Context, opts ...grpc.CallOption) (*structpb.ListValue, error) {
+	out := new(structpb.ListValue)
+	if err := c.conn.Invoke(ctx, ListPipelinesMethod, &emptypb.Empty{}, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) GetFingerprint(ctx context.Context, opts ...grpc.CallOption) (string, error) {
+	out := new(wrapperspb.StringValue)
+	if err := c.conn.Invoke(ctx, GetFingerprintMethod, &emptypb.Empty{}, out, opts...); err != nil {
+		return "", err
+	}
+	return out.GetValue(), nil
+}
+
+func (c *Client) ResyncObject(ctx context.Context, resource, namespace, name string, opts ...grpc.CallOption) error {
+	in, err := structpb.NewStruct(map[string]interface{}{
+		"resource":  resource,
+		"namespace": namespace,
+		"name":      name,
+	})
+	if err != nil {
+		return err
+	}
+	return c.conn.Invoke(ctx, ResyncObjectMethod, in, &emptypb.Empty{}, opts...)
+}
+
+func (c *Client) TriggerFullSync(ctx context.Context, opts ...grpc.CallOption) (map[string]int, error) {
+	out := new(structpb.Struct)
+	if err := c.conn.Invoke(ctx, TriggerFullSyncMethod, &emptypb.Empty{}, out, opts...); err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(out.GetFields()))
+	for resource, count := range out.GetFields() {
+		counts[resource] = int(count.GetNumberValue())
+	}
+	return counts, nil
+}
@@ -0,0 +1,13 @@
+package rpc
+
+import (
+	"github.com/meshery/meshkit/errors"
+)
+
+const (
+	ErrServeCode = "1020"
+)
+
+func ErrServe(address string, err error) error {
+	return errors.New(ErrServeCode, errors.Alert, []string{"Error while serving gRPC on: " + address, err.Error()}, []string{}, []string{"The address is in use or not valid."}, []string{"Configure a free address to serve gRPC on."})
+}
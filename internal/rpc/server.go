@@ -0,0 +1,28 @@
+package rpc
+
+import (
+	"net"
+
+	"github.com/meshery/meshkit/logger"
+	"google.golang.org/grpc"
+)
+
+// Serve serves the service exposing state on address until stopCh is closed
+func Serve(log logger.Handler, address string, state State, stopCh <-chan struct{}) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return ErrServe(address, err)
+	}
+	server := grpc.NewServer()
+	Register(server, state)
+
+	go func() {
+		<-stopCh
+		server.GracefulStop()
+	}()
+	log.Infof("Serving gRPC on %s", listener.Addr())
+	if err := server.Serve(listener); err != nil {
+		return ErrServe(address, err)
+	}
+	return nil
+}
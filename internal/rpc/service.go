@@ -0,0 +1,233 @@
+// Package rpc exposes the state of a running MeshSync as a gRPC service.
+//
+// The messages are the well-known google.protobuf.Struct and google.protobuf.ListValue types
+// holding the JSON representations of config.MeshsyncConfig and pipeline.PipelineStatus,
+// and a google.protobuf.StringValue holding the config fingerprint,
+// so clients need no generated code beyond the well-known types.
+// ResyncObject takes a google.protobuf.Struct holding the resource, namespace and name of the object,
+// TriggerFullSync returns one holding the number of objects re-emitted by resource.
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/internal/pipeline"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ServiceName is the fully qualified name of the gRPC service
+const ServiceName = "meshsync.v1.MeshSync"
+
+// full method names of the RPCs
+const (
+	GetConfigMethod       = "/" + ServiceName + "/GetConfig"
+	ListPipelinesMethod   = "/" + ServiceName + "/ListPipelines"
+	GetFingerprintMethod  = "/" + ServiceName + "/GetFingerprint"
+	ResyncObjectMethod    = "/" + ServiceName + "/ResyncObject"
+	TriggerFullSyncMethod = "/" + ServiceName + "/TriggerFullSync"
+)
+
+// State is the state of MeshSync the service exposes, implemented by meshsync.Handler
+type State interface {
+	// ResolvedConfig returns the configuration the pipelines run with, nil until it is resolved
+	ResolvedConfig() *config.MeshsyncConfig
+	// PipelineStatuses returns the status of every pipeline ordered by name
+	PipelineStatuses() []pipeline.PipelineStatus
+	// ResyncObject re-emits a single object of the resource, f.e. pods.v1.
+	ResyncObject(resource, namespace, name string) error
+	// TriggerFullSync re-emits the cached objects of every pipeline and returns their number by resource
+	TriggerFullSync(ctx context.Context) (map[string]int, error)
+}
+
+// Service implements the RPCs of ServiceName
+type Service interface {
+	// GetConfig returns the resolved config.MeshsyncConfig
+	GetConfig(ctx context.Context, in *emptypb.Empty) (*structpb.Struct, error)
+	// ListPipelines returns a pipeline.PipelineStatus per pipeline ordered by name
+	ListPipelines(ctx context.Context, in *emptypb.Empty) (*structpb.ListValue, error)
+	// GetFingerprint returns the config.ConfigFingerprint of the resolved configuration
+	GetFingerprint(ctx context.Context, in *emptypb.Empty) (*wrapperspb.StringValue, error)
+	// ResyncObject re-emits the object of the resource, namespace and name fields of in
+	ResyncObject(ctx context.Context, in *structpb.Struct) (*emptypb.Empty, error)
+	// TriggerFullSync re-emits the cached objects of every pipeline, returning their number by resource
+	TriggerFullSync(ctx context.Context, in *emptypb.Empty) (*structpb.Struct, error)
+}
+
+// Register registers the service exposing state on the server
+func Register(server *grpc.Server, state State) {
+	server.RegisterService(&serviceDesc, &service{state: state})
+}
+
+type service struct {
+	state State
+}
+
+func (s *service) GetConfig(context.Context, *emptypb.Empty) (*structpb.Struct, error) {
+	meshsyncConfig := s.state.ResolvedConfig()
+	if meshsyncConfig == nil {
+		return nil, status.Error(codes.Unavailable, "the configuration is not resolved yet")
+	}
+	var fields map[string]interface{}
+	if err := convert(meshsyncConfig, &fields); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	config, err := structpb.NewStruct(fields)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return config, nil
+}
+
+func (s *service) ListPipelines(context.Context, *emptypb.Empty) (*structpb.ListValue, error) {
+	var statuses []interface{}
+	if err := convert(s.state.PipelineStatuses(), &statuses); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	pipelines, err := structpb.NewList(statuses)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return pipelines, nil
+}
+
+func (s *service) GetFingerprint(context.Context, *emptypb.Empty) (*wrapperspb.StringValue, error) {
+	meshsyncConfig := s.state.ResolvedConfig()
+	if meshsyncConfig == nil {
+		return nil, status.Error(codes.Unavailable, "the configuration is not resolved yet")
+	}
+	return wrapperspb.String(config.ConfigFingerprint(meshsyncConfig)), nil
+}
+
+func (s *service) ResyncObject(_ context.Context, in *structpb.Struct) (*emptypb.Empty, error) {
+	fields := in.GetFields()
+	resource := fields["resource"].GetStringValue()
+	name := fields["name"].GetStringValue()
+	if resource == "" || name == "" {
+		return nil, status.Error(codes.InvalidArgument, "the resource and the name of the object are required")
+	}
+	if err := s.state.ResyncObject(resource, fields["namespace"].GetStringValue(), name); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *service) TriggerFullSync(ctx context.Context, _ *emptypb.Empty) (*structpb.Struct, error) {
+	counts, err := s.state.TriggerFullSync(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Aborted, err.Error())
+	}
+	var fields map[string]interface{}
+	if err := convert(counts, &fields); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	result, err := structpb.NewStruct(fields)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return result, nil
+}
+
+// convert round-trips value through its JSON representation into the generic types structpb accepts
+func convert(value interface{}, into interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, into)
+}
+
+// serviceDesc is written by hand in place of protoc generated code, as the messages are well-known types
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*Service)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetConfig",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(emptypb.Empty)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				if interceptor == nil {
+					return srv.(Service).GetConfig(ctx, in)
+				}
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: GetConfigMethod}
+				return interceptor(ctx, in, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(Service).GetConfig(ctx, req.(*emptypb.Empty))
+				})
+			},
+		},
+		{
+			MethodName: "ListPipelines",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(emptypb.Empty)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				if interceptor == nil {
+					return srv.(Service).ListPipelines(ctx, in)
+				}
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: ListPipelinesMethod}
+				return interceptor(ctx, in, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(Service).ListPipelines(ctx, req.(*emptypb.Empty))
+				})
+			},
+		},
+		{
+			MethodName: "GetFingerprint",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(emptypb.Empty)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				if interceptor == nil {
+					return srv.(Service).GetFingerprint(ctx, in)
+				}
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: GetFingerprintMethod}
+				return interceptor(ctx, in, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(Service).GetFingerprint(ctx, req.(*emptypb.Empty))
+				})
+			},
+		},
+		{
+			MethodName: "ResyncObject",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(structpb.Struct)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				if interceptor == nil {
+					return srv.(Service).ResyncObject(ctx, in)
+				}
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: ResyncObjectMethod}
+				return interceptor(ctx, in, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(Service).ResyncObject(ctx, req.(*structpb.Struct))
+				})
+			},
+		},
+		{
+			MethodName: "TriggerFullSync",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(emptypb.Empty)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				if interceptor == nil {
+					return srv.(Service).TriggerFullSync(ctx, in)
+				}
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: TriggerFullSyncMethod}
+				return interceptor(ctx, in, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(Service).TriggerFullSync(ctx, req.(*emptypb.Empty))
+				})
+			},
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
@@ -0,0 +1,183 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/internal/pipeline"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type testState struct {
+	config   *config.MeshsyncConfig
+	statuses []pipeline.PipelineStatus
+	// the objects re-emitted by ResyncObject as resource/namespace/name
+	resynced *[]string
+	counts   map[string]int
+	err      error
+}
+
+func (s testState) ResolvedConfig() *config.MeshsyncConfig {
+	return s.config
+}
+
+func (s testState) PipelineStatuses() []pipeline.PipelineStatus {
+	return s.statuses
+}
+
+func (s testState) ResyncObject(resource, namespace, name string) error {
+	if s.err != nil {
+		return s.err
+	}
+	*s.resynced = append(*s.resynced, resource+"/"+namespace+"/"+name)
+	return nil
+}
+
+func (s testState) TriggerFullSync(context.Context) (map[string]int, error) {
+	return s.counts, s.err
+}
+
+// newTestClient serves state in-process and returns a client connected to it
+func newTestClient(t *testing.T, state State) *Client {
+	t.Helper()
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	Register(server, state)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return NewClient(conn)
+}
+
+func TestGetConfig(t *testing.T) {
+	meshsyncConfig, err := config.PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\",\"DELETED\"],\"Mask\":[\"$.spec.nodeName\"]}]",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newTestClient(t, testState{config: meshsyncConfig})
+
+	response, err := client.GetConfig(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected map[string]interface{}
+	if err := convert(meshsyncConfig, &expected); err != nil {
+		t.Fatal(err)
+	}
+	if got := response.AsMap(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected config %v, got %v", expected, got)
+	}
+}
+
+func TestGetConfigUnresolved(t *testing.T) {
+	client := newTestClient(t, testState{})
+
+	_, err := client.GetConfig(context.Background())
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("expected %s while the config is not resolved, got %v", codes.Unavailable, err)
+	}
+}
+
+func TestListPipelines(t *testing.T) {
+	staleSince := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	statuses := []pipeline.PipelineStatus{
+		{Name: "pods.v1.", Synced: true, Objects: 12},
+		{Name: "services.v1.", Synced: true, Objects: 3, Stale: true, StaleSince: &staleSince},
+	}
+	client := newTestClient(t, testState{statuses: statuses})
+
+	response, err := client.ListPipelines(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []interface{}{
+		map[string]interface{}{"name": "pods.v1.", "synced": true, "objects": float64(12), "stale": false},
+		map[string]interface{}{"name": "services.v1.", "synced": true, "objects": float64(3), "stale": true, "stale_since": "2024-01-02T03:04:05Z"},
+	}
+	if got := response.AsSlice(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected pipelines %v, got %v", expected, got)
+	}
+}
+
+func TestGetFingerprint(t *testing.T) {
+	meshsyncConfig, err := config.PopulateConfigsFromMap(map[string]string{
+		"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newTestClient(t, testState{config: meshsyncConfig})
+
+	fingerprint, err := client.GetFingerprint(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := config.ConfigFingerprint(meshsyncConfig); fingerprint != expected {
+		t.Errorf("expected fingerprint %s, got %s", expected, fingerprint)
+	}
+
+	if _, err := newTestClient(t, testState{}).GetFingerprint(context.Background()); status.Code(err) != codes.Unavailable {
+		t.Errorf("expected %s while the config is not resolved, got %v", codes.Unavailable, err)
+	}
+}
+
+func TestResyncObject(t *testing.T) {
+	resynced := make([]string, 0)
+	client := newTestClient(t, testState{resynced: &resynced})
+
+	if err := client.ResyncObject(context.Background(), "pods.v1.", "default", "pod-a"); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"pods.v1./default/pod-a"}; !reflect.DeepEqual(resynced, expected) {
+		t.Errorf("expected the re-emitted objects %v, got %v", expected, resynced)
+	}
+	if err := client.ResyncObject(context.Background(), "pods.v1.", "default", ""); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected %s without the name of the object, got %v", codes.InvalidArgument, err)
+	}
+
+	failing := newTestClient(t, testState{err: errors.New("no pipeline watches services.v1.")})
+	if err := failing.ResyncObject(context.Background(), "services.v1.", "default", "svc-a"); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected %s for an object which cannot be re-emitted, got %v", codes.FailedPrecondition, err)
+	}
+}
+
+func TestTriggerFullSync(t *testing.T) {
+	counts := map[string]int{"pods.v1.": 12, "services.v1.": 3}
+	client := newTestClient(t, testState{counts: counts})
+
+	response, err := client.TriggerFullSync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(response, counts) {
+		t.Errorf("expected the counts %v, got %v", counts, response)
+	}
+
+	running := newTestClient(t, testState{err: errors.New("another full sync is running")})
+	if _, err := running.TriggerFullSync(context.Background()); status.Code(err) != codes.Aborted {
+		t.Errorf("expected %s while another full sync runs, got %v", codes.Aborted, err)
+	}
+}
@@ -28,6 +28,12 @@ var (
 	outputMode        string
 	outputFileName    string
 	stopAfterDuration time.Duration
+	skipServedCheck   bool
+	readOnlyCheck     string
+	grpcAddress       string
+	clientQPS         float64
+	clientBurst       int
+	noClientRateLimit bool
 )
 
 func main() {
@@ -53,8 +59,13 @@ func main() {
 		libmeshsync.WithVersion(version),
 		libmeshsync.WithPingEndpoint(pingEndpoint),
 		libmeshsync.WithMeshkitConfigProvider(provider),
+		libmeshsync.WithSkipServedResourcesCheck(skipServedCheck),
+		libmeshsync.WithReadOnlyCheck(readOnlyCheck),
+		libmeshsync.WithGRPCAddress(grpcAddress),
+		libmeshsync.WithClientRateLimit(float32(clientQPS), clientBurst),
+		clientRateLimitSetter(),
 	); err != nil {
-		log.Error(err)
+		log.Error(config.MeshkitError(err))
 		os.Exit(1)
 	}
 }
@@ -98,6 +109,42 @@ func parseFlags() {
 		-1,
 		"stop meshsync execution after specified duration, excepts value which is parsable by time.ParseDuration,  f.e. 8s",
 	)
+	flag.BoolVar(
+		&skipServedCheck,
+		"skipServedResourcesCheck",
+		false,
+		"do not check that the cluster serves the whitelisted resources before watching them, f.e. when running offline",
+	)
+	flag.StringVar(
+		&readOnlyCheck,
+		"readOnlyCheck",
+		config.ReadOnlyCheckOff,
+		fmt.Sprintf("check at startup that meshsync is not permitted to modify the watched resources: \"%s\" logs a warning, \"%s\" refuses to start", config.ReadOnlyCheckWarn, config.ReadOnlyCheckFail),
+	)
+	flag.StringVar(
+		&grpcAddress,
+		"grpcAddress",
+		"",
+		"address to serve the resolved config and the pipeline statuses on over gRPC, f.e. \":11000\", empty does not serve gRPC",
+	)
+	flag.Float64Var(
+		&clientQPS,
+		"kubeQPS",
+		config.DefaultClientQPS,
+		"sustained requests per second to the kubernetes API server, raise it when the initial lists of a large cluster get throttled",
+	)
+	flag.IntVar(
+		&clientBurst,
+		"kubeBurst",
+		config.DefaultClientBurst,
+		"requests to the kubernetes API server allowed at once above kubeQPS",
+	)
+	flag.BoolVar(
+		&noClientRateLimit,
+		"noKubeRateLimit",
+		false,
+		"do not rate limit the requests to the kubernetes API server, leaving their flow control to the API server",
+	)
 
 	// Parse the command=line flags to get the output mode
 	flag.Parse()
@@ -111,3 +158,11 @@ func parseFlags() {
 		}
 	}
 }
+
+// clientRateLimitSetter disables the client-side rate limit if requested, nil keeps it
+func clientRateLimitSetter() libmeshsync.OptionsSetter {
+	if noClientRateLimit {
+		return libmeshsync.WithoutClientRateLimit()
+	}
+	return nil
+}
@@ -27,9 +27,25 @@ func (h *Handler) startDiscovery(pipelineCh chan struct{}) {
 		h.Log.Error(ErrGetObject(err))
 		return
 	}
+	settings := config.GlobalSettings{}
+	err = h.Config.GetObject(config.GlobalSettingsKey, &settings)
+	if err != nil {
+		h.Log.Error(ErrGetObject(err))
+		return
+	}
+
+	pipelineConfigs, inactive, err := config.ActivePipelines(pipelineConfigs, h.kubeClient.KubeClient.Discovery())
+	if err != nil {
+		h.Log.Error(err)
+	}
+	for _, name := range inactive {
+		// activated on the resync following the installation of the CRDs they require
+		h.Log.Infof("Skipping pipeline %s, its required resources are not served", name)
+	}
 
 	h.Log.Info("Pipeline started")
-	pl := pipeline.New(h.Log, h.informer, h.outputWriter, pipelineConfigs, pipelineCh, h.clusterID)
+	schemas := h.kubeClient.KubeClient.Discovery().OpenAPIV3()
+	pl := pipeline.New(h.Log, h.informer, h.kubeClient.DynamicKubeClient, h.outputWriter, pipelineConfigs, settings, pipelineCh, h.clusterID, h.statuses, h.resyncer, schemas, h.phases)
 	result := pl.Run()
 	h.stores = result.Data.(map[string]cache.Store)
 	if result.Error != nil {
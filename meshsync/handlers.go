@@ -3,6 +3,7 @@ package meshsync
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/meshery/meshkit/broker"
@@ -10,10 +11,10 @@ import (
 	"github.com/meshery/meshkit/utils/kubernetes"
 	"github.com/meshery/meshsync/internal/channels"
 	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/internal/pipeline"
 	"github.com/meshery/meshsync/pkg/model"
 	"golang.org/x/net/context"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
@@ -40,6 +41,10 @@ func (h *Handler) Run() {
 		}
 	}()
 
+	livenessCh := make(chan struct{})
+	defer close(livenessCh)
+	h.startLiveness(livenessCh)
+
 	go h.startDiscovery(pipelineCh)
 
 	debouncedStartDiscovery := debounce(time.Second*5, func(pipelinechannel chan struct{}) {
@@ -69,6 +74,19 @@ loop:
 	h.Log.Info("Stopping Run")
 }
 
+// startLiveness emits the liveness events until stopCh is closed, across the restarts of the pipelines.
+// The interval is the one configured when the handler starts.
+func (h *Handler) startLiveness(stopCh chan struct{}) {
+	settings := config.GlobalSettings{}
+	if err := h.Config.GetObject(config.GlobalSettingsKey, &settings); err != nil {
+		h.Log.Error(ErrGetObject(err))
+		return
+	}
+	if settings.LivenessInterval > 0 {
+		go pipeline.EmitLiveness(h.Log, h.outputWriter, settings.LivenessInterval, stopCh)
+	}
+}
+
 func (h *Handler) UpdateInformer() error {
 	dynamicClient, err := dynamic.NewForConfig(&h.kubeClient.RestConfig)
 	if err != nil {
@@ -196,23 +214,10 @@ loop:
 	h.Log.Info("Stopping ListenToRequests")
 }
 
+// listStoreObjects returns the cached objects of the pipelines transformed like their events,
+// so the request path does not leak what the pipelines mask, drop or redact
 func (h *Handler) listStoreObjects() []model.KubernetesResource {
-	objects := make([]interface{}, 0)
-	for _, v := range h.stores {
-		objects = append(objects, v.List()...)
-	}
-	parsedObjects := make([]model.KubernetesResource, 0)
-	for _, obj := range objects {
-		parsedObjects = append(
-			parsedObjects,
-			model.ParseList(
-				*obj.(*unstructured.Unstructured),
-				broker.Add,
-				h.clusterID,
-			),
-		)
-	}
-	return parsedObjects
+	return h.resyncer.CachedObjects()
 }
 
 // TODO
@@ -235,69 +240,166 @@ func (h *Handler) WatchCRDs() {
 		return
 	}
 
-	processEvent := func(event watch.Event) {
-		crd := &kubernetes.CRDItem{}
-		byt, err := json.Marshal(event.Object)
-		if err != nil {
-			h.Log.Error(err)
-			return
+loop:
+	for {
+		select {
+		case <-h.channelPool[channels.Stop].(channels.StopChannel):
+			break loop
+		case event := <-crdWatcher.ResultChan():
+			h.handleCRDEvent(event)
 		}
+	}
+	h.Log.Info("Stopping WatchCRDs")
+}
 
-		err = json.Unmarshal(byt, crd)
-		if err != nil {
-			h.Log.Error(err)
-			return
-		}
+// handleCRDEvent adds the pipeline of an added CRD to the configured pipelines, removes the one of a deleted CRD,
+// and resyncs the informers
+func (h *Handler) handleCRDEvent(event watch.Event) {
+	crd := &kubernetes.CRDItem{}
+	byt, err := json.Marshal(event.Object)
+	if err != nil {
+		h.Log.Error(err)
+		return
+	}
 
-		gvr := kubernetes.GetGVRForCustomResources(crd)
+	err = json.Unmarshal(byt, crd)
+	if err != nil {
+		h.Log.Error(err)
+		return
+	}
 
-		existingPipelines := config.Pipelines
-		err = h.Config.GetObject(config.ResourcesKey, existingPipelines)
-		if err != nil {
-			h.Log.Error(err)
-			return
-		}
+	gvr := kubernetes.GetGVRForCustomResources(crd)
+
+	// decoded into a map of its own, the registry of config.Pipelines must not change
+	existingPipelines := make(map[string]config.PipelineConfigs)
+	err = h.Config.GetObject(config.ResourcesKey, &existingPipelines)
+	if err != nil {
+		h.Log.Error(err)
+		return
+	}
+
+	existingPipelineConfigs := existingPipelines[config.GlobalResourceKey]
+
+	configName := fmt.Sprintf("%s.%s.%s", gvr.Resource, gvr.Version, gvr.Group)
+	updatedPipelineConfigs := existingPipelineConfigs
+
+	switch event.Type {
+	case watch.Added:
+		// No need to verify if config is already added because If the config already exists then it indicates the informer has already synced that resource.
+		// Any subsequent updates will have event type as "modified"
+		updatedPipelineConfigs = existingPipelineConfigs.Add(config.PipelineConfig{
+			Name:      configName,
+			PublishTo: config.DefaultPublishingSubject,
+			Events:    []string{"ADDED", "MODIFIED", "DELETED"},
+		})
+	case watch.Deleted:
+		updatedPipelineConfigs = existingPipelineConfigs.Delete(config.PipelineConfig{
+			Name: configName,
+		})
+	}
+	existingPipelines[config.GlobalResourceKey] = updatedPipelineConfigs
+	err = h.Config.SetObject(config.ResourcesKey, existingPipelines)
+	if err != nil {
+		h.Log.Error(err)
+		h.Log.Info("skipping informer resync")
+		return
+	}
+	h.Log.Info("Resyncing informer from watch crd")
+	h.channelPool[channels.ReSync].(channels.ReSyncChannel).ReSyncInformer()
+}
 
-		existingPipelineConfigs := existingPipelines[config.GlobalResourceKey]
+// WatchConfigMap re-resolves the configuration whenever the ConfigMap holding the watch-list changes,
+// ref is nil if the configuration does not come from a ConfigMap. The watcher follows the reference
+// of the re-resolved configurations, f.e. when the Custom Resource references another ConfigMap.
+func (h *Handler) WatchConfigMap(ref *config.ConfigMapRef) {
+	h.watchConfigMap(ref)
+	<-h.channelPool[channels.Stop].(channels.StopChannel)
+	h.watchConfigMap(nil)
+	h.Log.Info("Stopping WatchConfigMap")
+}
 
-		configName := fmt.Sprintf("%s.%s.%s", gvr.Resource, gvr.Version, gvr.Group)
-		updatedPipelineConfigs := existingPipelineConfigs
+// watchConfigMap restarts the ConfigMap watcher if ref is not the watched reference, a nil ref stops it
+func (h *Handler) watchConfigMap(ref *config.ConfigMapRef) {
+	h.configMapMu.Lock()
+	defer h.configMapMu.Unlock()
+	if reflect.DeepEqual(h.configMapRef, ref) {
+		return
+	}
+	if h.configMapStopCh != nil {
+		h.Log.Info("Stopping the watcher of the ConfigMap ", h.configMapRef.Namespace, "/", h.configMapRef.Name)
+		close(h.configMapStopCh)
+		h.configMapStopCh = nil
+	}
+	h.configMapRef = ref
+	if ref == nil {
+		return
+	}
+	h.Log.Info("Watching the ConfigMap ", ref.Namespace, "/", ref.Name, " holding the watch-list")
+	h.configMapStopCh = make(chan struct{})
+	config.WatchConfigMap(h.kubeClient.KubeClient, *ref, h.configMapStopCh, h.applyWatchList)
+}
 
-		switch event.Type {
-		case watch.Added:
-			// No need to verify if config is already added because If the config already exists then it indicates the informer has already synced that resource.
-			// Any subsequent updates will have event type as "modified"
-			updatedPipelineConfigs = existingPipelineConfigs.Add(config.PipelineConfig{
-				Name:      configName,
-				PublishTo: config.DefaultPublishingSubject,
-				Events:    []string{"ADDED", "MODIFIED", "DELETED"},
-			})
-		case watch.Deleted:
-			updatedPipelineConfigs = existingPipelineConfigs.Delete(config.PipelineConfig{
-				Name: configName,
-			})
-		}
-		existingPipelines[config.GlobalResourceKey] = updatedPipelineConfigs
-		err = h.Config.SetObject(config.ResourcesKey, existingPipelines)
-		if err != nil {
+// WatchCRDConfig re-resolves the configuration whenever the watch-list of the Custom Resource changes
+func (h *Handler) WatchCRDConfig() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-h.channelPool[channels.Stop].(channels.StopChannel)
+		cancel()
+	}()
+
+	configs := config.WatchCRDConfig(ctx, h.kubeClient.DynamicKubeClient, config.CRDWatchOptions{
+		OnError: func(err error) {
 			h.Log.Error(err)
-			h.Log.Info("skipping informer resync")
-			return
-		}
-		h.Log.Info("Resyncing informer from watch crd")
-		h.channelPool[channels.ReSync].(channels.ReSyncChannel).ReSyncInformer()
+			h.Log.Info("keeping the previous configuration of the Custom Resource")
+			h.writeValidationStatus(nil, err)
+		},
+	})
+	for meshsyncConfig := range configs {
+		h.applyWatchList(meshsyncConfig, nil)
 	}
+	h.Log.Info("Stopping WatchCRDConfig")
+}
 
-loop:
-	for {
-		select {
-		case <-h.channelPool[channels.Stop].(channels.StopChannel):
-			break loop
-		case event := <-crdWatcher.ResultChan():
-			processEvent(event)
-		}
+// applyWatchList restarts the pipelines with the re-resolved configuration
+// and reports whether it was applied or rejected in the status of the Custom Resource
+func (h *Handler) applyWatchList(meshsyncConfig *config.MeshsyncConfig, err error) {
+	if err != nil {
+		h.Log.Error(config.MeshkitError(err))
+		h.Log.Info("skipping informer resync")
+		h.writeValidationStatus(nil, err)
+		return
+	}
+	err = h.Config.SetObject(config.ResourcesKey, meshsyncConfig.Pipelines)
+	if err != nil {
+		h.Log.Error(err)
+		h.Log.Info("skipping informer resync")
+		return
+	}
+	err = h.Config.SetObject(config.GlobalSettingsKey, meshsyncConfig.GlobalSettings)
+	if err != nil {
+		h.Log.Error(err)
+		h.Log.Info("skipping informer resync")
+		return
+	}
+	added, removed, changed := config.DiffConfigs(h.ResolvedConfig(), meshsyncConfig)
+	h.Log.Infof("The re-resolved watch-list adds %v, removes %v and changes the events of %v", added, removed, changed)
+	h.SetResolvedConfig(meshsyncConfig)
+	h.writeValidationStatus(meshsyncConfig, nil)
+	if !utils.IsClosed[struct{}](h.channelPool[channels.Stop].(channels.StopChannel)) {
+		h.watchConfigMap(meshsyncConfig.Source)
+	}
+	h.Log.Info("Resyncing informer from the re-resolved watch-list")
+	h.channelPool[channels.ReSync].(channels.ReSyncChannel).ReSyncInformer()
+}
+
+// writeValidationStatus reports the result of resolving the watch-list in the status of the Custom Resource,
+// err being the error the watch-list was rejected with
+func (h *Handler) writeValidationStatus(meshsyncConfig *config.MeshsyncConfig, err error) {
+	validation := config.NewValidationResult(meshsyncConfig, err)
+	if errStatus := config.WriteValidationStatus(context.Background(), h.kubeClient.DynamicKubeClient, validation); errStatus != nil {
+		h.Log.Warn(errStatus)
 	}
-	h.Log.Info("Stopping WatchCRDs")
 }
 
 // TODO: move this to meshkit
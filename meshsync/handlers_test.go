@@ -4,7 +4,16 @@ import (
 	"reflect"
 	"testing"
 
+	configprovider "github.com/meshery/meshkit/config/provider"
+	"github.com/meshery/meshkit/logger"
+	mesherykube "github.com/meshery/meshkit/utils/kubernetes"
+	"github.com/meshery/meshsync/internal/channels"
+	"github.com/meshery/meshsync/internal/config"
 	"github.com/meshery/meshsync/pkg/model"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 // TestSplitIntoMultipleSlices tests the splitIntoMultipleSlices function
@@ -50,3 +59,101 @@ func TestSplitIntoMultipleSlices(t *testing.T) {
 		})
 	}
 }
+
+func TestWatchConfigMapFollowsTheReference(t *testing.T) {
+	log, err := logger.New("meshsync-test", logger.Options{Format: logger.SyslogLogFormat})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the watchers are only started and stopped, they need no reachable cluster
+	h := &Handler{Log: log, kubeClient: &mesherykube.Client{KubeClient: kubernetes.NewForConfigOrDie(&rest.Config{Host: "http://127.0.0.1:1"})}}
+
+	h.watchConfigMap(&config.ConfigMapRef{Namespace: "meshery", Name: "watch-list"})
+	first := h.configMapStopCh
+	// the same reference keeps the watcher
+	h.watchConfigMap(&config.ConfigMapRef{Namespace: "meshery", Name: "watch-list"})
+	if h.configMapStopCh != first {
+		t.Fatal("expected the watcher of an unchanged reference to keep running")
+	}
+
+	h.watchConfigMap(&config.ConfigMapRef{Namespace: "meshery", Name: "other-watch-list"})
+	select {
+	case <-first:
+	default:
+		t.Fatal("expected the watcher of the previous reference to be stopped")
+	}
+	second := h.configMapStopCh
+	if second == nil || second == first {
+		t.Fatal("expected a watcher of the new reference to be started")
+	}
+
+	h.watchConfigMap(nil)
+	select {
+	case <-second:
+	default:
+		t.Fatal("expected the watcher to be stopped once the configuration no longer comes from a ConfigMap")
+	}
+	if h.configMapStopCh != nil {
+		t.Error("expected no watcher to run")
+	}
+}
+
+// copyPipelines copies the registry deeply enough to compare it after it may have changed
+func copyPipelines(pipelines map[string]config.PipelineConfigs) map[string]config.PipelineConfigs {
+	copied := make(map[string]config.PipelineConfigs, len(pipelines))
+	for key, configs := range pipelines {
+		copied[key] = append(config.PipelineConfigs{}, configs...)
+	}
+	return copied
+}
+
+func TestHandleCRDEventKeepsTheRegistry(t *testing.T) {
+	log, err := logger.New("meshsync-test", logger.Options{Format: logger.SyslogLogFormat})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := configprovider.NewViper(configprovider.Options{FilePath: t.TempDir(), FileType: "yaml", FileName: "meshsync_config"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolved := map[string]config.PipelineConfigs{
+		config.GlobalResourceKey: {{Name: "namespaces.v1.", PublishTo: config.DefaultPublishingSubject, Events: []string{"ADDED"}}},
+		config.LocalResourceKey:  {{Name: "pods.v1.", PublishTo: config.DefaultPublishingSubject, Events: []string{"ADDED"}}},
+	}
+	if err := cfg.SetObject(config.ResourcesKey, resolved); err != nil {
+		t.Fatal(err)
+	}
+	pool := channels.NewChannelPool()
+	pool[channels.ReSync] = channels.ReSyncChannel(make(chan struct{}, 1))
+	h := &Handler{Log: log, Config: cfg, channelPool: pool}
+
+	registry := copyPipelines(config.Pipelines)
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"spec": map[string]interface{}{
+			"group":    "example.com",
+			"names":    map[string]interface{}{"plural": "widgets"},
+			"versions": []interface{}{map[string]interface{}{"name": "v1"}},
+		},
+	}}
+	h.handleCRDEvent(watch.Event{Type: watch.Added, Object: crd})
+
+	if !reflect.DeepEqual(config.Pipelines, registry) {
+		t.Error("expected the registry of the pipelines to be left unchanged")
+	}
+	pipelines := make(map[string]config.PipelineConfigs)
+	if err := cfg.GetObject(config.ResourcesKey, &pipelines); err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0)
+	for _, pc := range pipelines[config.GlobalResourceKey] {
+		names = append(names, pc.Name)
+	}
+	if expected := []string{"namespaces.v1.", "widgets.v1.example.com"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected the global pipelines %v, got %v", expected, names)
+	}
+	if len(pipelines[config.LocalResourceKey]) != 1 {
+		t.Errorf("expected the local pipelines to be kept, got %v", pipelines[config.LocalResourceKey])
+	}
+}
@@ -1,12 +1,17 @@
 package meshsync
 
 import (
+	"context"
+	"sync"
+
 	"github.com/meshery/meshkit/broker"
 	"github.com/meshery/meshkit/config"
 	"github.com/meshery/meshkit/logger"
 	mesherykube "github.com/meshery/meshkit/utils/kubernetes"
 	"github.com/meshery/meshsync/internal/channels"
+	internalconfig "github.com/meshery/meshsync/internal/config"
 	"github.com/meshery/meshsync/internal/output"
+	"github.com/meshery/meshsync/internal/pipeline"
 	iutils "github.com/meshery/meshsync/pkg/utils"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/dynamic"
@@ -27,6 +32,17 @@ type Handler struct {
 	channelPool  map[string]channels.GenericChannel
 	stores       map[string]cache.Store
 	outputWriter output.Writer
+	statuses     *pipeline.StatusTracker
+	resyncer     *pipeline.ObjectResyncer
+	phases       pipeline.PhaseCallbacks
+
+	resolvedMu sync.RWMutex
+	resolved   *internalconfig.MeshsyncConfig
+
+	// the watcher of the ConfigMap holding the watch-list, restarted when the reference changes
+	configMapMu     sync.Mutex
+	configMapRef    *internalconfig.ConfigMapRef
+	configMapStopCh chan struct{}
 }
 
 func GetListOptionsFunc(config config.Handler) (func(*v1.ListOptions), error) {
@@ -75,9 +91,59 @@ func New(
 		kubeClient:   kubeClient,
 		clusterID:    clusterID,
 		channelPool:  pool,
+		statuses:     pipeline.NewStatusTracker(),
+		resyncer:     pipeline.NewObjectResyncer(),
 	}, nil
 }
 
 func GetDynamicInformer(config config.Handler, dynamicKubeClient dynamic.Interface, listOptionsFunc func(*v1.ListOptions)) dynamicinformer.DynamicSharedInformerFactory {
-	return dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicKubeClient, 0, v1.NamespaceAll, listOptionsFunc)
+	// the informers resync only the pipelines which have a ResyncPeriod
+	return dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicKubeClient, internalconfig.ResyncCheckPeriod, v1.NamespaceAll, listOptionsFunc)
+}
+
+// SetPhaseCallbacks sets the callbacks invoked when the pipelines move from the initial sync to the live watch,
+// it applies to the pipelines started afterwards
+func (h *Handler) SetPhaseCallbacks(callbacks pipeline.PhaseCallbacks) {
+	h.phases = callbacks
+}
+
+// PipelineStatuses returns the current status of every pipeline
+func (h *Handler) PipelineStatuses() []pipeline.PipelineStatus {
+	return h.statuses.List()
+}
+
+// ResyncObject re-emits a single object of the resource, f.e. pods.v1., when a consumer reports it out of sync.
+// It fails if the resource is not watched or the object does not exist.
+func (h *Handler) ResyncObject(resource, namespace, name string) error {
+	return h.resyncer.ResyncObject(resource, namespace, name)
+}
+
+// TriggerFullSync re-emits the cached objects of every watched resource as ADDED events, f.e. after an outage
+// of the broker, and returns the number of objects re-emitted by resource. It fails while another full sync runs.
+func (h *Handler) TriggerFullSync(ctx context.Context) (map[string]int, error) {
+	return h.resyncer.TriggerFullSync(ctx)
+}
+
+// SetResolvedConfig records the configuration the pipelines run with and emits its fingerprint,
+// so the instances which loaded different configurations can be told apart
+func (h *Handler) SetResolvedConfig(meshsyncConfig *internalconfig.MeshsyncConfig) {
+	h.resolvedMu.Lock()
+	h.resolved = meshsyncConfig
+	h.resolvedMu.Unlock()
+
+	fingerprint := internalconfig.ConfigFingerprint(meshsyncConfig)
+	h.Log.Infof("Configuration fingerprint: %s", fingerprint)
+	for _, resource := range meshsyncConfig.EffectiveResources() {
+		h.Log.Debugf("Watching %s (%s) for %v", resource.Resource, resource.Scope, resource.Events)
+	}
+	if err := output.WriteControl(h.outputWriter, output.NewConfigFingerprintEvent(fingerprint)); err != nil {
+		h.Log.Error(err)
+	}
+}
+
+// ResolvedConfig returns the configuration the pipelines run with, nil until it is set
+func (h *Handler) ResolvedConfig() *internalconfig.MeshsyncConfig {
+	h.resolvedMu.RLock()
+	defer h.resolvedMu.RUnlock()
+	return h.resolved
 }
@@ -2,6 +2,7 @@
 package meshsync
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -22,7 +23,10 @@ import (
 	"github.com/meshery/meshsync/internal/config"
 	"github.com/meshery/meshsync/internal/file"
 	"github.com/meshery/meshsync/internal/output"
+	"github.com/meshery/meshsync/internal/rpc"
 	"github.com/meshery/meshsync/meshsync"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/discovery"
 )
 
 // TODO fix cyclop error
@@ -44,17 +48,28 @@ func Run(log logger.Handler, optsSetters ...OptionsSetter) error {
 			strings.Join(AllowedOutputModes, ", "),
 		)
 	}
+	if !slices.Contains(config.ReadOnlyCheckModes, options.ReadOnlyCheck) {
+		return fmt.Errorf(
+			"unsupported read-only check mode \"%s\", supported list is [%s]",
+			options.ReadOnlyCheck,
+			strings.Join(config.ReadOnlyCheckModes[1:], ", "),
+		)
+	}
 
 	// Initialize kubeclient
 	// options.KubeConfig is nil by default
-	kubeClient, err := mesherykube.New(options.KubeConfig)
+	kubeClient, err := config.NewKubeClient(options.KubeConfig, options.ClientOptions)
 	if err != nil {
 		return err
 	}
 
-	useCRDFlag := determineUseCRDFlag(options, log, kubeClient)
+	ctx := context.Background()
+	// custom resources missing from the registry are watched in the bucket of their discovered scope
+	config.Scopes = config.NewScopeDiscovery(kubeClient.KubeClient.Discovery())
+	crd := getMeshsyncCRD(ctx, options, log, kubeClient)
+	useCRDFlag := crd != nil
 
-	crdConfigs, errGetMeshsyncCRDConfigs := getMeshsyncCRDConfigs(useCRDFlag, kubeClient)
+	crdConfigs, errGetMeshsyncCRDConfigs := getMeshsyncCRDConfigs(ctx, crd, kubeClient)
 	if errGetMeshsyncCRDConfigs != nil {
 		// no configs found from meshsync CRD log warning
 		log.Warn(err)
@@ -73,15 +88,34 @@ func Run(log logger.Handler, optsSetters ...OptionsSetter) error {
 
 	if useCRDFlag {
 		// this patch only make sense when CRD is present in cluster
-		if errPatchCRVersion := config.PatchCRVersion(&kubeClient.RestConfig); errPatchCRVersion != nil {
+		if errPatchCRVersion := config.PatchCRVersion(ctx, &kubeClient.RestConfig); errPatchCRVersion != nil {
 			log.Warn(errPatchCRVersion)
 		}
+		// report the health of the watch-list in the status of the Custom Resource
+		validation := config.NewValidationResult(crdConfigs, errGetMeshsyncCRDConfigs)
+		if errStatus := config.WriteValidationStatus(ctx, kubeClient.DynamicKubeClient, validation); errStatus != nil {
+			log.Warn(errStatus)
+		}
+	}
+
+	if errServed := checkServedResources(log, options, crdConfigs, useCRDFlag, kubeClient.KubeClient.Discovery()); errServed != nil {
+		return errServed
 	}
 
-	// pass configs from crd to default configs
+	// the pipelines resolved from the crd replace the default ones,
+	// config.Pipelines stays the registry the watch-list is resolved against on reload
+	pipelines := config.Pipelines
+	// the pipelines the sinks are opened for, once resumed the paused ones write to theirs
+	sinkPipelines := config.Pipelines
 	if crdConfigs != nil {
-		if len(crdConfigs.Pipelines) > 0 {
-			config.Pipelines = crdConfigs.Pipelines
+		if crdConfigs.Paused {
+			// the paused watch-list resolves to no pipelines, the default ones must not take over
+			log.Info("watching is paused by the MeshSync Custom Resource")
+			pipelines = crdConfigs.Pipelines
+			sinkPipelines = crdConfigs.PausedPipelines
+		} else if len(crdConfigs.Pipelines) > 0 {
+			pipelines = crdConfigs.Pipelines
+			sinkPipelines = crdConfigs.Pipelines
 		}
 
 		if len(crdConfigs.Listeners) > 0 {
@@ -89,9 +123,29 @@ func Run(log logger.Handler, optsSetters ...OptionsSetter) error {
 		}
 	}
 
+	if options.ReadOnlyCheck != config.ReadOnlyCheckOff {
+		reviews := kubeClient.KubeClient.AuthorizationV1().SelfSubjectAccessReviews()
+		if errReadOnly := config.ValidateReadOnly(ctx, pipelines, reviews); errReadOnly != nil {
+			if options.ReadOnlyCheck == config.ReadOnlyCheckFail {
+				return errReadOnly
+			}
+			log.Warn(errReadOnly)
+		}
+	}
+
 	cfg.SetKey(config.BrokerURL, os.Getenv("BROKER_URL"))
 
-	err = cfg.SetObject(config.ResourcesKey, config.Pipelines)
+	err = cfg.SetObject(config.ResourcesKey, pipelines)
+	if err != nil {
+		return err
+	}
+
+	// settings which apply to all pipelines, the local configs have none
+	globalSettings := config.GlobalSettings{}
+	if crdConfigs != nil {
+		globalSettings = crdConfigs.GlobalSettings
+	}
+	err = cfg.SetObject(config.GlobalSettingsKey, globalSettings)
 	if err != nil {
 		return err
 	}
@@ -175,14 +229,53 @@ func Run(log logger.Handler, optsSetters ...OptionsSetter) error {
 		)
 	}
 
+	sinks, closeSinks, err := openSinks(log, options, sinkPipelines)
+	if err != nil {
+		return err
+	}
+	defer closeSinks()
+	outputProcessor.RouteToSinks(sinks)
+
+	if options.InProcessSink != nil {
+		outputProcessor.AddOutput(options.InProcessSink.fanOut)
+	}
+	if options.SSESink != nil {
+		outputProcessor.AddOutput(options.SSESink.writer)
+	}
+
+	if options.TraceExporter != nil {
+		shutdownTracing := startTracing(log, options.TraceExporter, outputProcessor)
+		defer shutdownTracing()
+	}
+
 	chPool := channels.NewChannelPool()
 	meshsyncHandler, err := meshsync.New(cfg, kubeClient, log, br, outputProcessor, chPool)
 	if err != nil {
 		return err
 	}
 	defer meshsyncHandler.ShutdownInformer()
+	meshsyncHandler.SetPhaseCallbacks(options.PhaseCallbacks)
+	if crdConfigs != nil {
+		meshsyncHandler.SetResolvedConfig(crdConfigs)
+	} else {
+		meshsyncHandler.SetResolvedConfig(&config.MeshsyncConfig{Pipelines: pipelines, Listeners: config.Listeners})
+	}
+
+	if options.GRPCAddress != "" {
+		go func() {
+			if errServe := rpc.Serve(log, options.GRPCAddress, meshsyncHandler, chPool[channels.Stop].(channels.StopChannel)); errServe != nil {
+				log.Error(errServe)
+			}
+		}()
+	}
 
 	go meshsyncHandler.WatchCRDs()
+	if useCRDFlag {
+		go meshsyncHandler.WatchCRDConfig()
+	}
+	if crdConfigs != nil {
+		go meshsyncHandler.WatchConfigMap(crdConfigs.Source)
+	}
 
 	go meshsyncHandler.Run()
 	if options.OutputMode == config.OutputModeBroker {
@@ -267,18 +360,24 @@ func createNatsBrokerHandler(log logger.Handler, pingEndpoint string, brokerURL
 	})
 }
 
-func determineUseCRDFlag(
+// getMeshsyncCRD returns the Custom Resource, nil if it is not present in the cluster.
+// It is the only request for the Custom Resource retried on transient errors,
+// the configs are resolved from the Custom Resource it returns.
+func getMeshsyncCRD(
+	ctx context.Context,
 	options Options,
 	log logger.Handler,
 	kubeClient *mesherykube.Client,
-) bool {
+) *unstructured.Unstructured {
 	// if output mode is not nats generally it is not expected to have CRD present in cluster.
 	// theoretically CRD could be present even in file, channel output mode.
 	// hence check if CRD are present in the cluster,
 	// and only skip them if it is not present.
-	crd, errGetMeshsyncCRD := config.GetMeshsyncCRD(kubeClient.DynamicKubeClient)
-	useCRDFlag := crd != nil && errGetMeshsyncCRD == nil
-	if useCRDFlag {
+	crd, errGetMeshsyncCRD := config.GetMeshsyncCRDWithRetry(ctx, kubeClient.DynamicKubeClient, config.CRDConfigFromEnv(), options.CRDRetry)
+	if errGetMeshsyncCRD != nil {
+		crd = nil
+	}
+	if crd != nil {
 		log.Infof(
 			"running in %s output mode and meshsync CRD is present in the cluster",
 			options.OutputMode,
@@ -290,13 +389,35 @@ func determineUseCRDFlag(
 		)
 	}
 
-	return useCRDFlag
+	return crd
 }
 
-func getMeshsyncCRDConfigs(useCRDFlag bool, kubeClient *mesherykube.Client) (*config.MeshsyncConfig, error) {
-	if useCRDFlag {
+// checkServedResources fails if the watch-list of the Custom Resource names a resource the cluster does not serve,
+// unless the check is skipped for offline use.
+// The local watch-list includes optional CRDs (f.e. grafana, prometheus) whose informers start syncing
+// once the CRD gets installed, hence its unserved resources are only warned about.
+func checkServedResources(
+	log logger.Handler,
+	options Options,
+	crdConfigs *config.MeshsyncConfig,
+	fromCR bool,
+	client discovery.DiscoveryInterface,
+) error {
+	if crdConfigs == nil || options.SkipServedResourcesCheck {
+		return nil
+	}
+	err := config.ValidateServedResources(crdConfigs, client)
+	if err != nil && !fromCR {
+		log.Warn(err)
+		return nil
+	}
+	return err
+}
+
+func getMeshsyncCRDConfigs(ctx context.Context, crd *unstructured.Unstructured, kubeClient *mesherykube.Client) (*config.MeshsyncConfig, error) {
+	if crd != nil {
 		// get configs from meshsync crd if available
-		return config.GetMeshsyncCRDConfigs(kubeClient.DynamicKubeClient)
+		return config.GetMeshsyncCRDConfigsFrom(ctx, kubeClient.DynamicKubeClient, crd)
 	}
 	// get configs from local variable
 	return config.GetMeshsyncCRDConfigsLocal()
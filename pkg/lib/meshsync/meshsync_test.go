@@ -0,0 +1,121 @@
+package meshsync
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meshery/meshkit/errors"
+	"github.com/meshery/meshkit/logger"
+	mesherykube "github.com/meshery/meshkit/utils/kubernetes"
+	"github.com/meshery/meshsync/internal/config"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestGetMeshsyncCRDConfigsRetriesOnce(t *testing.T) {
+	crdConfig := config.DefaultCRDConfig
+	cr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": crdConfig.Group + "/" + crdConfig.Version,
+		"kind":       "MeshSync",
+		"metadata":   map[string]interface{}{"name": crdConfig.Name, "namespace": crdConfig.Namespace},
+		"spec": map[string]interface{}{
+			"watch-list": map[string]interface{}{
+				"data": map[string]interface{}{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]"},
+			},
+		},
+	}}
+	dyClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		crdConfig.GVR(): "MeshSyncList",
+	}, cr)
+	// the first requests for the Custom Resource fail while the API server is starting up
+	failures, requests := 2, 0
+	dyClient.PrependReactor("get", crdConfig.Resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		requests++
+		if requests <= failures {
+			return true, nil, apierrors.NewServiceUnavailable("starting up")
+		}
+		return false, nil, nil
+	})
+	kubeClient := &mesherykube.Client{DynamicKubeClient: dyClient}
+	log, err := logger.New("meshsync-test", logger.Options{Format: logger.SyslogLogFormat})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := DefautOptions
+	options.CRDRetry = config.RetryOptions{Retries: 3, BaseDelay: time.Millisecond}
+	crd := getMeshsyncCRD(context.Background(), options, log, kubeClient)
+	if crd == nil {
+		t.Fatal("expected the Custom Resource")
+	}
+	meshsyncConfig, err := getMeshsyncCRDConfigs(context.Background(), crd, kubeClient)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	// the Custom Resource is got once, after the retries, and the configs are resolved from it
+	if requests != failures+1 {
+		t.Errorf("expected %d requests for the Custom Resource, got %d", failures+1, requests)
+	}
+	if pipelines := meshsyncConfig.Pipelines[config.LocalResourceKey]; len(pipelines) != 1 || pipelines[0].Name != "pods.v1." {
+		t.Errorf("expected the pods.v1. pipeline of the Custom Resource, got %v", pipelines)
+	}
+}
+
+func TestCheckServedResources(t *testing.T) {
+	client := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{
+		Resources: []*metav1.APIResourceList{
+			{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "pods"}}},
+		},
+	}}
+	log, err := logger.New("meshsync-test", logger.Options{Format: logger.SyslogLogFormat})
+	if err != nil {
+		t.Fatal(err)
+	}
+	served, err := config.PopulateConfigsFromMap(map[string]string{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]}]"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unserved, err := config.PopulateConfigsFromMap(map[string]string{"whitelist": "[{\"Resource\":\"pods.v1.\",\"Events\":[\"ADDED\"]},{\"Resource\":\"statefulsets.v1.apps\",\"Events\":[\"ADDED\"]}]"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name        string
+		configs     *config.MeshsyncConfig
+		fromCR      bool
+		skip        bool
+		expectedErr bool
+	}{
+		{name: "served", configs: served, fromCR: true},
+		{name: "unserved in the Custom Resource", configs: unserved, fromCR: true, expectedErr: true},
+		{name: "unserved with the check skipped", configs: unserved, fromCR: true, skip: true},
+		{name: "unserved in the local configs", configs: unserved},
+	}
+	for _, tc := range testCases {
+		options := DefautOptions
+		options.SkipServedResourcesCheck = tc.skip
+		err := checkServedResources(log, options, tc.configs, tc.fromCR, client)
+		if !tc.expectedErr {
+			if err != nil {
+				t.Errorf("%s: unexpected error %s", tc.name, err.Error())
+			}
+			continue
+		}
+		if errors.GetCode(config.MeshkitError(err)) != config.ErrInitConfigCode {
+			t.Errorf("%s: expected error code %s, got %v", tc.name, config.ErrInitConfigCode, err)
+		}
+		if !strings.Contains(err.Error(), "statefulsets.v1.apps") {
+			t.Errorf("%s: expected the error to name statefulsets.v1.apps, got %s", tc.name, err.Error())
+		}
+	}
+}
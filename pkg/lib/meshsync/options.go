@@ -6,6 +6,7 @@ import (
 	"github.com/meshery/meshkit/broker"
 	mcp "github.com/meshery/meshkit/config/provider"
 	"github.com/meshery/meshsync/internal/config"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 type Options struct {
@@ -18,6 +19,41 @@ type Options struct {
 	Version               string
 	PingEndpoint          string
 	MeshkitConfigProvider string
+
+	// if not nil, events are additionally delivered to in-process subscribers
+	InProcessSink *InProcessSink
+
+	// if not nil, events are additionally streamed to the clients connected to it
+	SSESink *SSESink
+
+	// skips checking that the cluster serves the whitelisted resources, f.e. for offline use
+	SkipServedResourcesCheck bool
+
+	// if not nil, a span is created per emitted event and exported with it,
+	// the trace context is propagated to consumers in the event envelope
+	TraceExporter sdktrace.SpanExporter
+
+	// reviews at startup that MeshSync may not modify the watched resources,
+	// one of config.ReadOnlyCheckModes: off (empty), warn or fail
+	ReadOnlyCheck string
+
+	// invoked when the initial sync completes and live events start to flow
+	PhaseCallbacks PhaseCallbacks
+
+	// credentials of webhook sinks, if empty they are read from
+	// the WEBHOOK_TOKEN and WEBHOOK_HMAC_KEY environment variables
+	WebhookToken   string
+	WebhookHMACKey []byte
+
+	// if not empty, the resolved config and the pipeline statuses are served over gRPC on this address,
+	// f.e. ":11000"
+	GRPCAddress string
+
+	// the rate limit of the clients watching and discovering the resources
+	ClientOptions config.ClientOptions
+
+	// the retries of the requests for the Custom Resource failing with a transient error
+	CRDRetry config.RetryOptions
 }
 
 var DefautOptions = Options{
@@ -28,6 +64,8 @@ var DefautOptions = Options{
 	Version:               "Not Set",
 	PingEndpoint:          ":8222/connz",
 	MeshkitConfigProvider: mcp.ViperKey,
+	ClientOptions:         config.DefaultClientOptions,
+	CRDRetry:              config.DefaultRetryOptions,
 }
 
 var AllowedOutputModes = []string{
@@ -86,3 +124,80 @@ func WithMeshkitConfigProvider(value string) OptionsSetter {
 		o.MeshkitConfigProvider = value
 	}
 }
+
+func WithInProcessSink(value *InProcessSink) OptionsSetter {
+	return func(o *Options) {
+		o.InProcessSink = value
+	}
+}
+
+func WithSSESink(value *SSESink) OptionsSetter {
+	return func(o *Options) {
+		o.SSESink = value
+	}
+}
+
+// value is one of config.ReadOnlyCheckModes
+func WithReadOnlyCheck(value string) OptionsSetter {
+	return func(o *Options) {
+		o.ReadOnlyCheck = value
+	}
+}
+
+func WithSkipServedResourcesCheck(value bool) OptionsSetter {
+	return func(o *Options) {
+		o.SkipServedResourcesCheck = value
+	}
+}
+
+func WithTraceExporter(value sdktrace.SpanExporter) OptionsSetter {
+	return func(o *Options) {
+		o.TraceExporter = value
+	}
+}
+
+func WithPhaseCallbacks(value PhaseCallbacks) OptionsSetter {
+	return func(o *Options) {
+		o.PhaseCallbacks = value
+	}
+}
+
+// token is sent as bearer token, key signs the request body, either may be empty
+func WithWebhookCredentials(token string, hmacKey []byte) OptionsSetter {
+	return func(o *Options) {
+		o.WebhookToken = token
+		o.WebhookHMACKey = hmacKey
+	}
+}
+
+// value is a TCP address, f.e. ":11000", empty does not serve gRPC
+func WithGRPCAddress(value string) OptionsSetter {
+	return func(o *Options) {
+		o.GRPCAddress = value
+	}
+}
+
+// qps and burst limit the requests of the clients to the API server,
+// the defaults are config.DefaultClientQPS and config.DefaultClientBurst
+func WithClientRateLimit(qps float32, burst int) OptionsSetter {
+	return func(o *Options) {
+		o.ClientOptions.QPS = qps
+		o.ClientOptions.Burst = burst
+	}
+}
+
+// the requests of the clients are flow controlled by the API Priority and Fairness of the API server only
+func WithoutClientRateLimit() OptionsSetter {
+	return func(o *Options) {
+		o.ClientOptions.DisableRateLimit = true
+	}
+}
+
+// retries is how often the request for the Custom Resource is retried on transient errors, f.e. while
+// the API server starts up, baseDelay the delay before the first retry, doubled with every further one.
+// The defaults are config.DefaultRetries and config.DefaultRetryBaseDelay
+func WithCRDRetry(retries int, baseDelay time.Duration) OptionsSetter {
+	return func(o *Options) {
+		o.CRDRetry = config.RetryOptions{Retries: retries, BaseDelay: baseDelay}
+	}
+}
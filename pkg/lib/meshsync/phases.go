@@ -0,0 +1,10 @@
+package meshsync
+
+import (
+	"github.com/meshery/meshsync/internal/pipeline"
+)
+
+// PhaseCallbacks are invoked when meshsync moves from the initial sync of the watched resources
+// to watching them live, f.e. to mark a baseline before live events are received.
+// Pass them to Run with WithPhaseCallbacks.
+type PhaseCallbacks = pipeline.PhaseCallbacks
@@ -0,0 +1,174 @@
+package meshsync
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/meshery/meshkit/logger"
+	"github.com/meshery/meshsync/internal/config"
+	"github.com/meshery/meshsync/internal/file"
+	"github.com/meshery/meshsync/internal/output"
+)
+
+// openSinks opens the sinks pipelines configure in place of the global one, keyed by their URI.
+// The returned function closes all of them.
+func openSinks(
+	log logger.Handler,
+	options Options,
+	pipelines map[string]config.PipelineConfigs,
+) (map[string]output.Writer, func(), error) {
+	sinks := make(map[string]output.Writer)
+	closers := make([]func(), 0)
+	closeAll := func() {
+		for _, closeSink := range closers {
+			closeSink()
+		}
+	}
+
+	for _, configs := range pipelines {
+		for _, pc := range configs {
+			if pc.Sink == "" {
+				continue
+			}
+			if _, ok := sinks[pc.Sink]; ok {
+				continue
+			}
+			writer, closeSink, err := openSink(log, options, pc.Sink)
+			if err != nil {
+				closeAll()
+				return nil, nil, err
+			}
+			log.Infof("pipeline %s writes to sink %s", pc.Name, pc.Sink)
+			sinks[pc.Sink] = writer
+			closers = append(closers, closeSink)
+		}
+	}
+
+	return sinks, closeAll, nil
+}
+
+func openSink(log logger.Handler, options Options, uri string) (output.Writer, func(), error) {
+	u, err := config.Sinks.Validate(uri)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	writer, closeSink, err := openSinkWriter(log, options, u)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the limit applies to the sink itself, the events a queue holds on disk are not in flight
+	writer, closeSink, err = limitInflight(log, u, writer, closeSink)
+	if err != nil {
+		return nil, nil, err
+	}
+	return queueSink(log, u, writer, closeSink)
+}
+
+// limitInflight bounds the bytes of the events in flight to the sink if its URI configures a limit
+func limitInflight(log logger.Handler, u *url.URL, writer output.Writer, closeSink func()) (output.Writer, func(), error) {
+	maxBytes, err := config.SinkMaxInflightBytes(u)
+	if err != nil {
+		closeSink()
+		return nil, nil, err
+	}
+	if maxBytes == 0 {
+		return writer, closeSink, nil
+	}
+	log.Infof("sink %s is limited to %d bytes in flight", u.Redacted(), maxBytes)
+	return output.NewInflightLimiter(writer, u.Redacted(), maxBytes), closeSink, nil
+}
+
+// queueSink puts a disk-backed queue in front of the sink if its URI configures one
+func queueSink(log logger.Handler, u *url.URL, writer output.Writer, closeSink func()) (output.Writer, func(), error) {
+	settings, err := config.SinkQueueSettings(u)
+	if err != nil {
+		closeSink()
+		return nil, nil, err
+	}
+	if settings.Dir == "" {
+		return writer, closeSink, nil
+	}
+
+	queue, err := output.OpenDiskQueue(settings.Dir, writer, output.DiskQueueOptions{
+		MaxBytes:     settings.MaxBytes,
+		Overflow:     settings.Overflow,
+		RetryBackoff: time.Second,
+		OnError:      log.Error,
+	})
+	if err != nil {
+		closeSink()
+		return nil, nil, err
+	}
+	log.Infof("sink %s is queued in %s", u.Redacted(), settings.Dir)
+
+	closeQueue := func() {
+		// the events not delivered yet are replayed on the next start
+		if err := queue.Close(); err != nil {
+			log.Error(err)
+		}
+		closeSink()
+	}
+	return queue, closeQueue, nil
+}
+
+func openSinkWriter(log logger.Handler, options Options, u *url.URL) (output.Writer, func(), error) {
+	switch u.Scheme {
+	case config.SinkSchemeNATS:
+		br, err := createNatsBrokerHandler(log, options.PingEndpoint, u.Host)
+		if err != nil {
+			return nil, nil, err
+		}
+		return output.NewBrokerWriter(br), br.CloseConnection, nil
+	case config.SinkSchemeFile:
+		fw, err := file.NewYAMLWriter(config.SinkFilePath(u))
+		if err != nil {
+			return nil, nil, err
+		}
+		return output.NewFileWriter(fw), func() { _ = fw.Close() }, nil
+	case config.SinkSchemeHTTP, config.SinkSchemeHTTPS:
+		return openWebhookSink(log, options, u)
+	}
+
+	return nil, nil, fmt.Errorf("no writer for sink %q", u.String())
+}
+
+func openWebhookSink(log logger.Handler, options Options, u *url.URL) (output.Writer, func(), error) {
+	settings, err := config.SinkWebhookSettings(u)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	token := options.WebhookToken
+	if token == "" {
+		token = os.Getenv("WEBHOOK_TOKEN")
+	}
+	hmacKey := options.WebhookHMACKey
+	if len(hmacKey) == 0 {
+		hmacKey = []byte(os.Getenv("WEBHOOK_HMAC_KEY"))
+	}
+
+	target := config.SinkWebhookURL(u)
+	ctx, cancel := context.WithCancel(context.Background())
+	sink := output.NewWebhookSink(ctx, target, output.WebhookOptions{
+		Token:         token,
+		HMACKey:       hmacKey,
+		BatchSize:     settings.BatchSize,
+		FlushInterval: settings.FlushInterval,
+		MaxRetries:    settings.MaxRetries,
+		RetryBackoff:  time.Second,
+		Timeout:       settings.Timeout,
+		OnError:       log.Error,
+	})
+
+	closeSink := func() {
+		if err := sink.Flush(); err != nil {
+			log.Error(err)
+		}
+		cancel()
+	}
+	return sink, closeSink, nil
+}
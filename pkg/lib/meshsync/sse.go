@@ -0,0 +1,29 @@
+package meshsync
+
+import (
+	"net/http"
+
+	"github.com/meshery/meshsync/internal/output"
+)
+
+// SSEEvent is the data of every server-sent event, the event name is the event type
+type SSEEvent = output.SSEEvent
+
+// SSESink streams meshsync events to browsers as server-sent events.
+// Pass it to Run with WithSSESink and serve it on a mux of your own, f.e.
+// mux.Handle("/events", sink); clients select events with the "resource" and "namespace" query params.
+type SSESink struct {
+	writer *output.SSEWriter
+}
+
+// NewSSESink returns a sink buffering up to bufferSize events per client,
+// clients not keeping up are disconnected; zero uses output.DefaultSSEBufferSize
+func NewSSESink(bufferSize int) *SSESink {
+	return &SSESink{
+		writer: output.NewSSEWriter(bufferSize),
+	}
+}
+
+func (s *SSESink) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	s.writer.ServeHTTP(rw, r)
+}
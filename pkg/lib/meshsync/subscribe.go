@@ -0,0 +1,30 @@
+package meshsync
+
+import (
+	"github.com/meshery/meshsync/internal/output"
+)
+
+// Event is delivered to in-process subscribers for every object meshsync outputs
+type Event = output.Event
+
+// EventFilter selects the events a subscriber receives, nil selects all
+type EventFilter = output.EventFilter
+
+// InProcessSink allows to consume meshsync events within the same process, without a broker.
+// Pass it to Run with WithInProcessSink; subscriptions may be added before or while meshsync runs.
+type InProcessSink struct {
+	fanOut *output.FanOutWriter
+}
+
+func NewInProcessSink() *InProcessSink {
+	return &InProcessSink{
+		fanOut: output.NewFanOutWriter(output.DefaultFanOutBufferSize),
+	}
+}
+
+// Subscribe registers a consumer receiving the events matching filter.
+// The consumer must keep reading from the channel, a consumer falling output.DefaultFanOutBufferSize events
+// behind is evicted and its channel closed; the returned function cancels the subscription and closes the channel.
+func (s *InProcessSink) Subscribe(filter EventFilter) (<-chan Event, func()) {
+	return s.fanOut.Subscribe(filter)
+}
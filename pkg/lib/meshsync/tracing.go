@@ -0,0 +1,21 @@
+package meshsync
+
+import (
+	"context"
+
+	"github.com/meshery/meshkit/logger"
+	"github.com/meshery/meshsync/internal/output"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// startTracing makes the processor trace every emitted event with the exporter.
+// The returned function flushes the pending spans and stops the exporter.
+func startTracing(log logger.Handler, exporter sdktrace.SpanExporter, processor *output.Processor) func() {
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+	processor.TraceWith(tp)
+	return func() {
+		if err := tp.Shutdown(context.Background()); err != nil {
+			log.Error(err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// Canonicalize rewrites the object into its canonical form, so that serializing the same object
+// always yields the same bytes: embedded JSON documents are re-encoded compactly with sorted keys,
+// labels and annotations are sorted by key.
+func (obj *KubernetesResource) Canonicalize() {
+	documents := []*string{&obj.Data, &obj.BinaryData, &obj.StringData}
+	if obj.Spec != nil {
+		documents = append(documents, &obj.Spec.Attribute)
+	}
+	if obj.Status != nil {
+		documents = append(documents, &obj.Status.Attribute)
+	}
+	if meta := obj.KubernetesResourceMeta; meta != nil {
+		documents = append(documents, &meta.OwnerReferences, &meta.Finalizers, &meta.ManagedFields)
+		sortKeyValues(meta.Labels)
+		sortKeyValues(meta.Annotations)
+	}
+
+	for _, document := range documents {
+		if canonical, ok := canonicalJSON(*document); ok {
+			*document = canonical
+		}
+	}
+}
+
+// canonicalJSON re-encodes the JSON document with sorted object keys and without insignificant whitespace,
+// documents which are not valid JSON are reported as such
+func canonicalJSON(document string) (string, bool) {
+	if document == "" {
+		return "", false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(document)))
+	// keep numbers as written, float64 would change large integers
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", false
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	// encoding/json writes map keys in sorted order
+	if err := encoder.Encode(value); err != nil {
+		return "", false
+	}
+	return string(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))), true
+}
+
+func sortKeyValues(values []*KubernetesKeyValue) {
+	sort.SliceStable(values, func(i, j int) bool {
+		return values[i].Key < values[j].Key
+	})
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestCanonicalizeIsDeterministic(t *testing.T) {
+	resource := func(spec string, labelKeys ...string) KubernetesResource {
+		labels := make([]*KubernetesKeyValue, 0, len(labelKeys))
+		for _, key := range labelKeys {
+			labels = append(labels, &KubernetesKeyValue{Kind: KindLabel, Key: key, Value: "v-" + key})
+		}
+		return KubernetesResource{
+			Kind:                   "Deployment",
+			KubernetesResourceMeta: &KubernetesResourceObjectMeta{Name: "web", Labels: labels, OwnerReferences: `[{"uid":"1", "kind":"X"}]`},
+			Spec:                   &KubernetesResourceSpec{Attribute: spec},
+			Status:                 &KubernetesResourceStatus{Attribute: "not json"},
+		}
+	}
+
+	variants := []KubernetesResource{
+		resource(`{"replicas":3,"template":{"b":"<x>","a":12345678901234567890}}`, "app", "tier"),
+		resource(`{ "template": {"a": 12345678901234567890, "b": "<x>"}, "replicas": 3 }`, "tier", "app"),
+	}
+
+	var expected []byte
+	for run := 0; run < 3; run++ {
+		for _, variant := range variants {
+			obj := variant
+			meta := *variant.KubernetesResourceMeta
+			meta.Labels = append([]*KubernetesKeyValue{}, variant.KubernetesResourceMeta.Labels...)
+			obj.KubernetesResourceMeta = &meta
+			spec := *variant.Spec
+			obj.Spec = &spec
+
+			obj.Canonicalize()
+			serialized, err := json.Marshal(obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected == nil {
+				expected = serialized
+				continue
+			}
+			if !bytes.Equal(serialized, expected) {
+				t.Fatalf("expected identical serialization\n%s\ngot\n%s", expected, serialized)
+			}
+		}
+	}
+
+	obj := variants[1]
+	obj.Canonicalize()
+	if obj.Spec.Attribute != `{"replicas":3,"template":{"a":12345678901234567890,"b":"<x>"}}` {
+		t.Errorf("unexpected canonical spec %s", obj.Spec.Attribute)
+	}
+	if obj.Status.Attribute != "not json" {
+		t.Errorf("expected invalid JSON to be left untouched, got %s", obj.Status.Attribute)
+	}
+}
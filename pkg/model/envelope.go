@@ -0,0 +1,110 @@
+package model
+
+// deletion cause hints, best-effort guesses attached to DELETE events
+const (
+	DeletionHintUnknown      = "unknown"
+	DeletionHintOwnerRemoved = "owner-removed"
+	DeletionHintTTL          = "ttl"
+	DeletionHintManual       = "manual"
+)
+
+// Envelope schema versions. Consumers pin the version they understand,
+// MeshSync then leaves out everything introduced after it.
+//
+//	v1: the object only, no envelope is emitted
+//	v2: envelope with schema_version and deletion_hint
+//	v3: adds namespace_labels and namespace_annotations
+//	v4: adds trace_context
+//	v5: adds health
+const (
+	SchemaVersionV1 = "v1"
+	SchemaVersionV2 = "v2"
+	SchemaVersionV3 = "v3"
+	SchemaVersionV4 = "v4"
+	SchemaVersionV5 = "v5"
+
+	LatestSchemaVersion = SchemaVersionV5
+)
+
+// SchemaVersions lists the supported envelope schema versions, oldest first
+var SchemaVersions = []string{SchemaVersionV1, SchemaVersionV2, SchemaVersionV3, SchemaVersionV4, SchemaVersionV5}
+
+// IsSupportedSchemaVersion reports whether MeshSync is able to emit the given version,
+// empty stands for the latest version
+func IsSupportedSchemaVersion(version string) bool {
+	if version == "" {
+		return true
+	}
+	for _, v := range SchemaVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// SchemaVersionIncludes reports whether the schema version includes what was introduced in since,
+// empty stands for the latest version
+func SchemaVersionIncludes(version, since string) bool {
+	if version == "" {
+		version = LatestSchemaVersion
+	}
+	for _, v := range SchemaVersions {
+		if v == since {
+			return true
+		}
+		if v == version {
+			return false
+		}
+	}
+	return false
+}
+
+// Envelope carries information MeshSync attaches to an event in addition to the object itself.
+// It is not persisted.
+type Envelope struct {
+	// version of the envelope schema, allows consumers to detect the field set
+	SchemaVersion string `json:"schema_version,omitempty"`
+	// why the object was deleted, only set on DELETE events
+	DeletionHint string `json:"deletion_hint,omitempty"`
+	// metadata of the object's namespace, only set when namespace enrichment is enabled
+	NamespaceLabels      map[string]string `json:"namespace_labels,omitempty"`
+	NamespaceAnnotations map[string]string `json:"namespace_annotations,omitempty"`
+	// W3C trace context of the span emitting the event, only set when tracing is enabled
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+	// normalized health of the object, see HealthEvaluator, only set when health evaluation is enabled
+	// and an evaluator is registered for the kind of the object
+	Health string `json:"health,omitempty"`
+}
+
+// Versioned returns the envelope as emitted in the given schema version,
+// empty stands for the latest version. nil means no envelope is emitted.
+func (e *Envelope) Versioned(version string) *Envelope {
+	if e == nil {
+		e = &Envelope{}
+	}
+	switch version {
+	case SchemaVersionV1:
+		return nil
+	case SchemaVersionV2:
+		return &Envelope{
+			SchemaVersion: SchemaVersionV2,
+			DeletionHint:  e.DeletionHint,
+		}
+	case SchemaVersionV3:
+		versioned := *e
+		versioned.SchemaVersion = SchemaVersionV3
+		versioned.TraceContext = nil
+		versioned.Health = ""
+		return &versioned
+	case SchemaVersionV4:
+		versioned := *e
+		versioned.SchemaVersion = SchemaVersionV4
+		versioned.Health = ""
+		return &versioned
+	default:
+		versioned := *e
+		versioned.SchemaVersion = SchemaVersionV5
+		return &versioned
+	}
+}
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// normalized health of an object, attached to the envelope when health evaluation is enabled
+const (
+	HealthHealthy  = "Healthy"
+	HealthDegraded = "Degraded"
+	HealthUnknown  = "Unknown"
+)
+
+// HealthEvaluator computes the normalized health of an object of the kind it is registered for,
+// one of HealthHealthy, HealthDegraded or HealthUnknown
+type HealthEvaluator func(obj *unstructured.Unstructured) string
+
+var (
+	healthEvaluatorsMu sync.RWMutex
+	// the evaluators by group and kind, kinds without evaluator get no health
+	healthEvaluators = map[schema.GroupKind]HealthEvaluator{
+		{Group: "apps", Kind: "Deployment"}: deploymentHealth,
+		{Kind: "Pod"}:                       podHealth,
+		{Kind: "PersistentVolumeClaim"}:     persistentVolumeClaimHealth,
+	}
+)
+
+// RegisterHealthEvaluator sets the evaluator of the kind, replacing the built-in one if any
+func RegisterHealthEvaluator(groupKind schema.GroupKind, evaluator HealthEvaluator) {
+	healthEvaluatorsMu.Lock()
+	defer healthEvaluatorsMu.Unlock()
+	healthEvaluators[groupKind] = evaluator
+}
+
+// EvaluateHealth returns the health of the object and whether an evaluator is registered for its kind
+func EvaluateHealth(obj *unstructured.Unstructured) (string, bool) {
+	healthEvaluatorsMu.RLock()
+	evaluator, ok := healthEvaluators[obj.GroupVersionKind().GroupKind()]
+	healthEvaluatorsMu.RUnlock()
+	if !ok {
+		return "", false
+	}
+	return evaluator(obj), true
+}
+
+// deploymentHealth compares the available and updated replicas to the desired ones,
+// a status not yet observing the latest spec is unknown
+func deploymentHealth(obj *unstructured.Unstructured) string {
+	if _, ok, _ := unstructured.NestedMap(obj.Object, "status"); !ok {
+		return HealthUnknown
+	}
+	if observed, _, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration"); observed < obj.GetGeneration() {
+		return HealthUnknown
+	}
+	replicas, ok, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !ok {
+		// defaulted by the API server
+		replicas = 1
+	}
+	available, _, _ := unstructured.NestedInt64(obj.Object, "status", "availableReplicas")
+	updated, _, _ := unstructured.NestedInt64(obj.Object, "status", "updatedReplicas")
+	if available < replicas || updated < replicas {
+		return HealthDegraded
+	}
+	return HealthHealthy
+}
+
+// podHealth derives the health from the phase, a running pod is healthy once it is ready
+func podHealth(obj *unstructured.Unstructured) string {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	switch phase {
+	case "Succeeded":
+		return HealthHealthy
+	case "Failed":
+		return HealthDegraded
+	case "Running":
+		if conditionStatus(obj, "Ready") == "True" {
+			return HealthHealthy
+		}
+		return HealthDegraded
+	default:
+		// Pending or not reported yet
+		return HealthUnknown
+	}
+}
+
+// persistentVolumeClaimHealth derives the health from the phase, a claim which lost its volume is degraded
+func persistentVolumeClaimHealth(obj *unstructured.Unstructured) string {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	switch phase {
+	case "Bound":
+		return HealthHealthy
+	case "Lost":
+		return HealthDegraded
+	default:
+		return HealthUnknown
+	}
+}
+
+// conditionStatus returns the status of the condition of the given type, empty if it is missing
+func conditionStatus(obj *unstructured.Unstructured, conditionType string) string {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if !ok || condition["type"] != conditionType {
+			continue
+		}
+		status, _ := condition["status"].(string)
+		return status
+	}
+	return ""
+}
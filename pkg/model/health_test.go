@@ -0,0 +1,74 @@
+package model
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func newDeployment(replicas, available, updated int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "default", "generation": int64(2)},
+		"spec":       map[string]interface{}{"replicas": replicas},
+		"status": map[string]interface{}{
+			"observedGeneration": int64(2),
+			"replicas":           replicas,
+			"availableReplicas":  available,
+			"updatedReplicas":    updated,
+		},
+	}}
+}
+
+func TestDeploymentHealth(t *testing.T) {
+	stale := newDeployment(3, 3, 3)
+	stale.SetGeneration(3)
+	withoutStatus := newDeployment(3, 0, 0)
+	delete(withoutStatus.Object, "status")
+
+	testCases := []struct {
+		name       string
+		deployment *unstructured.Unstructured
+		expected   string
+	}{
+		{name: "all replicas available", deployment: newDeployment(3, 3, 3), expected: HealthHealthy},
+		{name: "replicas unavailable", deployment: newDeployment(3, 1, 3), expected: HealthDegraded},
+		{name: "rollout not complete", deployment: newDeployment(3, 3, 1), expected: HealthDegraded},
+		{name: "scaled to zero", deployment: newDeployment(0, 0, 0), expected: HealthHealthy},
+		{name: "status of a previous generation", deployment: stale, expected: HealthUnknown},
+		{name: "no status", deployment: withoutStatus, expected: HealthUnknown},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			health, ok := EvaluateHealth(tc.deployment)
+			if !ok {
+				t.Fatal("expected an evaluator for deployments")
+			}
+			if health != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, health)
+			}
+		})
+	}
+}
+
+func TestRegisterHealthEvaluator(t *testing.T) {
+	certificate := &unstructured.Unstructured{}
+	certificate.SetAPIVersion("cert-manager.io/v1")
+	certificate.SetKind("Certificate")
+	if _, ok := EvaluateHealth(certificate); ok {
+		t.Fatal("expected no evaluator for certificates")
+	}
+
+	groupKind := schema.GroupKind{Group: "cert-manager.io", Kind: "Certificate"}
+	RegisterHealthEvaluator(groupKind, func(obj *unstructured.Unstructured) string { return HealthDegraded })
+	defer func() {
+		healthEvaluatorsMu.Lock()
+		delete(healthEvaluators, groupKind)
+		healthEvaluatorsMu.Unlock()
+	}()
+	if health, ok := EvaluateHealth(certificate); !ok || health != HealthDegraded {
+		t.Errorf("expected the registered evaluator to report %s, got %q", HealthDegraded, health)
+	}
+}
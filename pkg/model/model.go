@@ -27,6 +27,8 @@ type KubernetesResource struct {
 	BinaryData string `json:"binaryData,omitempty"`
 	StringData string `json:"stringData,omitempty"`
 	Type       string `json:"type,omitempty"`
+
+	Envelope *Envelope `json:"envelope,omitempty" gorm:"-"`
 }
 
 type KubernetesKeyValue struct {